@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -53,10 +55,9 @@ func (c *Client) doRequest(method, requestPath string, data interface{}) ([]byte
 		body = bytes.NewReader(jsonData)
 	}
 
-	// 使用 url.JoinPath 安全拼接 URL
-	fullURL, err := url.JoinPath(c.baseURL, requestPath)
+	fullURL, err := c.buildURL(requestPath)
 	if err != nil {
-		return nil, fmt.Errorf("拼接URL失败: %w", err)
+		return nil, err
 	}
 
 	req, err := http.NewRequest(method, fullURL, body)
@@ -86,6 +87,20 @@ func (c *Client) doRequest(method, requestPath string, data interface{}) ([]byte
 	return respBody, nil
 }
 
+// buildURL 拼接请求URL，requestPath 可带查询参数
+func (c *Client) buildURL(requestPath string) (string, error) {
+	// 使用 url.JoinPath 安全拼接路径，查询参数单独拼接以免 "?" 被转义
+	p, query, _ := strings.Cut(requestPath, "?")
+	fullURL, err := url.JoinPath(c.baseURL, p)
+	if err != nil {
+		return "", fmt.Errorf("拼接URL失败: %w", err)
+	}
+	if query != "" {
+		fullURL += "?" + query
+	}
+	return fullURL, nil
+}
+
 type Instance struct {
 	Name    string         `json:"name"`
 	Status  string         `json:"status"`
@@ -94,28 +109,93 @@ type Instance struct {
 }
 
 type InstanceConfig struct {
-	Name       string         `json:"name"`
-	Type       string         `json:"type"`
-	Listen     string         `json:"listen"`
-	Target     string         `json:"target"`
-	Protocol   string         `json:"protocol"`
-	Auth       string         `json:"auth,omitempty"`
-	Enabled    bool           `json:"enabled"`
-	ClientCA   []string       `json:"clientCa,omitempty"`
-	ServerCA   []string       `json:"serverCa,omitempty"`
-	TLCP       *TLCPConfig    `json:"tlcp,omitempty"`
-	TLS        *TLSConfig     `json:"tls,omitempty"`
-	HTTP       *HTTPConfig    `json:"http,omitempty"`
-	SNI        string         `json:"sni,omitempty"`
-	BufferSize int            `json:"bufferSize,omitempty"`
-	Timeout    *TimeoutConfig `json:"timeout,omitempty"`
+	Name          string               `json:"name"`
+	Type          string               `json:"type"`
+	Listen        string               `json:"listen"`
+	Target        string               `json:"target"`
+	Targets       []TargetConfig       `json:"targets,omitempty"`
+	LoadBalance   *LoadBalanceConfig   `json:"loadBalance,omitempty"`
+	Protocol      string               `json:"protocol"`
+	Auth          string               `json:"auth,omitempty"`
+	Enabled       bool                 `json:"enabled"`
+	ClientCA      []string             `json:"clientCa,omitempty"`
+	ServerCA      []string             `json:"serverCa,omitempty"`
+	CRLCheck      bool                 `json:"crlCheck,omitempty"`
+	OCSP          *OCSPConfig          `json:"ocsp,omitempty"`
+	ProxyProtocol *ProxyProtocolConfig `json:"proxyProtocol,omitempty"`
+	HealthCheck   *HealthCheckConfig   `json:"healthCheck,omitempty"`
+	TLCP          *TLCPConfig          `json:"tlcp,omitempty"`
+	TLS           *TLSConfig           `json:"tls,omitempty"`
+	HTTP          *HTTPConfig          `json:"http,omitempty"`
+	SNI           string               `json:"sni,omitempty"`
+	BufferSize    int                  `json:"bufferSize,omitempty"`
+	Timeout       *TimeoutConfig       `json:"timeout,omitempty"`
+	AccessLog     *AccessLogConfig     `json:"accessLog,omitempty"`
+	Log           *InstanceLogConfig   `json:"log,omitempty"`
+}
+
+type AccessLogConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Format     string `json:"format,omitempty"`
+	File       string `json:"file,omitempty"`
+	MaxSize    int    `json:"maxSize,omitempty"`
+	MaxBackups int    `json:"maxBackups,omitempty"`
+	MaxAge     int    `json:"maxAge,omitempty"`
+	Compress   bool   `json:"compress,omitempty"`
+}
+
+type InstanceLogConfig struct {
+	Buffer     int    `json:"buffer,omitempty"`
+	File       string `json:"file,omitempty"`
+	MaxSize    int    `json:"maxSize,omitempty"`
+	MaxBackups int    `json:"maxBackups,omitempty"`
+	MaxAge     int    `json:"maxAge,omitempty"`
+	Compress   bool   `json:"compress,omitempty"`
+}
+
+type OCSPConfig struct {
+	Enabled       bool          `json:"enabled"`
+	Responders    []string      `json:"responders,omitempty"`
+	FailurePolicy string        `json:"failurePolicy,omitempty"`
+	Timeout       time.Duration `json:"timeout,omitempty"`
+	CacheTTL      time.Duration `json:"cacheTtl,omitempty"`
+	Stapling      bool          `json:"stapling,omitempty"`
+}
+
+type TargetConfig struct {
+	Address string `json:"address"`
+	Weight  int    `json:"weight,omitempty"`
+}
+
+type LoadBalanceConfig struct {
+	Strategy    string        `json:"strategy,omitempty"`
+	MaxFails    int           `json:"maxFails,omitempty"`
+	FailTimeout time.Duration `json:"failTimeout,omitempty"`
+}
+
+type HealthCheckConfig struct {
+	Enabled          bool          `json:"enabled"`
+	Type             string        `json:"type,omitempty"`
+	Interval         time.Duration `json:"interval,omitempty"`
+	Timeout          time.Duration `json:"timeout,omitempty"`
+	FailureThreshold int           `json:"failureThreshold,omitempty"`
+	HTTPPath         string        `json:"httpPath,omitempty"`
+	History          int           `json:"history,omitempty"`
+}
+
+type ProxyProtocolConfig struct {
+	Send    string   `json:"send,omitempty"`
+	Accept  bool     `json:"accept,omitempty"`
+	Trusted []string `json:"trusted,omitempty"`
 }
 
 type TimeoutConfig struct {
-	Dial      time.Duration `json:"dial,omitempty"`
-	Read      time.Duration `json:"read,omitempty"`
-	Write     time.Duration `json:"write,omitempty"`
-	Handshake time.Duration `json:"handshake,omitempty"`
+	Dial       time.Duration `json:"dial,omitempty"`
+	Read       time.Duration `json:"read,omitempty"`
+	Write      time.Duration `json:"write,omitempty"`
+	Handshake  time.Duration `json:"handshake,omitempty"`
+	MaxSession time.Duration `json:"maxSession,omitempty"`
+	Drain      time.Duration `json:"drain,omitempty"`
 }
 
 type KeyStoreConfig struct {
@@ -206,6 +286,33 @@ func (c *Client) StopInstance(name string) error {
 	return err
 }
 
+type DrainResult struct {
+	Status      string `json:"status"`
+	Active      int    `json:"active"`
+	ForceClosed int    `json:"forceClosed"`
+	DurationMs  int64  `json:"durationMs"`
+}
+
+// DrainInstance 排空实例，timeout 为 nil 时使用实例配置的宽限期
+func (c *Client) DrainInstance(name string, timeout *int) (*DrainResult, error) {
+	path := "/api/instances/" + url.PathEscape(name) + "/drain"
+	if timeout != nil {
+		path += fmt.Sprintf("?timeout=%d", *timeout)
+	}
+	// 排空在宽限期结束后才返回，不受默认请求超时限制
+	hc := *c.httpClient
+	hc.Timeout = 0
+	data, err := (&Client{baseURL: c.baseURL, httpClient: &hc}).Post(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp DrainResult
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &resp, nil
+}
+
 func (c *Client) ReloadInstance(name string) error {
 	_, err := c.Post("/api/instances/"+url.PathEscape(name)+"/reload", nil)
 	return err
@@ -228,16 +335,132 @@ func (c *Client) InstanceStats(name string) (map[string]interface{}, error) {
 	return stats, nil
 }
 
-func (c *Client) InstanceLogs(name string) ([]map[string]interface{}, error) {
-	data, err := c.Get("/api/instances/" + url.PathEscape(name) + "/logs")
+// LogEntry 日志记录
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	// Instance 实例名称，仅日志流推送的实例日志包含
+	Instance string `json:"instance,omitempty"`
+}
+
+// LogQuery 实例日志查询条件，零值字段不作为查询参数
+type LogQuery struct {
+	// Level 最低日志级别
+	Level string
+	// Since 起始时间，RFC3339 格式或相对当前的时长如 "15m"
+	Since string
+	// Until 结束时间，格式同 Since
+	Until string
+	// Limit 最多返回的条数
+	Limit int
+}
+
+func (q LogQuery) values() url.Values {
+	v := url.Values{}
+	if q.Level != "" {
+		v.Set("level", q.Level)
+	}
+	if q.Since != "" {
+		v.Set("since", q.Since)
+	}
+	if q.Until != "" {
+		v.Set("until", q.Until)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	return v
+}
+
+type LogsResponse struct {
+	Logs []LogEntry `json:"logs"`
+}
+
+func (c *Client) InstanceLogs(name string, q LogQuery) ([]LogEntry, error) {
+	path := "/api/instances/" + url.PathEscape(name) + "/logs"
+	if v := q.values(); len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+	data, err := c.Get(path)
 	if err != nil {
 		return nil, err
 	}
-	var logs []map[string]interface{}
-	if err := json.Unmarshal(data, &logs); err != nil {
+	var resp LogsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
-	return logs, nil
+	return resp.Logs, nil
+}
+
+// StreamLogs 订阅实时日志流，逐条回调直到连接断开或回调返回错误
+// 参数:
+//   - level: 最低日志级别，为空时不过滤
+//   - instance: 实例名称，为空时推送服务日志与所有实例日志
+//   - fn: 每条日志记录的回调
+func (c *Client) StreamLogs(level, instance string, fn func(LogEntry) error) error {
+	v := url.Values{}
+	if level != "" {
+		v.Set("level", level)
+	}
+	if instance != "" {
+		v.Set("instance", instance)
+	}
+	requestPath := "/api/system/logs/stream"
+	if len(v) > 0 {
+		requestPath += "?" + v.Encode()
+	}
+	fullURL, err := c.buildURL(requestPath)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// 日志流持续到连接断开，不受默认请求超时限制
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("请求失败: %s - %s", resp.Status, string(body))
+	}
+
+	// 按 Server-Sent Events 格式解析，空行结束一个事件，忽略 ":" 开头的心跳注释
+	var event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if (event == "" || event == "log") && data != "" {
+				var entry LogEntry
+				if err := json.Unmarshal([]byte(data), &entry); err != nil {
+					return fmt.Errorf("解析日志记录失败: %w", err)
+				}
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取日志流失败: %w", err)
+	}
+	return fmt.Errorf("日志流已断开")
 }
 
 type KeyStoreInfo struct {
@@ -246,10 +469,18 @@ type KeyStoreInfo struct {
 	LoaderType string            `json:"loaderType"`
 	Params     map[string]string `json:"params"`
 	Protected  bool              `json:"protected"`
+	Issuer     string            `json:"issuer,omitempty"`
+	Renewal    *RenewalPolicy    `json:"renewal,omitempty"`
 	CreatedAt  string            `json:"createdAt"`
 	UpdatedAt  string            `json:"updatedAt"`
 }
 
+// RenewalPolicy 证书自动续期策略
+type RenewalPolicy struct {
+	BeforeDays int  `json:"beforeDays,omitempty"`
+	RotateKey  bool `json:"rotateKey,omitempty"`
+}
+
 type GenerateKeyStoreRequest struct {
 	Name           string                     `json:"name"`
 	Type           string                     `json:"type"`
@@ -547,6 +778,25 @@ func (c *Client) UpdateKeyStoreCertificates(name string, files map[string][]byte
 	return &ks, nil
 }
 
+// ReloadKeyStore 按当前参数重新加载 keystore，引用该 keystore 的实例随之重新加载证书
+// 参数：
+//   - name: keystore 名称
+//
+// 返回：
+//   - *KeyStoreInfo: 重新加载后的 keystore 信息
+//   - error: 错误信息
+func (c *Client) ReloadKeyStore(name string) (*KeyStoreInfo, error) {
+	data, err := c.Post("/api/security/keystores/"+url.PathEscape(name)+"/reload", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ks KeyStoreInfo
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &ks, nil
+}
+
 type RootCertInfo struct {
 	Filename     string   `json:"filename"`
 	Subject      string   `json:"subject"`
@@ -663,9 +913,165 @@ func (c *Client) DeleteRootCert(filename string) error {
 	return c.Delete("/api/security/rootcerts/" + url.PathEscape(filename))
 }
 
-func (c *Client) ReloadRootCerts() error {
-	_, err := c.Post("/api/security/rootcerts/reload", nil)
-	return err
+// ReloadRootCerts 重新加载所有根证书
+// 返回：
+//   - []string: 已替换证书池的实例名称
+//   - error: 错误信息
+func (c *Client) ReloadRootCerts() ([]string, error) {
+	data, err := c.Post("/api/security/rootcerts/reload", nil)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Instances []string `json:"instances"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return result.Instances, nil
+}
+
+// CertExpiryItem 单个证书的有效期检查结果
+type CertExpiryItem struct {
+	Source       string `json:"source"`
+	Name         string `json:"name"`
+	Usage        string `json:"usage,omitempty"`
+	Subject      string `json:"subject,omitempty"`
+	SerialNumber string `json:"serialNumber,omitempty"`
+	NotBefore    string `json:"notBefore,omitempty"`
+	NotAfter     string `json:"notAfter,omitempty"`
+	DaysLeft     int    `json:"daysLeft"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// CertExpiryReport 证书有效期检查结果
+type CertExpiryReport struct {
+	CheckedAt    string           `json:"checkedAt"`
+	WarningDays  int              `json:"warningDays"`
+	CriticalDays int              `json:"criticalDays"`
+	Items        []CertExpiryItem `json:"items"`
+	Summary      map[string]int   `json:"summary"`
+}
+
+// GetCertExpiry 获取 keystore 证书与根证书的有效期检查结果
+// 参数：
+//   - refresh: 是否立即重新检查，false 时返回服务端最近一次检查的结果
+//
+// 返回：
+//   - *CertExpiryReport: 检查结果
+//   - error: 错误信息
+func (c *Client) GetCertExpiry(refresh bool) (*CertExpiryReport, error) {
+	path := "/api/security/expiry"
+	if refresh {
+		path += "?refresh=true"
+	}
+	data, err := c.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var report CertExpiryReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &report, nil
+}
+
+// IssuedCertRecord 内置 CA 的签发记录
+type IssuedCertRecord struct {
+	SerialNumber string   `json:"serialNumber"`
+	CA           string   `json:"ca"`
+	Kind         string   `json:"kind"`
+	Usage        string   `json:"usage"`
+	Subject      string   `json:"subject"`
+	DNSNames     []string `json:"dnsNames,omitempty"`
+	IPAddresses  []string `json:"ipAddresses,omitempty"`
+	NotBefore    string   `json:"notBefore"`
+	NotAfter     string   `json:"notAfter"`
+	IssuedAt     string   `json:"issuedAt"`
+}
+
+// SignCSRRequest 签发证书请求的参数
+type SignCSRRequest struct {
+	CSR   string `json:"csr"`
+	Days  int    `json:"days,omitempty"`
+	Usage string `json:"usage,omitempty"`
+}
+
+// SignCSRResult 签发证书请求的结果
+type SignCSRResult struct {
+	IssuedCertRecord
+	Certificate string `json:"certificate"`
+}
+
+// IssueClientCertRequest 签发客户端证书的参数
+type IssueClientCertRequest struct {
+	CommonName      string   `json:"commonName"`
+	Country         string   `json:"country,omitempty"`
+	StateOrProvince string   `json:"stateOrProvince,omitempty"`
+	Locality        string   `json:"locality,omitempty"`
+	Org             string   `json:"org,omitempty"`
+	OrgUnit         string   `json:"orgUnit,omitempty"`
+	Days            int      `json:"days,omitempty"`
+	DNSNames        []string `json:"dnsNames,omitempty"`
+	IPAddresses     []string `json:"ipAddresses,omitempty"`
+	KeyAlgorithm    string   `json:"keyAlgorithm,omitempty"`
+	KeyBits         int      `json:"keyBits,omitempty"`
+}
+
+// SignCSR 使用内置 CA 签发证书请求
+// 参数：
+//   - caName: CA keystore 名称
+//   - req: 签发参数
+//
+// 返回：
+//   - *SignCSRResult: 签发记录及 PEM 格式的证书
+//   - error: 错误信息
+func (c *Client) SignCSR(caName string, req SignCSRRequest) (*SignCSRResult, error) {
+	data, err := c.Post("/api/security/ca/"+url.PathEscape(caName)+"/sign", req)
+	if err != nil {
+		return nil, err
+	}
+	var result SignCSRResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &result, nil
+}
+
+// IssueClientCert 使用内置 CA 签发客户端证书
+// 参数：
+//   - caName: CA keystore 名称
+//   - req: 签发参数
+//
+// 返回：
+//   - []byte: ZIP 格式的证书包，包含证书、私钥与 CA 证书
+//   - error: 错误信息
+func (c *Client) IssueClientCert(caName string, req IssueClientCertRequest) ([]byte, error) {
+	return c.Post("/api/security/ca/"+url.PathEscape(caName)+"/issue", req)
+}
+
+// ListIssuedCerts 获取内置 CA 的签发记录
+// 参数：
+//   - caName: CA keystore 名称，为空时返回所有 CA 的签发记录
+//
+// 返回：
+//   - []IssuedCertRecord: 签发记录，按签发时间从新到旧排列
+//   - error: 错误信息
+func (c *Client) ListIssuedCerts(caName string) ([]IssuedCertRecord, error) {
+	path := "/api/security/ca/issued"
+	if caName != "" {
+		path += "?ca=" + url.QueryEscape(caName)
+	}
+	data, err := c.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	var records []IssuedCertRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return records, nil
 }
 
 func (c *Client) GetConfig() (map[string]interface{}, error) {
@@ -794,6 +1200,72 @@ func (c *Client) InstanceHealth(name string, timeout *int) (*InstanceHealthRespo
 	return &resp, nil
 }
 
+type HealthRecord struct {
+	Time      string `json:"time"`
+	Type      string `json:"type"`
+	Target    string `json:"target"`
+	Protocol  string `json:"protocol,omitempty"`
+	Success   bool   `json:"success"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type InstanceHealthHistory struct {
+	Enabled             bool           `json:"enabled"`
+	Type                string         `json:"type,omitempty"`
+	Degraded            bool           `json:"degraded"`
+	ConsecutiveFailures int            `json:"consecutiveFailures"`
+	FailureThreshold    int            `json:"failureThreshold,omitempty"`
+	LastCheck           string         `json:"lastCheck,omitempty"`
+	History             []HealthRecord `json:"history"`
+}
+
+func (c *Client) InstanceHealthHistory(name string) (*InstanceHealthHistory, error) {
+	data, err := c.Get("/api/instances/" + url.PathEscape(name) + "/health/history")
+	if err != nil {
+		return nil, err
+	}
+	var resp InstanceHealthHistory
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &resp, nil
+}
+
+type ConnInfo struct {
+	ID            string `json:"id"`
+	RemoteAddr    string `json:"remoteAddr"`
+	Target        string `json:"target,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+	Version       string `json:"version,omitempty"`
+	CipherSuite   string `json:"cipherSuite,omitempty"`
+	ServerName    string `json:"serverName,omitempty"`
+	PeerSubject   string `json:"peerSubject,omitempty"`
+	StartTime     string `json:"startTime"`
+	BytesReceived int64  `json:"bytesReceived"`
+	BytesSent     int64  `json:"bytesSent"`
+}
+
+type ConnectionsResponse struct {
+	Connections []ConnInfo `json:"connections"`
+}
+
+func (c *Client) InstanceConnections(name string) ([]ConnInfo, error) {
+	data, err := c.Get("/api/instances/" + url.PathEscape(name) + "/connections")
+	if err != nil {
+		return nil, err
+	}
+	var resp ConnectionsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.Connections, nil
+}
+
+func (c *Client) CloseInstanceConnection(name, id string) error {
+	return c.Delete("/api/instances/" + url.PathEscape(name) + "/connections/" + url.PathEscape(id))
+}
+
 type LogFileInfo struct {
 	Name    string `json:"name"`
 	Path    string `json:"path"`
@@ -825,6 +1297,78 @@ func (c *Client) ListLogs() (*LogFileListResponse, error) {
 	return &resp, nil
 }
 
+// LogSearchQuery 日志检索条件，零值字段不作为查询参数
+type LogSearchQuery struct {
+	// Since 起始时间，RFC3339 格式或相对当前的时长如 "6h"
+	Since string
+	// Until 结束时间，格式同 Since
+	Until    string
+	Level    string
+	Instance string
+	// Text 匹配内容，Regex 为 true 时按正则表达式匹配
+	Text   string
+	Regex  bool
+	Offset int
+	Limit  int
+}
+
+type LogSearchMatch struct {
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Instance  string    `json:"instance,omitempty"`
+	Text      string    `json:"text"`
+}
+
+type LogSearchResponse struct {
+	Matches []LogSearchMatch `json:"matches"`
+	Total   int              `json:"total"`
+	Offset  int              `json:"offset"`
+	Limit   int              `json:"limit"`
+}
+
+// SearchLogs 检索当前与轮转后的日志文件
+func (c *Client) SearchLogs(q LogSearchQuery) (*LogSearchResponse, error) {
+	v := url.Values{}
+	for key, value := range map[string]string{
+		"since":    q.Since,
+		"until":    q.Until,
+		"level":    q.Level,
+		"instance": q.Instance,
+		"q":        q.Text,
+	} {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	if q.Regex {
+		v.Set("regex", "true")
+	}
+	if q.Offset > 0 {
+		v.Set("offset", strconv.Itoa(q.Offset))
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	requestPath := "/api/system/logs/search"
+	if len(v) > 0 {
+		requestPath += "?" + v.Encode()
+	}
+	// 检索压缩的旧日志文件可能较慢，不受默认请求超时限制
+	hc := *c.httpClient
+	hc.Timeout = 0
+	data, err := (&Client{baseURL: c.baseURL, httpClient: &hc}).Get(requestPath)
+	if err != nil {
+		return nil, err
+	}
+	var resp LogSearchResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &resp, nil
+}
+
 func (c *Client) GetLogContent(fileName string, lines int, level string) (*LogContentResponse, error) {
 	requestPath := "/api/system/logs/content"
 	if fileName != "" || lines > 0 || level != "" {
@@ -111,3 +111,83 @@ func TestClient_ConnectionError(t *testing.T) {
 		t.Error("期望返回连接错误，但返回了 nil")
 	}
 }
+
+func TestClient_StreamLogs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/system/logs/stream" {
+			t.Errorf("请求路径应为 /api/system/logs/stream, 实际为 %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("level") != "warn" || r.URL.Query().Get("instance") != "gm-server" {
+			t.Errorf("查询参数错误: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(": connected\n\n" +
+			"event: log\ndata: {\"timestamp\":\"2024-01-01T00:00:00Z\",\"level\":\"warn\",\"message\":\"握手失败\",\"instance\":\"gm-server\"}\n\n" +
+			": ping\n\n" +
+			"event: log\ndata: {\"timestamp\":\"2024-01-01T00:00:01Z\",\"level\":\"error\",\"message\":\"连接目标服务失败\",\"instance\":\"gm-server\"}\n\n"))
+	}))
+	defer server.Close()
+
+	var got []LogEntry
+	err := NewClient(server.URL).StreamLogs("warn", "gm-server", func(e LogEntry) error {
+		got = append(got, e)
+		return nil
+	})
+	if err == nil {
+		t.Error("服务端关闭连接后应返回错误")
+	}
+	if len(got) != 2 || got[0].Message != "握手失败" || got[1].Level != "error" || got[1].Instance != "gm-server" {
+		t.Errorf("解析的日志记录错误: %+v", got)
+	}
+}
+
+func TestClient_GetCertExpiry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/security/expiry" {
+			t.Errorf("请求路径应为 /api/security/expiry, 实际为 %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("refresh") != "true" {
+			t.Errorf("查询参数错误: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"checkedAt":"2024-06-01T10:00:00Z","warningDays":30,"criticalDays":7,` +
+			`"items":[{"source":"keystore","name":"default-tlcp","usage":"enc","notAfter":"2024-06-05T00:00:00Z","daysLeft":3,"status":"critical"}],` +
+			`"summary":{"critical":1}}`))
+	}))
+	defer server.Close()
+
+	report, err := NewClient(server.URL).GetCertExpiry(true)
+	if err != nil {
+		t.Fatalf("GetCertExpiry() 失败: %v", err)
+	}
+	if len(report.Items) != 1 || report.Items[0].Status != "critical" || report.Items[0].DaysLeft != 3 {
+		t.Errorf("解析的检查结果错误: %+v", report.Items)
+	}
+	if report.Summary["critical"] != 1 {
+		t.Errorf("Summary 错误: %v", report.Summary)
+	}
+}
+
+func TestClient_SignCSR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/security/ca/gm-ca/sign" {
+			t.Errorf("请求应为 POST /api/security/ca/gm-ca/sign, 实际为 %s %s", r.Method, r.URL.Path)
+		}
+		var req SignCSRRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CSR != "csr-data" || req.Usage != "enc" {
+			t.Errorf("请求体错误: %+v, %v", req, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"serialNumber":"1A2B","ca":"gm-ca","kind":"csr","usage":"enc","subject":"CN=client",` +
+			`"certificate":"-----BEGIN CERTIFICATE-----"}`))
+	}))
+	defer server.Close()
+
+	result, err := NewClient(server.URL).SignCSR("gm-ca", SignCSRRequest{CSR: "csr-data", Usage: "enc"})
+	if err != nil {
+		t.Fatalf("SignCSR() 失败: %v", err)
+	}
+	if result.SerialNumber != "1A2B" || result.Usage != "enc" || result.Certificate == "" {
+		t.Errorf("解析的签发结果错误: %+v", result)
+	}
+}
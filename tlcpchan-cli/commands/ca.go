@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Trisia/tlcpchan-cli/client"
+)
+
+func caSign(args []string) error {
+	fs := flagSet("sign")
+	csrPath := fs.String("csr", "", "证书请求(CSR)文件路径")
+	days := fs.Int("days", 0, "有效期（天），默认365")
+	usage := fs.String("usage", "", "证书用途 (sign/enc/tls)，默认 SM2 证书请求为 sign，其他为 tls")
+	output := fs.String("output", "", "证书输出路径，默认输出到标准输出")
+	fs.StringVar(output, "o", "", "输出路径(缩写)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) == 0 {
+		return fmt.Errorf("请指定 CA keystore 名称")
+	}
+	if *csrPath == "" {
+		return fmt.Errorf("请指定 --csr")
+	}
+	csr, err := os.ReadFile(*csrPath)
+	if err != nil {
+		return fmt.Errorf("读取证书请求失败: %w", err)
+	}
+
+	result, err := cli.SignCSR(fs.Args()[0], client.SignCSRRequest{CSR: string(csr), Days: *days, Usage: *usage})
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		if isJSONOutput() {
+			return printJSON(result)
+		}
+		fmt.Print(result.Certificate)
+		return nil
+	}
+
+	if err := os.WriteFile(*output, []byte(result.Certificate), 0644); err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+	if isJSONOutput() {
+		return printJSON(result.IssuedCertRecord)
+	}
+	fmt.Printf("证书已签发: %s\n", result.Subject)
+	fmt.Printf("序列号:   %s\n", result.SerialNumber)
+	fmt.Printf("用途:     %s\n", result.Usage)
+	fmt.Printf("过期时间: %s\n", truncateTime(result.NotAfter))
+	fmt.Printf("已保存到: %s\n", *output)
+	return nil
+}
+
+func caIssue(args []string) error {
+	fs := flagSet("issue")
+	commonName := fs.String("cn", "", "证书通用名称 (CN)")
+	country := fs.String("c", "", "国家 (C, 2字母代码)")
+	stateOrProvince := fs.String("st", "", "省/州 (ST)")
+	locality := fs.String("l", "", "地区/城市 (L)")
+	org := fs.String("org", "", "组织名称 (O)")
+	orgUnit := fs.String("org-unit", "", "组织单位 (OU)")
+	days := fs.Int("days", 0, "有效期（天），默认365")
+	dnsNames := fs.String("dns", "", "DNS名称, 多个用逗号分隔")
+	ipAddrs := fs.String("ip", "", "IP地址, 多个用逗号分隔")
+	keyAlgorithm := fs.String("key-algorithm", "", "密钥算法，仅 RSA/ECDSA CA 有效 (rsa/ecdsa)，默认 ecdsa")
+	keyBits := fs.Int("key-bits", 0, "RSA 密钥长度，默认2048")
+	output := fs.String("output", "", "证书包输出路径，默认为 <cn>.zip")
+	fs.StringVar(output, "o", "", "输出路径(缩写)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(fs.Args()) == 0 {
+		return fmt.Errorf("请指定 CA keystore 名称")
+	}
+	if *commonName == "" {
+		return fmt.Errorf("请指定 --cn")
+	}
+
+	req := client.IssueClientCertRequest{
+		CommonName:      *commonName,
+		Country:         *country,
+		StateOrProvince: *stateOrProvince,
+		Locality:        *locality,
+		Org:             *org,
+		OrgUnit:         *orgUnit,
+		Days:            *days,
+		KeyAlgorithm:    *keyAlgorithm,
+		KeyBits:         *keyBits,
+	}
+	if *dnsNames != "" {
+		req.DNSNames = splitAndTrim(*dnsNames, ",")
+	}
+	if *ipAddrs != "" {
+		req.IPAddresses = splitAndTrim(*ipAddrs, ",")
+	}
+
+	data, err := cli.IssueClientCert(fs.Args()[0], req)
+	if err != nil {
+		return err
+	}
+
+	outputPath := *output
+	if outputPath == "" {
+		outputPath = *commonName + ".zip"
+	}
+	// 证书包中包含私钥
+	if err := os.WriteFile(outputPath, data, 0600); err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+
+	if isJSONOutput() {
+		return printJSON(map[string]interface{}{
+			"success": true,
+			"message": "客户端证书已签发",
+			"output":  outputPath,
+		})
+	}
+	fmt.Printf("客户端证书已签发: %s (%d bytes)\n", outputPath, len(data))
+	return nil
+}
+
+func caList(args []string) error {
+	fs := flagSet("list")
+	caName := fs.String("ca", "", "只显示该 CA 的签发记录")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	records, err := cli.ListIssuedCerts(*caName)
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(records)
+	}
+	if len(records) == 0 {
+		fmt.Println("没有签发记录")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "序列号\tCA\t方式\t用途\t主题\t过期时间\t签发时间")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.SerialNumber, r.CA, r.Kind, r.Usage, r.Subject, truncateTime(r.NotAfter), truncateTime(r.IssuedAt))
+	}
+	w.Flush()
+	return nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -29,7 +30,7 @@ func instanceList(args []string) error {
 	fmt.Fprintln(w, "名称\t状态\t类型\t监听\t目标\t启用")
 	for _, inst := range instances {
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%v\n",
-			inst.Name, inst.Status, inst.Config.Type, inst.Config.Listen, inst.Config.Target, inst.Enabled)
+			inst.Name, inst.Status, inst.Config.Type, inst.Config.Listen, formatTargets(inst.Config), inst.Enabled)
 	}
 	w.Flush()
 	return nil
@@ -53,7 +54,10 @@ func instanceShow(args []string) error {
 	fmt.Printf("状态: %s\n", inst.Status)
 	fmt.Printf("类型: %s\n", inst.Config.Type)
 	fmt.Printf("监听: %s\n", inst.Config.Listen)
-	fmt.Printf("目标: %s\n", inst.Config.Target)
+	fmt.Printf("目标: %s\n", formatTargets(inst.Config))
+	if inst.Config.LoadBalance != nil && inst.Config.LoadBalance.Strategy != "" {
+		fmt.Printf("负载均衡: %s\n", inst.Config.LoadBalance.Strategy)
+	}
 	fmt.Printf("协议: %s\n", inst.Config.Protocol)
 	fmt.Printf("TLCP认证: %s\n", inst.Config.TLCP.ClientAuthType)
 	fmt.Printf("TLS认证: %s\n", inst.Config.TLS.ClientAuthType)
@@ -66,7 +70,9 @@ func instanceCreate(args []string) error {
 	name := fs.String("name", "", "实例名称（必需）")
 	instType := fs.String("type", "server", "类型（server/client/http-server/http-client）")
 	listen := fs.String("listen", "", "监听地址（必需）")
-	target := fs.String("target", "", "目标地址（必需）")
+	target := fs.String("target", "", "目标地址（与 --targets 二选一）")
+	targets := fs.String("targets", "", "多目标地址，格式 host:port[=权重]，多个用逗号分隔")
+	lbStrategy := fs.String("lb-strategy", "", "多目标负载均衡策略（round-robin/least-conn/source-hash）")
 	protocol := fs.String("protocol", "auto", "协议（auto/tlcp/tls）")
 	tlcpClientAuthType := fs.String("tlcp-client-auth-type", "no-client-cert", "TLCP客户端认证类型")
 	tlsClientAuthType := fs.String("tls-client-auth-type", "no-client-cert", "TLS客户端认证类型")
@@ -74,6 +80,15 @@ func instanceCreate(args []string) error {
 	enabled := fs.Bool("enabled", true, "是否启用")
 	sni := fs.String("sni", "", "SNI 名称")
 	bufferSize := fs.Int("buffer-size", 0, "缓冲区大小")
+	proxyProtocol := fs.String("proxy-protocol", "", "向目标发送的PROXY协议头版本（v1/v2），仅 server 类型实例有效")
+	proxyProtocolAccept := fs.Bool("proxy-protocol-accept", false, "解析监听连接上的PROXY协议头（部署在四层负载均衡之后时使用）")
+	proxyProtocolTrusted := fs.String("proxy-protocol-trusted", "", "允许发送PROXY协议头的上游地址（CIDR或IP），多个用逗号分隔")
+	healthCheck := fs.String("health-check", "", "启用后台健康检查并指定探测方式（tcp/handshake/http）")
+	healthInterval := fs.Int("health-interval", 0, "后台健康检查间隔（秒）")
+	accessLog := fs.String("access-log", "", "启用连接访问日志并指定格式（json/logfmt），off 表示关闭")
+	accessLogFile := fs.String("access-log-file", "", "访问日志文件路径")
+	logBuffer := fs.Int("log-buffer", 0, "内存中保留的实例日志条数")
+	logFile := fs.String("log-file", "", "实例日志文件路径，off 表示不写入单独的文件")
 
 	tlcpSignCert := fs.String("tlcp-sign-cert", "", "TLCP 签名证书路径")
 	tlcpSignKey := fs.String("tlcp-sign-key", "", "TLCP 签名密钥路径")
@@ -85,11 +100,14 @@ func instanceCreate(args []string) error {
 
 	clientCA := fs.String("client-ca", "", "客户端CA证书路径，多个用逗号分隔")
 	serverCA := fs.String("server-ca", "", "服务端CA证书路径，多个用逗号分隔")
+	crlCheck := fs.Bool("crl-check", false, "是否检查对端证书吊销状态（CRL）")
 
 	timeoutDial := fs.Int("timeout-dial", 0, "连接建立超时（秒）")
 	timeoutRead := fs.Int("timeout-read", 0, "读取超时（秒）")
 	timeoutWrite := fs.Int("timeout-write", 0, "写入超时（秒）")
 	timeoutHandshake := fs.Int("timeout-handshake", 0, "握手超时（秒）")
+	timeoutMaxSession := fs.Int("timeout-max-session", 0, "单个连接最长存活时间（秒）")
+	timeoutDrain := fs.Int("timeout-drain", 0, "排空宽限期（秒）")
 
 	tlcpMinVersion := fs.String("tlcp-min-version", "", "TLCP最小协议版本")
 	tlcpMaxVersion := fs.String("tlcp-max-version", "", "TLCP最大协议版本")
@@ -114,8 +132,8 @@ func instanceCreate(args []string) error {
 	if *listen == "" {
 		return fmt.Errorf("请指定 --listen")
 	}
-	if *target == "" {
-		return fmt.Errorf("请指定 --target")
+	if *target == "" && *targets == "" {
+		return fmt.Errorf("请指定 --target 或 --targets")
 	}
 
 	cfg := client.InstanceConfig{
@@ -128,6 +146,27 @@ func instanceCreate(args []string) error {
 		SNI:        *sni,
 		BufferSize: *bufferSize,
 	}
+	if *proxyProtocol != "" || *proxyProtocolAccept || *proxyProtocolTrusted != "" {
+		if cfg.ProxyProtocol == nil {
+			cfg.ProxyProtocol = &client.ProxyProtocolConfig{}
+		}
+		if *proxyProtocol != "" {
+			cfg.ProxyProtocol.Send = *proxyProtocol
+		}
+		if *proxyProtocolAccept {
+			cfg.ProxyProtocol.Accept = true
+		}
+		if *proxyProtocolTrusted != "" {
+			cfg.ProxyProtocol.Trusted = splitString(*proxyProtocolTrusted, ",")
+		}
+	}
+
+	if err := applyTargetFlags(&cfg, *targets, *lbStrategy); err != nil {
+		return err
+	}
+	applyHealthCheckFlags(&cfg, *healthCheck, *healthInterval)
+	applyAccessLogFlags(&cfg, *accessLog, *accessLogFile)
+	applyInstanceLogFlags(&cfg, *logBuffer, *logFile)
 
 	if *clientCA != "" {
 		cfg.ClientCA = splitString(*clientCA, ",")
@@ -135,8 +174,11 @@ func instanceCreate(args []string) error {
 	if *serverCA != "" {
 		cfg.ServerCA = splitString(*serverCA, ",")
 	}
+	if *crlCheck {
+		cfg.CRLCheck = true
+	}
 
-	if *timeoutDial > 0 || *timeoutRead > 0 || *timeoutWrite > 0 || *timeoutHandshake > 0 {
+	if *timeoutDial > 0 || *timeoutRead > 0 || *timeoutWrite > 0 || *timeoutHandshake > 0 || *timeoutMaxSession > 0 || *timeoutDrain > 0 {
 		cfg.Timeout = &client.TimeoutConfig{}
 		if *timeoutDial > 0 {
 			cfg.Timeout.Dial = time.Duration(*timeoutDial) * time.Second
@@ -150,6 +192,12 @@ func instanceCreate(args []string) error {
 		if *timeoutHandshake > 0 {
 			cfg.Timeout.Handshake = time.Duration(*timeoutHandshake) * time.Second
 		}
+		if *timeoutMaxSession > 0 {
+			cfg.Timeout.MaxSession = time.Duration(*timeoutMaxSession) * time.Second
+		}
+		if *timeoutDrain > 0 {
+			cfg.Timeout.Drain = time.Duration(*timeoutDrain) * time.Second
+		}
 	}
 
 	if *keystoreName != "" {
@@ -241,6 +289,8 @@ func instanceUpdate(args []string) error {
 	instType := fs.String("type", "", "类型（server/client/http-server/http-client）")
 	listen := fs.String("listen", "", "监听地址")
 	target := fs.String("target", "", "目标地址")
+	targets := fs.String("targets", "", "多目标地址，格式 host:port[=权重]，多个用逗号分隔")
+	lbStrategy := fs.String("lb-strategy", "", "多目标负载均衡策略（round-robin/least-conn/source-hash）")
 	protocol := fs.String("protocol", "", "协议（auto/tlcp/tls）")
 	tlcpClientAuthType := fs.String("tlcp-client-auth-type", "", "TLCP客户端认证类型")
 	tlsClientAuthType := fs.String("tls-client-auth-type", "", "TLS客户端认证类型")
@@ -248,6 +298,15 @@ func instanceUpdate(args []string) error {
 	enabled := fs.Bool("enabled", false, "是否启用")
 	sni := fs.String("sni", "", "SNI 名称")
 	bufferSize := fs.Int("buffer-size", 0, "缓冲区大小")
+	proxyProtocol := fs.String("proxy-protocol", "", "向目标发送的PROXY协议头版本（v1/v2），仅 server 类型实例有效")
+	proxyProtocolAccept := fs.Bool("proxy-protocol-accept", false, "解析监听连接上的PROXY协议头（部署在四层负载均衡之后时使用）")
+	proxyProtocolTrusted := fs.String("proxy-protocol-trusted", "", "允许发送PROXY协议头的上游地址（CIDR或IP），多个用逗号分隔")
+	healthCheck := fs.String("health-check", "", "启用后台健康检查并指定探测方式（tcp/handshake/http）")
+	healthInterval := fs.Int("health-interval", 0, "后台健康检查间隔（秒）")
+	accessLog := fs.String("access-log", "", "启用连接访问日志并指定格式（json/logfmt），off 表示关闭")
+	accessLogFile := fs.String("access-log-file", "", "访问日志文件路径")
+	logBuffer := fs.Int("log-buffer", 0, "内存中保留的实例日志条数")
+	logFile := fs.String("log-file", "", "实例日志文件路径，off 表示不写入单独的文件")
 
 	tlcpSignCert := fs.String("tlcp-sign-cert", "", "TLCP 签名证书路径")
 	tlcpSignKey := fs.String("tlcp-sign-key", "", "TLCP 签名密钥路径")
@@ -259,11 +318,14 @@ func instanceUpdate(args []string) error {
 
 	clientCA := fs.String("client-ca", "", "客户端CA证书路径，多个用逗号分隔")
 	serverCA := fs.String("server-ca", "", "服务端CA证书路径，多个用逗号分隔")
+	crlCheck := fs.Bool("crl-check", false, "是否检查对端证书吊销状态（CRL）")
 
 	timeoutDial := fs.Int("timeout-dial", 0, "连接建立超时（秒）")
 	timeoutRead := fs.Int("timeout-read", 0, "读取超时（秒）")
 	timeoutWrite := fs.Int("timeout-write", 0, "写入超时（秒）")
 	timeoutHandshake := fs.Int("timeout-handshake", 0, "握手超时（秒）")
+	timeoutMaxSession := fs.Int("timeout-max-session", 0, "单个连接最长存活时间（秒）")
+	timeoutDrain := fs.Int("timeout-drain", 0, "排空宽限期（秒）")
 
 	tlcpMinVersion := fs.String("tlcp-min-version", "", "TLCP最小协议版本")
 	tlcpMaxVersion := fs.String("tlcp-max-version", "", "TLCP最大协议版本")
@@ -322,6 +384,27 @@ func instanceUpdate(args []string) error {
 	if *enabled {
 		cfg.Enabled = true
 	}
+	if *proxyProtocol != "" || *proxyProtocolAccept || *proxyProtocolTrusted != "" {
+		if cfg.ProxyProtocol == nil {
+			cfg.ProxyProtocol = &client.ProxyProtocolConfig{}
+		}
+		if *proxyProtocol != "" {
+			cfg.ProxyProtocol.Send = *proxyProtocol
+		}
+		if *proxyProtocolAccept {
+			cfg.ProxyProtocol.Accept = true
+		}
+		if *proxyProtocolTrusted != "" {
+			cfg.ProxyProtocol.Trusted = splitString(*proxyProtocolTrusted, ",")
+		}
+	}
+
+	if err := applyTargetFlags(&cfg, *targets, *lbStrategy); err != nil {
+		return err
+	}
+	applyHealthCheckFlags(&cfg, *healthCheck, *healthInterval)
+	applyAccessLogFlags(&cfg, *accessLog, *accessLogFile)
+	applyInstanceLogFlags(&cfg, *logBuffer, *logFile)
 
 	if *clientCA != "" {
 		cfg.ClientCA = splitString(*clientCA, ",")
@@ -329,8 +412,11 @@ func instanceUpdate(args []string) error {
 	if *serverCA != "" {
 		cfg.ServerCA = splitString(*serverCA, ",")
 	}
+	if *crlCheck {
+		cfg.CRLCheck = true
+	}
 
-	if *timeoutDial > 0 || *timeoutRead > 0 || *timeoutWrite > 0 || *timeoutHandshake > 0 {
+	if *timeoutDial > 0 || *timeoutRead > 0 || *timeoutWrite > 0 || *timeoutHandshake > 0 || *timeoutMaxSession > 0 || *timeoutDrain > 0 {
 		if cfg.Timeout == nil {
 			cfg.Timeout = &client.TimeoutConfig{}
 		}
@@ -346,6 +432,12 @@ func instanceUpdate(args []string) error {
 		if *timeoutHandshake > 0 {
 			cfg.Timeout.Handshake = time.Duration(*timeoutHandshake) * time.Second
 		}
+		if *timeoutMaxSession > 0 {
+			cfg.Timeout.MaxSession = time.Duration(*timeoutMaxSession) * time.Second
+		}
+		if *timeoutDrain > 0 {
+			cfg.Timeout.Drain = time.Duration(*timeoutDrain) * time.Second
+		}
 	}
 
 	if *keystoreName != "" {
@@ -512,6 +604,46 @@ func instanceStop(args []string) error {
 	return nil
 }
 
+func instanceDrain(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+
+	fs := flagSet("drain")
+	timeout := fs.Int("timeout", -1, "宽限期（秒），0 表示立即强制关闭所有连接，默认使用实例配置")
+	fs.IntVar(timeout, "t", -1, "宽限期（秒）(缩写)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	remaining := fs.Args()
+	if len(remaining) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+
+	name := remaining[0]
+
+	var timeoutPtr *int
+	if *timeout >= 0 {
+		timeoutPtr = timeout
+	}
+
+	result, err := cli.DrainInstance(name, timeoutPtr)
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(result)
+	}
+
+	fmt.Printf("实例 %s 已排空\n", name)
+	fmt.Printf("  活跃连接: %d\n", result.Active)
+	fmt.Printf("  强制关闭: %d\n", result.ForceClosed)
+	fmt.Printf("  耗时: %dms\n", result.DurationMs)
+	return nil
+}
+
 func instanceReload(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("请指定实例名称")
@@ -579,19 +711,55 @@ func instanceLogs(args []string) error {
 		return fmt.Errorf("请指定实例名称")
 	}
 
-	logs, err := cli.InstanceLogs(args[0])
+	fs := flagSet("logs")
+	level := fs.String("level", "", "最低日志级别: debug, info, warn, error")
+	fs.StringVar(level, "l", "", "最低日志级别 (缩写)")
+	limit := fs.Int("limit", 100, "最多显示的条数")
+	fs.IntVar(limit, "n", 100, "最多显示的条数 (缩写)")
+	since := fs.String("since", "", "起始时间，RFC3339 格式或相对时长如 15m、2h")
+	until := fs.String("until", "", "结束时间，格式同 --since")
+	follow := fs.Bool("follow", false, "显示最近的日志后持续输出新日志，按 Ctrl+C 退出")
+	fs.BoolVar(follow, "f", false, "持续输出新日志 (缩写)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	remaining := fs.Args()
+	if len(remaining) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+	if *follow && *until != "" {
+		return fmt.Errorf("--until 不能与 --follow 同时使用")
+	}
+
+	name := remaining[0]
+	logs, err := cli.InstanceLogs(name, client.LogQuery{
+		Level: *level,
+		Since: *since,
+		Until: *until,
+		Limit: *limit,
+	})
 	if err != nil {
 		return err
 	}
 
-	if isJSONOutput() {
+	if isJSONOutput() && !*follow {
 		return printJSON(logs)
 	}
-
 	for _, log := range logs {
-		fmt.Printf("[%s] %s: %s\n", log["level"], log["timestamp"], log["message"])
+		if err := printLogEntry(log); err != nil {
+			return err
+		}
 	}
-	return nil
+	if !*follow {
+		return nil
+	}
+
+	return cli.StreamLogs(*level, name, func(e client.LogEntry) error {
+		// 只查看单个实例时不重复显示实例名称
+		e.Instance = ""
+		return printLogEntry(e)
+	})
 }
 
 func instanceHealth(args []string) error {
@@ -642,6 +810,213 @@ func instanceHealth(args []string) error {
 	return nil
 }
 
+func instanceHealthHistory(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+
+	health, err := cli.InstanceHealthHistory(args[0])
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(health)
+	}
+
+	if !health.Enabled {
+		fmt.Println("未启用后台健康检查")
+		if len(health.History) == 0 {
+			return nil
+		}
+	} else {
+		fmt.Printf("探测方式: %s\n", health.Type)
+		fmt.Printf("连续失败: %d/%d\n", health.ConsecutiveFailures, health.FailureThreshold)
+		fmt.Printf("降级: %v\n", health.Degraded)
+		if health.LastCheck != "" {
+			fmt.Printf("最近探测: %s\n", health.LastCheck)
+		}
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "时间\t方式\t地址\t协议\t结果\t延迟\t错误")
+	for _, r := range health.History {
+		result := "成功"
+		if !r.Success {
+			result = "失败"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%dms\t%s\n", r.Time, r.Type, r.Target, r.Protocol, result, r.LatencyMs, r.Error)
+	}
+	return w.Flush()
+}
+
+func instanceConns(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+
+	fs := flagSet("conns")
+	kill := fs.String("kill", "", "强制关闭指定编号的连接")
+	fs.StringVar(kill, "k", "", "强制关闭指定编号的连接(缩写)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	remaining := fs.Args()
+	if len(remaining) == 0 {
+		return fmt.Errorf("请指定实例名称")
+	}
+	name := remaining[0]
+
+	if *kill != "" {
+		if err := cli.CloseInstanceConnection(name, *kill); err != nil {
+			return err
+		}
+		if isJSONOutput() {
+			return printJSON(map[string]interface{}{
+				"success": true,
+				"message": "连接已关闭",
+				"name":    name,
+				"id":      *kill,
+			})
+		}
+		fmt.Printf("实例 %s 的连接 %s 已关闭\n", name, *kill)
+		return nil
+	}
+
+	conns, err := cli.InstanceConnections(name)
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(conns)
+	}
+
+	if len(conns) == 0 {
+		fmt.Println("没有活跃连接")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "编号\t客户端\t目标\t版本\t密码套件\tSNI\t对端证书\t建立时间\t接收\t发送")
+	for _, c := range conns {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.ID, c.RemoteAddr, c.Target, c.Version,
+			c.CipherSuite, c.ServerName, c.PeerSubject, c.StartTime, formatBytes(c.BytesReceived), formatBytes(c.BytesSent))
+	}
+	return w.Flush()
+}
+
+// applyHealthCheckFlags 应用后台健康检查参数，指定探测方式时启用健康检查
+func applyHealthCheckFlags(cfg *client.InstanceConfig, checkType string, interval int) {
+	if checkType == "" && interval <= 0 {
+		return
+	}
+	if cfg.HealthCheck == nil {
+		cfg.HealthCheck = &client.HealthCheckConfig{}
+	}
+	if checkType != "" {
+		cfg.HealthCheck.Enabled = true
+		cfg.HealthCheck.Type = checkType
+	}
+	if interval > 0 {
+		cfg.HealthCheck.Interval = time.Duration(interval) * time.Second
+	}
+}
+
+// applyAccessLogFlags 应用访问日志参数，指定格式时启用访问日志，格式为 off 时关闭
+func applyAccessLogFlags(cfg *client.InstanceConfig, format, file string) {
+	if format == "" && file == "" {
+		return
+	}
+	if cfg.AccessLog == nil {
+		cfg.AccessLog = &client.AccessLogConfig{}
+	}
+	switch format {
+	case "":
+	case "off":
+		cfg.AccessLog.Enabled = false
+	default:
+		cfg.AccessLog.Enabled = true
+		cfg.AccessLog.Format = format
+	}
+	if file != "" {
+		cfg.AccessLog.File = file
+	}
+}
+
+// applyInstanceLogFlags 应用实例日志参数，文件路径为 off 时不写入单独的文件
+func applyInstanceLogFlags(cfg *client.InstanceConfig, buffer int, file string) {
+	if buffer <= 0 && file == "" {
+		return
+	}
+	if cfg.Log == nil {
+		cfg.Log = &client.InstanceLogConfig{}
+	}
+	if buffer > 0 {
+		cfg.Log.Buffer = buffer
+	}
+	switch file {
+	case "":
+	case "off":
+		cfg.Log.File = ""
+	default:
+		cfg.Log.File = file
+	}
+}
+
+// applyTargetFlags 应用多目标与负载均衡参数
+func applyTargetFlags(cfg *client.InstanceConfig, targets, strategy string) error {
+	if targets != "" {
+		list, err := parseTargets(targets)
+		if err != nil {
+			return err
+		}
+		cfg.Targets = list
+	}
+	if strategy != "" {
+		if cfg.LoadBalance == nil {
+			cfg.LoadBalance = &client.LoadBalanceConfig{}
+		}
+		cfg.LoadBalance.Strategy = strategy
+	}
+	return nil
+}
+
+// parseTargets 解析多目标参数，格式: host:port[=权重],...
+func parseTargets(s string) ([]client.TargetConfig, error) {
+	var targets []client.TargetConfig
+	for _, part := range splitString(s, ",") {
+		addr, weight, hasWeight := strings.Cut(part, "=")
+		t := client.TargetConfig{Address: addr}
+		if hasWeight {
+			w, err := strconv.Atoi(weight)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("无效的目标权重: %s", part)
+			}
+			t.Weight = w
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// formatTargets 格式化实例的目标地址，多目标时附带权重
+func formatTargets(cfg client.InstanceConfig) string {
+	if len(cfg.Targets) == 0 {
+		return cfg.Target
+	}
+	parts := make([]string, len(cfg.Targets))
+	for i, t := range cfg.Targets {
+		parts[i] = t.Address
+		if t.Weight > 1 {
+			parts[i] += "=" + strconv.Itoa(t.Weight)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
 func splitString(s, sep string) []string {
 	var result []string
 	for _, part := range strings.Split(s, sep) {
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets("10.0.0.1:8080=3, 10.0.0.2:8080")
+	if err != nil {
+		t.Fatalf("parseTargets() error = %v", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("应解析出 2 个目标, 实际为 %d", len(targets))
+	}
+	if targets[0].Address != "10.0.0.1:8080" || targets[0].Weight != 3 {
+		t.Errorf("第一个目标 = %+v", targets[0])
+	}
+	if targets[1].Address != "10.0.0.2:8080" || targets[1].Weight != 0 {
+		t.Errorf("第二个目标 = %+v", targets[1])
+	}
+
+	for _, invalid := range []string{"10.0.0.1:8080=0", "10.0.0.1:8080=x"} {
+		if _, err := parseTargets(invalid); err == nil {
+			t.Errorf("parseTargets(%q) 应返回错误", invalid)
+		}
+	}
+}
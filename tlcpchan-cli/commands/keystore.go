@@ -56,6 +56,7 @@ func keyStoreShow(args []string) error {
 	fmt.Printf("类型: %s\n", ks.Type)
 	fmt.Printf("加载器: %s\n", ks.LoaderType)
 	fmt.Printf("受保护: %v\n", ks.Protected)
+	printKeyStoreRenewal(ks)
 	fmt.Printf("创建时间: %s\n", ks.CreatedAt)
 	fmt.Printf("更新时间: %s\n", ks.UpdatedAt)
 	fmt.Println("参数:")
@@ -65,6 +66,25 @@ func keyStoreShow(args []string) error {
 	return nil
 }
 
+// printKeyStoreRenewal 显示签发 CA 与自动续期策略
+func printKeyStoreRenewal(ks *client.KeyStoreInfo) {
+	if ks.Issuer != "" {
+		fmt.Printf("签发CA: %s\n", ks.Issuer)
+	}
+	if ks.Renewal == nil {
+		return
+	}
+	beforeDays := ks.Renewal.BeforeDays
+	if beforeDays <= 0 {
+		beforeDays = 30
+	}
+	key := "沿用原密钥"
+	if ks.Renewal.RotateKey {
+		key = "生成新密钥"
+	}
+	fmt.Printf("自动续期: 过期前 %d 天, %s\n", beforeDays, key)
+}
+
 func keyStoreShowDetail(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("请指定 keystore 名称")
@@ -84,6 +104,7 @@ func keyStoreShowDetail(args []string) error {
 	fmt.Printf("类型: %s\n", ks.Type)
 	fmt.Printf("加载器类型: %s\n", ks.LoaderType)
 	fmt.Printf("受保护: %v\n", ks.Protected)
+	printKeyStoreRenewal(ks)
 	fmt.Printf("创建时间: %s\n", ks.CreatedAt)
 	fmt.Printf("更新时间: %s\n", ks.UpdatedAt)
 
@@ -114,19 +135,12 @@ func keyStoreShowDetail(args []string) error {
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "实例名称\t协议类型\t状态")
-	runningCount := 0
 	for _, inst := range instances {
 		fmt.Fprintf(w, "%s\t%s\t%s\n", inst.Name, inst.Protocol, inst.Status)
-		if inst.Status == "running" {
-			runningCount++
-		}
 	}
 	w.Flush()
 
-	if runningCount > 0 {
-		fmt.Printf("\n⚠️  有 %d 个运行中的实例，修改参数后需要重新加载\n", runningCount)
-		fmt.Printf("使用命令: tlcpchan-cli instance reload <instance-name>\n")
-	}
+	fmt.Println("\n更新参数、上传或替换证书文件后，关联实例自动重新加载证书")
 
 	return nil
 }
@@ -192,20 +206,10 @@ func keyStoreUpdateParams(args []string) error {
 		return nil
 	}
 
-	runningCount := 0
-	for _, inst := range instances {
-		if inst.Status == "running" {
-			runningCount++
-		}
-	}
-
-	if runningCount > 0 {
-		fmt.Printf("\n⚠️  警告: 有 %d 个运行中的实例引用此 keystore\n", runningCount)
-		fmt.Println("修改后需要重新加载这些实例才能生效:")
+	if len(instances) > 0 {
+		fmt.Println("\n以下实例已自动重新加载证书:")
 		for _, inst := range instances {
-			if inst.Status == "running" {
-				fmt.Printf("  - tlcpchan-cli instance reload %s\n", inst.Name)
-			}
+			fmt.Printf("  - %s\n", inst.Name)
 		}
 	}
 
@@ -389,6 +393,41 @@ func keyStoreDelete(args []string) error {
 	return nil
 }
 
+func keyStoreReload(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("请指定 keystore 名称")
+	}
+
+	ks, err := cli.ReloadKeyStore(args[0])
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(map[string]interface{}{
+			"success": true,
+			"message": "keystore 已重新加载",
+			"name":    ks.Name,
+		})
+	}
+
+	fmt.Printf("keystore %s 已重新加载\n", ks.Name)
+
+	instances, err := cli.GetKeyStoreInstances(ks.Name)
+	if err != nil {
+		return nil
+	}
+
+	if len(instances) > 0 {
+		fmt.Println("\n以下实例已自动重新加载证书:")
+		for _, inst := range instances {
+			fmt.Printf("  - %s\n", inst.Name)
+		}
+	}
+
+	return nil
+}
+
 func keyStoreExportCSR(args []string) error {
 	fs := flagSet("export-csr")
 	keyType := fs.String("key-type", "sign", "密钥类型 (sign/enc)")
@@ -545,20 +584,10 @@ func keyStoreUploadCertificates(args []string) error {
 		return nil
 	}
 
-	runningCount := 0
-	for _, inst := range instances {
-		if inst.Status == "running" {
-			runningCount++
-		}
-	}
-
-	if runningCount > 0 {
-		fmt.Printf("\n⚠️  警告: 有 %d 个运行实例引用此 keystore\n", runningCount)
-		fmt.Println("修改后需要重新加载这些实例才能生效:")
+	if len(instances) > 0 {
+		fmt.Println("\n以下实例已自动重新加载证书:")
 		for _, inst := range instances {
-			if inst.Status == "running" {
-				fmt.Printf("  - tlcpchan-cli instance reload %s\n", inst.Name)
-			}
+			fmt.Printf("  - %s\n", inst.Name)
 		}
 	}
 
@@ -3,8 +3,11 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
+
+	"github.com/Trisia/tlcpchan-cli/client"
 )
 
 func systemLogsList(args []string) error {
@@ -151,3 +154,82 @@ func systemLogsDownloadAll(args []string) error {
 	fmt.Printf("已下载所有日志: %s (%d bytes)\n", outputPath, len(data))
 	return nil
 }
+
+func systemLogsFollow(args []string) error {
+	fs := flagSet("follow")
+	level := fs.String("level", "", "最低日志级别：debug、info、warn、error")
+	fs.StringVar(level, "l", "", "最低日志级别(缩写)")
+	instance := fs.String("instance", "", "只显示指定实例的日志")
+	fs.StringVar(instance, "i", "", "实例名称(缩写)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return cli.StreamLogs(*level, *instance, printLogEntry)
+}
+
+func systemLogsSearch(args []string) error {
+	fs := flagSet("search")
+	since := fs.String("since", "", "起始时间，RFC3339 格式或相对时长如 6h")
+	until := fs.String("until", "", "结束时间，格式同 --since")
+	level := fs.String("level", "", "最低日志级别：debug、info、warn、error")
+	fs.StringVar(level, "l", "", "最低日志级别(缩写)")
+	instance := fs.String("instance", "", "只检索指定实例的日志")
+	fs.StringVar(instance, "i", "", "实例名称(缩写)")
+	text := fs.String("query", "", "匹配内容，默认为子串匹配")
+	fs.StringVar(text, "q", "", "匹配内容(缩写)")
+	regex := fs.Bool("regex", false, "按正则表达式匹配 --query")
+	fs.BoolVar(regex, "E", false, "按正则表达式匹配(缩写)")
+	offset := fs.Int("offset", 0, "跳过的匹配条数")
+	limit := fs.Int("limit", 100, "每页条数，最大1000")
+	fs.IntVar(limit, "n", 100, "每页条数(缩写)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resp, err := cli.SearchLogs(client.LogSearchQuery{
+		Since:    *since,
+		Until:    *until,
+		Level:    *level,
+		Instance: *instance,
+		Text:     *text,
+		Regex:    *regex,
+		Offset:   *offset,
+		Limit:    *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(resp)
+	}
+
+	if len(resp.Matches) == 0 {
+		fmt.Printf("共 %d 条匹配，当前页没有记录\n", resp.Total)
+		return nil
+	}
+	for _, m := range resp.Matches {
+		fmt.Printf("%s:%d: %s\n", m.File, m.Line, m.Text)
+	}
+	fmt.Printf("---\n共 %d 条匹配，显示第 %d-%d 条\n", resp.Total, resp.Offset+1, resp.Offset+len(resp.Matches))
+	if next := resp.Offset + len(resp.Matches); next < resp.Total {
+		fmt.Printf("使用 --offset %d 查看下一页\n", next)
+	}
+	return nil
+}
+
+// printLogEntry 输出一条日志记录，JSON 输出时每行一个对象
+func printLogEntry(e client.LogEntry) error {
+	if isJSONOutput() {
+		return jsonEncoder(os.Stdout).Encode(e)
+	}
+	prefix := ""
+	if e.Instance != "" {
+		prefix = "[" + e.Instance + "] "
+	}
+	fmt.Printf("%s [%s] %s%s\n", e.Timestamp.Local().Format("2006-01-02 15:04:05"), strings.ToUpper(e.Level), prefix, e.Message)
+	return nil
+}
@@ -67,18 +67,21 @@ func getCommands() map[string]Command {
 			Description: "实例管理",
 			Usage:       "instance <子命令>",
 			SubCommands: map[string]Command{
-				"list":    {Name: "list", Description: "列出所有实例", Usage: "list", Run: instanceList},
-				"show":    {Name: "show", Description: "显示实例详情", Usage: "show <name>", Run: instanceShow},
-				"create":  {Name: "create", Description: "创建实例", Usage: "create [-f file]", Run: instanceCreate},
-				"update":  {Name: "update", Description: "更新实例配置", Usage: "update <name> [-f file]", Run: instanceUpdate},
-				"delete":  {Name: "delete", Description: "删除实例", Usage: "delete <name>", Run: instanceDelete},
-				"start":   {Name: "start", Description: "启动实例", Usage: "start <name>", Run: instanceStart},
-				"stop":    {Name: "stop", Description: "停止实例", Usage: "stop <name>", Run: instanceStop},
-				"reload":  {Name: "reload", Description: "重载实例", Usage: "reload <name>", Run: instanceReload},
-				"restart": {Name: "restart", Description: "重启实例", Usage: "restart <name>", Run: instanceRestart},
-				"stats":   {Name: "stats", Description: "查看统计信息", Usage: "stats <name>", Run: instanceStats},
-				"logs":    {Name: "logs", Description: "查看日志", Usage: "logs <name>", Run: instanceLogs},
-				"health":  {Name: "health", Description: "健康检查", Usage: "health <name> [-t timeout]", Run: instanceHealth},
+				"list":           {Name: "list", Description: "列出所有实例", Usage: "list", Run: instanceList},
+				"show":           {Name: "show", Description: "显示实例详情", Usage: "show <name>", Run: instanceShow},
+				"create":         {Name: "create", Description: "创建实例", Usage: "create [-f file]", Run: instanceCreate},
+				"update":         {Name: "update", Description: "更新实例配置", Usage: "update <name> [-f file]", Run: instanceUpdate},
+				"delete":         {Name: "delete", Description: "删除实例", Usage: "delete <name>", Run: instanceDelete},
+				"start":          {Name: "start", Description: "启动实例", Usage: "start <name>", Run: instanceStart},
+				"stop":           {Name: "stop", Description: "停止实例", Usage: "stop <name>", Run: instanceStop},
+				"drain":          {Name: "drain", Description: "排空实例", Usage: "drain [-t timeout] <name>", Run: instanceDrain},
+				"reload":         {Name: "reload", Description: "重载实例", Usage: "reload <name>", Run: instanceReload},
+				"restart":        {Name: "restart", Description: "重启实例", Usage: "restart <name>", Run: instanceRestart},
+				"stats":          {Name: "stats", Description: "查看统计信息", Usage: "stats <name>", Run: instanceStats},
+				"logs":           {Name: "logs", Description: "查看日志", Usage: "logs [-f] [-l level] [-n limit] [--since time] [--until time] <name>", Run: instanceLogs},
+				"health":         {Name: "health", Description: "健康检查", Usage: "health <name> [-t timeout]", Run: instanceHealth},
+				"health-history": {Name: "health-history", Description: "查看后台健康检查记录", Usage: "health-history <name>", Run: instanceHealthHistory},
+				"conns":          {Name: "conns", Description: "查看或关闭活跃连接", Usage: "conns [-k id] <name>", Run: instanceConns},
 			},
 		},
 		"config": {
@@ -101,6 +104,7 @@ func getCommands() map[string]Command {
 				"detail":     {Name: "detail", Description: "显示 keystore 详情（含关联实例）", Usage: "detail <name>", Run: keyStoreShowDetail},
 				"update":     {Name: "update", Description: "更新 keystore 参数", Usage: "update <name> [选项]", Run: keyStoreUpdateParams},
 				"upload":     {Name: "upload", Description: "上传更新 keystore 证书和密钥", Usage: "upload <name> [选项]", Run: keyStoreUploadCertificates},
+				"reload":     {Name: "reload", Description: "重新加载 keystore 证书和密钥", Usage: "reload <name>", Run: keyStoreReload},
 				"create":     {Name: "create", Description: "创建 keystore", Usage: "create [选项]", Run: keyStoreCreate},
 				"generate":   {Name: "generate", Description: "生成 keystore（含证书）", Usage: "generate [选项]", Run: keyStoreGenerate},
 				"export-csr": {Name: "export-csr", Description: "导出证书请求(CSR)", Usage: "export-csr <name> [选项]", Run: keyStoreExportCSR},
@@ -120,6 +124,24 @@ func getCommands() map[string]Command {
 				"reload":   {Name: "reload", Description: "重载所有根证书", Usage: "reload", Run: rootCertReload},
 			},
 		},
+		"security": {
+			Name:        "security",
+			Description: "证书安全检查",
+			Usage:       "security <子命令>",
+			SubCommands: map[string]Command{
+				"expiry": {Name: "expiry", Description: "检查证书有效期", Usage: "expiry [--refresh] [--all]", Run: securityExpiry},
+			},
+		},
+		"ca": {
+			Name:        "ca",
+			Description: "内置 CA 证书签发",
+			Usage:       "ca <子命令>",
+			SubCommands: map[string]Command{
+				"sign":  {Name: "sign", Description: "签发证书请求(CSR)", Usage: "sign --csr <file> [--days n] [--usage sign|enc|tls] [-o output] <ca>", Run: caSign},
+				"issue": {Name: "issue", Description: "签发客户端证书并下载证书包", Usage: "issue --cn <name> [选项] [-o output] <ca>", Run: caIssue},
+				"list":  {Name: "list", Description: "列出签发记录", Usage: "list [--ca name]", Run: caList},
+			},
+		},
 		"system": {
 			Name:        "system",
 			Description: "系统信息",
@@ -130,6 +152,8 @@ func getCommands() map[string]Command {
 				"logs": {Name: "logs", Description: "日志管理", Usage: "logs <子命令>", SubCommands: map[string]Command{
 					"list":         {Name: "list", Description: "列出日志文件", Usage: "list", Run: systemLogsList},
 					"content":      {Name: "content", Description: "读取日志内容", Usage: "content [选项]", Run: systemLogsContent},
+					"follow":       {Name: "follow", Description: "实时查看日志", Usage: "follow [-l level] [-i instance]", Run: systemLogsFollow},
+					"search":       {Name: "search", Description: "检索日志（包括轮转和压缩的旧文件）", Usage: "search [-q text] [-E] [-l level] [-i instance] [--since time] [--until time] [--offset n] [-n limit]", Run: systemLogsSearch},
 					"download":     {Name: "download", Description: "下载单个日志文件", Usage: "download <filename> [选项]", Run: systemLogsDownload},
 					"download-all": {Name: "download-all", Description: "打包下载所有日志", Usage: "download-all [选项]", Run: systemLogsDownloadAll},
 				}},
@@ -189,17 +189,25 @@ func rootCertDelete(args []string) error {
 }
 
 func rootCertReload(args []string) error {
-	if err := cli.ReloadRootCerts(); err != nil {
+	instances, err := cli.ReloadRootCerts()
+	if err != nil {
 		return err
 	}
 
 	if isJSONOutput() {
 		return printJSON(map[string]interface{}{
-			"success": true,
-			"message": "根证书已重新加载",
+			"success":   true,
+			"message":   "根证书已重新加载",
+			"instances": instances,
 		})
 	}
 
 	fmt.Println("根证书已重新加载")
+	if len(instances) > 0 {
+		fmt.Println("\n以下实例已替换信任的根证书:")
+		for _, name := range instances {
+			fmt.Printf("  - %s\n", name)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// expiryStatusText 证书有效期状态的显示文本
+var expiryStatusText = map[string]string{
+	"ok":       "正常",
+	"warning":  "即将过期",
+	"critical": "即将过期(严重)",
+	"expired":  "已过期",
+	"error":    "无法读取",
+}
+
+func securityExpiry(args []string) error {
+	fs := flagSet("expiry")
+	refresh := fs.Bool("refresh", false, "立即重新检查")
+	all := fs.Bool("all", false, "显示所有证书，默认只显示状态异常的证书")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := cli.GetCertExpiry(*refresh)
+	if err != nil {
+		return err
+	}
+
+	if isJSONOutput() {
+		return printJSON(report)
+	}
+
+	fmt.Printf("检查时间: %s  告警阈值: %d 天  严重告警阈值: %d 天\n",
+		truncateTime(report.CheckedAt), report.WarningDays, report.CriticalDays)
+	fmt.Printf("正常: %d  即将过期: %d  严重: %d  已过期: %d  无法读取: %d\n\n",
+		report.Summary["ok"], report.Summary["warning"], report.Summary["critical"],
+		report.Summary["expired"], report.Summary["error"])
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "来源\t名称\t用途\t主题\t过期时间\t剩余天数\t状态")
+	shown := 0
+	for _, item := range report.Items {
+		if !*all && item.Status == "ok" {
+			continue
+		}
+		shown++
+		status := expiryStatusText[item.Status]
+		if status == "" {
+			status = item.Status
+		}
+		if item.Status == "error" {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t-\t-\t%s\n",
+				item.Source, item.Name, orDash(item.Usage), item.Error, status)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			item.Source, item.Name, orDash(item.Usage), item.Subject,
+			truncateTime(item.NotAfter), item.DaysLeft, status)
+	}
+	if shown == 0 {
+		fmt.Println("所有证书均在有效期内")
+		return nil
+	}
+	w.Flush()
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -1,7 +1,18 @@
 import axios from 'axios'
 import type {
+  CertExpiryReport,
+  ConnInfo,
+  DrainResult,
   GenerateKeyStoreRequest,
   GenerateRootCARequest,
+  InstanceLogEntry,
+  InstanceLogQuery,
+  IssueClientCertRequest,
+  IssuedCertRecord,
+  LogSearchQuery,
+  LogSearchResponse,
+  SignCSRRequest,
+  SignCSRResult,
 } from '@/types'
 
 export const API_CONFIG = {
@@ -111,6 +122,11 @@ export const keyStoreApi = {
     const res = await http.get(`/security/keystores/${name}/instances`)
     return res.data
   },
+
+  reload: async (name: string) => {
+    const res = await http.post(`/security/keystores/${name}/reload`)
+    return res.data
+  },
 }
 
 export const rootCertApi = {
@@ -153,8 +169,43 @@ export const rootCertApi = {
     await http.delete(`/security/rootcerts/${filename}`)
   },
 
-  reload: async () => {
-    await http.post('/security/rootcerts/reload')
+  reload: async (): Promise<{ instances: string[] }> => {
+    const res = await http.post('/security/rootcerts/reload')
+    return res.data
+  },
+}
+
+export const securityApi = {
+  expiry: async (refresh = false): Promise<CertExpiryReport> => {
+    const res = await http.get('/security/expiry', { params: refresh ? { refresh: true } : undefined })
+    return res.data
+  },
+}
+
+export const caApi = {
+  sign: async (caName: string, data: SignCSRRequest): Promise<SignCSRResult> => {
+    const res = await http.post(`/security/ca/${caName}/sign`, data)
+    return res.data
+  },
+
+  issue: async (caName: string, data: IssueClientCertRequest) => {
+    const res = await http.post(`/security/ca/${caName}/issue`, data, {
+      responseType: 'blob'
+    })
+
+    const url = window.URL.createObjectURL(new Blob([res.data]))
+    const link = document.createElement('a')
+    link.href = url
+    link.setAttribute('download', `${data.commonName}.zip`)
+    document.body.appendChild(link)
+    link.click()
+    document.body.removeChild(link)
+    window.URL.revokeObjectURL(url)
+  },
+
+  issued: async (caName?: string): Promise<IssuedCertRecord[]> => {
+    const res = await http.get('/security/ca/issued', { params: caName ? { ca: caName } : undefined })
+    return res.data || []
   },
 }
 
@@ -200,6 +251,13 @@ export const instanceApi = {
     return res.data
   },
 
+  drain: async (name: string, timeout?: number) => {
+    const params = timeout !== undefined ? { timeout } : {}
+    // 排空在宽限期结束后才返回，不限制请求超时
+    const res = await http.post(`/instances/${name}/drain`, null, { params, timeout: 0 })
+    return res.data as DrainResult
+  },
+
   reload: async (name: string) => {
     const res = await http.post(`/instances/${name}/reload`)
     return res.data
@@ -216,15 +274,30 @@ export const instanceApi = {
     return res.data
   },
 
-  logs: async (name: string, params?: { lines?: number; level?: string }) => {
+  logs: async (name: string, params?: InstanceLogQuery): Promise<InstanceLogEntry[]> => {
     const res = await http.get(`/instances/${name}/logs`, { params })
-    return res.data || []
+    return res.data?.logs || []
   },
 
   health: async (name: string, params?: { timeout?: number; protocol?: string }) => {
     const res = await http.get(`/instances/${name}/health`, { params })
     return res.data
   },
+
+  healthHistory: async (name: string) => {
+    const res = await http.get(`/instances/${name}/health/history`)
+    return res.data
+  },
+
+  connections: async (name: string) => {
+    const res = await http.get(`/instances/${name}/connections`)
+    return (res.data?.connections || []) as ConnInfo[]
+  },
+
+  closeConnection: async (name: string, id: string) => {
+    const res = await http.delete(`/instances/${name}/connections/${id}`)
+    return res.data
+  },
 }
 
 export const systemApi = {
@@ -255,6 +328,21 @@ export const logsApi = {
     return res.data
   },
 
+  search: async (params: LogSearchQuery): Promise<LogSearchResponse> => {
+    const res = await http.get('/system/logs/search', { params, timeout: 0 })
+    return res.data
+  },
+
+  // 订阅实时日志流，返回取消订阅的函数
+  stream: (params: { level?: string; instance?: string }, onEntry: (entry: InstanceLogEntry) => void) => {
+    const query = new URLSearchParams()
+    if (params.level) query.set('level', params.level)
+    if (params.instance) query.set('instance', params.instance)
+    const source = new EventSource(`${API_CONFIG.baseURL}/system/logs/stream?${query}`)
+    source.addEventListener('log', (e) => onEntry(JSON.parse((e as MessageEvent).data)))
+    return () => source.close()
+  },
+
   download: async (filename: string) => {
     const res = await http.get(`/system/logs/download/${filename}`, {
       responseType: 'blob'
@@ -1,6 +1,6 @@
 export interface Instance {
   name: string
-  status: 'created' | 'running' | 'stopped' | 'error'
+  status: 'created' | 'running' | 'degraded' | 'stopped' | 'error'
   config: InstanceConfig
   enabled: boolean
   uptime?: number
@@ -11,17 +11,121 @@ export interface InstanceConfig {
   type: 'server' | 'client' | 'http-server' | 'http-client'
   listen: string
   target: string
+  targets?: TargetConfig[]
+  loadBalance?: LoadBalanceConfig
   protocol: 'auto' | 'tlcp' | 'tls'
   auth?: 'none' | 'one-way' | 'mutual'
   enabled: boolean
   clientCa?: string[]
   serverCa?: string[]
+  crlCheck?: boolean
+  ocsp?: OCSPConfig
+  proxyProtocol?: ProxyProtocolConfig
+  healthCheck?: HealthCheckConfig
   tlcp: TLCPConfig
   tls: TLSConfig
   http?: HTTPConfig
   sni?: string
   bufferSize?: number
   stats?: StatsConfig
+  accessLog?: AccessLogConfig
+  log?: InstanceLogConfig
+}
+
+export interface TargetConfig {
+  address: string
+  weight?: number
+}
+
+export interface LoadBalanceConfig {
+  strategy?: 'round-robin' | 'least-conn' | 'source-hash'
+  maxFails?: number
+  failTimeout?: number
+}
+
+export interface OCSPConfig {
+  enabled: boolean
+  responders?: string[]
+  failurePolicy?: 'soft-fail' | 'hard-fail'
+  timeout?: number
+  cacheTtl?: number
+  stapling?: boolean
+}
+
+export interface ProxyProtocolConfig {
+  send?: '' | 'v1' | 'v2'
+  accept?: boolean
+  trusted?: string[]
+}
+
+export interface HealthCheckConfig {
+  enabled: boolean
+  type?: 'tcp' | 'handshake' | 'http'
+  interval?: number
+  timeout?: number
+  failureThreshold?: number
+  httpPath?: string
+  history?: number
+}
+
+export interface AccessLogConfig {
+  enabled: boolean
+  format?: 'json' | 'logfmt'
+  file?: string
+  maxSize?: number
+  maxBackups?: number
+  maxAge?: number
+  compress?: boolean
+}
+
+export interface InstanceLogConfig {
+  buffer?: number
+  file?: string
+  maxSize?: number
+  maxBackups?: number
+  maxAge?: number
+  compress?: boolean
+}
+
+export interface InstanceLogEntry {
+  timestamp: string
+  level: 'debug' | 'info' | 'warn' | 'error'
+  message: string
+  instance?: string
+}
+
+export interface LogSearchQuery {
+  since?: string
+  until?: string
+  level?: string
+  instance?: string
+  q?: string
+  regex?: boolean
+  offset?: number
+  limit?: number
+}
+
+export interface LogSearchMatch {
+  file: string
+  line: number
+  timestamp: string
+  level: 'debug' | 'info' | 'warn' | 'error' | 'fatal'
+  instance?: string
+  text: string
+}
+
+export interface LogSearchResponse {
+  matches: LogSearchMatch[]
+  total: number
+  offset: number
+  limit: number
+}
+
+export interface InstanceLogQuery {
+  level?: string
+  since?: string
+  until?: string
+  limit?: number
 }
 
 export interface LogConfig {
@@ -32,6 +136,16 @@ export interface LogConfig {
   maxAge: number
   compress: boolean
   enabled: boolean
+  format?: 'text' | 'json'
+  outputs?: ('stdout' | 'file' | 'syslog')[]
+  syslog?: SyslogConfig
+}
+
+export interface SyslogConfig {
+  network?: 'udp' | 'tcp' | 'unix'
+  address: string
+  facility?: string
+  tag?: string
 }
 
 export interface StatsConfig {
@@ -94,7 +208,18 @@ export interface InstanceStats {
   bytesSent: number
   requestsTotal?: number
   errors?: number
+  handshakeFailures?: number
   avgLatencyMs?: number
+  dialLatency?: LatencyPercentiles
+  handshakeLatency?: LatencyPercentiles
+  sessionDuration?: LatencyPercentiles
+}
+
+export interface LatencyPercentiles {
+  count: number
+  p50Ms: number
+  p90Ms: number
+  p99Ms: number
 }
 
 export interface KeyStoreInfo {
@@ -103,10 +228,17 @@ export interface KeyStoreInfo {
   loaderType: string
   params: Record<string, string>
   protected: boolean
+  issuer?: string
+  renewal?: RenewalPolicy
   createdAt: string
   updatedAt: string
 }
 
+export interface RenewalPolicy {
+  beforeDays?: number
+  rotateKey?: boolean
+}
+
 export interface GenerateKeyStoreRequest {
   name: string
   type: string
@@ -142,6 +274,66 @@ export interface RootCertInfo {
   keyUsage: string[]
 }
 
+export type CertExpiryStatus = 'ok' | 'warning' | 'critical' | 'expired' | 'error'
+
+export interface CertExpiryItem {
+  source: 'keystore' | 'rootcert'
+  name: string
+  usage?: 'sign' | 'enc'
+  subject?: string
+  serialNumber?: string
+  notBefore?: string
+  notAfter?: string
+  daysLeft: number
+  status: CertExpiryStatus
+  error?: string
+}
+
+export interface CertExpiryReport {
+  checkedAt: string
+  warningDays: number
+  criticalDays: number
+  items: CertExpiryItem[]
+  summary: Partial<Record<CertExpiryStatus, number>>
+}
+
+export interface IssuedCertRecord {
+  serialNumber: string
+  ca: string
+  kind: 'csr' | 'client'
+  usage: 'sign' | 'enc' | 'tls'
+  subject: string
+  dnsNames?: string[]
+  ipAddresses?: string[]
+  notBefore: string
+  notAfter: string
+  issuedAt: string
+}
+
+export interface SignCSRRequest {
+  csr: string
+  days?: number
+  usage?: 'sign' | 'enc' | 'tls'
+}
+
+export interface SignCSRResult extends IssuedCertRecord {
+  certificate: string
+}
+
+export interface IssueClientCertRequest {
+  commonName: string
+  country?: string
+  stateOrProvince?: string
+  locality?: string
+  org?: string
+  orgUnit?: string
+  days?: number
+  dnsNames?: string[]
+  ipAddresses?: string[]
+  keyAlgorithm?: 'rsa' | 'ecdsa'
+  keyBits?: number
+}
+
 export interface GenerateRootCARequest {
   type?: string
   commonName: string
@@ -200,9 +392,50 @@ export interface InstanceHealthResponse {
   results: HealthCheckResult[]
 }
 
+export interface HealthRecord {
+  time: string
+  type: 'tcp' | 'handshake' | 'http'
+  target: string
+  protocol?: string
+  success: boolean
+  latencyMs: number
+  error?: string
+}
+
+export interface InstanceHealthHistory {
+  enabled: boolean
+  type?: 'tcp' | 'handshake' | 'http'
+  degraded: boolean
+  consecutiveFailures: number
+  failureThreshold?: number
+  lastCheck?: string
+  history: HealthRecord[]
+}
+
+export interface ConnInfo {
+  id: string
+  remoteAddr?: string
+  target?: string
+  protocol?: 'tlcp' | 'tls'
+  version?: string
+  cipherSuite?: string
+  serverName?: string
+  peerSubject?: string
+  startTime: string
+  bytesReceived: number
+  bytesSent: number
+}
+
+export interface DrainResult {
+  status: 'created' | 'running' | 'degraded' | 'stopped' | 'error'
+  active: number
+  forceClosed: number
+  durationMs: number
+}
+
 export interface KeystoreInstance {
   name: string
-  status: 'created' | 'running' | 'stopped' | 'error'
+  status: 'created' | 'running' | 'degraded' | 'stopped' | 'error'
   protocol: 'auto' | 'tlcp' | 'tls'
 }
 
@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/tls"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -54,6 +55,17 @@ type MCPConfig struct {
 	APIKey string `yaml:"api_key,omitempty" json:"api_key,omitempty"`
 }
 
+// CertExpiryConfig 证书有效期监控配置
+// 定期检查所有 keystore 证书和根证书的剩余有效期，剩余天数少于阈值时告警
+type CertExpiryConfig struct {
+	// Interval 检查间隔，默认: 1h
+	Interval time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// WarningDays 剩余有效期少于该天数时为 warning，默认: 30
+	WarningDays int `yaml:"warning-days,omitempty" json:"warningDays,omitempty"`
+	// CriticalDays 剩余有效期少于该天数时为 critical，默认: 7，不能大于 WarningDays
+	CriticalDays int `yaml:"critical-days,omitempty" json:"criticalDays,omitempty"`
+}
+
 // Config 主配置结构，包含服务端配置、代理实例列表和证书目录
 type Config struct {
 	// Server 服务端配置，包含API、UI和日志配置
@@ -64,6 +76,8 @@ type Config struct {
 	Instances []InstanceConfig `yaml:"instances" json:"instances"`
 	// MCP MCP服务配置
 	MCP MCPConfig `yaml:"mcp,omitempty" json:"mcp,omitempty"`
+	// CertExpiry 证书有效期监控配置，nil表示使用默认配置
+	CertExpiry *CertExpiryConfig `yaml:"cert-expiry,omitempty" json:"certExpiry,omitempty"`
 	// WorkDir 工作目录（运行时设置，不从配置文件读取）
 	// Linux默认: /etc/tlcpchan
 	// Windows默认: 程序所在目录
@@ -78,6 +92,10 @@ type KeyStoreConfig struct {
 	Type keystore.LoaderType `yaml:"type" json:"type"`
 	// Params 加载器参数
 	Params map[string]string `yaml:"params" json:"params"`
+	// Issuer 签发证书的 CA keystore 名称，仅 keystores 列表中的文件 keystore 有效
+	Issuer string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	// Renewal 证书自动续期策略，需同时设置 Issuer，nil 表示不自动续期
+	Renewal *keystore.RenewalPolicy `yaml:"renewal,omitempty" json:"renewal,omitempty"`
 }
 
 // ServerConfig 服务端配置，定义管理界面和日志设置
@@ -114,6 +132,26 @@ type LogConfig struct {
 	Compress bool `yaml:"compress" json:"compress"`
 	// Enabled 是否启用日志
 	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Format 标准输出与日志文件的格式，可选值: "text"（默认）, "json"（每行一个JSON对象）
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+	// Outputs 输出目标，可选值: "stdout", "file", "syslog"，可同时使用
+	// 为空时输出到标准输出，设置了 File 时同时输出到日志文件；容器中可只使用 "stdout"
+	Outputs []string `yaml:"outputs,omitempty" json:"outputs,omitempty"`
+	// Syslog syslog 输出配置，Outputs 包含 "syslog" 时必须设置
+	Syslog *SyslogConfig `yaml:"syslog,omitempty" json:"syslog,omitempty"`
+}
+
+// SyslogConfig syslog 输出配置，按 RFC 5424 格式发送
+type SyslogConfig struct {
+	// Network 传输方式，可选值: "udp"（默认）, "tcp", "unix"
+	Network string `yaml:"network,omitempty" json:"network,omitempty"`
+	// Address syslog 服务地址，udp/tcp 为 "host:port"，unix 为套接字路径
+	// 示例: "10.0.0.10:514", "/dev/log"
+	Address string `yaml:"address" json:"address"`
+	// Facility 设施，可选值: "kern", "user", "daemon", "auth", "local0"~"local7" 等，默认: "local0"
+	Facility string `yaml:"facility,omitempty" json:"facility,omitempty"`
+	// Tag 应用名称（APP-NAME），默认: "tlcpchan"
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
 }
 
 // InstanceConfig 代理实例配置，定义单个代理服务的所有参数
@@ -133,6 +171,10 @@ type InstanceConfig struct {
 	// Target 目标地址，格式: "host:port"
 	// 示例: "192.168.1.100:443"
 	Target string `yaml:"target" json:"target"`
+	// Targets 多目标地址列表，配置后忽略 Target，按 LoadBalance 策略选择目标
+	Targets []TargetConfig `yaml:"targets,omitempty" json:"targets,omitempty"`
+	// LoadBalance 多目标负载均衡配置
+	LoadBalance *LoadBalanceConfig `yaml:"load-balance,omitempty" json:"loadBalance,omitempty"`
 	// Protocol 协议类型，可选值:
 	// - "auto": 自动检测，同时支持TLCP和TLS
 	// - "tlcp": 仅使用TLCP协议（国密）
@@ -144,12 +186,24 @@ type InstanceConfig struct {
 	TLCP TLCPConfig `yaml:"tlcp,omitempty" json:"tlcp,omitempty"`
 	// TLS TLS协议专用配置
 	TLS TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
-	// ClientCA 客户端CA证书路径列表，用于验证客户端证书
+	// ClientCA 客户端CA证书文件名列表，用于验证客户端证书
+	// 文件名对应根证书目录（rootcerts）中的文件，只有列出的根证书会用于验证本实例的客户端
 	// 示例: ["ca1.crt", "ca2.crt"]
 	ClientCA []string `yaml:"client-ca,omitempty" json:"clientCa,omitempty"`
-	// ServerCA 服务端CA证书路径列表，用于验证服务端证书
+	// ServerCA 服务端CA证书文件名列表，用于验证服务端证书
+	// 文件名对应根证书目录（rootcerts）中的文件，只有列出的根证书会用于验证本实例的服务端
 	// 示例: ["server-ca.crt"]
 	ServerCA []string `yaml:"server-ca,omitempty" json:"serverCa,omitempty"`
+	// CRLCheck 是否检查对端证书吊销状态
+	// 启用后服务端实例拒绝出现在CRL中的客户端证书，客户端实例拒绝出现在CRL中的服务端证书
+	// CRL文件（*.crl）存放于根证书目录（rootcerts），支持 SM2 与 RSA/ECDSA 签名
+	CRLCheck bool `yaml:"crl-check,omitempty" json:"crlCheck,omitempty"`
+	// OCSP OCSP证书状态检查与装订配置
+	OCSP *OCSPConfig `yaml:"ocsp,omitempty" json:"ocsp,omitempty"`
+	// ProxyProtocol PROXY协议配置，用于从负载均衡接收真实客户端地址，或向目标服务传递客户端地址与证书身份
+	ProxyProtocol *ProxyProtocolConfig `yaml:"proxy-protocol,omitempty" json:"proxyProtocol,omitempty"`
+	// HealthCheck 后台健康检查配置，连续失败达到阈值时实例状态标记为 degraded
+	HealthCheck *HealthCheckConfig `yaml:"health-check,omitempty" json:"healthCheck,omitempty"`
 	// HTTP HTTP协议专用配置，用于HTTP代理
 	HTTP *HTTPConfig `yaml:"http,omitempty" json:"http,omitempty"`
 	// Stats 统计信息配置
@@ -161,6 +215,10 @@ type InstanceConfig struct {
 	Timeout *TimeoutConfig `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	// BufferSize 缓冲区大小，单位字节，默认 4096
 	BufferSize int `yaml:"buffer-size,omitempty" json:"bufferSize,omitempty"`
+	// AccessLog 连接访问日志配置，每个连接结束时写入一条结构化记录，用于安全审计
+	AccessLog *AccessLogConfig `yaml:"access-log,omitempty" json:"accessLog,omitempty"`
+	// Log 实例日志配置，实例的运行日志保留在内存中供查询，可另外写入单独的文件
+	Log *InstanceLogConfig `yaml:"log,omitempty" json:"log,omitempty"`
 }
 
 // TLCPConfig TLCP协议配置（国密协议）
@@ -262,16 +320,227 @@ type StatsConfig struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
 }
 
+// OCSPConfig OCSP证书状态检查配置
+type OCSPConfig struct {
+	// Enabled 是否通过OCSP检查对端证书状态
+	// 服务端实例检查客户端证书，客户端实例检查服务端证书，TLCP与TLS均支持
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Responders OCSP响应服务地址列表，为空时使用对端证书AIA扩展中的地址
+	// 示例: ["http://ocsp.example.com"]
+	Responders []string `yaml:"responders,omitempty" json:"responders,omitempty"`
+	// FailurePolicy 无法获取有效响应时的处理策略，可选值:
+	// - "soft-fail": 放行，仅拒绝明确被吊销的证书（默认）
+	// - "hard-fail": 拒绝握手
+	FailurePolicy string `yaml:"failure-policy,omitempty" json:"failurePolicy,omitempty"`
+	// Timeout 单次查询超时，默认: 5s
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// CacheTTL 响应缓存最长时间，默认: 1h，响应的下次更新时间更早时以其为准
+	CacheTTL time.Duration `yaml:"cache-ttl,omitempty" json:"cacheTtl,omitempty"`
+	// Stapling 是否在TLS握手中装订本端证书的OCSP响应，仅服务端实例有效
+	// 响应服务地址取自本端证书的AIA扩展，TLCP不支持装订
+	Stapling bool `yaml:"stapling,omitempty" json:"stapling,omitempty"`
+}
+
+// InstanceLogConfig 实例日志配置
+type InstanceLogConfig struct {
+	// Buffer 内存中保留的最近日志条数，默认: 1000
+	Buffer int `yaml:"buffer,omitempty" json:"buffer,omitempty"`
+	// File 实例日志文件路径，为空则只输出到全局日志
+	// 示例: "./logs/proxy-1.log"
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// MaxSize 单个日志文件最大大小，单位: MB，0 表示不轮转
+	MaxSize int `yaml:"max-size,omitempty" json:"maxSize,omitempty"`
+	// MaxBackups 保留的旧日志文件最大数量，单位: 个
+	MaxBackups int `yaml:"max-backups,omitempty" json:"maxBackups,omitempty"`
+	// MaxAge 保留旧日志文件的最大天数，单位: 天
+	MaxAge int `yaml:"max-age,omitempty" json:"maxAge,omitempty"`
+	// Compress 是否压缩旧日志文件
+	Compress bool `yaml:"compress,omitempty" json:"compress,omitempty"`
+}
+
+// 访问日志格式
+const (
+	// AccessLogJSON 每行一个JSON对象（默认）
+	AccessLogJSON = "json"
+	// AccessLogLogfmt 每行一组 key=value
+	AccessLogLogfmt = "logfmt"
+)
+
+// AccessLogConfig 连接访问日志配置
+type AccessLogConfig struct {
+	// Enabled 是否启用访问日志
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Format 记录格式，可选值: "json"（默认）、"logfmt"
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+	// File 访问日志文件路径，默认: "./logs/<实例名称>-access.log"
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+	// MaxSize 单个文件最大大小，单位: MB，默认: 100
+	MaxSize int `yaml:"max-size,omitempty" json:"maxSize,omitempty"`
+	// MaxBackups 保留的旧文件最大数量，单位: 个，默认: 0（不限制）
+	MaxBackups int `yaml:"max-backups,omitempty" json:"maxBackups,omitempty"`
+	// MaxAge 保留旧文件的最大天数，单位: 天，默认: 0（不限制）
+	MaxAge int `yaml:"max-age,omitempty" json:"maxAge,omitempty"`
+	// Compress 是否压缩旧文件
+	Compress bool `yaml:"compress,omitempty" json:"compress,omitempty"`
+}
+
+// DefaultAccessLogMaxSize 访问日志单个文件默认最大大小，单位: MB
+const DefaultAccessLogMaxSize = 100
+
+// AccessLogFile 获取实例的访问日志文件路径
+// 返回:
+//   - string: 配置的 File，未配置时返回 "./logs/<实例名称>-access.log"
+func (c *InstanceConfig) AccessLogFile() string {
+	if c.AccessLog != nil && c.AccessLog.File != "" {
+		return c.AccessLog.File
+	}
+	return "./logs/" + c.Name + "-access.log"
+}
+
+// TargetConfig 目标地址配置
+type TargetConfig struct {
+	// Address 目标地址，格式: "host:port"
+	Address string `yaml:"address" json:"address"`
+	// Weight 权重，默认: 1
+	Weight int `yaml:"weight,omitempty" json:"weight,omitempty"`
+}
+
+// 负载均衡策略
+const (
+	// LoadBalanceRoundRobin 加权轮询（默认）
+	LoadBalanceRoundRobin = "round-robin"
+	// LoadBalanceLeastConn 加权最少连接
+	LoadBalanceLeastConn = "least-conn"
+	// LoadBalanceSourceHash 按客户端IP哈希，同一客户端固定连接同一目标
+	LoadBalanceSourceHash = "source-hash"
+)
+
+// LoadBalanceConfig 多目标负载均衡配置
+type LoadBalanceConfig struct {
+	// Strategy 负载均衡策略，可选值: "round-robin"（默认）、"least-conn"、"source-hash"
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	// MaxFails 连续连接失败多少次后暂时摘除目标，默认: 3
+	MaxFails int `yaml:"max-fails,omitempty" json:"maxFails,omitempty"`
+	// FailTimeout 目标被摘除的时长，到期后重新尝试，默认: 30s
+	FailTimeout time.Duration `yaml:"fail-timeout,omitempty" json:"failTimeout,omitempty"`
+}
+
+// 健康检查探测方式
+const (
+	// HealthCheckTCP 仅建立TCP连接
+	HealthCheckTCP = "tcp"
+	// HealthCheckHandshake 完成TLCP/TLS握手（默认）
+	HealthCheckHandshake = "handshake"
+	// HealthCheckHTTP 通过隧道发送HTTP GET请求
+	HealthCheckHTTP = "http"
+)
+
+// HealthCheckConfig 后台健康检查配置
+type HealthCheckConfig struct {
+	// Enabled 是否启用后台健康检查
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Type 探测方式，可选值: "tcp"、"handshake"（默认）、"http"
+	// - tcp: 连接每个目标地址
+	// - handshake: 服务端实例与监听地址握手，客户端实例与每个目标地址握手
+	// - http: 通过本实例的隧道发送 HTTP GET 请求，响应状态码小于500视为成功
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Interval 探测间隔，默认: 30s
+	Interval time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	// Timeout 单次探测超时时间，默认: 5s
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// FailureThreshold 连续失败多少次后将实例标记为 degraded，默认: 3
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failureThreshold,omitempty"`
+	// HTTPPath http 探测的请求路径，默认: "/"
+	HTTPPath string `yaml:"http-path,omitempty" json:"httpPath,omitempty"`
+	// History 保留的探测记录数量，默认: 100
+	History int `yaml:"history,omitempty" json:"history,omitempty"`
+}
+
+// TargetList 获取实例的目标地址列表
+// 返回:
+//   - []TargetConfig: 配置了 Targets 时返回 Targets，否则返回只包含 Target 的列表，权重未设置时为1
+func (c *InstanceConfig) TargetList() []TargetConfig {
+	if len(c.Targets) == 0 {
+		if c.Target == "" {
+			return nil
+		}
+		return []TargetConfig{{Address: c.Target, Weight: 1}}
+	}
+	targets := make([]TargetConfig, len(c.Targets))
+	for i, t := range c.Targets {
+		if t.Weight <= 0 {
+			t.Weight = 1
+		}
+		targets[i] = t
+	}
+	return targets
+}
+
+// PrimaryTarget 获取主目标地址，即目标地址列表中的第一个
+func (c *InstanceConfig) PrimaryTarget() string {
+	if targets := c.TargetList(); len(targets) > 0 {
+		return targets[0].Address
+	}
+	return ""
+}
+
+// ProxyProtocolConfig PROXY协议配置
+type ProxyProtocolConfig struct {
+	// Send 连接目标服务后发送的PROXY协议头版本，仅 server 类型实例有效，可选值:
+	// - "": 不发送（默认）
+	// - "v1": 文本格式，只包含客户端与代理的地址
+	// - "v2": 二进制格式，额外通过TLV携带协商协议、密码套件、SNI及客户端签名证书的主题与序列号
+	Send string `yaml:"send,omitempty" json:"send,omitempty"`
+	// Accept 是否在握手前解析监听连接上的PROXY协议头（v1/v2），用于部署在四层负载均衡之后
+	// 启用后日志、变量等使用协议头中的真实客户端地址，所有连接都必须携带协议头
+	Accept bool `yaml:"accept,omitempty" json:"accept,omitempty"`
+	// Trusted 允许发送PROXY协议头的上游地址，CIDR 或 IP，启用 Accept 时不能为空
+	// 启用 Accept 后来自其他地址的连接会被拒绝
+	// 示例: ["10.0.0.0/8", "192.168.1.10"]
+	Trusted []string `yaml:"trusted,omitempty" json:"trusted,omitempty"`
+}
+
+// ParseTrustedCIDRs 解析PROXY协议可信上游地址列表
+// 参数:
+//   - trusted: CIDR 或 IP 列表，单个 IP 视为只包含该地址的网段
+//
+// 返回:
+//   - []*net.IPNet: 网段列表
+//   - error: 地址格式无效时返回错误
+func ParseTrustedCIDRs(trusted []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(trusted))
+	for _, s := range trusted {
+		if _, ipNet, err := net.ParseCIDR(s); err == nil {
+			nets = append(nets, ipNet)
+			continue
+		}
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("无效的地址 %s", s)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets, nil
+}
+
 // TimeoutConfig 连接超时配置
 type TimeoutConfig struct {
 	// Dial 连接建立超时，默认: 10s
 	Dial time.Duration `yaml:"dial,omitempty" json:"dial,omitempty"`
-	// Read 读取超时，默认: 30s
+	// Read 读取空闲超时，连接两个方向均无数据传输超过该时长时关闭连接，HTTP代理用作请求头读取超时，默认: 30s
 	Read time.Duration `yaml:"read,omitempty" json:"read,omitempty"`
-	// Write 写入超时，默认: 30s
+	// Write 单次写入超时，对端长时间不接收数据时关闭连接，默认: 30s
 	Write time.Duration `yaml:"write,omitempty" json:"write,omitempty"`
 	// Handshake TLS/TLCP握手超时，默认: 15s
 	Handshake time.Duration `yaml:"handshake,omitempty" json:"handshake,omitempty"`
+	// MaxSession 单个连接的最长存活时间，到期后关闭连接，默认: 0（不限制）
+	MaxSession time.Duration `yaml:"max-session,omitempty" json:"maxSession,omitempty"`
+	// Drain 排空宽限期，排空实例时等待活跃连接结束的最长时间，超时后强制关闭剩余连接，默认: 30s
+	Drain time.Duration `yaml:"drain,omitempty" json:"drain,omitempty"`
 }
 
 // DefaultTimeout 返回默认超时配置
@@ -283,7 +552,21 @@ func DefaultTimeout() *TimeoutConfig {
 		Read:      30 * time.Second,
 		Write:     30 * time.Second,
 		Handshake: 15 * time.Second,
+		Drain:     DefaultDrainTimeout,
+	}
+}
+
+// DefaultDrainTimeout 默认排空宽限期
+const DefaultDrainTimeout = 30 * time.Second
+
+// DrainTimeout 获取实例的排空宽限期
+// 返回:
+//   - time.Duration: 超时配置中的 Drain，未配置时返回 DefaultDrainTimeout
+func (c *InstanceConfig) DrainTimeout() time.Duration {
+	if c.Timeout != nil && c.Timeout.Drain > 0 {
+		return c.Timeout.Drain
 	}
+	return DefaultDrainTimeout
 }
 
 // Default 返回默认配置
@@ -383,6 +666,23 @@ func Validate(cfg *Config) error {
 		cfg.Server.API.Address = ":20080"
 	}
 
+	if err := validateLog(cfg.Server.Log); err != nil {
+		return err
+	}
+
+	if e := cfg.CertExpiry; e != nil {
+		if e.Interval < 0 || e.WarningDays < 0 || e.CriticalDays < 0 {
+			return fmt.Errorf("证书有效期监控的检查间隔和阈值不能为负数")
+		}
+		warningDays := e.WarningDays
+		if warningDays == 0 {
+			warningDays = 30
+		}
+		if e.CriticalDays > warningDays {
+			return fmt.Errorf("证书有效期监控的 critical-days(%d) 不能大于 warning-days(%d)", e.CriticalDays, warningDays)
+		}
+	}
+
 	// 验证 keystores
 	ksNames := make(map[string]bool)
 	for i, ks := range cfg.KeyStores {
@@ -398,6 +698,23 @@ func Validate(cfg *Config) error {
 			return fmt.Errorf("keystore %s: 加载器类型不能为空", ks.Name)
 		}
 	}
+	for _, ks := range cfg.KeyStores {
+		if ks.Issuer != "" && !ksNames[ks.Issuer] {
+			return fmt.Errorf("keystore %s: 签发者 keystore %s 不存在", ks.Name, ks.Issuer)
+		}
+		if ks.Renewal == nil {
+			continue
+		}
+		if ks.Issuer == "" {
+			return fmt.Errorf("keystore %s: 自动续期需要设置签发者 issuer", ks.Name)
+		}
+		if ks.Type != keystore.LoaderTypeFile {
+			return fmt.Errorf("keystore %s: 只有文件类型的 keystore 支持自动续期", ks.Name)
+		}
+		if ks.Renewal.BeforeDays < 0 {
+			return fmt.Errorf("keystore %s: 续期提前天数不能为负数", ks.Name)
+		}
+	}
 
 	instanceNames := make(map[string]bool)
 	for i, inst := range cfg.Instances {
@@ -412,9 +729,24 @@ func Validate(cfg *Config) error {
 		if inst.Listen == "" {
 			return fmt.Errorf("实例 %s: 监听地址不能为空", inst.Name)
 		}
-		if inst.Target == "" {
+		if inst.Target == "" && len(inst.Targets) == 0 {
 			return fmt.Errorf("实例 %s: 目标地址不能为空", inst.Name)
 		}
+		for _, t := range inst.Targets {
+			if t.Address == "" {
+				return fmt.Errorf("实例 %s: 目标地址不能为空", inst.Name)
+			}
+			if t.Weight < 0 {
+				return fmt.Errorf("实例 %s: 目标 %s 的权重不能为负数", inst.Name, t.Address)
+			}
+		}
+		if inst.LoadBalance != nil {
+			switch inst.LoadBalance.Strategy {
+			case "", LoadBalanceRoundRobin, LoadBalanceLeastConn, LoadBalanceSourceHash:
+			default:
+				return fmt.Errorf("实例 %s: 无效的负载均衡策略 %s", inst.Name, inst.LoadBalance.Strategy)
+			}
+		}
 
 		if inst.Type == "" {
 			return fmt.Errorf("实例 %s: 类型不能为空", inst.Name)
@@ -467,6 +799,56 @@ func Validate(cfg *Config) error {
 			cfg.Instances[i].TLS.MaxVersion = "1.3"
 		}
 
+		if inst.OCSP != nil {
+			switch inst.OCSP.FailurePolicy {
+			case "", "soft-fail", "hard-fail":
+			default:
+				return fmt.Errorf("实例 %s: 无效的OCSP失败策略 %s", inst.Name, inst.OCSP.FailurePolicy)
+			}
+		}
+
+		if inst.ProxyProtocol != nil {
+			switch inst.ProxyProtocol.Send {
+			case "", "v1", "v2":
+			default:
+				return fmt.Errorf("实例 %s: 无效的PROXY协议版本 %s", inst.Name, inst.ProxyProtocol.Send)
+			}
+			if _, err := ParseTrustedCIDRs(inst.ProxyProtocol.Trusted); err != nil {
+				return fmt.Errorf("实例 %s: PROXY协议可信上游配置错误: %w", inst.Name, err)
+			}
+			if inst.ProxyProtocol.Accept && len(inst.ProxyProtocol.Trusted) == 0 {
+				return fmt.Errorf("实例 %s: 启用PROXY协议接收时必须配置可信上游 trusted", inst.Name)
+			}
+		}
+
+		if inst.HealthCheck != nil {
+			switch inst.HealthCheck.Type {
+			case "", HealthCheckTCP, HealthCheckHandshake, HealthCheckHTTP:
+			default:
+				return fmt.Errorf("实例 %s: 无效的健康检查方式 %s", inst.Name, inst.HealthCheck.Type)
+			}
+			if inst.HealthCheck.Interval < 0 || inst.HealthCheck.Timeout < 0 {
+				return fmt.Errorf("实例 %s: 健康检查间隔与超时时间不能为负数", inst.Name)
+			}
+		}
+
+		if inst.AccessLog != nil {
+			switch inst.AccessLog.Format {
+			case "", AccessLogJSON, AccessLogLogfmt:
+			default:
+				return fmt.Errorf("实例 %s: 无效的访问日志格式 %s", inst.Name, inst.AccessLog.Format)
+			}
+			if inst.AccessLog.MaxSize < 0 || inst.AccessLog.MaxBackups < 0 || inst.AccessLog.MaxAge < 0 {
+				return fmt.Errorf("实例 %s: 访问日志轮转参数不能为负数", inst.Name)
+			}
+		}
+
+		if inst.Log != nil {
+			if inst.Log.Buffer < 0 || inst.Log.MaxSize < 0 || inst.Log.MaxBackups < 0 || inst.Log.MaxAge < 0 {
+				return fmt.Errorf("实例 %s: 实例日志配置不能为负数", inst.Name)
+			}
+		}
+
 		// 设置默认超时配置
 		if inst.Timeout == nil {
 			cfg.Instances[i].Timeout = DefaultTimeout()
@@ -481,6 +863,48 @@ func Validate(cfg *Config) error {
 	return nil
 }
 
+// validateLog 校验服务日志的格式、输出目标与 syslog 配置
+func validateLog(log *LogConfig) error {
+	if log == nil {
+		return nil
+	}
+	switch log.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("无效的日志格式: %s", log.Format)
+	}
+	for _, output := range log.Outputs {
+		switch output {
+		case "stdout":
+		case "file":
+			if log.File == "" {
+				return fmt.Errorf("日志输出到文件时必须设置 file")
+			}
+		case "syslog":
+			if log.Syslog == nil || log.Syslog.Address == "" {
+				return fmt.Errorf("日志输出到 syslog 时必须设置 syslog.address")
+			}
+		default:
+			return fmt.Errorf("无效的日志输出目标: %s", output)
+		}
+	}
+	if log.Syslog != nil {
+		switch log.Syslog.Network {
+		case "", "udp", "tcp", "unix":
+		default:
+			return fmt.Errorf("无效的 syslog 传输方式: %s", log.Syslog.Network)
+		}
+		switch log.Syslog.Facility {
+		case "", "kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+			"uucp", "cron", "authpriv", "ftp",
+			"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7":
+		default:
+			return fmt.Errorf("无效的 syslog 设施: %s", log.Syslog.Facility)
+		}
+	}
+	return nil
+}
+
 var TLCPCipherSuiteNames = map[string]uint16{
 	"ECC_SM4_CBC_SM3":   tlcp.ECC_SM4_CBC_SM3,
 	"ECC_SM4_GCM_SM3":   tlcp.ECC_SM4_GCM_SM3,
@@ -713,6 +1137,16 @@ func (c *Config) GetRootCertDir() string {
 	return "./rootcerts"
 }
 
+// GetCADir 获取内置 CA 签发记录存储目录路径
+// 返回:
+//   - string: 签发记录存储目录路径
+func (c *Config) GetCADir() string {
+	if c.WorkDir != "" {
+		return filepath.Join(c.WorkDir, "ca")
+	}
+	return "./ca"
+}
+
 var (
 	globalConfig     *Config
 	globalConfigPath string
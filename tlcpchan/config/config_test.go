@@ -342,3 +342,290 @@ mcp:
 		t.Errorf("期望保存的 API Key 'new-api-key-456', 实际 '%s'", reloadedCfg.MCP.APIKey)
 	}
 }
+
+func TestLogOutputConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "JSON格式同时输出到标准输出与syslog",
+			yaml: `
+server:
+  log:
+    level: info
+    format: json
+    outputs: [stdout, syslog]
+    syslog:
+      network: tcp
+      address: "10.0.0.10:514"
+      facility: local3
+`,
+		},
+		{
+			name: "仅标准输出",
+			yaml: `
+server:
+  log:
+    outputs: [stdout]
+`,
+		},
+		{
+			name: "无效的格式",
+			yaml: `
+server:
+  log:
+    format: xml
+`,
+			wantErr: true,
+		},
+		{
+			name: "无效的输出目标",
+			yaml: `
+server:
+  log:
+    outputs: [kafka]
+`,
+			wantErr: true,
+		},
+		{
+			name: "输出到文件但未设置文件路径",
+			yaml: `
+server:
+  log:
+    file: ""
+    outputs: [file]
+`,
+			wantErr: true,
+		},
+		{
+			name: "输出到syslog但未设置地址",
+			yaml: `
+server:
+  log:
+    outputs: [syslog]
+`,
+			wantErr: true,
+		},
+		{
+			name: "无效的syslog设施",
+			yaml: `
+server:
+  log:
+    outputs: [syslog]
+    syslog:
+      address: 127.0.0.1:514
+      facility: local9
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if err := yaml.Unmarshal([]byte(tt.yaml), cfg); err != nil {
+				t.Fatalf("解析 YAML 失败: %v", err)
+			}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCertExpiryConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "自定义检查间隔与阈值",
+			yaml: `
+cert-expiry:
+  interval: 30m
+  warning-days: 60
+  critical-days: 14
+`,
+		},
+		{
+			name: "只设置严重告警阈值",
+			yaml: `
+cert-expiry:
+  critical-days: 10
+`,
+		},
+		{
+			name: "阈值为负数",
+			yaml: `
+cert-expiry:
+  warning-days: -1
+`,
+			wantErr: true,
+		},
+		{
+			name: "严重告警阈值大于默认告警阈值",
+			yaml: `
+cert-expiry:
+  critical-days: 45
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if err := yaml.Unmarshal([]byte(tt.yaml), cfg); err != nil {
+				t.Fatalf("解析 YAML 失败: %v", err)
+			}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyStoreRenewalConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "由CA签发并自动续期",
+			yaml: `
+keystores:
+  - name: ca
+    type: file
+    params: {sign-cert: ca.crt, sign-key: ca.key}
+  - name: web
+    type: file
+    params: {sign-cert: web.crt, sign-key: web.key}
+    issuer: ca
+    renewal:
+      before-days: 20
+      rotate-key: true
+`,
+		},
+		{
+			name: "签发者不存在",
+			yaml: `
+keystores:
+  - name: web
+    type: file
+    params: {sign-cert: web.crt, sign-key: web.key}
+    issuer: ca
+`,
+			wantErr: true,
+		},
+		{
+			name: "自动续期未设置签发者",
+			yaml: `
+keystores:
+  - name: web
+    type: file
+    params: {sign-cert: web.crt, sign-key: web.key}
+    renewal: {}
+`,
+			wantErr: true,
+		},
+		{
+			name: "named 类型不支持自动续期",
+			yaml: `
+keystores:
+  - name: ca
+    type: file
+    params: {sign-cert: ca.crt, sign-key: ca.key}
+  - name: alias
+    type: named
+    params: {name: ca}
+    issuer: ca
+    renewal: {before-days: 10}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if err := yaml.Unmarshal([]byte(tt.yaml), cfg); err != nil {
+				t.Fatalf("解析 YAML 失败: %v", err)
+			}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{
+			name: "接收PROXY协议并配置可信上游",
+			yaml: `
+instances:
+  - name: lb
+    type: server
+    protocol: tlcp
+    listen: ":20001"
+    target: "127.0.0.1:8080"
+    proxy-protocol:
+      accept: true
+      trusted: ["10.0.0.0/8", "192.168.1.10"]
+`,
+		},
+		{
+			name: "接收PROXY协议未配置可信上游",
+			yaml: `
+instances:
+  - name: lb
+    type: server
+    protocol: tlcp
+    listen: ":20001"
+    target: "127.0.0.1:8080"
+    proxy-protocol:
+      accept: true
+`,
+			wantErr: true,
+		},
+		{
+			name: "可信上游地址无效",
+			yaml: `
+instances:
+  - name: lb
+    type: server
+    protocol: tlcp
+    listen: ":20001"
+    target: "127.0.0.1:8080"
+    proxy-protocol:
+      accept: true
+      trusted: ["10.0.0.0/33"]
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			if err := yaml.Unmarshal([]byte(tt.yaml), cfg); err != nil {
+				t.Fatalf("解析 YAML 失败: %v", err)
+			}
+			err := Validate(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"github.com/Trisia/tlcpchan/security/ca"
+	"github.com/Trisia/tlcpchan/security/certgen"
+)
+
+// SignCSRRequest 签发证书请求的请求体
+type SignCSRRequest struct {
+	CSR   string `json:"csr"`
+	Days  int    `json:"days,omitempty"`
+	Usage string `json:"usage,omitempty"`
+}
+
+// SignCSRResponse 签发证书请求的响应
+type SignCSRResponse struct {
+	ca.Record
+	Certificate string `json:"certificate"`
+}
+
+// IssueClientCertRequest 签发客户端证书的请求体
+type IssueClientCertRequest struct {
+	CommonName      string               `json:"commonName"`
+	Country         string               `json:"country,omitempty"`
+	StateOrProvince string               `json:"stateOrProvince,omitempty"`
+	Locality        string               `json:"locality,omitempty"`
+	Org             string               `json:"org,omitempty"`
+	OrgUnit         string               `json:"orgUnit,omitempty"`
+	Days            int                  `json:"days,omitempty"`
+	DNSNames        []string             `json:"dnsNames,omitempty"`
+	IPAddresses     []string             `json:"ipAddresses,omitempty"`
+	KeyAlgorithm    certgen.KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	KeyBits         int                  `json:"keyBits,omitempty"`
+}
+
+// bundleNamePattern 证书包文件名中需要替换的字符
+var bundleNamePattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+/**
+ * @api {post} /api/security/ca/:name/sign 签发证书请求(CSR)
+ * @apiName SignCSR
+ * @apiGroup Security-CA
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 使用 CA keystore 签发上传的证书请求，支持 SM2 与 RSA/ECDSA 证书请求。
+ * CA keystore 必须为文件类型且证书为 CA 证书，证书主题和备用名称取自证书请求，
+ * 有效期不超过 CA 证书的剩余有效期，签发记录保存在工作目录的 ca/issued.json 中
+ *
+ * @apiParam {String} name CA keystore 名称（路径参数），如 "tlcpchan-tlcp-root-ca"
+ *
+ * @apiBody {String} csr 证书请求，支持 PEM、DER（HEX/Base64 编码）格式
+ * @apiBody {Number} [days=365] 有效期（天）
+ * @apiBody {String} [usage] 证书用途，可选值: "sign"（TLCP 签名证书）、"enc"（TLCP 加密证书）、"tls"（TLS 证书）；
+ * 为空时 SM2 证书请求签发签名证书，其他签发 TLS 证书
+ *
+ * @apiSuccess {String} serialNumber 证书序列号（十六进制）
+ * @apiSuccess {String} ca 签发 CA 的 keystore 名称
+ * @apiSuccess {String} kind 签发方式，固定为 "csr"
+ * @apiSuccess {String} usage 证书用途
+ * @apiSuccess {String} subject 证书主题
+ * @apiSuccess {String[]} [dnsNames] DNS 备用名称
+ * @apiSuccess {String[]} [ipAddresses] IP 备用名称
+ * @apiSuccess {String} notBefore 证书生效时间，ISO 8601 格式
+ * @apiSuccess {String} notAfter 证书过期时间，ISO 8601 格式
+ * @apiSuccess {String} issuedAt 签发时间，ISO 8601 格式
+ * @apiSuccess {String} certificate PEM 格式的证书
+ *
+ * @apiParamExample {json} Request-Example:
+ *     {
+ *       "csr": "-----BEGIN CERTIFICATE REQUEST-----\n...\n-----END CERTIFICATE REQUEST-----",
+ *       "days": 365,
+ *       "usage": "sign"
+ *     }
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "serialNumber": "5C1E0A3F9B2D4E6F8A7B6C5D4E3F2A1B",
+ *       "ca": "tlcpchan-tlcp-root-ca",
+ *       "kind": "csr",
+ *       "usage": "sign",
+ *       "subject": "CN=client-01,O=example",
+ *       "notBefore": "2024-06-01T10:00:00Z",
+ *       "notAfter": "2025-06-01T10:00:00Z",
+ *       "issuedAt": "2024-06-01T10:00:00Z",
+ *       "certificate": "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----\n"
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     CA 不存在
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     签发失败: CSR签名无效: 具体错误信息
+ */
+func (c *SecurityController) SignCSR(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	if _, err := c.keyStoreMgr.Get(name); err != nil {
+		NotFound(w, "CA 不存在")
+		return
+	}
+
+	var req SignCSRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		BadRequest(w, "无效的请求: "+err.Error())
+		return
+	}
+	if req.CSR == "" {
+		BadRequest(w, "csr不能为空")
+		return
+	}
+
+	issued, err := c.ca.SignCSR(name, []byte(req.CSR), ca.SignRequest{Days: req.Days, Usage: req.Usage})
+	if err != nil {
+		BadRequest(w, "签发失败: "+err.Error())
+		return
+	}
+
+	c.log.Info("CA %s 签发证书: %s, 序列号: %s", name, issued.Subject, issued.SerialNumber)
+	Success(w, SignCSRResponse{Record: issued.Record, Certificate: string(issued.CertPEM)})
+}
+
+/**
+ * @api {post} /api/security/ca/:name/issue 签发客户端证书
+ * @apiName IssueClientCert
+ * @apiGroup Security-CA
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 使用 CA keystore 生成客户端密钥并签发证书，打包为 ZIP 下载，用于双向认证的客户端。
+ * SM2 CA 签发 TLCP 签名与加密双证书（sign.crt、sign.key、enc.crt、enc.key），
+ * RSA/ECDSA CA 签发 TLS 证书（client.crt、client.key），证书包中均包含 CA 证书 ca.crt。
+ * 私钥仅包含在证书包中，服务端不保存；签发记录保存在工作目录的 ca/issued.json 中
+ *
+ * @apiParam {String} name CA keystore 名称（路径参数），如 "tlcpchan-tlcp-root-ca"
+ *
+ * @apiBody {String} commonName 通用名称(CN)
+ * @apiBody {String} [country] 国家代码(C)
+ * @apiBody {String} [stateOrProvince] 省/州(ST)
+ * @apiBody {String} [locality] 地区/城市(L)
+ * @apiBody {String} [org=tlcpchan] 组织名称(O)
+ * @apiBody {String} [orgUnit] 组织单位(OU)
+ * @apiBody {Number} [days=365] 有效期（天），不超过 CA 证书的剩余有效期
+ * @apiBody {String[]} [dnsNames] DNS主题备用名称列表
+ * @apiBody {String[]} [ipAddresses] IP主题备用名称列表
+ * @apiBody {String} [keyAlgorithm=ecdsa] 密钥算法，仅 RSA/ECDSA CA 有效，可选值: "rsa", "ecdsa"
+ * @apiBody {Number} [keyBits=2048] RSA 密钥长度
+ *
+ * @apiSuccess {File} - ZIP 格式的证书包，文件名为 "<commonName>.zip"
+ *
+ * @apiParamExample {json} Request-Example:
+ *     {
+ *       "commonName": "client-01",
+ *       "org": "example",
+ *       "days": 365
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     CA 不存在
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     commonName不能为空
+ */
+func (c *SecurityController) IssueClientCert(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	if _, err := c.keyStoreMgr.Get(name); err != nil {
+		NotFound(w, "CA 不存在")
+		return
+	}
+
+	var req IssueClientCertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		BadRequest(w, "无效的请求: "+err.Error())
+		return
+	}
+	if req.CommonName == "" {
+		BadRequest(w, "commonName不能为空")
+		return
+	}
+
+	bundle, err := c.ca.IssueClient(name, ca.ClientRequest{
+		CommonName:      req.CommonName,
+		Country:         req.Country,
+		StateOrProvince: req.StateOrProvince,
+		Locality:        req.Locality,
+		Org:             req.Org,
+		OrgUnit:         req.OrgUnit,
+		Days:            req.Days,
+		DNSNames:        req.DNSNames,
+		IPAddresses:     req.IPAddresses,
+		KeyAlgorithm:    req.KeyAlgorithm,
+		KeyBits:         req.KeyBits,
+	})
+	if err != nil {
+		BadRequest(w, "签发失败: "+err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		InternalError(w, "打包证书失败: "+err.Error())
+		return
+	}
+
+	for _, record := range bundle.Records {
+		c.log.Info("CA %s 签发客户端证书: %s, 序列号: %s", name, record.Subject, record.SerialNumber)
+	}
+	zipName := bundleNamePattern.ReplaceAllString(req.CommonName, "_") + ".zip"
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+zipName+"\"")
+	w.Header().Set("Content-Type", "application/zip")
+	w.Write(buf.Bytes())
+}
+
+/**
+ * @api {get} /api/security/ca/issued 签发记录列表
+ * @apiName ListIssuedCerts
+ * @apiGroup Security-CA
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取内置 CA 签发的所有证书记录，按签发时间从新到旧排列，记录中不包含私钥
+ *
+ * @apiQuery {String} [ca] CA keystore 名称，只返回该 CA 的签发记录
+ *
+ * @apiSuccess {Object[]} - 签发记录列表
+ * @apiSuccess {String} -.serialNumber 证书序列号（十六进制），不重复
+ * @apiSuccess {String} -.ca 签发 CA 的 keystore 名称
+ * @apiSuccess {String} -.kind 签发方式，可选值: "csr"（签发证书请求）、"client"（签发客户端证书）
+ * @apiSuccess {String} -.usage 证书用途，可选值: "sign", "enc", "tls"
+ * @apiSuccess {String} -.subject 证书主题
+ * @apiSuccess {String[]} [-.dnsNames] DNS 备用名称
+ * @apiSuccess {String[]} [-.ipAddresses] IP 备用名称
+ * @apiSuccess {String} -.notBefore 证书生效时间，ISO 8601 格式
+ * @apiSuccess {String} -.notAfter 证书过期时间，ISO 8601 格式
+ * @apiSuccess {String} -.issuedAt 签发时间，ISO 8601 格式
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "serialNumber": "5C1E0A3F9B2D4E6F8A7B6C5D4E3F2A1B",
+ *         "ca": "tlcpchan-tlcp-root-ca",
+ *         "kind": "client",
+ *         "usage": "sign",
+ *         "subject": "CN=client-01,O=example",
+ *         "notBefore": "2024-06-01T10:00:00Z",
+ *         "notAfter": "2025-06-01T10:00:00Z",
+ *         "issuedAt": "2024-06-01T10:00:00Z"
+ *       }
+ *     ]
+ */
+func (c *SecurityController) ListIssuedCerts(w http.ResponseWriter, r *http.Request) {
+	records, err := c.ca.List(r.URL.Query().Get("ca"))
+	if err != nil {
+		InternalError(w, "读取签发记录失败: "+err.Error())
+		return
+	}
+	Success(w, records)
+}
@@ -40,6 +40,13 @@ func NewConfigController(configPath string) *ConfigController {
  * @apiSuccess {Number} [config.server.log.maxAge] 保留旧日志文件的最大天数，单位: 天
  * @apiSuccess {Boolean} [config.server.log.compress] 是否压缩旧日志文件
  * @apiSuccess {Boolean} [config.server.log.enabled] 是否启用日志
+ * @apiSuccess {String} [config.server.log.format] 标准输出与日志文件的格式，可选值: "text"（默认）, "json"
+ * @apiSuccess {String[]} [config.server.log.outputs] 输出目标，可选值: "stdout", "file", "syslog"，可同时使用，为空时输出到标准输出与日志文件
+ * @apiSuccess {Object} [config.server.log.syslog] syslog 输出配置（RFC 5424）
+ * @apiSuccess {String} [config.server.log.syslog.network] 传输方式，可选值: "udp"（默认）, "tcp", "unix"
+ * @apiSuccess {String} config.server.log.syslog.address syslog 服务地址，示例: "10.0.0.10:514", "/dev/log"
+ * @apiSuccess {String} [config.server.log.syslog.facility] 设施，默认: "local0"
+ * @apiSuccess {String} [config.server.log.syslog.tag] 应用名称，默认: "tlcpchan"
  * @apiSuccess {Object[]} [config.keystores] 密钥存储配置列表
  * @apiSuccess {String} [config.keystores.name] 密钥存储名称，唯一标识符
  * @apiSuccess {String} config.keystores.type 加载器类型
@@ -150,6 +157,13 @@ func (c *ConfigController) Get(w http.ResponseWriter, r *http.Request) {
  * @apiBody {Number} [config.server.log.maxAge] 保留旧日志文件的最大天数，单位: 天
  * @apiBody {Boolean} [config.server.log.compress] 是否压缩旧日志文件
  * @apiBody {Boolean} [config.server.log.enabled] 是否启用日志
+ * @apiBody {String} [config.server.log.format] 标准输出与日志文件的格式，可选值: "text"（默认）, "json"
+ * @apiBody {String[]} [config.server.log.outputs] 输出目标，可选值: "stdout", "file", "syslog"，可同时使用，为空时输出到标准输出与日志文件
+ * @apiBody {Object} [config.server.log.syslog] syslog 输出配置（RFC 5424）
+ * @apiBody {String} [config.server.log.syslog.network] 传输方式，可选值: "udp"（默认）, "tcp", "unix"
+ * @apiBody {String} config.server.log.syslog.address syslog 服务地址，示例: "10.0.0.10:514", "/dev/log"
+ * @apiBody {String} [config.server.log.syslog.facility] 设施，默认: "local0"
+ * @apiBody {String} [config.server.log.syslog.tag] 应用名称，默认: "tlcpchan"
  * @apiBody {Object[]} [config.keystores] 密钥存储配置列表
  * @apiBody {String} [config.keystores.name] 密钥存储名称，唯一标识符
  * @apiBody {String} config.keystores.type 加载器类型
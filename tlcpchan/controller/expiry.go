@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"net/http"
+)
+
+/**
+ * @api {get} /api/security/expiry 证书有效期检查结果
+ * @apiName GetCertExpiry
+ * @apiGroup Security-Expiry
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取所有 keystore 证书（签名与加密）和根证书的剩余有效期。
+ * 后台按 cert-expiry.interval（默认1小时）定期检查，keystore 或根证书变化后立即重新检查，
+ * 剩余有效期少于 warning-days（默认30天）为 warning，少于 critical-days（默认7天）为 critical
+ *
+ * @apiQuery {Boolean} [refresh=false] 是否立即重新检查，默认返回最近一次检查的结果
+ *
+ * @apiSuccess {String} checkedAt 检查时间，ISO 8601 格式
+ * @apiSuccess {Number} warningDays 告警阈值（天）
+ * @apiSuccess {Number} criticalDays 严重告警阈值（天）
+ * @apiSuccess {Object[]} items 证书检查结果，按剩余有效期从短到长排列，加载失败的 keystore 排在最前
+ * @apiSuccess {String} items.source 证书来源，可选值: "keystore", "rootcert"
+ * @apiSuccess {String} items.name keystore 名称或根证书文件名
+ * @apiSuccess {String} [items.usage] keystore 证书用途，可选值: "sign", "enc"
+ * @apiSuccess {String} items.subject 证书主题
+ * @apiSuccess {String} items.serialNumber 证书序列号（十六进制）
+ * @apiSuccess {String} items.notBefore 证书生效时间，ISO 8601 格式
+ * @apiSuccess {String} items.notAfter 证书过期时间，ISO 8601 格式
+ * @apiSuccess {Number} items.daysLeft 剩余有效天数，已过期时为负数
+ * @apiSuccess {String} items.status 状态，可选值: "ok", "warning", "critical", "expired", "error"
+ * @apiSuccess {String} [items.error] keystore 加载失败的原因
+ * @apiSuccess {Object} summary 各状态的证书数量
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "checkedAt": "2024-06-01T10:00:00Z",
+ *       "warningDays": 30,
+ *       "criticalDays": 7,
+ *       "items": [
+ *         {
+ *           "source": "keystore",
+ *           "name": "default-tlcp",
+ *           "usage": "enc",
+ *           "subject": "CN=tlcpchan-enc",
+ *           "serialNumber": "3A7F",
+ *           "notBefore": "2023-06-05T00:00:00Z",
+ *           "notAfter": "2024-06-05T00:00:00Z",
+ *           "daysLeft": 3,
+ *           "status": "critical"
+ *         },
+ *         {
+ *           "source": "rootcert",
+ *           "name": "tlcpchan-tlcp-root-ca.crt",
+ *           "subject": "CN=tlcpchan-tlcp-root-ca",
+ *           "serialNumber": "01",
+ *           "notBefore": "2024-01-01T00:00:00Z",
+ *           "notAfter": "2034-01-01T00:00:00Z",
+ *           "daysLeft": 3501,
+ *           "status": "ok"
+ *         }
+ *       ],
+ *       "summary": {
+ *         "critical": 1,
+ *         "ok": 1
+ *       }
+ *     }
+ */
+func (c *SecurityController) GetCertExpiry(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("refresh") == "true" {
+		Success(w, c.expiry.Check())
+		return
+	}
+	Success(w, c.expiry.Report())
+}
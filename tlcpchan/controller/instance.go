@@ -443,6 +443,12 @@ func (c *InstanceController) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 验证 client-ca/server-ca 引用的根证书是否存在
+	if err := c.manager.ValidateRootCerts(&cfg); err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+
 	inst, err := c.manager.Create(&cfg)
 	if err != nil {
 		BadRequest(w, err.Error())
@@ -637,6 +643,12 @@ func (c *InstanceController) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 验证 client-ca/server-ca 引用的根证书是否存在
+	if err := c.manager.ValidateRootCerts(&newCfg); err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+
 	found := false
 	for i, instance := range currentCfg.Instances {
 		if instance.Name == name {
@@ -658,7 +670,7 @@ func (c *InstanceController) Update(w http.ResponseWriter, r *http.Request) {
 
 	c.log.Info("实例配置已保存: %s", name)
 
-	if inst.Status() == instance.StatusRunning {
+	if inst.Status().IsRunning() {
 		if err := inst.Reload(&newCfg); err != nil {
 			BadRequest(w, "热重载失败: "+err.Error())
 			return
@@ -776,7 +788,7 @@ func (c *InstanceController) Start(w http.ResponseWriter, r *http.Request) {
  * @apiGroup Instance
  * @apiVersion 1.0.0
  *
- * @apiDescription 停止指定的代理实例，实例将停止监听端口，现有连接会被优雅关闭
+ * @apiDescription 停止指定的代理实例，实例将停止监听端口，已建立的连接继续转发直到结束；需要等待或强制关闭现有连接时使用排空接口
  *
  * @apiParam {String} name 实例名称（路径参数），实例的唯一标识符
  *
@@ -816,6 +828,74 @@ func (c *InstanceController) Stop(w http.ResponseWriter, r *http.Request) {
 	Success(w, map[string]string{"status": string(inst.Status())})
 }
 
+/**
+ * @api {post} /api/instances/:name/drain 排空实例
+ * @apiName DrainInstance
+ * @apiGroup Instance
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 排空指定的代理实例：立即停止监听端口，等待活跃连接在宽限期内结束，
+ * 宽限期结束后强制关闭剩余连接。请求在排空完成后返回，排空后实例状态为 stopped
+ *
+ * @apiParam {String} name 实例名称（路径参数），实例的唯一标识符
+ * @apiQuery {Number} [timeout] 宽限期（秒），为 0 时立即强制关闭所有连接，默认使用实例配置的 timeout.drain（默认 30 秒）
+ *
+ * @apiSuccess {String} status 实例状态，排空完成后为 "stopped"
+ * @apiSuccess {Number} active 开始排空时的活跃连接数
+ * @apiSuccess {Number} forceClosed 宽限期结束后被强制关闭的连接数
+ * @apiSuccess {Number} durationMs 排空耗时（毫秒）
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "status": "stopped",
+ *       "active": 12,
+ *       "forceClosed": 1,
+ *       "durationMs": 30002
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     Content-Type: text/plain
+ *
+ *     实例不存在
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     Content-Type: text/plain
+ *
+ *     无效的宽限期
+ */
+func (c *InstanceController) Drain(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	inst, ok := c.manager.Get(name)
+	if !ok {
+		NotFound(w, "实例不存在")
+		return
+	}
+
+	grace := inst.Config().DrainTimeout()
+	if timeoutStr := r.URL.Query().Get("timeout"); timeoutStr != "" {
+		t, err := strconv.Atoi(timeoutStr)
+		if err != nil || t < 0 {
+			BadRequest(w, "无效的宽限期")
+			return
+		}
+		grace = time.Duration(t) * time.Second
+	}
+
+	// 排空在宽限期结束后才返回，延长响应写入截止时间避免超过API服务的写超时
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(grace + 10*time.Second))
+
+	c.log.Info("排空实例: %s, 宽限期 %v", name, grace)
+	result := inst.Drain(grace)
+	Success(w, map[string]interface{}{
+		"status":      string(inst.Status()),
+		"active":      result.Active,
+		"forceClosed": result.ForceClosed,
+		"durationMs":  result.DurationMs,
+	})
+}
+
 /**
  * @api {post} /api/instances/:name/reload 热重载实例
  * @apiName ReloadInstance
@@ -927,7 +1007,15 @@ func (c *InstanceController) Restart(w http.ResponseWriter, r *http.Request) {
  * @apiSuccess {Number} bytesSent 发送字节数，单位：字节，自实例启动以来累计发送的数据量
  * @apiSuccess {Number} [requestsTotal] 总请求数，HTTP模式下的请求总数
  * @apiSuccess {Number} [errors] 错误数，发生的错误总数
- * @apiSuccess {Number} [avgLatencyMs] 平均延迟，单位：毫秒，请求处理的平均延迟
+ * @apiSuccess {Number} [handshakeFailures] 握手失败数
+ * @apiSuccess {Number} [avgLatencyMs] 平均延迟，单位：毫秒，TCP代理为连接建立耗时，HTTP代理为请求处理耗时
+ * @apiSuccess {Object} dialLatency 连接目标服务耗时分位数
+ * @apiSuccess {Number} dialLatency.count 观测次数
+ * @apiSuccess {Number} dialLatency.p50Ms 50分位数，单位：毫秒
+ * @apiSuccess {Number} dialLatency.p90Ms 90分位数，单位：毫秒
+ * @apiSuccess {Number} dialLatency.p99Ms 99分位数，单位：毫秒
+ * @apiSuccess {Object} handshakeLatency TLCP/TLS握手耗时分位数，字段同 dialLatency
+ * @apiSuccess {Object} sessionDuration 会话持续时长分位数，字段同 dialLatency
  *
  * @apiSuccessExample {json} Success-Response:
  *     HTTP/1.1 200 OK
@@ -938,7 +1026,11 @@ func (c *InstanceController) Restart(w http.ResponseWriter, r *http.Request) {
  *       "bytesSent": 2097152,
  *       "requestsTotal": 500,
  *       "errors": 2,
- *       "avgLatencyMs": 5.2
+ *       "handshakeFailures": 3,
+ *       "avgLatencyMs": 5.2,
+ *       "dialLatency": {"count": 1000, "p50Ms": 0.6, "p90Ms": 0.9, "p99Ms": 4.2},
+ *       "handshakeLatency": {"count": 1000, "p50Ms": 3.1, "p90Ms": 8.4, "p99Ms": 21.5},
+ *       "sessionDuration": {"count": 990, "p50Ms": 1200, "p90Ms": 8000, "p99Ms": 28000}
  *     }
  *
  * @apiErrorExample {text} Error-Response:
@@ -958,19 +1050,89 @@ func (c *InstanceController) Stats(w http.ResponseWriter, r *http.Request) {
 	Success(w, inst.Stats())
 }
 
+/**
+ * @api {get} /api/instances/:name/stats/snapshots 获取实例统计快照
+ * @apiName GetInstanceStatsSnapshots
+ * @apiGroup Instance
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取指定实例的统计快照历史，实例运行期间每10秒记录一次，最多保留最近1小时
+ *
+ * @apiParam {String} name 实例名称（路径参数），实例的唯一标识符
+ *
+ * @apiSuccess {Object[]} snapshots 快照列表，按时间升序
+ * @apiSuccess {String} snapshots.timestamp 快照时间，ISO 8601 格式
+ * @apiSuccess {Number} snapshots.totalConnections 累计连接总数
+ * @apiSuccess {Number} snapshots.activeConnections 当前活跃连接数
+ * @apiSuccess {Number} snapshots.bytesReceived 累计接收字节数，单位：字节
+ * @apiSuccess {Number} snapshots.bytesSent 累计发送字节数，单位：字节
+ * @apiSuccess {Number} snapshots.requests 累计请求数（HTTP代理）
+ * @apiSuccess {Number} snapshots.errors 累计错误数
+ * @apiSuccess {Number} snapshots.avgLatencyNs 平均延迟，单位：纳秒
+ * @apiSuccess {Number} snapshots.maxLatencyNs 最大延迟，单位：纳秒
+ * @apiSuccess {Number} snapshots.minLatencyNs 最小延迟，单位：纳秒
+ * @apiSuccess {Number} snapshots.handshakeFailures 累计握手失败数
+ * @apiSuccess {Object} snapshots.dialLatency 连接目标服务耗时分位数，包含 count、p50Ns、p90Ns、p99Ns
+ * @apiSuccess {Object} snapshots.handshakeLatency 握手耗时分位数，字段同 dialLatency
+ * @apiSuccess {Object} snapshots.sessionDuration 会话持续时长分位数，字段同 dialLatency
+ * @apiSuccess {Boolean} enabled 实例是否启用统计收集
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "snapshots": [
+ *         {
+ *           "timestamp": "2024-01-01T10:30:00Z",
+ *           "totalConnections": 1000,
+ *           "activeConnections": 10,
+ *           "bytesReceived": 1048576,
+ *           "bytesSent": 2097152,
+ *           "requests": 500,
+ *           "errors": 2,
+ *           "avgLatencyNs": 5200000,
+ *           "maxLatencyNs": 30000000,
+ *           "minLatencyNs": 800000
+ *         }
+ *       ],
+ *       "enabled": true
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     Content-Type: text/plain
+ *
+ *     实例不存在
+ */
+func (c *InstanceController) StatsSnapshots(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	inst, ok := c.manager.Get(name)
+	if !ok {
+		NotFound(w, "实例不存在")
+		return
+	}
+
+	collector := inst.Collector()
+	Success(w, map[string]interface{}{
+		"snapshots": collector.GetSnapshots(),
+		"enabled":   collector.IsEnabled(),
+	})
+}
+
 /**
  * @api {get} /api/instances/:name/logs 获取实例日志
  * @apiName GetInstanceLogs
  * @apiGroup Instance
  * @apiVersion 1.0.0
  *
- * @apiDescription 获取指定实例的日志信息
+ * @apiDescription 获取指定实例最近的运行日志，日志保留在内存中，条数由实例配置 log.buffer 决定（默认1000条），实例重启后保留，删除后清空
  *
  * @apiParam {String} name 实例名称（路径参数），实例的唯一标识符
- * @apiQuery {Number} [lines=100] 返回日志行数，默认值：100，最大值：1000
- * @apiQuery {String} [level] 日志级别过滤，可选值：debug（调试）、info（信息）、warn（警告）、error（错误）
+ * @apiQuery {Number} [limit=100] 最多返回的条数，超出时返回最近的记录，最大值：10000
+ * @apiQuery {String} [level] 最低日志级别，可选值：debug（调试）、info（信息）、warn（警告）、error（错误）
+ * @apiQuery {String} [since] 起始时间（含），RFC3339 格式如 "2024-01-01T00:00:00Z"，或相对当前的时长如 "15m"、"2h"
+ * @apiQuery {String} [until] 结束时间（不含），格式同 since
  *
- * @apiSuccess {Object[]} logs 日志列表数组
+ * @apiSuccess {Object[]} logs 日志列表数组，按时间顺序排列
  * @apiSuccess {String} logs.timestamp 时间戳，ISO 8601 格式，例如 "2024-01-01T00:00:00Z"
  * @apiSuccess {String} logs.level 日志级别，可选值：debug、info、warn、error
  * @apiSuccess {String} logs.message 日志消息内容
@@ -982,12 +1144,12 @@ func (c *InstanceController) Stats(w http.ResponseWriter, r *http.Request) {
  *         {
  *           "timestamp": "2024-01-01T00:00:00Z",
  *           "level": "info",
- *           "message": "connection accepted from 192.168.1.1:12345"
+ *           "message": "服务端代理启动: :8443 -> 127.0.0.1:8080, 协议: auto"
  *         },
  *         {
  *           "timestamp": "2024-01-01T00:00:01Z",
  *           "level": "warn",
- *           "message": "client certificate validation failed"
+ *           "message": "握手失败 conn=7 remote=192.168.1.1:12345 error=\"tls: bad certificate\""
  *         }
  *       ]
  *     }
@@ -997,6 +1159,12 @@ func (c *InstanceController) Stats(w http.ResponseWriter, r *http.Request) {
  *     Content-Type: text/plain
  *
  *     实例不存在
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     Content-Type: text/plain
+ *
+ *     无效的起始时间: yesterday
  */
 func (c *InstanceController) Logs(w http.ResponseWriter, r *http.Request) {
 	name := PathParam(r, "name")
@@ -1006,11 +1174,69 @@ func (c *InstanceController) Logs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	Success(w, []map[string]interface{}{
-		{"timestamp": "2024-01-01T00:00:00Z", "level": "info", "message": "示例日志"},
+	q, err := parseLogQuery(r)
+	if err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	Success(w, map[string]interface{}{
+		"logs": logger.InstanceEntries(name, q),
 	})
 }
 
+// parseLogQuery 解析实例日志查询参数 level、since、until、limit
+// 返回:
+//   - logger.Query: 查询条件，未指定 limit 时 Limit 为 0
+//   - error: 参数无效时返回错误
+func parseLogQuery(r *http.Request) (logger.Query, error) {
+	var q logger.Query
+	query := r.URL.Query()
+
+	var err error
+	if q.Level, err = parseLevelParam(query.Get("level")); err != nil {
+		return q, err
+	}
+
+	now := time.Now()
+	if q.Since, err = parseLogTime(query.Get("since"), now); err != nil {
+		return q, fmt.Errorf("无效的起始时间: %s", query.Get("since"))
+	}
+	if q.Until, err = parseLogTime(query.Get("until"), now); err != nil {
+		return q, fmt.Errorf("无效的结束时间: %s", query.Get("until"))
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("无效的条数: %s", limit)
+		}
+		q.Limit = min(n, 10000)
+	}
+	return q, nil
+}
+
+// parseLogTime 解析日志查询的时间参数
+// 参数:
+//   - s: RFC3339 时间或相对 now 的时长（如 "15m"），为空时返回零值
+//   - now: 当前时间
+func parseLogTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("无效的时间: %s", s)
+	}
+	return now.Add(-d), nil
+}
+
 /**
  * @api {get} /api/instances/:name/health 实例健康检查
  * @apiName InstanceHealthCheck
@@ -1103,6 +1329,179 @@ func (c *InstanceController) InstanceHealth(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+/**
+ * @api {get} /api/instances/:name/health/history 获取后台健康检查记录
+ * @apiName GetInstanceHealthHistory
+ * @apiGroup Instance
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取实例后台健康检查的状态与探测记录，需在实例配置中启用 healthCheck。
+ * 连续失败达到 failureThreshold 时实例状态为 "degraded"，实例仍在运行并处理连接。
+ *
+ * @apiParam {String} name 实例名称
+ *
+ * @apiSuccess {Boolean} enabled 是否启用后台健康检查
+ * @apiSuccess {String} [type] 探测方式，可选值: tcp, handshake, http
+ * @apiSuccess {Boolean} degraded 连续失败次数是否已达到阈值
+ * @apiSuccess {Number} consecutiveFailures 连续失败的探测轮数
+ * @apiSuccess {Number} [failureThreshold] 标记为 degraded 的连续失败阈值
+ * @apiSuccess {String} [lastCheck] 最近一次探测时间
+ * @apiSuccess {Object[]} history 探测记录，按时间从旧到新排列
+ * @apiSuccess {String} history.time 探测时间
+ * @apiSuccess {String} history.type 探测方式
+ * @apiSuccess {String} history.target 探测地址
+ * @apiSuccess {String} [history.protocol] 握手使用的协议
+ * @apiSuccess {Boolean} history.success 是否成功
+ * @apiSuccess {Number} history.latencyMs 耗时（毫秒）
+ * @apiSuccess {String} [history.error] 失败原因
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "enabled": true,
+ *       "type": "handshake",
+ *       "degraded": false,
+ *       "consecutiveFailures": 0,
+ *       "failureThreshold": 3,
+ *       "lastCheck": "2024-01-01T00:00:30Z",
+ *       "history": [
+ *         {
+ *           "time": "2024-01-01T00:00:30Z",
+ *           "type": "handshake",
+ *           "target": "192.168.1.100:443",
+ *           "protocol": "tlcp",
+ *           "success": true,
+ *           "latencyMs": 12
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     Content-Type: text/plain
+ *
+ *     实例不存在
+ */
+func (c *InstanceController) HealthHistory(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	inst, ok := c.manager.Get(name)
+	if !ok {
+		NotFound(w, "实例不存在")
+		return
+	}
+
+	Success(w, inst.Health())
+}
+
+/**
+ * @api {get} /api/instances/:name/connections 获取活跃连接列表
+ * @apiName ListInstanceConnections
+ * @apiGroup Instance
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取实例当前处理的所有活跃连接，按建立时间排序。
+ * 服务端实例的安全协商信息来自客户端连接，客户端实例来自与目标服务之间的连接；握手完成前协议相关字段为空。
+ * 停止后仍未结束的连接同样会列出。
+ *
+ * @apiParam {String} name 实例名称
+ *
+ * @apiSuccess {Object[]} connections 活跃连接列表
+ * @apiSuccess {String} connections.id 连接编号，实例内唯一
+ * @apiSuccess {String} connections.remoteAddr 客户端地址，启用 PROXY 协议接收时为上游传递的真实地址
+ * @apiSuccess {String} [connections.target] 转发的目标地址
+ * @apiSuccess {String} [connections.protocol] 协商的协议，可选值: tlcp, tls
+ * @apiSuccess {String} [connections.version] 协议版本，如 TLCPv1.1、TLSv1.3
+ * @apiSuccess {String} [connections.cipherSuite] 密码套件
+ * @apiSuccess {String} [connections.serverName] SNI名称
+ * @apiSuccess {String} [connections.peerSubject] 对端证书主题
+ * @apiSuccess {String} connections.startTime 连接建立时间
+ * @apiSuccess {Number} connections.bytesReceived 写回客户端的字节数
+ * @apiSuccess {Number} connections.bytesSent 从客户端读取并转发的字节数
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "connections": [
+ *         {
+ *           "id": "17",
+ *           "remoteAddr": "192.168.1.20:50123",
+ *           "target": "127.0.0.1:8080",
+ *           "protocol": "tlcp",
+ *           "version": "TLCPv1.1",
+ *           "cipherSuite": "ECC_SM4_GCM_SM3",
+ *           "serverName": "gm.example.com",
+ *           "peerSubject": "CN=client,O=example",
+ *           "startTime": "2024-01-01T00:00:00Z",
+ *           "bytesReceived": 10240,
+ *           "bytesSent": 512
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     Content-Type: text/plain
+ *
+ *     实例不存在
+ */
+func (c *InstanceController) Connections(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	inst, ok := c.manager.Get(name)
+	if !ok {
+		NotFound(w, "实例不存在")
+		return
+	}
+
+	Success(w, map[string]interface{}{
+		"connections": inst.Connections(),
+	})
+}
+
+/**
+ * @api {delete} /api/instances/:name/connections/:id 关闭连接
+ * @apiName CloseInstanceConnection
+ * @apiGroup Instance
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 强制关闭实例的指定活跃连接，同时关闭与目标服务之间的连接，实例继续运行
+ *
+ * @apiParam {String} name 实例名称
+ * @apiParam {String} id 连接编号
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "17",
+ *       "closed": true
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     Content-Type: text/plain
+ *
+ *     连接不存在
+ */
+func (c *InstanceController) CloseConnection(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+	inst, ok := c.manager.Get(name)
+	if !ok {
+		NotFound(w, "实例不存在")
+		return
+	}
+
+	id := PathParam(r, "id")
+	if !inst.CloseConnection(id) {
+		NotFound(w, "连接不存在")
+		return
+	}
+
+	c.log.Info("关闭实例 %s 的连接: %s", name, id)
+	Success(w, map[string]interface{}{
+		"id":     id,
+		"closed": true,
+	})
+}
+
 func (c *InstanceController) RegisterRoutes(router *Router) {
 	router.GET("/api/instances", c.List)
 	router.POST("/api/instances", c.Create)
@@ -1112,9 +1511,14 @@ func (c *InstanceController) RegisterRoutes(router *Router) {
 	router.DELETE("/api/instances/:name", c.Delete)
 	router.POST("/api/instances/:name/start", c.Start)
 	router.POST("/api/instances/:name/stop", c.Stop)
+	router.POST("/api/instances/:name/drain", c.Drain)
 	router.POST("/api/instances/:name/reload", c.Reload)
 	router.POST("/api/instances/:name/restart", c.Restart)
 	router.GET("/api/instances/:name/stats", c.Stats)
+	router.GET("/api/instances/:name/stats/snapshots", c.StatsSnapshots)
 	router.GET("/api/instances/:name/logs", c.Logs)
 	router.GET("/api/instances/:name/health", c.InstanceHealth)
+	router.GET("/api/instances/:name/health/history", c.HealthHistory)
+	router.GET("/api/instances/:name/connections", c.Connections)
+	router.DELETE("/api/instances/:name/connections/:id", c.CloseConnection)
 }
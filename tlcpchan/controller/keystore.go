@@ -80,6 +80,8 @@ type ExportCSRRequest struct {
  * @apiSuccess {String} -.loaderType 加载器类型，可选值："file"（文件）、"named"（命名）、"skf"（SKF设备）、"sdf"（SDF设备）
  * @apiSuccess {Object} -.params 加载器参数，键值对形式，具体内容取决于加载器类型
  * @apiSuccess {Boolean} -.protected 是否受保护，true 表示需要密码访问
+ * @apiSuccess {String} [-.issuer] 签发证书的 CA keystore 名称
+ * @apiSuccess {Object} [-.renewal] 证书自动续期策略，包含 beforeDays（过期前多少天续期）与 rotateKey（是否生成新密钥）
  * @apiSuccess {String} -.createdAt 创建时间，ISO 8601 格式
  * @apiSuccess {String} -.updatedAt 更新时间，ISO 8601 格式
  *
@@ -379,6 +381,10 @@ func (c *SecurityController) CreateKeyStore(w http.ResponseWriter, r *http.Reque
  * @apiSuccess {String} loaderType 加载器类型，可选值："file"、"named"、"skf"、"sdf"
  * @apiSuccess {Object} params 加载器参数
  * @apiSuccess {Boolean} protected 是否受保护
+ * @apiSuccess {String} [issuer] 签发证书的 CA keystore 名称
+ * @apiSuccess {Object} [renewal] 证书自动续期策略，过期前由 issuer 重新签发
+ * @apiSuccess {Number} [renewal.beforeDays] 过期前多少天续期，默认30
+ * @apiSuccess {Boolean} [renewal.rotateKey] 续期时是否生成新密钥，默认沿用原有密钥
  * @apiSuccess {String} createdAt 创建时间，ISO 8601 格式
  * @apiSuccess {String} updatedAt 更新时间，ISO 8601 格式
  *
@@ -1116,7 +1122,8 @@ func getInstanceStateStatus(instanceName string, instances []config.InstanceConf
  * @apiGroup Security-KeyStore
  * @apiVersion 1.0.0
  *
- * @apiDescription 更新指定 keystore 的参数（如证书和密钥路径的文件路径）
+ * @apiDescription 更新指定 keystore 的参数（如证书和密钥路径的文件路径），新参数加载成功后才保存配置，
+ * 引用该 keystore 的实例随之重新加载证书，无需手动重载实例
  *
  * @apiParam {String} name keystore 名称（路径参数），唯一标识符
  * @apiBody {Object} params 要更新的参数键值对，只更新提供的字段
@@ -1210,36 +1217,38 @@ func (c *SecurityController) UpdateKeystoreParams(w http.ResponseWriter, r *http
 
 	// 更新 keystore 配置
 	// 需要在配置文件中找到对应的 keystore 并更新其参数
-	found := false
+	index := -1
 	for i := range c.cfg.KeyStores {
 		if c.cfg.KeyStores[i].Name == name {
-			if c.cfg.KeyStores[i].Params == nil {
-				c.cfg.KeyStores[i].Params = make(map[string]string)
-			}
-			// 更新参数
-			for key, value := range reqBody.Params {
-				c.cfg.KeyStores[i].Params[key] = value
-			}
-			found = true
+			index = i
 			break
 		}
 	}
 
-	if !found {
+	if index < 0 {
 		NotFound(w, "配置中未找到 keystore")
 		return
 	}
 
-	// 保存配置文件
-	if err := config.Save(c.cfg); err != nil {
-		InternalError(w, "保存配置失败: "+err.Error())
-		return
+	params := make(map[string]string, len(c.cfg.KeyStores[index].Params)+len(reqBody.Params))
+	for key, value := range c.cfg.KeyStores[index].Params {
+		params[key] = value
+	}
+	for key, value := range reqBody.Params {
+		params[key] = value
 	}
 
-	// 重新加载 keystore
-	updatedInfo, err := c.keyStoreMgr.Get(name)
+	// 重新加载 keystore，加载失败时不修改配置；引用该 keystore 的实例随之重新加载证书
+	updatedInfo, err := c.keyStoreMgr.Reload(name, params)
 	if err != nil {
-		InternalError(w, "重新加载 keystore 失败: "+err.Error())
+		BadRequest(w, "参数无效: "+err.Error())
+		return
+	}
+	c.cfg.KeyStores[index].Params = params
+
+	// 保存配置文件
+	if err := config.Save(c.cfg); err != nil {
+		InternalError(w, "保存配置失败: "+err.Error())
 		return
 	}
 
@@ -1253,7 +1262,8 @@ func (c *SecurityController) UpdateKeystoreParams(w http.ResponseWriter, r *http
  * @apiGroup Security-KeyStore
  * @apiVersion 1.0.0
  *
- * @apiDescription 上传文件以更新指定 keystore 的证书和密钥。对于 TLS 类型，使用 signCert 和 signKey；对于 TLCP 类型，使用 signCert、signKey、encCert 和 encKey
+ * @apiDescription 上传文件以更新指定 keystore 的证书和密钥。对于 TLS 类型，使用 signCert 和 signKey；对于 TLCP 类型，使用 signCert、signKey、encCert 和 encKey。
+ * 更新后引用该 keystore 的实例随之重新加载证书，无需手动重载实例
  *
  * @apiParam {String} name keystore 名称（路径参数），唯一标识符
  * @apiBody {File} signCert 签名证书文件（TLS类型时为证书，TLCP类型时为签名证书）
@@ -1383,21 +1393,21 @@ func (c *SecurityController) UpdateCertificates(w http.ResponseWriter, r *http.R
 			return
 		}
 	} else {
-		// TLS 类型
+		// TLS 类型，文件加载器同样从 sign-cert、sign-key 读取证书和密钥
 		if certFile, certData, err := handleFormFile(r, "cert", tempDir, name, "", "crt"); err != nil {
 			BadRequest(w, err.Error())
 			return
 		} else if certFile != "" {
-			tempFiles["cert"] = certFile
-			finalFiles["cert"] = filepath.Join(keystoreDir, name+".crt")
+			tempFiles["sign-cert"] = certFile
+			finalFiles["sign-cert"] = filepath.Join(keystoreDir, name+".crt")
 
 			// 如果同时上传了密钥，验证配对
 			if keyFile, keyData, err := handleFormFile(r, "key", tempDir, name, "", "key"); err != nil {
 				BadRequest(w, err.Error())
 				return
 			} else if keyFile != "" {
-				tempFiles["key"] = keyFile
-				finalFiles["key"] = filepath.Join(keystoreDir, name+".key")
+				tempFiles["sign-key"] = keyFile
+				finalFiles["sign-key"] = filepath.Join(keystoreDir, name+".key")
 
 				if err := keystore.VerifyCertificateKeyPair(certData, keyData, false); err != nil {
 					BadRequest(w, "证书与密钥不匹配: "+err.Error())
@@ -1457,8 +1467,15 @@ func (c *SecurityController) UpdateCertificates(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// 重新加载 keystore
-	updatedInfo, err := c.keyStoreMgr.Get(name)
+	// 重新加载 keystore，替换缓存的证书，引用该 keystore 的实例随之重新加载证书
+	var params map[string]string
+	for _, ks := range c.cfg.KeyStores {
+		if ks.Name == name {
+			params = ks.Params
+			break
+		}
+	}
+	updatedInfo, err := c.keyStoreMgr.Reload(name, params)
 	if err != nil {
 		InternalError(w, "重新加载 keystore 失败: "+err.Error())
 		return
@@ -1468,6 +1485,71 @@ func (c *SecurityController) UpdateCertificates(w http.ResponseWriter, r *http.R
 	Success(w, updatedInfo)
 }
 
+/**
+ * @api {post} /api/security/keystores/:name/reload 重新加载 keystore
+ * @apiName ReloadKeyStore
+ * @apiGroup Security-KeyStore
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 按当前参数重新读取 keystore 的证书和密钥，替换内存中缓存的证书，
+ * 引用该 keystore 的实例随之重新加载TLCP/TLS配置，新连接使用新证书。
+ *
+ * 文件类型的 keystore 每 30 秒检测一次证书和密钥文件，内容变化时自动重新加载；
+ * 通过其他方式替换证书文件后需要立即生效时调用该接口
+ *
+ * @apiParam {String} name keystore 名称（路径参数），唯一标识符
+ *
+ * @apiSuccess {String} name keystore 名称
+ * @apiSuccess {String} type keystore 类型
+ * @apiSuccess {String} loaderType 加载器类型
+ * @apiSuccess {Object} params 加载器参数
+ * @apiSuccess {Boolean} protected 是否受保护
+ * @apiSuccess {String} createdAt 创建时间，ISO 8601 格式
+ * @apiSuccess {String} updatedAt 更新时间，ISO 8601 格式
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "name": "tlcp-server",
+ *       "type": "tlcp",
+ *       "loaderType": "file",
+ *       "params": {
+ *         "sign-cert": "./keystores/tlcp-server-sign.crt",
+ *         "sign-key": "./keystores/tlcp-server-sign.key",
+ *         "enc-cert": "./keystores/tlcp-server-enc.crt",
+ *         "enc-key": "./keystores/tlcp-server-enc.key"
+ *       },
+ *       "protected": false,
+ *       "createdAt": "2024-01-01T00:00:00Z",
+ *       "updatedAt": "2024-02-25T10:30:00Z"
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 404 Not Found
+ *     keystore 不存在
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     重新加载 keystore 失败: 证书 tlcp-server-sign.crt 与密钥不匹配: ...
+ */
+func (c *SecurityController) ReloadKeyStore(w http.ResponseWriter, r *http.Request) {
+	name := PathParam(r, "name")
+
+	if _, err := c.keyStoreMgr.Get(name); err != nil {
+		NotFound(w, "keystore 不存在")
+		return
+	}
+
+	// 加载失败时保留原有的 keystore
+	info, err := c.keyStoreMgr.Reload(name, nil)
+	if err != nil {
+		BadRequest(w, "重新加载 keystore 失败: "+err.Error())
+		return
+	}
+
+	c.log.Info("重新加载 keystore: %s", name)
+	Success(w, info)
+}
+
 // handleFormFile 处理表单文件上传
 // 参数：
 //   - r: HTTP 请求
@@ -4,11 +4,13 @@ import (
 	"archive/zip"
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -165,6 +167,233 @@ func (c *LogsController) ReadContent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// logStreamHeartbeat 日志流无新记录时发送心跳注释的间隔，避免代理或客户端因空闲断开连接
+const logStreamHeartbeat = 15 * time.Second
+
+/**
+ * @api {get} /api/system/logs/stream 实时日志流
+ * @apiName StreamLogs
+ * @apiGroup System
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 以 Server-Sent Events 推送之后写入的日志记录（包括服务日志与所有实例日志），连接保持直到客户端断开。
+ * 每条记录为一个 "log" 事件，data 为JSON；无新记录时每15秒发送一条心跳注释。
+ * 只推送通过全局日志级别过滤的记录，客户端处理过慢时丢弃记录。
+ *
+ * @apiQuery {String} [level] 最低日志级别，可选值：debug、info、warn、error
+ * @apiQuery {String} [instance] 实例名称，只推送该实例的日志
+ *
+ * @apiSuccess {String} timestamp 时间戳，ISO 8601 格式
+ * @apiSuccess {String} level 日志级别，可选值：debug、info、warn、error、fatal
+ * @apiSuccess {String} message 日志消息内容
+ * @apiSuccess {String} [instance] 实例名称，服务日志不包含
+ *
+ * @apiSuccessExample {text} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     Content-Type: text/event-stream
+ *
+ *     : connected
+ *
+ *     event: log
+ *     data: {"timestamp":"2024-01-01T10:30:00.123+08:00","level":"info","message":"服务端代理启动: :8443 -> 127.0.0.1:8080, 协议: auto","instance":"gm-server"}
+ *
+ *     : ping
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     Content-Type: text/plain
+ *
+ *     无效的日志级别: trace
+ */
+func (c *LogsController) Stream(w http.ResponseWriter, r *http.Request) {
+	minLevel, err := parseLevelParam(r.URL.Query().Get("level"))
+	if err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+	instance := r.URL.Query().Get("instance")
+
+	// 流式响应不受 API 服务器写入超时限制
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	entries, cancel := logger.Subscribe(256)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		c.log.Error("日志流不支持刷新响应: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(logStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case e := <-entries:
+			if logger.ParseLevel(e.Level) < minLevel || (instance != "" && e.Instance != instance) {
+				continue
+			}
+			data, _ := json.Marshal(e)
+			_, err = fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+/**
+ * @api {get} /api/system/logs/search 检索日志
+ * @apiName SearchLogs
+ * @apiGroup System
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 按时间顺序检索当前日志文件与轮转后的旧日志文件（包括 gzip 压缩的 .gz 文件），支持时间范围、级别、实例与内容过滤，分页返回。
+ * 日志时间以服务所在时区解析；没有时间前缀的行（如多行日志的后续行）沿用上一行的时间、级别和实例。
+ *
+ * @apiQuery {String} [since] 起始时间（含），RFC3339 格式如 "2024-01-01T08:00:00+08:00"，或相对当前的时长如 "6h"
+ * @apiQuery {String} [until] 结束时间（不含），格式同 since
+ * @apiQuery {String} [level] 最低日志级别，可选值：debug、info、warn、error
+ * @apiQuery {String} [instance] 实例名称，只匹配该实例的日志
+ * @apiQuery {String} [q] 匹配内容，默认为子串匹配
+ * @apiQuery {Boolean} [regex=false] 为 true 时 q 按正则表达式（Go RE2 语法）匹配
+ * @apiQuery {Number} [offset=0] 跳过的匹配条数
+ * @apiQuery {Number} [limit=100] 每页条数，最大值：1000
+ *
+ * @apiSuccess {Object[]} matches 当前页的匹配行，按时间顺序排列
+ * @apiSuccess {String} matches.file 所在文件名
+ * @apiSuccess {Number} matches.line 在文件中的行号
+ * @apiSuccess {String} matches.timestamp 记录时间，ISO 8601 格式
+ * @apiSuccess {String} matches.level 日志级别
+ * @apiSuccess {String} [matches.instance] 实例名称，非实例日志不包含
+ * @apiSuccess {String} matches.text 原始日志行
+ * @apiSuccess {Number} total 匹配的总条数
+ * @apiSuccess {Number} offset 跳过的匹配条数
+ * @apiSuccess {Number} limit 每页条数
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "matches": [
+ *         {
+ *           "file": "tlcpchan.log.20240101-100000.gz",
+ *           "line": 1532,
+ *           "timestamp": "2024-01-01T09:58:12+08:00",
+ *           "level": "warn",
+ *           "instance": "gm-server",
+ *           "text": "2024/01/01 09:58:12 server.go:173: [WARN] [gm-server] 握手失败 conn=7 remote=10.0.0.8:51234 error=\"tlcp: bad certificate\""
+ *         }
+ *       ],
+ *       "total": 37,
+ *       "offset": 0,
+ *       "limit": 1
+ *     }
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     Content-Type: text/plain
+ *
+ *     无效的正则表达式: error parsing regexp: missing closing ): `(bad`
+ */
+func (c *LogsController) Search(w http.ResponseWriter, r *http.Request) {
+	logDir := c.getLogDir()
+	if logDir == "" {
+		BadRequest(w, "日志未配置文件输出")
+		return
+	}
+
+	query := r.URL.Query()
+	var q logger.SearchQuery
+	var err error
+	if q.Level, err = parseLevelParam(query.Get("level")); err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+	now := time.Now()
+	if q.Since, err = parseLogTime(query.Get("since"), now); err != nil {
+		BadRequest(w, "无效的起始时间: "+query.Get("since"))
+		return
+	}
+	if q.Until, err = parseLogTime(query.Get("until"), now); err != nil {
+		BadRequest(w, "无效的结束时间: "+query.Get("until"))
+		return
+	}
+	q.Instance = query.Get("instance")
+
+	if text := query.Get("q"); text != "" {
+		if query.Get("regex") != "true" {
+			text = regexp.QuoteMeta(text)
+		}
+		if q.Pattern, err = regexp.Compile(text); err != nil {
+			BadRequest(w, "无效的正则表达式: "+err.Error())
+			return
+		}
+	}
+
+	q.Limit = 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			BadRequest(w, "无效的每页条数: "+v)
+			return
+		}
+		q.Limit = min(n, 1000)
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			BadRequest(w, "无效的偏移量: "+v)
+			return
+		}
+		q.Offset = n
+	}
+
+	files, err := c.listLogFiles(logDir)
+	if err != nil {
+		InternalError(w, "读取日志目录失败: "+err.Error())
+		return
+	}
+	// 按时间从早到晚检索，当前日志文件最后
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Current != files[j].Current {
+			return files[j].Current
+		}
+		return files[i].ModTime.Before(files[j].ModTime)
+	})
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+
+	result, err := logger.Search(paths, q)
+	if err != nil {
+		InternalError(w, err.Error())
+		return
+	}
+
+	Success(w, map[string]interface{}{
+		"matches": result.Matches,
+		"total":   result.Total,
+		"offset":  q.Offset,
+		"limit":   q.Limit,
+	})
+}
+
 /**
  * @api {get} /api/system/logs/download/:filename 下载单个日志文件
  * @apiName DownloadLogFile
@@ -285,9 +514,26 @@ func (c *LogsController) DownloadAll(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parseLevelParam 解析最低日志级别查询参数
+// 返回:
+//   - logger.Level: 日志级别，参数为空时为 LevelDebug（不过滤）
+//   - error: 不是 debug、info、warn、error 之一时返回错误
+func parseLevelParam(level string) (logger.Level, error) {
+	switch strings.ToLower(level) {
+	case "":
+		return logger.LevelDebug, nil
+	case "debug", "info", "warn", "warning", "error":
+		return logger.ParseLevel(level), nil
+	default:
+		return 0, fmt.Errorf("无效的日志级别: %s", level)
+	}
+}
+
 func (c *LogsController) RegisterRoutes(router *Router) {
 	router.GET("/api/system/logs", c.List)
 	router.GET("/api/system/logs/content", c.ReadContent)
+	router.GET("/api/system/logs/stream", c.Stream)
+	router.GET("/api/system/logs/search", c.Search)
 	router.GET("/api/system/logs/download/:filename", c.Download)
 	router.GET("/api/system/logs/download-all", c.DownloadAll)
 }
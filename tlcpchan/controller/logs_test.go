@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+)
+
+func TestLogsController_Stream(t *testing.T) {
+	defer logger.RemoveInstance("gm-server")
+	defer logger.RemoveInstance("other")
+
+	router := NewRouter()
+	NewLogsController(config.Default()).RegisterRoutes(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/system/logs/stream?level=warn&instance=gm-server")
+	if err != nil {
+		t.Fatalf("请求日志流失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type 应为 text/event-stream, 实际为 %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("首行应为连接注释, 实际为 %q", line)
+	}
+
+	logger.Instance("other").Warn("其他实例")
+	logger.Instance("gm-server").Info("级别过低")
+	logger.Warn("服务日志")
+	logger.Instance("gm-server").Error("目标服务不可达")
+
+	var event, data string
+	for data == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("读取日志流失败: %v", err)
+		}
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if event != "log" {
+		t.Errorf("事件类型应为 log, 实际为 %s", event)
+	}
+
+	var entry logger.Entry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("解析日志记录失败: %v", err)
+	}
+	if entry.Instance != "gm-server" || entry.Level != "error" || entry.Message != "目标服务不可达" {
+		t.Errorf("应只推送匹配实例与级别的记录, 实际为 %+v", entry)
+	}
+
+	bad := httptest.NewRecorder()
+	router.ServeHTTP(bad, httptest.NewRequest(http.MethodGet, "/api/system/logs/stream?level=trace", nil))
+	if bad.Code != http.StatusBadRequest {
+		t.Errorf("无效级别状态码应为 %d, 实际为 %d", http.StatusBadRequest, bad.Code)
+	}
+}
+
+func TestLogsController_Search(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Server.Log = &config.LogConfig{File: filepath.Join(dir, "tlcpchan.log")}
+
+	// 旧备份的修改时间早于当前文件，检索结果按时间顺序排列
+	backup := filepath.Join(dir, "tlcpchan.log.20240101-100000")
+	os.WriteFile(backup, []byte("2024/01/01 09:30:00 server.go:172: [WARN] [gm-server] 握手失败\n"), 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(backup, old, old)
+	os.WriteFile(cfg.Server.Log.File, []byte("2024/01/01 10:00:00 server.go:172: [WARN] [gm-server] 握手失败\n"+
+		"2024/01/01 10:00:01 main.go:20: [INFO] 配置已重载\n"), 0644)
+
+	router := NewRouter()
+	NewLogsController(cfg).RegisterRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system/logs/search?instance=gm-server&q=%E6%8F%A1%E6%89%8B&limit=1&offset=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("状态码应为 %d, 实际为 %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Matches []logger.SearchMatch `json:"matches"`
+		Total   int                  `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if resp.Total != 2 || len(resp.Matches) != 1 || resp.Matches[0].File != "tlcpchan.log" {
+		t.Errorf("第二页应为当前日志文件中的记录, 实际为 %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/system/logs/search?q=(bad&regex=true", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("无效正则状态码应为 %d, 实际为 %d", http.StatusBadRequest, rec.Code)
+	}
+}
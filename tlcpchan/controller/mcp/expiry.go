@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Trisia/tlcpchan/security"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CheckCertExpiryInput 证书有效期检查输入
+type CheckCertExpiryInput struct {
+	// Refresh 是否立即重新检查，默认返回最近一次检查的结果
+	Refresh bool `json:"refresh,omitempty"`
+}
+
+// CheckCertExpiryOutput 证书有效期检查输出
+type CheckCertExpiryOutput struct {
+	// Report 检查结果
+	Report *security.ExpiryReport `json:"report"`
+}
+
+// handleCheckCertExpiry 处理证书有效期检查请求
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: MCP 工具调用请求
+//   - input: 证书有效期检查输入参数
+//
+// 返回:
+//   - *mcpsdk.CallToolResult: MCP 工具调用结果（可以为 nil，SDK 自动处理）
+//   - CheckCertExpiryOutput: 证书有效期检查输出参数
+//   - error: 证书有效期监控未初始化时返回错误
+func (c *MCPController) handleCheckCertExpiry(_ context.Context, _ *mcpsdk.CallToolRequest, input CheckCertExpiryInput) (
+	*mcpsdk.CallToolResult,
+	CheckCertExpiryOutput,
+	error,
+) {
+	if c.expiryMonitor == nil {
+		return nil, CheckCertExpiryOutput{}, fmt.Errorf("证书有效期监控未初始化")
+	}
+	if input.Refresh {
+		return nil, CheckCertExpiryOutput{Report: c.expiryMonitor.Check()}, nil
+	}
+	return nil, CheckCertExpiryOutput{Report: c.expiryMonitor.Report()}, nil
+}
+
+// registerExpiryTools 注册证书有效期检查工具到 MCP 服务器
+//
+// 注意:
+//   - 注册 check_cert_expiry 工具，获取 keystore 证书与根证书的剩余有效期
+func (c *MCPController) registerExpiryTools() {
+	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
+		Name:        "check_cert_expiry",
+		Description: "检查所有 keystore 证书（签名与加密）和根证书的剩余有效期，返回即将过期（warning/critical）、已过期（expired）和无法读取（error）的证书",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"refresh": map[string]any{
+					"description": "是否立即重新检查，默认返回最近一次检查的结果",
+					"type":        "boolean",
+				},
+			},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"report": map[string]any{
+					"description": "检查结果",
+					"type":        "object",
+					"properties": map[string]any{
+						"checkedAt": map[string]any{
+							"description": "检查时间",
+							"type":        "string",
+						},
+						"warningDays": map[string]any{
+							"description": "告警阈值（天）",
+							"type":        "integer",
+						},
+						"criticalDays": map[string]any{
+							"description": "严重告警阈值（天）",
+							"type":        "integer",
+						},
+						"items": map[string]any{
+							"description": "证书检查结果，按剩余有效期从短到长排列",
+							"type":        "array",
+							"items": map[string]any{
+								"type": "object",
+								"properties": map[string]any{
+									"source": map[string]any{
+										"description": "证书来源: keystore 或 rootcert",
+										"type":        "string",
+									},
+									"name": map[string]any{
+										"description": "keystore 名称或根证书文件名",
+										"type":        "string",
+									},
+									"usage": map[string]any{
+										"description": "keystore 证书用途: sign 或 enc",
+										"type":        "string",
+									},
+									"subject": map[string]any{
+										"description": "证书主题",
+										"type":        "string",
+									},
+									"notAfter": map[string]any{
+										"description": "证书过期时间",
+										"type":        "string",
+									},
+									"daysLeft": map[string]any{
+										"description": "剩余有效天数，已过期时为负数",
+										"type":        "integer",
+									},
+									"status": map[string]any{
+										"description": "状态: ok、warning、critical、expired、error",
+										"type":        "string",
+									},
+									"error": map[string]any{
+										"description": "keystore 加载失败的原因",
+										"type":        "string",
+									},
+								},
+							},
+						},
+						"summary": map[string]any{
+							"description": "各状态的证书数量",
+							"type":        "object",
+						},
+					},
+				},
+			},
+		},
+	}, c.handleCheckCertExpiry)
+}
@@ -149,6 +149,70 @@ type CheckInstanceHealthOutput struct {
 	Results []*proxy.HealthCheckResult `json:"results"`
 }
 
+// GetInstanceHealthHistoryInput 获取实例后台健康检查记录输入
+type GetInstanceHealthHistoryInput struct {
+	// Name 实例名称
+	Name string `json:"name"`
+}
+
+// GetInstanceHealthHistoryOutput 获取实例后台健康检查记录输出
+type GetInstanceHealthHistoryOutput struct {
+	// Instance 实例名称
+	Instance string `json:"instance"`
+	// Status 实例状态，连续失败达到阈值时为 degraded
+	Status string `json:"status"`
+	// Health 后台健康检查状态与探测记录
+	Health *instance.HealthStatus `json:"health"`
+}
+
+// DrainInstanceInput 排空实例输入
+type DrainInstanceInput struct {
+	// Name 实例名称
+	Name string `json:"name"`
+	// Timeout 宽限期（秒），为 nil 时使用实例配置的排空宽限期，为 0 时立即强制关闭所有连接
+	Timeout *int `json:"timeout,omitempty"`
+}
+
+// DrainInstanceOutput 排空实例输出
+type DrainInstanceOutput struct {
+	// Status 实例状态
+	Status instance.Status `json:"status"`
+	// Active 开始排空时的活跃连接数
+	Active int `json:"active"`
+	// ForceClosed 宽限期结束后被强制关闭的连接数
+	ForceClosed int `json:"forceClosed"`
+	// DurationMs 排空耗时（毫秒）
+	DurationMs int64 `json:"durationMs"`
+}
+
+// ListInstanceConnectionsInput 获取实例活跃连接输入
+type ListInstanceConnectionsInput struct {
+	// Name 实例名称
+	Name string `json:"name"`
+}
+
+// ListInstanceConnectionsOutput 获取实例活跃连接输出
+type ListInstanceConnectionsOutput struct {
+	// Connections 活跃连接列表，按建立时间排序
+	Connections []*proxy.ConnInfo `json:"connections"`
+}
+
+// CloseInstanceConnectionInput 关闭实例连接输入
+type CloseInstanceConnectionInput struct {
+	// Name 实例名称
+	Name string `json:"name"`
+	// ID 连接编号
+	ID string `json:"id"`
+}
+
+// CloseInstanceConnectionOutput 关闭实例连接输出
+type CloseInstanceConnectionOutput struct {
+	// ID 连接编号
+	ID string `json:"id"`
+	// Closed 是否已关闭
+	Closed bool `json:"closed"`
+}
+
 // handleListInstances 处理 list_instances 工具调用
 //
 // 参数:
@@ -246,6 +310,11 @@ func (c *MCPController) handleCreateInstance(_ context.Context, _ *mcpsdk.CallTo
 		return nil, CreateInstanceOutput{}, err
 	}
 
+	// 验证 client-ca/server-ca 引用的根证书是否存在
+	if err := c.instanceMgr.ValidateRootCerts(&cfg); err != nil {
+		return nil, CreateInstanceOutput{}, err
+	}
+
 	// 创建实例
 	inst, err := c.instanceMgr.Create(&cfg)
 	if err != nil {
@@ -303,6 +372,11 @@ func (c *MCPController) handleUpdateInstance(_ context.Context, _ *mcpsdk.CallTo
 		return nil, UpdateInstanceOutput{}, err
 	}
 
+	// 验证 client-ca/server-ca 引用的根证书是否存在
+	if err := c.instanceMgr.ValidateRootCerts(&newCfg); err != nil {
+		return nil, UpdateInstanceOutput{}, err
+	}
+
 	// 更新配置
 	found := false
 	for i, instance := range currentCfg.Instances {
@@ -324,7 +398,7 @@ func (c *MCPController) handleUpdateInstance(_ context.Context, _ *mcpsdk.CallTo
 	c.log.Info("实例配置已保存: %s", input.Name)
 
 	// 如果实例运行中，热重载
-	if inst.Status() == instance.StatusRunning {
+	if inst.Status().IsRunning() {
 		if err := inst.Reload(&newCfg); err != nil {
 			return nil, UpdateInstanceOutput{}, fmt.Errorf("热重载失败: %w", err)
 		}
@@ -547,10 +621,130 @@ func (c *MCPController) handleCheckInstanceHealth(_ context.Context, _ *mcpsdk.C
 	}, nil
 }
 
+// handleGetInstanceHealthHistory 处理 get_instance_health_history 工具调用
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: MCP 工具调用请求
+//   - input: 获取后台健康检查记录输入参数
+//
+// 返回:
+//   - *mcpsdk.CallToolResult: MCP 工具调用结果（可以为 nil，SDK 自动处理）
+//   - GetInstanceHealthHistoryOutput: 后台健康检查状态与探测记录
+//   - error: 实例不存在时返回错误
+func (c *MCPController) handleGetInstanceHealthHistory(_ context.Context, _ *mcpsdk.CallToolRequest, input GetInstanceHealthHistoryInput) (
+	*mcpsdk.CallToolResult,
+	GetInstanceHealthHistoryOutput,
+	error,
+) {
+	inst, ok := c.instanceMgr.Get(input.Name)
+	if !ok {
+		return nil, GetInstanceHealthHistoryOutput{}, fmt.Errorf("实例不存在: %s", input.Name)
+	}
+
+	return nil, GetInstanceHealthHistoryOutput{
+		Instance: input.Name,
+		Status:   string(inst.Status()),
+		Health:   inst.Health(),
+	}, nil
+}
+
+// handleDrainInstance 处理 drain_instance 工具调用
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: MCP 工具调用请求
+//   - input: 排空实例输入参数
+//
+// 返回:
+//   - *mcpsdk.CallToolResult: MCP 工具调用结果（可以为 nil，SDK 自动处理）
+//   - DrainInstanceOutput: 排空结果
+//   - error: 实例不存在或宽限期无效时返回错误
+func (c *MCPController) handleDrainInstance(_ context.Context, _ *mcpsdk.CallToolRequest, input DrainInstanceInput) (
+	*mcpsdk.CallToolResult,
+	DrainInstanceOutput,
+	error,
+) {
+	inst, ok := c.instanceMgr.Get(input.Name)
+	if !ok {
+		return nil, DrainInstanceOutput{}, fmt.Errorf("实例不存在: %s", input.Name)
+	}
+
+	grace := inst.Config().DrainTimeout()
+	if input.Timeout != nil {
+		if *input.Timeout < 0 {
+			return nil, DrainInstanceOutput{}, fmt.Errorf("无效的宽限期: %d", *input.Timeout)
+		}
+		grace = time.Duration(*input.Timeout) * time.Second
+	}
+
+	c.log.Info("排空实例: %s, 宽限期 %v", input.Name, grace)
+	result := inst.Drain(grace)
+	return nil, DrainInstanceOutput{
+		Status:      inst.Status(),
+		Active:      result.Active,
+		ForceClosed: result.ForceClosed,
+		DurationMs:  result.DurationMs,
+	}, nil
+}
+
+// handleListInstanceConnections 处理 list_instance_connections 工具调用
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: MCP 工具调用请求
+//   - input: 获取实例活跃连接输入参数
+//
+// 返回:
+//   - *mcpsdk.CallToolResult: MCP 工具调用结果（可以为 nil，SDK 自动处理）
+//   - ListInstanceConnectionsOutput: 活跃连接列表
+//   - error: 实例不存在时返回错误
+func (c *MCPController) handleListInstanceConnections(_ context.Context, _ *mcpsdk.CallToolRequest, input ListInstanceConnectionsInput) (
+	*mcpsdk.CallToolResult,
+	ListInstanceConnectionsOutput,
+	error,
+) {
+	inst, ok := c.instanceMgr.Get(input.Name)
+	if !ok {
+		return nil, ListInstanceConnectionsOutput{}, fmt.Errorf("实例不存在: %s", input.Name)
+	}
+
+	return nil, ListInstanceConnectionsOutput{Connections: inst.Connections()}, nil
+}
+
+// handleCloseInstanceConnection 处理 close_instance_connection 工具调用
+//
+// 参数:
+//   - ctx: 上下文
+//   - req: MCP 工具调用请求
+//   - input: 关闭实例连接输入参数
+//
+// 返回:
+//   - *mcpsdk.CallToolResult: MCP 工具调用结果（可以为 nil，SDK 自动处理）
+//   - CloseInstanceConnectionOutput: 关闭结果
+//   - error: 实例或连接不存在时返回错误
+func (c *MCPController) handleCloseInstanceConnection(_ context.Context, _ *mcpsdk.CallToolRequest, input CloseInstanceConnectionInput) (
+	*mcpsdk.CallToolResult,
+	CloseInstanceConnectionOutput,
+	error,
+) {
+	inst, ok := c.instanceMgr.Get(input.Name)
+	if !ok {
+		return nil, CloseInstanceConnectionOutput{}, fmt.Errorf("实例不存在: %s", input.Name)
+	}
+
+	if !inst.CloseConnection(input.ID) {
+		return nil, CloseInstanceConnectionOutput{}, fmt.Errorf("连接不存在: %s", input.ID)
+	}
+
+	c.log.Info("关闭实例 %s 的连接: %s", input.Name, input.ID)
+	return nil, CloseInstanceConnectionOutput{ID: input.ID, Closed: true}, nil
+}
+
 // registerInstanceTools 注册实例管理工具到 MCP 服务器
 //
 // 注意:
-//   - 注册 10 个实例管理工具
+//   - 注册 11 个实例管理工具
 //   - 在 NewMCPController 中调用此函数
 func (c *MCPController) registerInstanceTools() {
 	// 1. list_instances - 获取所有代理实例的列表信息
@@ -869,7 +1063,142 @@ func (c *MCPController) registerInstanceTools() {
 		},
 	}, c.handleCheckInstanceHealth)
 
-	c.log.Info("已已注册 10 个实例管理 MCP 工具")
+	// 11. get_instance_health_history - 获取实例后台健康检查记录
+	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
+		Name:        "get_instance_health_history",
+		Description: "获取实例后台健康检查状态与探测记录，连续失败达到阈值时实例状态为 degraded",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "实例名称",
+				},
+			},
+			"required": []string{"name"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"instance": map[string]any{
+					"description": "实例名称",
+					"type":        "string",
+				},
+				"status": map[string]any{
+					"description": "实例状态",
+					"type":        "string",
+				},
+				"health": map[string]any{
+					"description": "后台健康检查状态，包含 enabled、degraded、consecutiveFailures、failureThreshold、lastCheck、history",
+					"type":        "object",
+				},
+			},
+		},
+	}, c.handleGetInstanceHealthHistory)
+
+	// 12. drain_instance - 排空指定实例
+	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
+		Name:        "drain_instance",
+		Description: "排空指定实例：停止接收新连接，等待活跃连接在宽限期内结束，超时后强制关闭剩余连接",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "实例名称",
+				},
+				"timeout": map[string]any{
+					"type":        "integer",
+					"description": "宽限期（秒），为 0 时立即强制关闭所有连接，默认使用实例配置的 timeout.drain（默认 30 秒）",
+				},
+			},
+			"required": []string{"name"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"status": map[string]any{
+					"description": "实例状态",
+					"type":        "string",
+				},
+				"active": map[string]any{
+					"description": "开始排空时的活跃连接数",
+					"type":        "integer",
+				},
+				"forceClosed": map[string]any{
+					"description": "宽限期结束后被强制关闭的连接数",
+					"type":        "integer",
+				},
+				"durationMs": map[string]any{
+					"description": "排空耗时（毫秒）",
+					"type":        "integer",
+				},
+			},
+		},
+	}, c.handleDrainInstance)
+
+	// 13. list_instance_connections - 获取实例活跃连接列表
+	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
+		Name:        "list_instance_connections",
+		Description: "获取实例当前处理的活跃连接列表，包括客户端地址、协商协议与版本、密码套件、SNI、对端证书主题、建立时间和实时收发字节数",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "实例名称",
+				},
+			},
+			"required": []string{"name"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"connections": map[string]any{
+					"description": "活跃连接列表，按建立时间排序，每项包含 id、remoteAddr、target、protocol、version、cipherSuite、serverName、peerSubject、startTime、bytesReceived、bytesSent",
+					"type":        "array",
+					"items": map[string]any{
+						"type": "object",
+					},
+				},
+			},
+		},
+	}, c.handleListInstanceConnections)
+
+	// 14. close_instance_connection - 关闭实例的指定连接
+	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
+		Name:        "close_instance_connection",
+		Description: "强制关闭实例的指定活跃连接，实例继续运行",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "实例名称",
+				},
+				"id": map[string]any{
+					"type":        "string",
+					"description": "连接编号，可通过 list_instance_connections 获取",
+				},
+			},
+			"required": []string{"name", "id"},
+		},
+		OutputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{
+					"description": "连接编号",
+					"type":        "string",
+				},
+				"closed": map[string]any{
+					"description": "是否已关闭",
+					"type":        "boolean",
+				},
+			},
+		},
+	}, c.handleCloseInstanceConnection)
+
+	c.log.Info("已已注册 14 个实例管理 MCP 工具")
 }
 
 // checkPortConflict 检查实例端口是否与已启用的其他实例冲突
@@ -249,35 +249,37 @@ func (c *MCPController) handleUpdateKeystore(_ context.Context, _ *mcpsdk.CallTo
 		}
 	}
 
-	// 更新 keystore 配置
-	found := false
+	// 查找 keystore 配置
+	index := -1
 	for i := range c.config.KeyStores {
 		if c.config.KeyStores[i].Name == input.Name {
-			if c.config.KeyStores[i].Params == nil {
-				c.config.KeyStores[i].Params = make(map[string]string)
-			}
-			// 更新参数
-			for key, value := range input.Params {
-				c.config.KeyStores[i].Params[key] = value
-			}
-			found = true
+			index = i
 			break
 		}
 	}
 
-	if !found {
+	if index == -1 {
 		return nil, UpdateKeystoreOutput{}, fmt.Errorf("配置中未找到 keystore")
 	}
 
-	// 保存配置文件
-	if err := config.Save(c.config); err != nil {
-		return nil, UpdateKeystoreOutput{}, fmt.Errorf("保存配置失败: %w", err)
+	params := make(map[string]string, len(c.config.KeyStores[index].Params)+len(input.Params))
+	for key, value := range c.config.KeyStores[index].Params {
+		params[key] = value
+	}
+	for key, value := range input.Params {
+		params[key] = value
 	}
 
-	// 重新加载 keystore
-	updatedInfo, err := c.keyStoreMgr.Get(input.Name)
+	// 重新加载 keystore，加载失败时不修改配置；引用该 keystore 的实例随之重新加载证书
+	updatedInfo, err := c.keyStoreMgr.Reload(input.Name, params)
 	if err != nil {
-		return nil, UpdateKeystoreOutput{}, fmt.Errorf("重新加载 keystore 失败: %w", err)
+		return nil, UpdateKeystoreOutput{}, fmt.Errorf("参数无效: %w", err)
+	}
+	c.config.KeyStores[index].Params = params
+
+	// 保存配置文件
+	if err := config.Save(c.config); err != nil {
+		return nil, UpdateKeystoreOutput{}, fmt.Errorf("保存配置失败: %w", err)
 	}
 
 	c.log.Info("更新 keystore 参数: %s", input.Name)
@@ -464,7 +466,7 @@ func (c *MCPController) registerKeystoreTools() {
 	// 注册 update_keystore 工具
 	mcpsdk.AddTool(c.server, &mcpsdk.Tool{
 		Name:        "update_keystore",
-		Description: "更新指定密钥存储（keystore）的参数（如证书和密钥路径），更新后自动保存配置",
+		Description: "更新指定密钥存储（keystore）的参数（如证书和密钥路径），更新后自动保存配置，引用该密钥存储的实例自动重新加载证书",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
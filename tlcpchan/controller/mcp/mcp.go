@@ -27,6 +27,8 @@ type ServerOptions struct {
 	RootCertManager *security.RootCertManager
 	// InstanceManager 实例管理器
 	InstanceManager *instance.Manager
+	// ExpiryMonitor 证书有效期监控
+	ExpiryMonitor *security.ExpiryMonitor
 	// StaticDir 静态文件目录
 	StaticDir string
 }
@@ -37,12 +39,14 @@ type MCPController struct {
 	instanceMgr *instance.Manager
 	keyStoreMgr *security.KeyStoreManager
 	rootCertMgr *security.RootCertManager
-	configPath  string
-	server      *mcpsdk.Server
-	sseHandler  *mcpsdk.SSEHandler
-	log         *logger.Logger
-	mu          sync.RWMutex
-	started     bool
+	// expiryMonitor 证书有效期监控
+	expiryMonitor *security.ExpiryMonitor
+	configPath    string
+	server        *mcpsdk.Server
+	sseHandler    *mcpsdk.SSEHandler
+	log           *logger.Logger
+	mu            sync.RWMutex
+	started       bool
 }
 
 // NewMCPController 创建新的 MCP 控制器
@@ -59,13 +63,14 @@ type MCPController struct {
 //   - config.MCP.APIKey 为空时，跳过认证（开放访问）
 func NewMCPController(opts *ServerOptions) (*MCPController, error) {
 	c := &MCPController{
-		config:      opts.Config,
-		instanceMgr: opts.InstanceManager,
-		keyStoreMgr: opts.KeyStoreManager,
-		rootCertMgr: opts.RootCertManager,
-		configPath:  opts.ConfigPath,
-		log:         logger.Default(),
-		started:     false,
+		config:        opts.Config,
+		instanceMgr:   opts.InstanceManager,
+		keyStoreMgr:   opts.KeyStoreManager,
+		rootCertMgr:   opts.RootCertManager,
+		expiryMonitor: opts.ExpiryMonitor,
+		configPath:    opts.ConfigPath,
+		log:           logger.Default(),
+		started:       false,
 	}
 
 	// 检查是否启用 MCP 服务
@@ -109,6 +114,9 @@ func NewMCPController(opts *ServerOptions) (*MCPController, error) {
 	// 注册实例管理工具
 	c.registerInstanceTools()
 
+	// 注册证书有效期检查工具
+	c.registerExpiryTools()
+
 	c.log.Info("MCP 控制器创建成功: %s v%s", serverName, serverVersion)
 
 	return c, nil
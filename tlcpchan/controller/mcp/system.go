@@ -5,7 +5,6 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/Trisia/tlcpchan/instance"
 	"github.com/Trisia/tlcpchan/version"
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -118,7 +117,7 @@ func (c *MCPController) handleGetSystemStats(_ context.Context, _ *mcpsdk.CallTo
 			}
 
 			// 统计活跃实例数
-			if inst.Status() == instance.StatusRunning {
+			if inst.Status().IsRunning() {
 				activeInstances++
 			}
 		}
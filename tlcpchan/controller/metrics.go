@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Trisia/tlcpchan/instance"
+	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/security/expiry"
+	"github.com/Trisia/tlcpchan/stats"
+)
+
+// metricsContentType Prometheus 文本格式的内容类型
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsController Prometheus 指标导出控制器
+type MetricsController struct {
+	manager *instance.Manager
+	expiry  *security.ExpiryMonitor
+}
+
+// NewMetricsController 创建指标导出控制器
+// 参数:
+//   - mgr: 实例管理器
+//   - expiryMonitor: 证书有效期监控，为 nil 时不导出证书有效期指标
+func NewMetricsController(mgr *instance.Manager, expiryMonitor *security.ExpiryMonitor) *MetricsController {
+	return &MetricsController{manager: mgr, expiry: expiryMonitor}
+}
+
+/**
+ * @api {get} /metrics Prometheus指标
+ * @apiName GetMetrics
+ * @apiGroup Metrics
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 以 Prometheus 文本格式导出各实例的统计指标，
+ * 序列按实例名称(instance)、实例类型(type)和协商协议(protocol)打标签，
+ * 握手完成前无法确定协议的连接归入 protocol="unknown"。
+ * 直方图包括连接建立/请求延迟、连接目标服务耗时、握手耗时和会话持续时长。
+ * 证书有效期指标按来源(source)、名称(name)和用途(usage)打标签，取自最近一次证书有效期检查的结果
+ *
+ * @apiSuccessExample {text} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     # HELP tlcpchan_connections_total 累计连接总数
+ *     # TYPE tlcpchan_connections_total counter
+ *     tlcpchan_connections_total{instance="proxy-1",type="server",protocol="tlcp"} 42
+ *     # HELP tlcpchan_latency_seconds 连接建立/请求延迟分布
+ *     # TYPE tlcpchan_latency_seconds histogram
+ *     tlcpchan_latency_seconds_bucket{instance="proxy-1",type="server",protocol="tlcp",le="0.001"} 0
+ *     tlcpchan_latency_seconds_bucket{instance="proxy-1",type="server",protocol="tlcp",le="+Inf"} 42
+ *     tlcpchan_latency_seconds_sum{instance="proxy-1",type="server",protocol="tlcp"} 12.5
+ *     tlcpchan_latency_seconds_count{instance="proxy-1",type="server",protocol="tlcp"} 42
+ *     # HELP tlcpchan_cert_expiry_days 证书剩余有效天数，已过期时为负数
+ *     # TYPE tlcpchan_cert_expiry_days gauge
+ *     tlcpchan_cert_expiry_days{source="keystore",name="default-tlcp",usage="sign"} 1520
+ *     # HELP tlcpchan_cert_expiry_state 证书有效期状态: 0=ok, 1=warning, 2=critical, 3=expired, 4=error
+ *     # TYPE tlcpchan_cert_expiry_state gauge
+ *     tlcpchan_cert_expiry_state{source="keystore",name="default-tlcp",usage="sign"} 0
+ */
+func (c *MetricsController) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	writeMetrics(w, c.manager.List())
+	if c.expiry != nil {
+		writeExpiryMetrics(w, c.expiry.Report())
+	}
+}
+
+func (c *MetricsController) RegisterRoutes(r *Router) {
+	r.GET("/metrics", c.Metrics)
+}
+
+// metricSeries 单个实例单个协议的统计数据
+type metricSeries struct {
+	labels    string
+	snapshot  stats.Snapshot
+	collector *stats.Collector
+}
+
+// histogramMetric 由收集器直方图导出的指标
+type histogramMetric struct {
+	name string
+	help string
+	hist func(c *stats.Collector) stats.HistogramSnapshot
+}
+
+var histogramMetrics = []histogramMetric{
+	{"tlcpchan_latency_seconds", "连接建立/请求延迟分布", (*stats.Collector).LatencyHistogram},
+	{"tlcpchan_dial_duration_seconds", "连接目标服务耗时分布", (*stats.Collector).DialLatencyHistogram},
+	{"tlcpchan_handshake_duration_seconds", "TLCP/TLS握手耗时分布", (*stats.Collector).HandshakeLatencyHistogram},
+	{"tlcpchan_session_duration_seconds", "会话持续时长分布", (*stats.Collector).SessionDurationHistogram},
+}
+
+// counterMetric 由快照字段导出的计数器/仪表指标
+type counterMetric struct {
+	name  string
+	help  string
+	kind  string
+	value func(s stats.Snapshot) int64
+}
+
+var counterMetrics = []counterMetric{
+	{"tlcpchan_connections_total", "累计连接总数", "counter", func(s stats.Snapshot) int64 { return s.TotalConnections }},
+	{"tlcpchan_active_connections", "当前活跃连接数", "gauge", func(s stats.Snapshot) int64 { return s.ActiveConnections }},
+	{"tlcpchan_received_bytes_total", "累计接收字节数", "counter", func(s stats.Snapshot) int64 { return s.BytesReceived }},
+	{"tlcpchan_sent_bytes_total", "累计发送字节数", "counter", func(s stats.Snapshot) int64 { return s.BytesSent }},
+	{"tlcpchan_requests_total", "累计请求数（HTTP代理）", "counter", func(s stats.Snapshot) int64 { return s.Requests }},
+	{"tlcpchan_errors_total", "累计错误数", "counter", func(s stats.Snapshot) int64 { return s.Errors }},
+	{"tlcpchan_handshake_failures_total", "累计握手失败数", "counter", func(s stats.Snapshot) int64 { return s.HandshakeFailures }},
+}
+
+// writeMetrics 以 Prometheus 文本格式输出实例指标
+// 参数:
+//   - w: 输出目标
+//   - instances: 实例列表
+//
+// 注意: 每个实例按协商协议拆分序列，同一指标的所有序列连续输出
+func writeMetrics(w io.Writer, instances []instance.Instance) {
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Name() < instances[j].Name() })
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	fmt.Fprintln(bw, "# HELP tlcpchan_instance_up 实例是否运行中")
+	fmt.Fprintln(bw, "# TYPE tlcpchan_instance_up gauge")
+	var series []metricSeries
+	for _, inst := range instances {
+		up := 0
+		if inst.Status().IsRunning() {
+			up = 1
+		}
+		fmt.Fprintf(bw, "tlcpchan_instance_up{instance=\"%s\",type=\"%s\"} %d\n",
+			escapeLabelValue(inst.Name()), escapeLabelValue(string(inst.Type())), up)
+
+		names, children := inst.Collector().Children()
+		for _, protocol := range names {
+			child := children[protocol]
+			series = append(series, metricSeries{
+				labels: fmt.Sprintf("instance=\"%s\",type=\"%s\",protocol=\"%s\"",
+					escapeLabelValue(inst.Name()), escapeLabelValue(string(inst.Type())), escapeLabelValue(protocol)),
+				snapshot:  child.GetSnapshot(),
+				collector: child,
+			})
+		}
+	}
+
+	for _, m := range counterMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, m.kind)
+		for _, s := range series {
+			fmt.Fprintf(bw, "%s{%s} %d\n", m.name, s.labels, m.value(s.snapshot))
+		}
+	}
+
+	for _, m := range histogramMetrics {
+		writeHistogram(bw, m, series)
+	}
+}
+
+// expiryStates 证书有效期状态对应的指标值
+var expiryStates = map[expiry.Status]int{
+	expiry.StatusOK:       0,
+	expiry.StatusWarning:  1,
+	expiry.StatusCritical: 2,
+	expiry.StatusExpired:  3,
+	expiry.StatusError:    4,
+}
+
+// writeExpiryMetrics 以 Prometheus 文本格式输出证书有效期指标
+// 注意: 加载失败的 keystore 没有过期时间，只输出状态
+func writeExpiryMetrics(w io.Writer, report *expiry.Report) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	labels := func(item *expiry.Item) string {
+		return fmt.Sprintf("source=\"%s\",name=\"%s\",usage=\"%s\"",
+			escapeLabelValue(item.Source), escapeLabelValue(item.Name), escapeLabelValue(item.Usage))
+	}
+
+	fmt.Fprintln(bw, "# HELP tlcpchan_cert_expiry_days 证书剩余有效天数，已过期时为负数")
+	fmt.Fprintln(bw, "# TYPE tlcpchan_cert_expiry_days gauge")
+	for i := range report.Items {
+		item := &report.Items[i]
+		if item.Status != expiry.StatusError {
+			fmt.Fprintf(bw, "tlcpchan_cert_expiry_days{%s} %d\n", labels(item), item.DaysLeft)
+		}
+	}
+
+	fmt.Fprintln(bw, "# HELP tlcpchan_cert_expiry_state 证书有效期状态: 0=ok, 1=warning, 2=critical, 3=expired, 4=error")
+	fmt.Fprintln(bw, "# TYPE tlcpchan_cert_expiry_state gauge")
+	for i := range report.Items {
+		item := &report.Items[i]
+		fmt.Fprintf(bw, "tlcpchan_cert_expiry_state{%s} %d\n", labels(item), expiryStates[item.Status])
+	}
+}
+
+// writeHistogram 输出直方图指标，桶上界单位为秒
+func writeHistogram(w io.Writer, m histogramMetric, series []metricSeries) {
+	name := m.name
+	fmt.Fprintf(w, "# HELP %s %s\n", name, m.help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for _, s := range series {
+		h := m.hist(s.collector)
+		for _, b := range h.Buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, s.labels, formatSeconds(b.UpperBound), b.Count)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, s.labels, h.Count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, s.labels, formatSeconds(h.Sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, s.labels, h.Count)
+	}
+}
+
+// formatSeconds 将纳秒转换为秒并格式化
+func formatSeconds(ns int64) string {
+	return strconv.FormatFloat(time.Duration(ns).Seconds(), 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue 转义标签值中的反斜杠、双引号和换行符
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
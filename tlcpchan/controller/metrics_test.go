@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/instance"
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/security/rootcert"
+)
+
+func TestMetricsController_Metrics(t *testing.T) {
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := instance.NewManager(log, security.NewKeyStoreManager(), rootcert.NewManager(""))
+	inst, err := mgr.Create(&config.InstanceConfig{
+		Name:     "metrics-\"a\"",
+		Type:     "client",
+		Protocol: "auto",
+		Listen:   ":18601",
+		Target:   "127.0.0.1:8080",
+	})
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete(inst.Name())
+
+	tlcpStats := inst.Collector().Child("tlcp")
+	tlcpStats.IncrementConnections()
+	tlcpStats.AddBytesSent(100)
+	tlcpStats.RecordLatency(3 * time.Millisecond)
+	inst.Collector().Child("tls").IncrementHandshakeFailures()
+
+	ctrl := NewMetricsController(mgr, nil)
+	router := NewRouter()
+	ctrl.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("状态码应为 %d, 实际为 %d", http.StatusOK, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type 应为 Prometheus 文本格式, 实际为 %s", ct)
+	}
+
+	body := rec.Body.String()
+	tlcpLabels := `instance="metrics-\"a\"",type="client",protocol="tlcp"`
+	tlsLabels := `instance="metrics-\"a\"",type="client",protocol="tls"`
+	for _, want := range []string{
+		`tlcpchan_instance_up{instance="metrics-\"a\"",type="client"} 0`,
+		"# TYPE tlcpchan_connections_total counter",
+		"tlcpchan_connections_total{" + tlcpLabels + "} 1",
+		"tlcpchan_active_connections{" + tlcpLabels + "} 1",
+		"tlcpchan_sent_bytes_total{" + tlcpLabels + "} 100",
+		"tlcpchan_handshake_failures_total{" + tlsLabels + "} 1",
+		"tlcpchan_handshake_failures_total{" + tlcpLabels + "} 0",
+		"# TYPE tlcpchan_latency_seconds histogram",
+		"tlcpchan_latency_seconds_bucket{" + tlcpLabels + `,le="0.001"} 0`,
+		"tlcpchan_latency_seconds_bucket{" + tlcpLabels + `,le="0.005"} 1`,
+		"tlcpchan_latency_seconds_bucket{" + tlcpLabels + `,le="+Inf"} 1`,
+		"tlcpchan_latency_seconds_sum{" + tlcpLabels + "} 0.003",
+		"tlcpchan_latency_seconds_count{" + tlcpLabels + "} 1",
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("指标输出缺少 %q", want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"path/filepath"
 
@@ -220,23 +221,32 @@ func (c *SecurityController) AddRootCert(w http.ResponseWriter, r *http.Request)
  * @apiGroup Security-RootCert
  * @apiVersion 1.0.0
  *
- * @apiDescription 重新加载所有根证书
+ * @apiDescription 重新加载所有根证书，引用根证书的实例（服务端配置 client-ca、客户端配置 server-ca）随之替换证书池，
+ * 已删除的根证书不再信任。添加、删除根证书时同样自动替换，无需重启实例
+ *
+ * @apiSuccess {String[]} instances 替换证书池成功的实例名称，按名称排序；替换失败的实例不包含在内，原因见服务日志
  *
  * @apiSuccessExample {json} Success-Response:
  *     HTTP/1.1 200 OK
- *     null
+ *     {
+ *       "instances": ["tlcp-server", "tlcp-client"]
+ *     }
  *
  * @apiErrorExample {text} Error-Response:
  *     HTTP/1.1 500 Internal Server Error
  *     重载失败: 具体错误信息
  */
 func (c *SecurityController) ReloadRootCerts(w http.ResponseWriter, r *http.Request) {
-	if err := c.rootCertMgr.Reload(); err != nil {
+	// 订阅方替换引用根证书的实例证书池并返回替换成功的实例
+	instances, err := c.rootCertMgr.Reload()
+	if err != nil {
 		InternalError(w, "重载失败: "+err.Error())
 		return
 	}
 	c.log.Info("重载根证书")
-	Success(w, nil)
+	Success(w, map[string]interface{}{
+		"instances": instances,
+	})
 }
 
 /**
@@ -387,3 +397,126 @@ func (c *SecurityController) GenerateRootCA(w http.ResponseWriter, r *http.Reque
 	c.log.Info("生成根CA证书: %s", req.CommonName)
 	Success(w, cert)
 }
+
+/**
+ * @api {get} /api/security/crls 列出所有证书吊销列表
+ * @apiName ListCRLs
+ * @apiGroup Security-RootCert
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 获取根证书目录中已加载的证书吊销列表（CRL），
+ * CRL 每10分钟自动重载一次，也可通过 /api/security/rootcerts/reload 立即重载
+ *
+ * @apiSuccess {Object[]} - CRL列表数组
+ * @apiSuccess {String} -.filename CRL文件名，唯一标识符
+ * @apiSuccess {String} -.issuer CRL签发者
+ * @apiSuccess {String} -.thisUpdate 本次更新时间，ISO 8601 格式
+ * @apiSuccess {String} -.nextUpdate 下次更新时间，ISO 8601 格式
+ * @apiSuccess {String} -.number CRL序号（十六进制）
+ * @apiSuccess {Number} -.revokedCount 吊销证书数量
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "filename": "root-ca.crl",
+ *         "issuer": "CN=Root CA, O=Example Org",
+ *         "thisUpdate": "2024-01-01T00:00:00Z",
+ *         "nextUpdate": "2024-01-08T00:00:00Z",
+ *         "number": "0a",
+ *         "revokedCount": 2
+ *       }
+ *     ]
+ */
+func (c *SecurityController) ListCRLs(w http.ResponseWriter, r *http.Request) {
+	Success(w, c.rootCertMgr.ListCRLs())
+}
+
+/**
+ * @api {post} /api/security/crls 上传证书吊销列表
+ * @apiName AddCRL
+ * @apiGroup Security-RootCert
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 上传证书吊销列表（CRL）到根证书目录，支持 SM2 与 RSA/ECDSA 签名的 CRL，
+ * 同名文件会被覆盖。启用 crl-check 的实例在握手时拒绝出现在 CRL 中的对端证书
+ *
+ * @apiBody {String} filename 文件名，表单字段，扩展名必须为 .crl（必需）
+ * @apiBody {File} crl CRL文件，表单字段，PEM 或 DER 格式，最大 10MB
+ *
+ * @apiSuccess {String} filename CRL文件名
+ * @apiSuccess {String} issuer CRL签发者
+ * @apiSuccess {String} thisUpdate 本次更新时间，ISO 8601 格式
+ * @apiSuccess {String} nextUpdate 下次更新时间，ISO 8601 格式
+ * @apiSuccess {String} number CRL序号（十六进制）
+ * @apiSuccess {Number} revokedCount 吊销证书数量
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     文件名不能为空
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 400 Bad Request
+ *     添加失败: 解析CRL失败: 具体错误信息
+ */
+func (c *SecurityController) AddCRL(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		BadRequest(w, "解析表单失败: "+err.Error())
+		return
+	}
+
+	filename := r.FormValue("filename")
+	if filename == "" {
+		BadRequest(w, "文件名不能为空")
+		return
+	}
+
+	file, _, err := r.FormFile("crl")
+	if err != nil {
+		BadRequest(w, "CRL文件不能为空: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		BadRequest(w, "读取CRL文件失败: "+err.Error())
+		return
+	}
+
+	crl, err := c.rootCertMgr.AddCRL(filename, data)
+	if err != nil {
+		BadRequest(w, "添加失败: "+err.Error())
+		return
+	}
+
+	c.log.Info("添加CRL: %s, 吊销证书数量: %d", filename, crl.RevokedCount)
+	Success(w, crl)
+}
+
+/**
+ * @api {delete} /api/security/crls/:filename 删除证书吊销列表
+ * @apiName DeleteCRL
+ * @apiGroup Security-RootCert
+ * @apiVersion 1.0.0
+ *
+ * @apiDescription 删除指定的证书吊销列表
+ *
+ * @apiParam {String} filename CRL文件名（路径参数）
+ *
+ * @apiSuccessExample {json} Success-Response:
+ *     HTTP/1.1 200 OK
+ *     null
+ *
+ * @apiErrorExample {text} Error-Response:
+ *     HTTP/1.1 500 Internal Server Error
+ *     删除失败: 具体错误信息
+ */
+func (c *SecurityController) DeleteCRL(w http.ResponseWriter, r *http.Request) {
+	filename := PathParam(r, "filename")
+	if err := c.rootCertMgr.DeleteCRL(filename); err != nil {
+		InternalError(w, "删除失败: "+err.Error())
+		return
+	}
+	c.log.Info("删除CRL: %s", filename)
+	Success(w, nil)
+}
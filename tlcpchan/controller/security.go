@@ -11,6 +11,8 @@ import (
 type SecurityController struct {
 	keyStoreMgr *security.KeyStoreManager // keystore 管理器
 	rootCertMgr *security.RootCertManager // 根证书管理器
+	expiry      *security.ExpiryMonitor   // 证书有效期监控
+	ca          *security.CertAuthority   // 内置 CA
 	cfg         *config.Config            // 全局配置
 	configPath  string                    // 配置文件路径
 	log         *logger.Logger            // 日志记录器
@@ -20,15 +22,19 @@ type SecurityController struct {
 // 参数：
 //   - keyStoreMgr: keystore 管理器
 //   - rootCertMgr: 根证书管理器
+//   - expiryMonitor: 证书有效期监控
+//   - authority: 内置 CA，用于签发证书请求和客户端证书
 //   - cfg: 全局配置对象
 //   - configPath: 配置文件路径
 //
 // 返回：
 //   - *SecurityController: 新的控制器实例
-func NewSecurityController(keyStoreMgr *security.KeyStoreManager, rootCertMgr *security.RootCertManager, cfg *config.Config, configPath string) *SecurityController {
+func NewSecurityController(keyStoreMgr *security.KeyStoreManager, rootCertMgr *security.RootCertManager, expiryMonitor *security.ExpiryMonitor, authority *security.CertAuthority, cfg *config.Config, configPath string) *SecurityController {
 	return &SecurityController{
 		keyStoreMgr: keyStoreMgr,
 		rootCertMgr: rootCertMgr,
+		expiry:      expiryMonitor,
+		ca:          authority,
 		cfg:         cfg,
 		configPath:  configPath,
 		log:         logger.Default(),
@@ -43,6 +49,7 @@ func (c *SecurityController) RegisterRoutes(r *Router) {
 	r.GET("/api/security/keystores/:name", c.GetKeyStore)
 	r.PUT("/api/security/keystores/:name", c.UpdateKeystoreParams)
 	r.POST("/api/security/keystores/:name/upload", c.UpdateCertificates)
+	r.POST("/api/security/keystores/:name/reload", c.ReloadKeyStore)
 	r.GET("/api/security/keystores/:name/instances", c.GetKeyStoreInstances)
 	r.DELETE("/api/security/keystores/:name", c.DeleteKeyStore)
 	r.POST("/api/security/keystores/:name/export-csr", c.ExportCSR)
@@ -53,4 +60,14 @@ func (c *SecurityController) RegisterRoutes(r *Router) {
 	r.GET("/api/security/rootcerts/:filename", c.GetRootCert)
 	r.DELETE("/api/security/rootcerts/:filename", c.DeleteRootCert)
 	r.POST("/api/security/rootcerts/reload", c.ReloadRootCerts)
+
+	r.GET("/api/security/crls", c.ListCRLs)
+	r.POST("/api/security/crls", c.AddCRL)
+	r.DELETE("/api/security/crls/:filename", c.DeleteCRL)
+
+	r.GET("/api/security/expiry", c.GetCertExpiry)
+
+	r.GET("/api/security/ca/issued", c.ListIssuedCerts)
+	r.POST("/api/security/ca/:name/sign", c.SignCSR)
+	r.POST("/api/security/ca/:name/issue", c.IssueClientCert)
 }
@@ -35,7 +35,9 @@ type ServerOptions struct {
 	KeyStoreManager *security.KeyStoreManager
 	RootCertManager *security.RootCertManager
 	InstanceManager *instance.Manager
-	StaticDir       string
+	// ExpiryMonitor 证书有效期监控，为 nil 时创建不定期检查的监控，查询时检查
+	ExpiryMonitor *security.ExpiryMonitor
+	StaticDir     string
 }
 
 // NewServer 创建新的API服务器
@@ -71,17 +73,24 @@ func NewServer(opts ServerOptions) *Server {
 		}
 	}
 
+	expiryMonitor := opts.ExpiryMonitor
+	if expiryMonitor == nil {
+		expiryMonitor = security.NewExpiryMonitor(keyStoreMgr, rootCertMgr, security.ExpiryOptions{})
+	}
+
 	instanceCtrl := NewInstanceController(instMgr, opts.ConfigPath)
 	configCtrl := NewConfigController(opts.ConfigPath)
-	securityCtrl := NewSecurityController(keyStoreMgr, rootCertMgr, opts.Config, opts.ConfigPath)
+	securityCtrl := NewSecurityController(keyStoreMgr, rootCertMgr, expiryMonitor, security.NewCertAuthority(keyStoreMgr, opts.Config.GetCADir()), opts.Config, opts.ConfigPath)
 	systemCtrl := NewSystemController()
 	logsCtrl := NewLogsController(opts.Config)
+	metricsCtrl := NewMetricsController(instMgr, expiryMonitor)
 
 	instanceCtrl.RegisterRoutes(router)
 	configCtrl.RegisterRoutes(router)
 	securityCtrl.RegisterRoutes(router)
 	systemCtrl.RegisterRoutes(router)
 	logsCtrl.RegisterRoutes(router)
+	metricsCtrl.RegisterRoutes(router)
 
 	// 创建 MCP 控制器
 	var mcpCtrl *mcp.MCPController
@@ -93,6 +102,7 @@ func NewServer(opts ServerOptions) *Server {
 			KeyStoreManager: opts.KeyStoreManager,
 			RootCertManager: opts.RootCertManager,
 			InstanceManager: instMgr,
+			ExpiryMonitor:   expiryMonitor,
 			StaticDir:       opts.StaticDir,
 		}
 		mcpCtrl, mcpErr = mcp.NewMCPController(mcpOpts)
@@ -5,12 +5,12 @@ go 1.26.0
 require (
 	gitee.com/Trisia/gotlcp v1.4.4
 	github.com/emmansun/gmsm v0.41.0
+	github.com/modelcontextprotocol/go-sdk v1.4.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/google/jsonschema-go v0.4.2 // indirect
-	github.com/modelcontextprotocol/go-sdk v1.4.0 // indirect
 	github.com/segmentio/asm v1.1.3 // indirect
 	github.com/segmentio/encoding v0.5.3 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
@@ -260,6 +260,9 @@ func (m *Manager) Initialize() error {
 				"enc-cert":  "./keystores/default-tlcp-enc.crt",
 				"enc-key":   "./keystores/default-tlcp-enc.key",
 			},
+			// 由内置 TLCP 根 CA 签发，过期前自动续期
+			Issuer:  "tlcpchan-tlcp-root-ca",
+			Renewal: &keystore.RenewalPolicy{BeforeDays: keystore.DefaultRenewBeforeDays},
 		},
 		{
 			Name: "default-tls",
@@ -268,6 +271,9 @@ func (m *Manager) Initialize() error {
 				"sign-cert": "./keystores/default-tls.crt",
 				"sign-key":  "./keystores/default-tls.key",
 			},
+			// 由内置 TLS 根 CA 签发，过期前自动续期
+			Issuer:  "tlcpchan-tls-root-ca",
+			Renewal: &keystore.RenewalPolicy{BeforeDays: keystore.DefaultRenewBeforeDays},
 		},
 	}
 
@@ -0,0 +1,424 @@
+package instance
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/proxy"
+)
+
+const (
+	// defaultHealthInterval 默认健康检查间隔
+	defaultHealthInterval = 30 * time.Second
+	// defaultHealthTimeout 默认单次探测超时时间
+	defaultHealthTimeout = 5 * time.Second
+	// defaultHealthFailureThreshold 默认连续失败阈值
+	defaultHealthFailureThreshold = 3
+	// defaultHealthHistory 默认保留的探测记录数量
+	defaultHealthHistory = 100
+)
+
+// HealthRecord 单次健康检查探测记录
+type HealthRecord struct {
+	// Time 探测时间
+	Time time.Time `json:"time"`
+	// Type 探测方式：tcp、handshake、http
+	Type string `json:"type"`
+	// Target 探测地址
+	Target string `json:"target"`
+	// Protocol 握手使用的协议，tcp 探测时为空
+	Protocol string `json:"protocol,omitempty"`
+	// Success 是否成功
+	Success bool `json:"success"`
+	// LatencyMs 耗时（毫秒）
+	LatencyMs int64 `json:"latencyMs"`
+	// Error 失败原因
+	Error string `json:"error,omitempty"`
+}
+
+// HealthStatus 实例后台健康检查状态
+type HealthStatus struct {
+	// Enabled 是否启用后台健康检查
+	Enabled bool `json:"enabled"`
+	// Type 探测方式
+	Type string `json:"type,omitempty"`
+	// Degraded 连续失败次数是否已达到阈值
+	Degraded bool `json:"degraded"`
+	// ConsecutiveFailures 连续失败的探测轮数
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// FailureThreshold 标记为 degraded 的连续失败阈值
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	// LastCheck 最近一次探测时间
+	LastCheck *time.Time `json:"lastCheck,omitempty"`
+	// History 探测记录，按时间从旧到新排列
+	History []HealthRecord `json:"history"`
+}
+
+// healthSettings 补齐默认值后的健康检查配置
+type healthSettings struct {
+	checkType string
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+	httpPath  string
+	history   int
+}
+
+// newHealthSettings 根据实例配置生成健康检查配置，未启用时返回 nil
+func newHealthSettings(cfg *config.InstanceConfig) *healthSettings {
+	hc := cfg.HealthCheck
+	if hc == nil || !hc.Enabled {
+		return nil
+	}
+	s := &healthSettings{
+		checkType: hc.Type,
+		interval:  hc.Interval,
+		timeout:   hc.Timeout,
+		threshold: hc.FailureThreshold,
+		httpPath:  hc.HTTPPath,
+		history:   hc.History,
+	}
+	if s.checkType == "" {
+		s.checkType = config.HealthCheckHandshake
+	}
+	if s.interval <= 0 {
+		s.interval = defaultHealthInterval
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultHealthTimeout
+	}
+	if s.threshold <= 0 {
+		s.threshold = defaultHealthFailureThreshold
+	}
+	if s.httpPath == "" {
+		s.httpPath = "/"
+	}
+	if s.history <= 0 {
+		s.history = defaultHealthHistory
+	}
+	return s
+}
+
+// healthMonitor 实例后台健康检查
+// 按配置的间隔探测实例，保留有限数量的探测记录，一轮探测中任一地址失败即视为该轮失败
+type healthMonitor struct {
+	adapter func() *proxy.TLCPAdapter
+	logger  *logger.Logger
+
+	mu        sync.Mutex
+	cfg       *config.InstanceConfig
+	settings  *healthSettings
+	history   []HealthRecord
+	failures  int
+	degraded  bool
+	lastCheck time.Time
+	stopCh    chan struct{}
+}
+
+// newHealthMonitor 创建健康检查
+// 参数:
+//   - adapter: 获取实例当前协议适配器的函数，实例重启后适配器会变化
+//   - log: 日志记录器，为 nil 时使用默认日志记录器
+func newHealthMonitor(adapter func() *proxy.TLCPAdapter, log *logger.Logger) *healthMonitor {
+	if log == nil {
+		log = logger.Default()
+	}
+	return &healthMonitor{adapter: adapter, logger: log}
+}
+
+// start 按实例配置开始后台探测，未启用健康检查时只清空状态
+// 注意: 已在运行时先停止原有探测
+func (h *healthMonitor) start(cfg *config.InstanceConfig) {
+	h.stop()
+
+	settings := newHealthSettings(cfg)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cfg = cfg
+	h.settings = settings
+	if settings == nil {
+		h.history = nil
+		return
+	}
+	if len(h.history) > settings.history {
+		h.history = append([]HealthRecord(nil), h.history[len(h.history)-settings.history:]...)
+	}
+	h.stopCh = make(chan struct{})
+	go h.run(cfg, settings, h.stopCh)
+}
+
+// stop 停止后台探测并清除失败计数，保留探测记录
+func (h *healthMonitor) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.stopCh != nil {
+		close(h.stopCh)
+		h.stopCh = nil
+	}
+	h.failures = 0
+	h.degraded = false
+}
+
+func (h *healthMonitor) run(cfg *config.InstanceConfig, settings *healthSettings, stopCh chan struct{}) {
+	ticker := time.NewTicker(settings.interval)
+	defer ticker.Stop()
+	for {
+		records := h.probe(cfg, settings)
+		select {
+		case <-stopCh:
+			// 探测期间已停止或按新配置重启，丢弃本轮结果
+			return
+		default:
+		}
+		h.record(cfg.Name, settings, records)
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// record 保存一轮探测结果并更新连续失败计数
+func (h *healthMonitor) record(name string, settings *healthSettings, records []HealthRecord) {
+	failed := len(records) == 0
+	for _, r := range records {
+		if !r.Success {
+			failed = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastCheck = time.Now()
+	h.history = append(h.history, records...)
+	if n := len(h.history) - settings.history; n > 0 {
+		h.history = append(h.history[:0], h.history[n:]...)
+	}
+
+	if !failed {
+		if h.degraded {
+			h.logger.Info("实例 %s 健康检查已恢复", name)
+		}
+		h.failures = 0
+		h.degraded = false
+		return
+	}
+	h.failures++
+	if !h.degraded && h.failures >= settings.threshold {
+		h.degraded = true
+		h.logger.Warn("实例 %s 健康检查连续失败 %d 次，标记为 %s", name, h.failures, StatusDegraded)
+	}
+}
+
+// isDegraded 连续失败次数是否已达到阈值
+func (h *healthMonitor) isDegraded() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.degraded
+}
+
+// status 获取健康检查状态与探测记录副本
+func (h *healthMonitor) status() *HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &HealthStatus{
+		Enabled:             h.settings != nil,
+		Degraded:            h.degraded,
+		ConsecutiveFailures: h.failures,
+		History:             append([]HealthRecord{}, h.history...),
+	}
+	if h.settings != nil {
+		s.Type = h.settings.checkType
+		s.FailureThreshold = h.settings.threshold
+	}
+	if !h.lastCheck.IsZero() {
+		t := h.lastCheck
+		s.LastCheck = &t
+	}
+	return s
+}
+
+// probe 按探测方式执行一轮探测
+func (h *healthMonitor) probe(cfg *config.InstanceConfig, settings *healthSettings) []HealthRecord {
+	serverSide := isServerSide(cfg)
+	protocol := proxy.ParseProtocolType(cfg.Protocol)
+
+	var records []HealthRecord
+	switch settings.checkType {
+	case config.HealthCheckTCP:
+		for _, t := range cfg.TargetList() {
+			records = append(records, probeTCP(t.Address, settings.timeout))
+		}
+	case config.HealthCheckHTTP:
+		records = append(records, h.probeHTTP(cfg, settings, serverSide, protocol))
+	default:
+		// 服务端实例检查本实例监听的TLCP/TLS服务，客户端实例检查每个目标的TLCP/TLS服务
+		if serverSide {
+			if addr, prepare, ok := listenProbe(cfg); ok {
+				records = append(records, h.probeHandshake(addr, protocol, settings.timeout, prepare))
+				break
+			}
+			// 无法通过监听地址探测时检查目标是否可达
+			for _, t := range cfg.TargetList() {
+				records = append(records, probeTCP(t.Address, settings.timeout))
+			}
+			break
+		}
+		for _, t := range cfg.TargetList() {
+			records = append(records, h.probeHandshake(t.Address, protocol, settings.timeout, nil))
+		}
+	}
+	return records
+}
+
+// listenProbe 获取探测本实例监听地址的方式
+// 启用PROXY协议接收时，探测连接在握手前发送 v2 LOCAL 命令头
+//
+// 返回:
+//   - string: 探测地址，未指定监听 IP 时使用 127.0.0.1
+//   - func(net.Conn) error: 握手前对连接执行的操作，未启用PROXY协议接收时为 nil
+//   - bool: 本机地址不在可信上游中、探测连接会被拒绝时返回 false
+func listenProbe(cfg *config.InstanceConfig) (string, func(net.Conn) error, bool) {
+	pp := cfg.ProxyProtocol
+	if pp == nil || !pp.Accept {
+		return cfg.Listen, nil, true
+	}
+
+	host, port, err := net.SplitHostPort(cfg.Listen)
+	if err != nil {
+		return cfg.Listen, nil, false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	// 探测本机监听地址时，连接的源地址与目的地址相同
+	trusted, err := config.ParseTrustedCIDRs(pp.Trusted)
+	if err != nil {
+		return cfg.Listen, nil, false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			prepare := func(conn net.Conn) error { return proxy.WriteProxyLocalHeader(conn) }
+			return net.JoinHostPort(ip.String(), port), prepare, true
+		}
+	}
+	return cfg.Listen, nil, false
+}
+
+// isServerSide 实例是否接收TLCP/TLS连接
+func isServerSide(cfg *config.InstanceConfig) bool {
+	t := ParseInstanceType(cfg.Type)
+	return t == TypeServer || t == TypeHTTPServer
+}
+
+func probeTCP(addr string, timeout time.Duration) HealthRecord {
+	r := HealthRecord{Time: time.Now(), Type: config.HealthCheckTCP, Target: addr}
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	r.LatencyMs = time.Since(r.Time).Milliseconds()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	conn.Close()
+	r.Success = true
+	return r
+}
+
+func (h *healthMonitor) probeHandshake(addr string, protocol proxy.ProtocolType, timeout time.Duration, prepare func(net.Conn) error) HealthRecord {
+	r := HealthRecord{Time: time.Now(), Type: config.HealthCheckHandshake, Target: addr}
+	conn, used, err := h.adapter().DialHealthWith(protocol, timeout, addr, prepare)
+	r.LatencyMs = time.Since(r.Time).Milliseconds()
+	r.Protocol = used.String()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	conn.Close()
+	r.Success = true
+	return r
+}
+
+// probeHTTP 通过本实例的隧道发送 HTTP GET 请求
+// 服务端实例以TLCP/TLS连接监听地址，客户端实例以明文连接监听地址，由实例转发到目标；
+// 无法通过监听地址探测时直接请求主目标，服务端实例使用明文，客户端实例使用TLCP/TLS
+func (h *healthMonitor) probeHTTP(cfg *config.InstanceConfig, settings *healthSettings, serverSide bool, protocol proxy.ProtocolType) HealthRecord {
+	addr, prepare, viaListen := listenProbe(cfg)
+	// 服务端实例的目标为明文服务，客户端实例的目标为TLCP/TLS服务
+	secure := serverSide
+	if !viaListen {
+		addr = cfg.PrimaryTarget()
+		secure = !serverSide
+	}
+	r := HealthRecord{Time: time.Now(), Type: config.HealthCheckHTTP, Target: addr}
+	err := func() error {
+		var conn net.Conn
+		var err error
+		if secure {
+			var used proxy.ProtocolType
+			conn, used, err = h.adapter().DialHealthWith(protocol, settings.timeout, addr, prepare)
+			r.Protocol = used.String()
+		} else {
+			conn, err = dialPlain(addr, settings.timeout, prepare)
+		}
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(settings.timeout))
+
+		host := cfg.SNI
+		if host == "" {
+			host = cfg.PrimaryTarget()
+		}
+		req, err := http.NewRequest(http.MethodGet, "http://"+host+settings.httpPath, nil)
+		if err != nil {
+			return err
+		}
+		req.Close = true
+		req.Header.Set("User-Agent", "tlcpchan-health-check")
+		if err := req.Write(conn); err != nil {
+			return fmt.Errorf("发送请求失败: %w", err)
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+		if err != nil {
+			return fmt.Errorf("读取响应失败: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("响应状态码 %d", resp.StatusCode)
+		}
+		return nil
+	}()
+	r.LatencyMs = time.Since(r.Time).Milliseconds()
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Success = true
+	return r
+}
+
+// dialPlain 建立明文 TCP 连接，并在连接建立后执行 prepare
+func dialPlain(addr string, timeout time.Duration, prepare func(net.Conn) error) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil || prepare == nil {
+		return conn, err
+	}
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err := prepare(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
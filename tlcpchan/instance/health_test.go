@@ -0,0 +1,204 @@
+package instance
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/rootcert"
+)
+
+// closedAddr 返回一个没有服务监听的本地地址
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestHealthCheckDegraded(t *testing.T) {
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := NewManager(log, security.NewKeyStoreManager(), rootcert.NewManager(""))
+
+	inst, err := mgr.Create(&config.InstanceConfig{
+		Name:     "health-degraded",
+		Type:     "client",
+		Protocol: "auto",
+		Listen:   "127.0.0.1:18511",
+		Target:   closedAddr(t),
+		HealthCheck: &config.HealthCheckConfig{
+			Enabled:          true,
+			Type:             config.HealthCheckTCP,
+			Interval:         10 * time.Millisecond,
+			Timeout:          time.Second,
+			FailureThreshold: 2,
+			History:          3,
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("health-degraded")
+
+	if err := inst.Start(); err != nil {
+		t.Fatalf("启动实例失败: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for inst.Status() != StatusDegraded && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if inst.Status() != StatusDegraded {
+		t.Fatalf("目标不可达时实例状态应为 degraded, 实际为 %s", inst.Status())
+	}
+	if !inst.Status().IsRunning() {
+		t.Error("degraded 状态的实例应视为运行中")
+	}
+
+	// 等待记录超过保留数量
+	deadline = time.Now().Add(5 * time.Second)
+	for inst.Health().ConsecutiveFailures < 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	health := inst.Health()
+	if !health.Enabled || !health.Degraded || health.FailureThreshold != 2 {
+		t.Errorf("健康检查状态错误: %+v", health)
+	}
+	if len(health.History) != 3 {
+		t.Errorf("探测记录应保留 3 条, 实际为 %d", len(health.History))
+	}
+	for _, r := range health.History {
+		if r.Success || r.Error == "" || r.Type != config.HealthCheckTCP {
+			t.Errorf("探测记录错误: %+v", r)
+		}
+	}
+
+	if err := inst.Stop(); err != nil {
+		t.Fatalf("停止实例失败: %v", err)
+	}
+	if inst.Status() != StatusStopped {
+		t.Errorf("停止后实例状态应为 stopped, 实际为 %s", inst.Status())
+	}
+}
+
+func TestHealthCheckRecover(t *testing.T) {
+	h := newHealthMonitor(nil, nil)
+	settings := newHealthSettings(&config.InstanceConfig{
+		HealthCheck: &config.HealthCheckConfig{Enabled: true, History: 2},
+	})
+	if settings.threshold != defaultHealthFailureThreshold || settings.checkType != config.HealthCheckHandshake {
+		t.Fatalf("默认配置错误: %+v", settings)
+	}
+
+	fail := HealthRecord{Target: "a"}
+	ok := HealthRecord{Target: "a", Success: true}
+	for i := 0; i < defaultHealthFailureThreshold; i++ {
+		if h.isDegraded() {
+			t.Fatalf("第 %d 次失败时不应标记为 degraded", i)
+		}
+		// 同一轮中任一地址失败即视为该轮失败
+		h.record("test", settings, []HealthRecord{ok, fail})
+	}
+	if !h.isDegraded() {
+		t.Fatal("连续失败达到阈值后应标记为 degraded")
+	}
+
+	h.record("test", settings, []HealthRecord{ok})
+	status := h.status()
+	if status.Degraded || status.ConsecutiveFailures != 0 {
+		t.Errorf("探测成功后应恢复, 实际为 %+v", status)
+	}
+	if len(status.History) != 2 || !status.History[1].Success {
+		t.Errorf("探测记录应保留最近 2 条, 实际为 %+v", status.History)
+	}
+}
+
+func TestHealthCheckProxyProtocolAccept(t *testing.T) {
+	dir := t.TempDir()
+	cert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "web", Days: 1})
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	certPath, keyPath := filepath.Join(dir, "web.crt"), filepath.Join(dir, "web.key")
+	if err := certgen.SaveCertToFile(cert.CertPEM, cert.KeyPEM, certPath, keyPath); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	ksMgr := security.NewKeyStoreManager()
+	if _, err := ksMgr.Create("web", security.LoaderTypeFile, map[string]string{"sign-cert": certPath, "sign-key": keyPath}, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := NewManager(log, ksMgr, rootcert.NewManager(""))
+
+	tests := []struct {
+		name       string
+		checkType  string
+		trusted    []string
+		wantType   string
+		wantTarget string
+	}{
+		{"握手探测发送LOCAL头", config.HealthCheckHandshake, []string{"127.0.0.0/8"}, config.HealthCheckHandshake, ""},
+		{"HTTP探测发送LOCAL头", config.HealthCheckHTTP, []string{"127.0.0.1"}, config.HealthCheckHTTP, ""},
+		{"本机不可信时探测目标", config.HealthCheckHandshake, []string{"10.0.0.0/8"}, config.HealthCheckTCP, backend.Listener.Addr().String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listen := closedAddr(t)
+			inst, err := mgr.Create(&config.InstanceConfig{
+				Name:          "health-proxy-protocol",
+				Type:          "server",
+				Protocol:      "tls",
+				Listen:        listen,
+				Target:        backend.Listener.Addr().String(),
+				TLS:           config.TLSConfig{Keystore: &config.KeyStoreConfig{Type: "named", Name: "web"}},
+				ProxyProtocol: &config.ProxyProtocolConfig{Accept: true, Trusted: tt.trusted},
+				HealthCheck: &config.HealthCheckConfig{
+					Enabled:          true,
+					Type:             tt.checkType,
+					Interval:         10 * time.Millisecond,
+					Timeout:          time.Second,
+					FailureThreshold: 1,
+				},
+			})
+			if err != nil {
+				t.Fatalf("创建实例失败: %v", err)
+			}
+			defer mgr.Delete("health-proxy-protocol")
+			if err := inst.Start(); err != nil {
+				t.Fatalf("启动实例失败: %v", err)
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(inst.Health().History) < 3 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			health := inst.Health()
+			if health.Degraded || len(health.History) < 3 {
+				t.Fatalf("健康检查状态错误: %+v", health)
+			}
+			wantTarget := tt.wantTarget
+			if wantTarget == "" {
+				wantTarget = listen
+			}
+			for _, r := range health.History {
+				if !r.Success || r.Type != tt.wantType || r.Target != wantTarget {
+					t.Errorf("探测记录错误: %+v", r)
+				}
+			}
+		})
+	}
+}
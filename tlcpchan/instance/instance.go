@@ -12,6 +12,13 @@ import (
 	"github.com/Trisia/tlcpchan/stats"
 )
 
+const (
+	// statsSnapshotInterval 实例统计快照间隔
+	statsSnapshotInterval = 10 * time.Second
+	// statsMaxSnapshots 实例统计快照保留数量，按快照间隔保留最近1小时
+	statsMaxSnapshots = 360
+)
+
 // Instance 代理实例接口，定义实例的基本操作
 type Instance interface {
 	Name() string
@@ -19,12 +26,28 @@ type Instance interface {
 	Protocol() string
 	Start() error
 	Stop() error
+	// Drain 排空实例：停止接收新连接，等待活跃连接在宽限期内结束，超时后强制关闭剩余连接
+	Drain(grace time.Duration) *proxy.DrainResult
 	Reload(cfg *config.InstanceConfig) error
+	// ReloadCertificates 以当前配置重新加载 keystore 证书与根证书，实例未运行时同样更新，下次启动时生效
+	ReloadCertificates() error
+	// ReloadRootCerts 以根证书管理器中的最新根证书替换 client-ca/server-ca 证书池，已删除的根证书不再信任
+	ReloadRootCerts() error
 	Restart(cfg *config.InstanceConfig) error
 	Status() Status
 	Stats() *stats.Stats
+	// Collector 获取实例的统计收集器
+	Collector() *stats.Collector
 	Config() *config.InstanceConfig
 	CheckHealth(protocol proxy.ProtocolType, timeout time.Duration) *proxy.HealthCheckResult
+	// Health 获取后台健康检查状态与探测记录
+	Health() *HealthStatus
+	// Connections 获取活跃连接列表
+	Connections() []*proxy.ConnInfo
+	// CloseConnection 强制关闭指定的活跃连接，连接不存在时返回 false
+	CloseConnection(id string) bool
+	// Close 释放实例占用的资源（如日志文件），实例删除后调用
+	Close() error
 }
 
 // baseInstance 实例基类，包含所有实例类型的公共属性
@@ -32,12 +55,16 @@ type baseInstance struct {
 	cfg             *config.InstanceConfig
 	instanceType    InstanceType
 	status          Status
-	stats           *stats.Stats
+	collector       *stats.Collector
 	keyStoreManager *security.KeyStoreManager
 	rootCertManager *security.RootCertManager
 	logger          *logger.Logger
 	startTime       time.Time
-	mu              sync.RWMutex
+	health          *healthMonitor
+	// accessLog 连接访问日志，随配置重新打开，实例删除时关闭
+	accessLog *proxy.AccessLogger
+	// mu 保护状态、配置以及各实例类型重启时替换的 proxy
+	mu sync.RWMutex
 }
 
 // serverInstance TCP服务端代理实例
@@ -79,37 +106,63 @@ func NewInstance(cfg *config.InstanceConfig,
 		cfg:             cfg,
 		instanceType:    ParseInstanceType(cfg.Type),
 		status:          StatusCreated,
-		stats:           &stats.Stats{},
+		collector:       stats.NewChildCollector(stats.DefaultCollector(), statsMaxSnapshots),
 		keyStoreManager: keyStoreMgr,
 		rootCertManager: rootCertMgr,
 		logger:          log,
 	}
 
+	base.applyStatsConfig(cfg)
+	if err := logger.ConfigureInstance(cfg.Name, instanceLogConfig(cfg)); err != nil {
+		return nil, err
+	}
+	base.accessLog = proxy.NewAccessLogger(cfg.Name)
+	if err := base.accessLog.Configure(cfg); err != nil {
+		logger.RemoveInstance(cfg.Name)
+		return nil, err
+	}
+
 	switch base.instanceType {
 	case TypeServer:
-		p, err := proxy.NewServerProxy(cfg, keyStoreMgr, rootCertMgr)
+		p, err := proxy.NewServerProxy(cfg, keyStoreMgr, rootCertMgr, base.collector)
 		if err != nil {
+			base.Close()
 			return nil, err
 		}
-		return &serverInstance{baseInstance: base, proxy: p}, nil
+		p.SetAccessLogger(base.accessLog)
+		inst := &serverInstance{baseInstance: base, proxy: p}
+		base.health = newHealthMonitor(inst.adapter, log)
+		return inst, nil
 	case TypeClient:
-		p, err := proxy.NewClientProxy(cfg, keyStoreMgr, rootCertMgr)
+		p, err := proxy.NewClientProxy(cfg, keyStoreMgr, rootCertMgr, base.collector)
 		if err != nil {
+			base.Close()
 			return nil, err
 		}
-		return &clientInstance{baseInstance: base, proxy: p}, nil
+		p.SetAccessLogger(base.accessLog)
+		inst := &clientInstance{baseInstance: base, proxy: p}
+		base.health = newHealthMonitor(inst.adapter, log)
+		return inst, nil
 	case TypeHTTPServer:
-		p, err := proxy.NewHTTPServerProxy(cfg, keyStoreMgr, rootCertMgr)
+		p, err := proxy.NewHTTPServerProxy(cfg, keyStoreMgr, rootCertMgr, base.collector)
 		if err != nil {
+			base.Close()
 			return nil, err
 		}
-		return &httpServerInstance{baseInstance: base, proxy: p}, nil
+		p.SetAccessLogger(base.accessLog)
+		inst := &httpServerInstance{baseInstance: base, proxy: p}
+		base.health = newHealthMonitor(inst.adapter, log)
+		return inst, nil
 	case TypeHTTPClient:
-		p, err := proxy.NewHTTPClientProxy(cfg, keyStoreMgr, rootCertMgr)
+		p, err := proxy.NewHTTPClientProxy(cfg, keyStoreMgr, rootCertMgr, base.collector)
 		if err != nil {
+			base.Close()
 			return nil, err
 		}
-		return &httpClientInstance{baseInstance: base, proxy: p}, nil
+		p.SetAccessLogger(base.accessLog)
+		inst := &httpClientInstance{baseInstance: base, proxy: p}
+		base.health = newHealthMonitor(inst.adapter, log)
+		return inst, nil
 	default:
 		return nil, fmt.Errorf("未知的实例类型: %s", cfg.Type)
 	}
@@ -129,20 +182,31 @@ func (i *baseInstance) Protocol() string {
 
 func (i *baseInstance) Status() Status {
 	i.mu.RLock()
-	defer i.mu.RUnlock()
-	return i.status
+	status := i.status
+	i.mu.RUnlock()
+	if status == StatusRunning && i.health.isDegraded() {
+		return StatusDegraded
+	}
+	return status
 }
 
 func (i *baseInstance) Stats() *stats.Stats {
-	i.mu.RLock()
-	defer i.mu.RUnlock()
-	return i.stats
+	s := i.collector.GetStats()
+	return &s
+}
+
+func (i *baseInstance) Collector() *stats.Collector {
+	return i.collector
 }
 
 func (i *baseInstance) Config() *config.InstanceConfig {
 	return i.cfg
 }
 
+func (i *baseInstance) Health() *HealthStatus {
+	return i.health.status()
+}
+
 func (i *baseInstance) setStatus(status Status) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -155,252 +219,481 @@ func (i *baseInstance) setStartTime() {
 	i.startTime = time.Now()
 }
 
-func (i *baseInstance) updateStats(s stats.Stats) {
-	i.mu.Lock()
-	defer i.mu.Unlock()
-	i.stats = &s
+// applyStatsConfig 根据配置启用或禁用统计收集，未配置 stats 时默认启用
+func (i *baseInstance) applyStatsConfig(cfg *config.InstanceConfig) {
+	if cfg.Stats == nil || cfg.Stats.Enabled {
+		i.collector.Enable()
+	} else {
+		i.collector.Disable()
+	}
+}
+
+// applyLogConfig 按配置更新实例日志与访问日志，失败时保持原有日志文件
+func (i *baseInstance) applyLogConfig(cfg *config.InstanceConfig) {
+	if err := logger.ConfigureInstance(cfg.Name, instanceLogConfig(cfg)); err != nil {
+		i.logger.Error("实例 %s 更新日志配置失败: %v", cfg.Name, err)
+	}
+	if err := i.accessLog.Configure(cfg); err != nil {
+		i.logger.Error("实例 %s 更新访问日志配置失败: %v", cfg.Name, err)
+	}
+}
+
+// instanceLogConfig 将实例配置中的日志配置转换为 logger 包的配置
+func instanceLogConfig(cfg *config.InstanceConfig) logger.InstanceLogConfig {
+	if cfg.Log == nil {
+		return logger.InstanceLogConfig{}
+	}
+	return logger.InstanceLogConfig{
+		Buffer:     cfg.Log.Buffer,
+		File:       cfg.Log.File,
+		MaxSize:    cfg.Log.MaxSize,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAge:     cfg.Log.MaxAge,
+		Compress:   cfg.Log.Compress,
+	}
+}
+
+// Close 释放实例占用的资源，关闭访问日志与实例日志文件并丢弃内存中的实例日志
+func (i *baseInstance) Close() error {
+	logger.RemoveInstance(i.Name())
+	return i.accessLog.Close()
+}
+
+// onStarted 实例启动成功后更新状态并开始记录统计快照
+func (i *baseInstance) onStarted() {
+	i.setStatus(StatusRunning)
+	i.setStartTime()
+	i.collector.StartSnapshotScheduler(statsSnapshotInterval)
+	i.restartHealthCheck()
+}
+
+// onStopped 实例停止后更新状态并停止记录统计快照与健康检查
+func (i *baseInstance) onStopped() {
+	i.setStatus(StatusStopped)
+	i.collector.StopSnapshotScheduler()
+	i.health.stop()
+}
+
+// onDrained 实例排空后更新状态并记录排空结果
+func (i *baseInstance) onDrained(result *proxy.DrainResult) {
+	i.onStopped()
+	i.logger.Info("实例 %s 排空完成: 活跃连接 %d, 强制关闭 %d, 耗时 %dms",
+		i.Name(), result.Active, result.ForceClosed, result.DurationMs)
+}
+
+// restartHealthCheck 按当前配置重新开始后台健康检查，实例未运行时不进行探测
+func (i *baseInstance) restartHealthCheck() {
+	if !i.Status().IsRunning() {
+		i.health.stop()
+		return
+	}
+	i.mu.RLock()
+	cfg := i.cfg
+	i.mu.RUnlock()
+	i.health.start(cfg)
 }
 
 func (i *serverInstance) Start() error {
-	if err := i.proxy.Start(); err != nil {
+	if err := i.current().Start(); err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.setStatus(StatusRunning)
-	i.setStartTime()
+	i.onStarted()
 	return nil
 }
 
 func (i *serverInstance) Stop() error {
-	if err := i.proxy.Stop(); err != nil {
+	if err := i.current().Stop(); err != nil {
 		return err
 	}
-	i.setStatus(StatusStopped)
+	i.onStopped()
 	return nil
 }
 
+func (i *serverInstance) Drain(grace time.Duration) *proxy.DrainResult {
+	result := i.current().Drain(grace)
+	i.onDrained(result)
+	return result
+}
+
 func (i *serverInstance) Reload(cfg *config.InstanceConfig) error {
-	if i.Status() != StatusRunning {
+	if !i.Status().IsRunning() {
 		// 实例未运行，无需执行热加载
 		return nil
 	}
 
-	if err := i.proxy.Reload(cfg); err == nil {
+	if err := i.current().Reload(cfg); err == nil {
 		i.mu.Lock()
 		i.cfg = cfg
 		i.mu.Unlock()
+		i.applyStatsConfig(cfg)
+		i.applyLogConfig(cfg)
+		i.restartHealthCheck()
 		return nil
 	}
 	return fmt.Errorf("热加载不支持或失败")
 }
 
+func (i *serverInstance) ReloadCertificates() error {
+	return i.current().Adapter().ReloadConfig(i.Config())
+}
+
+func (i *serverInstance) ReloadRootCerts() error {
+	return i.current().Adapter().ReloadRootCerts(i.Config())
+}
+
 func (i *serverInstance) Restart(cfg *config.InstanceConfig) error {
-	if err := i.proxy.Stop(); err != nil {
+	i.health.stop()
+	old := i.current()
+	if err := old.Stop(); err != nil {
 		return err
 	}
-	newProxy, err := proxy.NewServerProxy(cfg, i.keyStoreManager, i.rootCertManager)
+	newProxy, err := proxy.NewServerProxy(cfg, i.keyStoreManager, i.rootCertManager, i.collector)
 	if err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.proxy = newProxy
+	// 旧代理停止监听后仍在转发的连接由新代理继续跟踪，查询、强制关闭和排空时一并处理
+	newProxy.InheritConnections(old)
+	newProxy.SetAccessLogger(i.accessLog)
 	i.mu.Lock()
+	i.proxy = newProxy
 	i.cfg = cfg
 	i.mu.Unlock()
-	return i.proxy.Start()
-}
-
-func (i *serverInstance) Stats() *stats.Stats {
-	i.updateStats(i.proxy.Stats())
-	return i.baseInstance.Stats()
+	i.applyStatsConfig(cfg)
+	i.applyLogConfig(cfg)
+	if err := newProxy.Start(); err != nil {
+		return err
+	}
+	i.restartHealthCheck()
+	return nil
 }
 
 func (i *clientInstance) Start() error {
-	if err := i.proxy.Start(); err != nil {
+	if err := i.current().Start(); err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.setStatus(StatusRunning)
-	i.setStartTime()
+	i.onStarted()
 	return nil
 }
 
 func (i *clientInstance) Stop() error {
-	if err := i.proxy.Stop(); err != nil {
+	if err := i.current().Stop(); err != nil {
 		return err
 	}
-	i.setStatus(StatusStopped)
+	i.onStopped()
 	return nil
 }
 
+func (i *clientInstance) Drain(grace time.Duration) *proxy.DrainResult {
+	result := i.current().Drain(grace)
+	i.onDrained(result)
+	return result
+}
+
 func (i *clientInstance) Reload(cfg *config.InstanceConfig) error {
-	if i.Status() != StatusRunning {
+	if !i.Status().IsRunning() {
 		// 实例未运行，无需执行热加载
 		return nil
 	}
 
-	if err := i.proxy.Reload(cfg); err == nil {
+	if err := i.current().Reload(cfg); err == nil {
 		i.mu.Lock()
 		i.cfg = cfg
 		i.mu.Unlock()
+		i.applyStatsConfig(cfg)
+		i.applyLogConfig(cfg)
+		i.restartHealthCheck()
 		return nil
 	}
 	return fmt.Errorf("热加载不支持或失败")
 }
 
+func (i *clientInstance) ReloadCertificates() error {
+	return i.current().Adapter().ReloadConfig(i.Config())
+}
+
+func (i *clientInstance) ReloadRootCerts() error {
+	return i.current().Adapter().ReloadRootCerts(i.Config())
+}
+
 func (i *clientInstance) Restart(cfg *config.InstanceConfig) error {
-	if err := i.proxy.Stop(); err != nil {
+	i.health.stop()
+	old := i.current()
+	if err := old.Stop(); err != nil {
 		return err
 	}
-	newProxy, err := proxy.NewClientProxy(cfg, i.keyStoreManager, i.rootCertManager)
+	newProxy, err := proxy.NewClientProxy(cfg, i.keyStoreManager, i.rootCertManager, i.collector)
 	if err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.proxy = newProxy
+	// 旧代理停止监听后仍在转发的连接由新代理继续跟踪，查询、强制关闭和排空时一并处理
+	newProxy.InheritConnections(old)
+	newProxy.SetAccessLogger(i.accessLog)
 	i.mu.Lock()
+	i.proxy = newProxy
 	i.cfg = cfg
 	i.mu.Unlock()
-	return i.proxy.Start()
-}
-
-func (i *clientInstance) Stats() *stats.Stats {
-	i.updateStats(i.proxy.Stats())
-	return i.baseInstance.Stats()
+	i.applyStatsConfig(cfg)
+	i.applyLogConfig(cfg)
+	if err := newProxy.Start(); err != nil {
+		return err
+	}
+	i.restartHealthCheck()
+	return nil
 }
 
 func (i *httpServerInstance) Start() error {
-	if err := i.proxy.Start(); err != nil {
+	if err := i.current().Start(); err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.setStatus(StatusRunning)
-	i.setStartTime()
+	i.onStarted()
 	return nil
 }
 
 func (i *httpServerInstance) Stop() error {
-	if err := i.proxy.Stop(); err != nil {
+	if err := i.current().Stop(); err != nil {
 		return err
 	}
-	i.setStatus(StatusStopped)
+	i.onStopped()
 	return nil
 }
 
+func (i *httpServerInstance) Drain(grace time.Duration) *proxy.DrainResult {
+	result := i.current().Drain(grace)
+	i.onDrained(result)
+	return result
+}
+
 func (i *httpServerInstance) Reload(cfg *config.InstanceConfig) error {
-	if i.Status() != StatusRunning {
+	if !i.Status().IsRunning() {
 		// 实例未运行，无需执行热加载
 		return nil
 	}
 
-	if err := i.proxy.Reload(cfg); err == nil {
+	if err := i.current().Reload(cfg); err == nil {
 		i.mu.Lock()
 		i.cfg = cfg
 		i.mu.Unlock()
+		i.applyStatsConfig(cfg)
+		i.applyLogConfig(cfg)
+		i.restartHealthCheck()
 		return nil
 	}
 	return fmt.Errorf("热加载不支持或失败")
 }
 
+func (i *httpServerInstance) ReloadCertificates() error {
+	return i.current().Adapter().ReloadConfig(i.Config())
+}
+
+func (i *httpServerInstance) ReloadRootCerts() error {
+	return i.current().Adapter().ReloadRootCerts(i.Config())
+}
+
 func (i *httpServerInstance) Restart(cfg *config.InstanceConfig) error {
-	if err := i.proxy.Stop(); err != nil {
+	i.health.stop()
+	old := i.current()
+	if err := old.Stop(); err != nil {
 		return err
 	}
-	newProxy, err := proxy.NewHTTPServerProxy(cfg, i.keyStoreManager, i.rootCertManager)
+	newProxy, err := proxy.NewHTTPServerProxy(cfg, i.keyStoreManager, i.rootCertManager, i.collector)
 	if err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.proxy = newProxy
+	// 旧代理停止监听后仍在转发的连接由新代理继续跟踪，查询、强制关闭和排空时一并处理
+	newProxy.InheritConnections(old.ServerProxy)
+	newProxy.SetAccessLogger(i.accessLog)
 	i.mu.Lock()
+	i.proxy = newProxy
 	i.cfg = cfg
 	i.mu.Unlock()
-	return i.proxy.Start()
-}
-
-func (i *httpServerInstance) Stats() *stats.Stats {
-	i.updateStats(i.proxy.Stats())
-	return i.baseInstance.Stats()
+	i.applyStatsConfig(cfg)
+	i.applyLogConfig(cfg)
+	if err := newProxy.Start(); err != nil {
+		return err
+	}
+	i.restartHealthCheck()
+	return nil
 }
 
 func (i *httpClientInstance) Start() error {
-	if err := i.proxy.Start(); err != nil {
+	if err := i.current().Start(); err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.setStatus(StatusRunning)
-	i.setStartTime()
+	i.onStarted()
 	return nil
 }
 
 func (i *httpClientInstance) Stop() error {
-	if err := i.proxy.Stop(); err != nil {
+	if err := i.current().Stop(); err != nil {
 		return err
 	}
-	i.setStatus(StatusStopped)
+	i.onStopped()
 	return nil
 }
 
+func (i *httpClientInstance) Drain(grace time.Duration) *proxy.DrainResult {
+	result := i.current().Drain(grace)
+	i.onDrained(result)
+	return result
+}
+
 func (i *httpClientInstance) Reload(cfg *config.InstanceConfig) error {
-	if i.Status() != StatusRunning {
+	if !i.Status().IsRunning() {
 		// 实例未运行，无需执行热加载
 		return nil
 	}
 
-	if err := i.proxy.Reload(cfg); err == nil {
+	if err := i.current().Reload(cfg); err == nil {
 		i.mu.Lock()
 		i.cfg = cfg
 		i.mu.Unlock()
+		i.applyStatsConfig(cfg)
+		i.applyLogConfig(cfg)
+		i.restartHealthCheck()
 		return nil
 	}
 	return fmt.Errorf("热加载不支持或失败")
 }
 
+func (i *httpClientInstance) ReloadCertificates() error {
+	return i.current().ReloadCertificates()
+}
+
+func (i *httpClientInstance) ReloadRootCerts() error {
+	return i.current().ReloadRootCerts()
+}
+
 func (i *httpClientInstance) Restart(cfg *config.InstanceConfig) error {
-	if err := i.proxy.Stop(); err != nil {
+	i.health.stop()
+	old := i.current()
+	if err := old.Stop(); err != nil {
 		return err
 	}
-	newProxy, err := proxy.NewHTTPClientProxy(cfg, i.keyStoreManager, i.rootCertManager)
+	newProxy, err := proxy.NewHTTPClientProxy(cfg, i.keyStoreManager, i.rootCertManager, i.collector)
 	if err != nil {
 		i.setStatus(StatusError)
 		return err
 	}
-	i.proxy = newProxy
+	// 旧代理停止监听后仍在转发的连接由新代理继续跟踪，查询、强制关闭和排空时一并处理
+	newProxy.InheritConnections(old.ClientProxy)
+	newProxy.SetAccessLogger(i.accessLog)
 	i.mu.Lock()
+	i.proxy = newProxy
 	i.cfg = cfg
 	i.mu.Unlock()
-	return i.proxy.Start()
-}
-
-func (i *httpClientInstance) Stats() *stats.Stats {
-	i.updateStats(i.proxy.Stats())
-	return i.baseInstance.Stats()
+	i.applyStatsConfig(cfg)
+	i.applyLogConfig(cfg)
+	if err := newProxy.Start(); err != nil {
+		return err
+	}
+	i.restartHealthCheck()
+	return nil
 }
 
 func (i *serverInstance) CheckHealth(protocol proxy.ProtocolType, timeout time.Duration) *proxy.HealthCheckResult {
 	i.mu.RLock()
 	listenAddr := i.cfg.Listen
 	i.mu.RUnlock()
-	return i.proxy.Adapter().CheckHealth(protocol, timeout, listenAddr)
+	return i.current().Adapter().CheckHealth(protocol, timeout, listenAddr)
 }
 
 func (i *clientInstance) CheckHealth(protocol proxy.ProtocolType, timeout time.Duration) *proxy.HealthCheckResult {
 	i.mu.RLock()
-	targetAddr := i.cfg.Target
+	targetAddr := i.cfg.PrimaryTarget()
 	i.mu.RUnlock()
-	return i.proxy.Adapter().CheckHealth(protocol, timeout, targetAddr)
+	return i.current().Adapter().CheckHealth(protocol, timeout, targetAddr)
 }
 
 func (i *httpServerInstance) CheckHealth(protocol proxy.ProtocolType, timeout time.Duration) *proxy.HealthCheckResult {
 	i.mu.RLock()
 	listenAddr := i.cfg.Listen
 	i.mu.RUnlock()
-	return i.proxy.Adapter().CheckHealth(protocol, timeout, listenAddr)
+	return i.current().Adapter().CheckHealth(protocol, timeout, listenAddr)
 }
 
 func (i *httpClientInstance) CheckHealth(protocol proxy.ProtocolType, timeout time.Duration) *proxy.HealthCheckResult {
 	i.mu.RLock()
-	targetAddr := i.cfg.Target
+	targetAddr := i.cfg.PrimaryTarget()
 	i.mu.RUnlock()
-	return i.proxy.Adapter().CheckHealth(protocol, timeout, targetAddr)
+	return i.current().Adapter().CheckHealth(protocol, timeout, targetAddr)
+}
+
+func (i *serverInstance) Connections() []*proxy.ConnInfo {
+	return i.current().Connections()
+}
+
+func (i *clientInstance) Connections() []*proxy.ConnInfo {
+	return i.current().Connections()
+}
+
+func (i *httpServerInstance) Connections() []*proxy.ConnInfo {
+	return i.current().Connections()
+}
+
+func (i *httpClientInstance) Connections() []*proxy.ConnInfo {
+	return i.current().Connections()
+}
+
+func (i *serverInstance) CloseConnection(id string) bool {
+	return i.current().CloseConnection(id)
+}
+
+func (i *clientInstance) CloseConnection(id string) bool {
+	return i.current().CloseConnection(id)
+}
+
+func (i *httpServerInstance) CloseConnection(id string) bool {
+	return i.current().CloseConnection(id)
+}
+
+func (i *httpClientInstance) CloseConnection(id string) bool {
+	return i.current().CloseConnection(id)
+}
+
+// current 获取当前代理，重启时会被替换
+func (i *serverInstance) current() *proxy.ServerProxy {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.proxy
+}
+
+func (i *clientInstance) current() *proxy.ClientProxy {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.proxy
+}
+
+func (i *httpServerInstance) current() *proxy.HTTPServerProxy {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.proxy
+}
+
+func (i *httpClientInstance) current() *proxy.HTTPClientProxy {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.proxy
+}
+
+func (i *serverInstance) adapter() *proxy.TLCPAdapter {
+	return i.current().Adapter()
+}
+
+func (i *clientInstance) adapter() *proxy.TLCPAdapter {
+	return i.current().Adapter()
+}
+
+func (i *httpServerInstance) adapter() *proxy.TLCPAdapter {
+	return i.current().Adapter()
+}
+
+func (i *httpClientInstance) adapter() *proxy.TLCPAdapter {
+	return i.current().Adapter()
 }
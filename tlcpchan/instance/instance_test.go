@@ -0,0 +1,241 @@
+package instance
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/rootcert"
+)
+
+func TestInstanceStatsIsolation(t *testing.T) {
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := NewManager(log, security.NewKeyStoreManager(), rootcert.NewManager(""))
+
+	newCfg := func(name, listen string, statsCfg *config.StatsConfig) *config.InstanceConfig {
+		return &config.InstanceConfig{
+			Name:     name,
+			Type:     "client",
+			Protocol: "auto",
+			Listen:   listen,
+			Target:   "127.0.0.1:8080",
+			Stats:    statsCfg,
+		}
+	}
+
+	a, err := mgr.Create(newCfg("stats-a", ":18501", nil))
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("stats-a")
+	b, err := mgr.Create(newCfg("stats-b", ":18502", &config.StatsConfig{Enabled: true}))
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("stats-b")
+	off, err := mgr.Create(newCfg("stats-off", ":18503", &config.StatsConfig{Enabled: false}))
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("stats-off")
+
+	if a.Collector() == b.Collector() {
+		t.Fatal("每个实例应拥有独立的统计收集器")
+	}
+
+	a.Collector().IncrementConnections()
+	a.Collector().IncrementErrors()
+	off.Collector().IncrementConnections()
+
+	if got := a.Stats().TotalConnections; got != 1 {
+		t.Errorf("实例 a TotalConnections 应为 1, 实际为 %d", got)
+	}
+	if got := b.Stats().TotalConnections; got != 0 {
+		t.Errorf("实例 b TotalConnections 应为 0, 实际为 %d", got)
+	}
+	if off.Collector().IsEnabled() {
+		t.Error("stats.enabled=false 的实例应禁用统计收集")
+	}
+	if got := off.Stats().TotalConnections; got != 0 {
+		t.Errorf("禁用统计的实例 TotalConnections 应为 0, 实际为 %d", got)
+	}
+
+	// 重载配置后重新启用统计
+	cfg := *off.Config()
+	cfg.Stats = &config.StatsConfig{Enabled: true}
+	if err := off.Restart(&cfg); err != nil {
+		t.Fatalf("重启实例失败: %v", err)
+	}
+	defer off.Stop()
+	if !off.Collector().IsEnabled() {
+		t.Error("重启后 stats.enabled=true 的实例应启用统计收集")
+	}
+}
+
+func TestInstanceRestartConcurrentAccess(t *testing.T) {
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := NewManager(log, security.NewKeyStoreManager(), rootcert.NewManager(""))
+
+	cfg := &config.InstanceConfig{
+		Name:     "restart-race",
+		Type:     "client",
+		Protocol: "tls",
+		Listen:   closedAddr(t),
+		Target:   closedAddr(t),
+	}
+	inst, err := mgr.Create(cfg)
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("restart-race")
+	if err := inst.Start(); err != nil {
+		t.Fatalf("启动实例失败: %v", err)
+	}
+
+	// 重启替换代理期间并发查询实例
+	stop := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			inst.Connections()
+		}
+	}()
+	<-started
+	for n := 0; n < 5; n++ {
+		next := *cfg
+		if err := inst.Restart(&next); err != nil {
+			t.Errorf("重启实例失败: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// newWebKeyStore 创建包含 TLS 证书的 keystore "web"
+func newWebKeyStore(t *testing.T) *security.KeyStoreManager {
+	t.Helper()
+	dir := t.TempDir()
+	cert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "web", Days: 1})
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	certPath, keyPath := filepath.Join(dir, "web.crt"), filepath.Join(dir, "web.key")
+	if err := certgen.SaveCertToFile(cert.CertPEM, cert.KeyPEM, certPath, keyPath); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	ksMgr := security.NewKeyStoreManager()
+	if _, err := ksMgr.Create("web", security.LoaderTypeFile, map[string]string{"sign-cert": certPath, "sign-key": keyPath}, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	return ksMgr
+}
+
+func TestInstanceRestartKeepsConnections(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	mgr := NewManager(log, newWebKeyStore(t), rootcert.NewManager(""))
+	cfg := &config.InstanceConfig{
+		Name:     "restart-conns",
+		Type:     "server",
+		Protocol: "tls",
+		Listen:   closedAddr(t),
+		Target:   echo.Addr().String(),
+		TLS:      config.TLSConfig{Keystore: &config.KeyStoreConfig{Type: "named", Name: "web"}},
+	}
+	inst, err := mgr.Create(cfg)
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("restart-conns")
+	if err := inst.Start(); err != nil {
+		t.Fatalf("启动实例失败: %v", err)
+	}
+
+	roundTrip := func(conn net.Conn) error {
+		conn.SetDeadline(time.Now().Add(2 * time.Second))
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			return err
+		}
+		_, err := io.ReadFull(conn, make([]byte, 4))
+		return err
+	}
+	var conns []net.Conn
+	for n := 0; n < 2; n++ {
+		conn, err := tls.Dial("tcp", cfg.Listen, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("握手失败: %v", err)
+		}
+		defer conn.Close()
+		if err := roundTrip(conn); err != nil {
+			t.Fatalf("转发失败: %v", err)
+		}
+		conns = append(conns, conn)
+	}
+
+	// 以新的监听地址重启，旧代理上的连接继续转发，并可通过实例查询、强制关闭和排空
+	next := *cfg
+	next.Listen = closedAddr(t)
+	if err := inst.Restart(&next); err != nil {
+		t.Fatalf("重启实例失败: %v", err)
+	}
+	if err := roundTrip(conns[0]); err != nil {
+		t.Fatalf("重启后旧连接转发失败: %v", err)
+	}
+	infos := inst.Connections()
+	if len(infos) != 2 {
+		t.Fatalf("重启后活跃连接数 = %d, 期望 2", len(infos))
+	}
+	if !inst.CloseConnection(infos[0].ID) {
+		t.Fatalf("关闭重启前建立的连接 %s 失败", infos[0].ID)
+	}
+	if err := roundTrip(conns[0]); err == nil {
+		t.Error("强制关闭后连接应断开")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(inst.Connections()) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	result := inst.Drain(0)
+	if result.ForceClosed != 1 {
+		t.Errorf("排空结果 = %+v, 期望强制关闭 1 个连接", result)
+	}
+	if err := roundTrip(conns[1]); err == nil {
+		t.Error("排空后连接应断开")
+	}
+}
@@ -2,10 +2,12 @@ package instance
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 
 	"github.com/Trisia/tlcpchan/config"
 	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/proxy"
 	"github.com/Trisia/tlcpchan/security"
 )
 
@@ -61,6 +63,28 @@ func (m *Manager) Create(cfg *config.InstanceConfig) (Instance, error) {
 	return inst, nil
 }
 
+// ValidateRootCerts 验证实例配置引用的根证书是否存在
+// 参数:
+//   - cfg: 实例配置
+//
+// 返回:
+//   - error: client-ca 或 server-ca 引用的根证书不存在，或启用CRL检查但未配置根证书管理器时返回错误
+func (m *Manager) ValidateRootCerts(cfg *config.InstanceConfig) error {
+	if len(cfg.ClientCA) == 0 && len(cfg.ServerCA) == 0 && !cfg.CRLCheck {
+		return nil
+	}
+	if m.rootCertManager == nil {
+		return fmt.Errorf("未配置根证书管理器")
+	}
+	if err := m.rootCertManager.CheckFiles(cfg.ClientCA); err != nil {
+		return fmt.Errorf("client-ca 配置错误: %w", err)
+	}
+	if err := m.rootCertManager.CheckFiles(cfg.ServerCA); err != nil {
+		return fmt.Errorf("server-ca 配置错误: %w", err)
+	}
+	return nil
+}
+
 func (m *Manager) Get(name string) (Instance, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -95,7 +119,7 @@ func (m *Manager) Delete(name string) error {
 		return fmt.Errorf("实例 %s 不存在", name)
 	}
 
-	if inst.Status() == StatusRunning {
+	if inst.Status().IsRunning() {
 		if err := inst.Stop(); err != nil {
 			m.logger.Warn("停止实例 %s 失败，但继续删除: %v", name, err)
 		} else {
@@ -103,11 +127,72 @@ func (m *Manager) Delete(name string) error {
 		}
 	}
 
+	if err := inst.Close(); err != nil {
+		m.logger.Warn("释放实例 %s 资源失败: %v", name, err)
+	}
 	delete(m.instances, name)
 	m.logger.Info("删除实例: %s", name)
 	return nil
 }
 
+// ReloadKeyStore 重新加载引用指定 keystore 的实例的TLCP/TLS配置
+// 参数:
+//   - name: keystore 名称
+//
+// 返回:
+//   - []string: 重新加载成功的实例名称
+//
+// 注意: 用于订阅 keystore 管理器的变化，证书续期后无需手动重载实例；重新加载失败的实例继续使用原有配置
+func (m *Manager) ReloadKeyStore(name string) []string {
+	m.mu.RLock()
+	instances := make([]Instance, 0, len(m.instances))
+	for _, inst := range m.instances {
+		if slices.Contains(proxy.KeyStoreNames(inst.Config()), name) {
+			instances = append(instances, inst)
+		}
+	}
+	m.mu.RUnlock()
+
+	var reloaded []string
+	for _, inst := range instances {
+		if err := inst.ReloadCertificates(); err != nil {
+			m.logger.Error("keystore %s 已更新，重新加载实例 %s 失败: %v", name, inst.Name(), err)
+			continue
+		}
+		m.logger.Info("keystore %s 已更新，实例 %s 已重新加载证书", name, inst.Name())
+		reloaded = append(reloaded, inst.Name())
+	}
+	return reloaded
+}
+
+// ReloadRootCerts 为引用根证书的实例替换 client-ca/server-ca 证书池
+// 返回:
+//   - []string: 替换成功的实例名称，按名称排序
+//
+// 注意: 用于订阅根证书管理器的变化，添加、删除根证书后无需手动重载实例
+func (m *Manager) ReloadRootCerts() []string {
+	m.mu.RLock()
+	instances := make([]Instance, 0, len(m.instances))
+	for _, inst := range m.instances {
+		if len(proxy.RootCertNames(inst.Config())) > 0 {
+			instances = append(instances, inst)
+		}
+	}
+	m.mu.RUnlock()
+
+	reloaded := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		if err := inst.ReloadRootCerts(); err != nil {
+			m.logger.Error("根证书已更新，实例 %s 替换证书池失败: %v", inst.Name(), err)
+			continue
+		}
+		m.logger.Info("根证书已更新，实例 %s 已替换证书池", inst.Name())
+		reloaded = append(reloaded, inst.Name())
+	}
+	slices.Sort(reloaded)
+	return reloaded
+}
+
 // StartAll 启动所有已启用的实例
 // 返回:
 //   - []error: 启动失败的错误列表，为空表示全部成功
@@ -128,7 +213,7 @@ func (m *Manager) StartAll() []error {
 			m.logger.Debug("实例 %s 未启用，跳过启动", inst.Name())
 			continue
 		}
-		if inst.Status() == StatusRunning {
+		if inst.Status().IsRunning() {
 			continue
 		}
 		if err := inst.Start(); err != nil {
@@ -151,7 +236,7 @@ func (m *Manager) StopAll() {
 	m.mu.RUnlock()
 
 	for _, inst := range instances {
-		if inst.Status() != StatusRunning {
+		if !inst.Status().IsRunning() {
 			continue
 		}
 		if err := inst.Stop(); err != nil {
@@ -161,3 +246,27 @@ func (m *Manager) StopAll() {
 		}
 	}
 }
+
+// DrainAll 并行排空所有运行中的实例，用于进程优雅退出
+// 每个实例使用各自配置的排空宽限期，所有实例排空完成后返回
+func (m *Manager) DrainAll() {
+	m.mu.RLock()
+	instances := make([]Instance, 0, len(m.instances))
+	for _, inst := range m.instances {
+		instances = append(instances, inst)
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, inst := range instances {
+		if !inst.Status().IsRunning() {
+			continue
+		}
+		wg.Add(1)
+		go func(inst Instance) {
+			defer wg.Done()
+			inst.Drain(inst.Config().DrainTimeout())
+		}(inst)
+	}
+	wg.Wait()
+}
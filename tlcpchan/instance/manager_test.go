@@ -1,11 +1,15 @@
 package instance
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"path/filepath"
 	"testing"
 
 	"github.com/Trisia/tlcpchan/config"
 	"github.com/Trisia/tlcpchan/logger"
 	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/security/certgen"
 	"github.com/Trisia/tlcpchan/security/rootcert"
 )
 
@@ -122,3 +126,183 @@ func TestInstanceManagerCreateDuplicate(t *testing.T) {
 		t.Error("期望创建重复实例失败，但成功了")
 	}
 }
+
+func TestInstanceManagerReloadKeyStore(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "web.crt")
+	keyPath := filepath.Join(dir, "web.key")
+	writeCert := func(cn string) {
+		cert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: cn, Days: 1})
+		if err != nil {
+			t.Fatalf("生成证书失败: %v", err)
+		}
+		if err := certgen.SaveCertToFile(cert.CertPEM, cert.KeyPEM, certPath, keyPath); err != nil {
+			t.Fatalf("保存证书失败: %v", err)
+		}
+	}
+	writeCert("web-v1")
+
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	ksMgr := security.NewKeyStoreManager()
+	if _, err := ksMgr.Create("web", security.LoaderTypeFile, map[string]string{"sign-cert": certPath, "sign-key": keyPath}, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	mgr := NewManager(log, ksMgr, rootcert.NewManager(""))
+	ksMgr.Subscribe(func(name string) { mgr.ReloadKeyStore(name) })
+
+	listen := closedAddr(t)
+	inst, err := mgr.Create(&config.InstanceConfig{
+		Name:     "keystore-reload",
+		Type:     "server",
+		Protocol: "tls",
+		Listen:   listen,
+		Target:   closedAddr(t),
+		TLS: config.TLSConfig{
+			Keystore: &config.KeyStoreConfig{Type: "named", Name: "web"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("keystore-reload")
+	if err := inst.Start(); err != nil {
+		t.Fatalf("启动实例失败: %v", err)
+	}
+
+	serverCN := func() string {
+		conn, err := tls.Dial("tcp", listen, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("握手失败: %v", err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+	}
+	if cn := serverCN(); cn != "web-v1" {
+		t.Fatalf("初始证书 CN = %s, 期望 web-v1", cn)
+	}
+
+	// 证书续期后通过 keystore 管理器重新加载，实例无需手动重载
+	writeCert("web-v2")
+	if _, err := ksMgr.Reload("web", nil); err != nil {
+		t.Fatalf("重新加载 keystore 失败: %v", err)
+	}
+	if cn := serverCN(); cn != "web-v2" {
+		t.Errorf("续期后证书 CN = %s, 期望 web-v2", cn)
+	}
+
+	if got := mgr.ReloadKeyStore("other"); len(got) != 0 {
+		t.Errorf("未引用该 keystore 的实例不应重新加载: %v", got)
+	}
+	if got := mgr.ReloadKeyStore("web"); len(got) != 1 || got[0] != "keystore-reload" {
+		t.Errorf("ReloadKeyStore() = %v, 期望 [keystore-reload]", got)
+	}
+}
+
+func TestInstanceManagerReloadRootCerts(t *testing.T) {
+	dir := t.TempDir()
+	rootDir := filepath.Join(dir, "rootcerts")
+	ca, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "client-ca", Days: 1})
+	if err != nil {
+		t.Fatalf("生成根证书失败: %v", err)
+	}
+	caPair, err := tls.X509KeyPair(ca.CertPEM, ca.KeyPEM)
+	if err != nil {
+		t.Fatalf("解析根证书失败: %v", err)
+	}
+	caCert, err := x509.ParseCertificate(caPair.Certificate[0])
+	if err != nil {
+		t.Fatalf("解析根证书失败: %v", err)
+	}
+	clientCert, err := certgen.GenerateTLSCert(caCert, caPair.PrivateKey, certgen.CertGenConfig{CommonName: "client", Days: 1})
+	if err != nil {
+		t.Fatalf("签发客户端证书失败: %v", err)
+	}
+	clientPair, err := tls.X509KeyPair(clientCert.CertPEM, clientCert.KeyPEM)
+	if err != nil {
+		t.Fatalf("解析客户端证书失败: %v", err)
+	}
+
+	serverCert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "server", Days: 1})
+	if err != nil {
+		t.Fatalf("生成服务端证书失败: %v", err)
+	}
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	if err := certgen.SaveCertToFile(serverCert.CertPEM, serverCert.KeyPEM, certPath, keyPath); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+
+	log, _ := logger.New(logger.LogConfig{Level: "info", Enabled: false})
+	ksMgr := security.NewKeyStoreManager()
+	if _, err := ksMgr.Create("web", security.LoaderTypeFile, map[string]string{"sign-cert": certPath, "sign-key": keyPath}, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	rcMgr := rootcert.NewManager(rootDir)
+	if _, err := rcMgr.Add("client-ca.crt", ca.CertPEM); err != nil {
+		t.Fatalf("添加根证书失败: %v", err)
+	}
+	mgr := NewManager(log, ksMgr, rcMgr)
+	rcMgr.Subscribe(mgr.ReloadRootCerts)
+
+	listen := closedAddr(t)
+	inst, err := mgr.Create(&config.InstanceConfig{
+		Name:     "rootcert-reload",
+		Type:     "server",
+		Protocol: "tls",
+		Listen:   listen,
+		Target:   closedAddr(t),
+		ClientCA: []string{"client-ca.crt"},
+		TLS: config.TLSConfig{
+			ClientAuthType: "require-and-verify-client-cert",
+			Keystore:       &config.KeyStoreConfig{Type: "named", Name: "web"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建实例失败: %v", err)
+	}
+	defer mgr.Delete("rootcert-reload")
+	if err := inst.Start(); err != nil {
+		t.Fatalf("启动实例失败: %v", err)
+	}
+
+	// TLS 1.2 下客户端证书验证失败会使握手失败
+	handshake := func() error {
+		conn, err := tls.Dial("tcp", listen, &tls.Config{
+			InsecureSkipVerify: true,
+			MaxVersion:         tls.VersionTLS12,
+			Certificates:       []tls.Certificate{clientPair},
+		})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	if err := handshake(); err != nil {
+		t.Fatalf("删除根证书前握手失败: %v", err)
+	}
+
+	// 重新加载根证书时由订阅方替换实例证书池，并返回替换成功的实例
+	got, err := rcMgr.Reload()
+	if err != nil {
+		t.Fatalf("重新加载根证书失败: %v", err)
+	}
+	if len(got) != 1 || got[0] != "rootcert-reload" {
+		t.Errorf("Reload() = %v, 期望 [rootcert-reload]", got)
+	}
+
+	// 删除根证书后实例不再信任该CA签发的客户端证书，无需手动重载实例
+	if err := rcMgr.Delete("client-ca.crt"); err != nil {
+		t.Fatalf("删除根证书失败: %v", err)
+	}
+	if err := handshake(); err == nil {
+		t.Error("删除根证书后客户端证书仍被信任")
+	}
+
+	// 重新添加后恢复信任
+	if _, err := rcMgr.Add("client-ca.crt", ca.CertPEM); err != nil {
+		t.Fatalf("添加根证书失败: %v", err)
+	}
+	if err := handshake(); err != nil {
+		t.Errorf("重新添加根证书后握手失败: %v", err)
+	}
+}
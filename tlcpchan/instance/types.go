@@ -12,8 +12,15 @@ const (
 	StatusStopped Status = "stopped"
 	// StatusError 错误状态
 	StatusError Status = "error"
+	// StatusDegraded 运行中，但后台健康检查连续失败达到阈值
+	StatusDegraded Status = "degraded"
 )
 
+// IsRunning 实例是否处于运行中，degraded 状态的实例仍在运行并处理连接
+func (s Status) IsRunning() bool {
+	return s == StatusRunning || s == StatusDegraded
+}
+
 // InstanceType 实例类型
 type InstanceType string
 
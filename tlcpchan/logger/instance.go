@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInstanceBuffer 实例日志在内存中默认保留的条数
+const DefaultInstanceBuffer = 1000
+
+// Entry 实例日志记录
+type Entry struct {
+	// Time 记录时间
+	Time time.Time `json:"timestamp"`
+	// Level 日志级别，可选值: "debug", "info", "warn", "error", "fatal"
+	Level string `json:"level"`
+	// Message 日志内容
+	Message string `json:"message"`
+	// Instance 实例名称，仅订阅推送的实例日志记录包含
+	Instance string `json:"instance,omitempty"`
+}
+
+// Query 实例日志查询条件
+type Query struct {
+	// Level 最低日志级别，低于该级别的记录不返回
+	Level Level
+	// Since 起始时间（含），零值表示不限制
+	Since time.Time
+	// Until 结束时间（不含），零值表示不限制
+	Until time.Time
+	// Limit 最多返回的条数，超出时返回最近的记录，<=0 表示不限制
+	Limit int
+}
+
+// match 判断记录是否满足级别与时间范围条件
+func (q *Query) match(e *Entry) bool {
+	if ParseLevel(e.Level) < q.Level {
+		return false
+	}
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// InstanceLogConfig 实例日志配置
+type InstanceLogConfig struct {
+	// Buffer 内存中保留的最近日志条数，<=0 时使用 DefaultInstanceBuffer
+	Buffer int
+	// File 实例日志文件路径，为空则不写入单独的文件
+	File string
+	// MaxSize 单个日志文件最大大小，单位: MB
+	MaxSize int
+	// MaxBackups 保留的旧日志文件最大数量，单位: 个
+	MaxBackups int
+	// MaxAge 保留旧日志文件的最大天数，单位: 天
+	MaxAge int
+	// Compress 是否压缩旧日志文件
+	Compress bool
+}
+
+// ring 固定容量的日志环形缓冲，写满后覆盖最早的记录
+type ring struct {
+	entries []Entry
+	// start 最早一条记录的位置
+	start int
+	size  int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{entries: make([]Entry, capacity)}
+}
+
+func (r *ring) add(e Entry) {
+	if r.size < len(r.entries) {
+		r.entries[(r.start+r.size)%len(r.entries)] = e
+		r.size++
+		return
+	}
+	r.entries[r.start] = e
+	r.start = (r.start + 1) % len(r.entries)
+}
+
+// resize 调整容量，保留最近的记录
+func (r *ring) resize(capacity int) *ring {
+	n := newRing(capacity)
+	skip := r.size - capacity
+	for i := 0; i < r.size; i++ {
+		if i >= skip {
+			n.add(r.entries[(r.start+i)%len(r.entries)])
+		}
+	}
+	return n
+}
+
+// query 按时间顺序返回满足条件的记录
+func (r *ring) query(q Query) []Entry {
+	result := make([]Entry, 0)
+	for i := 0; i < r.size; i++ {
+		e := &r.entries[(r.start+i)%len(r.entries)]
+		if q.match(e) {
+			result = append(result, *e)
+		}
+	}
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[len(result)-q.Limit:]
+	}
+	return result
+}
+
+var (
+	instanceMu      sync.Mutex
+	instanceLoggers = make(map[string]*Logger)
+)
+
+// Instance 获取实例的日志记录器，不存在时创建
+// 参数:
+//   - name: 实例名称
+//
+// 返回:
+//   - *Logger: 实例日志记录器，输出时带有 "[实例名称]" 前缀，并写入实例的内存缓冲与日志文件
+//
+// 注意:
+//   - 同一实例名称始终返回同一个记录器，实例重启后仍保留之前的记录
+//   - 输出到默认日志记录器，级别与启用状态以默认日志记录器为准
+func Instance(name string) *Logger {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	l, ok := instanceLoggers[name]
+	if !ok {
+		l = &Logger{
+			level:    LevelDebug,
+			enabled:  true,
+			instance: name,
+			ring:     newRing(DefaultInstanceBuffer),
+		}
+		instanceLoggers[name] = l
+	}
+	return l
+}
+
+// ConfigureInstance 按配置调整实例日志的内存缓冲大小并打开或关闭实例日志文件
+// 参数:
+//   - name: 实例名称
+//   - cfg: 实例日志配置
+//
+// 返回:
+//   - error: 打开日志文件失败时返回错误，此时保持原有日志文件
+func ConfigureInstance(name string, cfg InstanceLogConfig) error {
+	l := Instance(name)
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = DefaultInstanceBuffer
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cfg.Buffer != len(l.ring.entries) {
+		l.ring = l.ring.resize(cfg.Buffer)
+	}
+
+	fileCfg := LogConfig{
+		File:       cfg.File,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}
+	if fileCfg.File == l.config.File && fileCfg.MaxSize == l.config.MaxSize && fileCfg.MaxBackups == l.config.MaxBackups &&
+		fileCfg.MaxAge == l.config.MaxAge && fileCfg.Compress == l.config.Compress {
+		return nil
+	}
+	var file *RotateWriter
+	if cfg.File != "" {
+		f, err := NewRotateWriter(fileCfg)
+		if err != nil {
+			return fmt.Errorf("打开实例日志文件失败: %w", err)
+		}
+		file = f
+	}
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = file
+	l.config = fileCfg
+	return nil
+}
+
+// RemoveInstance 删除实例的日志记录器，关闭实例日志文件并丢弃内存中的记录
+func RemoveInstance(name string) {
+	instanceMu.Lock()
+	l, ok := instanceLoggers[name]
+	delete(instanceLoggers, name)
+	instanceMu.Unlock()
+	if ok {
+		l.Close()
+	}
+}
+
+// InstanceEntries 查询实例内存中的日志记录
+// 参数:
+//   - name: 实例名称
+//   - q: 查询条件
+//
+// 返回:
+//   - []Entry: 按时间顺序排列的记录，实例没有日志时返回空列表
+func InstanceEntries(name string, q Query) []Entry {
+	instanceMu.Lock()
+	l, ok := instanceLoggers[name]
+	instanceMu.Unlock()
+	if !ok {
+		return []Entry{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ring.query(q)
+}
+
+// logInstance 将实例日志写入内存缓冲和实例日志文件
+func (l *Logger) logInstance(r *Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ring.add(Entry{Time: r.Time, Level: strings.ToLower(r.Level.String()), Message: r.text()})
+	if l.file != nil {
+		fmt.Fprintf(l.file, "%s [%s] %s\n", r.Time.Format(lineTimeLayout), r.Level.String(), r.text())
+	}
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstanceLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sinks := Default().sinks
+	Default().sinks = []Sink{NewWriterSink(&buf, FormatText)}
+	defer func() { Default().sinks = sinks }()
+
+	path := filepath.Join(t.TempDir(), "gm-server.log")
+	if err := ConfigureInstance("gm-server", InstanceLogConfig{Buffer: 3, File: path}); err != nil {
+		t.Fatalf("ConfigureInstance() error = %v", err)
+	}
+	defer RemoveInstance("gm-server")
+
+	l := Instance("gm-server")
+	if Instance("gm-server") != l {
+		t.Error("同一实例名称应返回同一个记录器")
+	}
+	l.Info("第%d条", 1)
+	l.Warn("第%d条", 2)
+	l.Info("第%d条", 3)
+	l.Error("第%d条", 4)
+	l.Debug("默认级别为info，不记录")
+
+	if !strings.Contains(buf.String(), "[INFO] [gm-server] 第1条") {
+		t.Errorf("输出应带实例名称前缀, 实际为 %q", buf.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取实例日志文件失败: %v", err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 4 {
+		t.Errorf("实例日志文件行数 = %d, want 4", n)
+	}
+
+	messages := func(entries []Entry) string {
+		var s []string
+		for _, e := range entries {
+			s = append(s, e.Message)
+		}
+		return strings.Join(s, ",")
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want string
+	}{
+		{"超出缓冲覆盖最早记录", Query{}, "第2条,第3条,第4条"},
+		{"按级别过滤", Query{Level: LevelWarn}, "第2条,第4条"},
+		{"限制条数返回最近记录", Query{Limit: 2}, "第3条,第4条"},
+		{"起始时间之后", Query{Since: time.Now().Add(time.Minute)}, ""},
+		{"结束时间之前", Query{Until: time.Now().Add(-time.Minute)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messages(InstanceEntries("gm-server", tt.q)); got != tt.want {
+				t.Errorf("InstanceEntries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// 缩小缓冲保留最近的记录
+	ConfigureInstance("gm-server", InstanceLogConfig{Buffer: 1})
+	if got := messages(InstanceEntries("gm-server", Query{})); got != "第4条" {
+		t.Errorf("缩小缓冲后 = %q, want %q", got, "第4条")
+	}
+
+	RemoveInstance("gm-server")
+	if entries := InstanceEntries("gm-server", Query{}); entries == nil || len(entries) != 0 {
+		t.Errorf("删除后应返回空列表, 实际为 %v", entries)
+	}
+}
@@ -1,13 +1,12 @@
 package logger
 
 import (
-	"compress/gzip"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -81,25 +80,32 @@ type LogConfig struct {
 	Compress bool
 	// Enabled 是否启用日志
 	Enabled bool
+	// Format 标准输出与日志文件的格式，可选值: FormatText（默认）, FormatJSON
+	Format string
+	// Outputs 输出目标，可选值: OutputStdout, OutputFile, OutputSyslog，可同时使用
+	// 为空时输出到标准输出，设置了 File 时同时输出到日志文件
+	Outputs []string
+	// Syslog syslog 输出配置，Outputs 包含 OutputSyslog 时必须设置
+	Syslog *SyslogConfig
 }
 
-// Logger 日志记录器，支持文件输出、日志轮转和压缩
+// Logger 日志记录器，支持标准输出、轮转文件、syslog 等多个输出目标
 type Logger struct {
-	*log.Logger
 	mu sync.Mutex
 	// level 当前日志级别
 	level Level
 	// enabled 是否启用
 	enabled bool
-	// writer 输出目标
-	writer io.Writer
-	// file 日志文件句柄
-	file   *os.File
+	// sinks 输出目标
+	sinks []Sink
+	// file 日志文件，按配置轮转
+	file   *RotateWriter
 	config LogConfig
-	// filePath 日志文件路径
-	filePath string
-	// curSize 当前日志文件大小，单位: 字节
-	curSize int64
+
+	// instance 实例名称，仅实例日志记录器（见 Instance）设置
+	instance string
+	// ring 实例日志的内存缓冲
+	ring *ring
 }
 
 var (
@@ -111,10 +117,9 @@ var (
 func Default() *Logger {
 	once.Do(func() {
 		defaultLogger = &Logger{
-			Logger:  log.New(os.Stdout, "", log.LstdFlags|log.Lshortfile),
 			level:   LevelInfo,
 			enabled: true,
-			writer:  os.Stdout,
+			sinks:   []Sink{NewWriterSink(os.Stdout, FormatText)},
 		}
 	})
 	return defaultLogger
@@ -131,41 +136,47 @@ func Init(cfg LogConfig) (*Logger, error) {
 	}
 
 	if !cfg.Enabled {
-		l.Logger = log.New(io.Discard, "", 0)
-		l.writer = io.Discard
 		return l, nil
 	}
 
-	var writers []io.Writer
-	writers = append(writers, os.Stdout)
-
-	if cfg.File != "" {
-		if err := os.MkdirAll(filepath.Dir(cfg.File), 0755); err != nil {
-			return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputStdout}
+		if cfg.File != "" {
+			outputs = append(outputs, OutputFile)
 		}
-
-		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		if err != nil {
-			return nil, fmt.Errorf("打开日志文件失败: %w", err)
-		}
-
-		fi, err := f.Stat()
-		if err != nil {
-			f.Close()
-			return nil, fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	for _, output := range outputs {
+		var sink Sink
+		switch output {
+		case OutputStdout:
+			sink = NewWriterSink(os.Stdout, cfg.Format)
+		case OutputFile:
+			f, err := NewRotateWriter(cfg)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			l.file = f
+			sink = NewWriterSink(f, cfg.Format)
+		case OutputSyslog:
+			if cfg.Syslog == nil {
+				l.Close()
+				return nil, fmt.Errorf("未配置 syslog 输出")
+			}
+			s, err := NewSyslogSink(*cfg.Syslog)
+			if err != nil {
+				l.Close()
+				return nil, err
+			}
+			sink = s
+		default:
+			l.Close()
+			return nil, fmt.Errorf("无效的日志输出目标: %s", output)
 		}
-
-		l.file = f
-		l.filePath = cfg.File
-		l.curSize = fi.Size()
-		writers = append(writers, f)
-
-		go l.cleanupLoop()
+		l.sinks = append(l.sinks, sink)
 	}
 
-	l.writer = io.MultiWriter(writers...)
-	l.Logger = log.New(l.writer, "", log.LstdFlags|log.Lshortfile)
-
 	return l, nil
 }
 
@@ -188,6 +199,10 @@ func (l *Logger) SetEnabled(enabled bool) {
 func (l *Logger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		s.Close()
+	}
+	l.sinks = nil
 	if l.file != nil {
 		return l.file.Close()
 	}
@@ -198,137 +213,47 @@ func (l *Logger) shouldLog(level Level) bool {
 	return l.enabled && level >= l.level
 }
 
-func (l *Logger) log(level Level, format string, args ...interface{}) {
-	if !l.shouldLog(level) {
-		return
-	}
-
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	msg := fmt.Sprintf("[%s] %s", level.String(), fmt.Sprintf(format, args...))
-	l.Logger.Output(3, msg)
-
-	if l.file != nil && l.config.MaxSize > 0 {
-		l.curSize += int64(len(msg) + 30)
-		if l.curSize >= int64(l.config.MaxSize)*1024*1024 {
-			l.rotate()
-		}
+// target 获取决定级别与输出目标的日志记录器，实例日志记录器为默认日志记录器
+func (l *Logger) target() *Logger {
+	if l.instance != "" {
+		return Default()
 	}
+	return l
 }
 
-func (l *Logger) rotate() {
-	if l.file == nil {
+// emit 输出一条记录：推送给订阅方并写入所有输出目标，实例日志同时写入实例的内存缓冲与日志文件
+func (l *Logger) emit(r *Record) {
+	target := l.target()
+	if !target.shouldLog(r.Level) {
 		return
 	}
-
-	oldPath := l.filePath
-	l.file.Close()
-
-	ts := time.Now().Format("20060102-150405")
-	backupPath := fmt.Sprintf("%s.%s", oldPath, ts)
-	os.Rename(oldPath, backupPath)
-
-	if l.config.Compress {
-		go compressFile(backupPath)
-	}
-
-	f, err := os.OpenFile(oldPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		log.Printf("重新打开日志文件失败: %v", err)
-		return
-	}
-	l.file = f
-	l.curSize = 0
-
-	writers := []io.Writer{os.Stdout, f}
-	l.writer = io.MultiWriter(writers...)
-	l.Logger.SetOutput(l.writer)
-
-	l.cleanOldBackups()
-}
-
-func (l *Logger) cleanOldBackups() {
-	if l.filePath == "" || (l.config.MaxBackups <= 0 && l.config.MaxAge <= 0) {
-		return
+	if l.instance != "" {
+		r.Instance = l.instance
+		l.logInstance(r)
 	}
-
-	dir := filepath.Dir(l.filePath)
-	base := filepath.Base(l.filePath)
-
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return
-	}
-
-	var backups []os.FileInfo
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, base+".") && name != base {
-			info, err := entry.Info()
-			if err == nil {
-				backups = append(backups, info)
-			}
-		}
-	}
-
-	sort.Slice(backups, func(i, j int) bool {
-		return backups[i].ModTime().After(backups[j].ModTime())
-	})
-
-	now := time.Now()
-	for i, info := range backups {
-		shouldDelete := false
-
-		if l.config.MaxBackups > 0 && i >= l.config.MaxBackups {
-			shouldDelete = true
-		}
-
-		if l.config.MaxAge > 0 {
-			if now.Sub(info.ModTime()) > time.Duration(l.config.MaxAge)*24*time.Hour {
-				shouldDelete = true
-			}
-		}
-
-		if shouldDelete {
-			path := filepath.Join(dir, info.Name())
-			os.Remove(path)
-			if strings.HasSuffix(info.Name(), ".gz") {
-				os.Remove(strings.TrimSuffix(path, ".gz"))
-			}
+	publish(Entry{Time: r.Time, Level: strings.ToLower(r.Level.String()), Message: r.text(), Instance: r.Instance})
+
+	target.mu.Lock()
+	sinks := target.sinks
+	target.mu.Unlock()
+	for _, s := range sinks {
+		if err := s.Write(r); err != nil {
+			fmt.Fprintf(os.Stderr, "写入日志失败: %v\n", err)
 		}
 	}
 }
 
-func (l *Logger) cleanupLoop() {
-	ticker := time.NewTicker(time.Hour)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		l.mu.Lock()
-		l.cleanOldBackups()
-		l.mu.Unlock()
-	}
-}
-
-func compressFile(path string) {
-	src, err := os.Open(path)
-	if err != nil {
+// log 格式化并输出日志，由 Debug/Info 等方法或同名包级函数调用
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	if !l.target().shouldLog(level) {
 		return
 	}
-	defer src.Close()
-
-	dst, err := os.Create(path + ".gz")
-	if err != nil {
-		return
+	r := &Record{Time: time.Now(), Level: level, Message: fmt.Sprintf(format, args...)}
+	// 调用栈: log <- Debug/Info/... <- 调用方
+	if _, file, line, ok := runtime.Caller(2); ok {
+		r.Source = filepath.Base(file) + ":" + strconv.Itoa(line)
 	}
-	defer dst.Close()
-
-	gz := gzip.NewWriter(dst)
-	defer gz.Close()
-
-	io.Copy(gz, src)
-	os.Remove(path)
+	l.emit(r)
 }
 
 func (l *Logger) Debug(format string, args ...interface{}) {
@@ -369,12 +294,21 @@ func (l *Logger) Fatalf(format string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// InitDefault 按配置创建并替换默认日志记录器，同时将 log/slog 与标准库 log 的默认输出转发到该日志记录器
+// 参数:
+//   - cfg: 日志配置
+//
+// 返回:
+//   - error: 打开日志文件或连接 syslog 服务失败时返回错误，此时保持原有默认日志记录器
 func InitDefault(cfg LogConfig) error {
 	l, err := Init(cfg)
 	if err != nil {
 		return err
 	}
+	// 确保 Default 不会在之后覆盖已替换的默认日志记录器
+	Default()
 	defaultLogger = l
+	slog.SetDefault(slog.New(l.Handler()))
 	return nil
 }
 
@@ -387,29 +321,31 @@ func SetEnabled(enabled bool) {
 }
 
 func Debug(format string, args ...interface{}) {
-	Default().Debug(format, args...)
+	Default().log(LevelDebug, format, args...)
 }
 
 func Info(format string, args ...interface{}) {
-	Default().Info(format, args...)
+	Default().log(LevelInfo, format, args...)
 }
 
 func Warn(format string, args ...interface{}) {
-	Default().Warn(format, args...)
+	Default().log(LevelWarn, format, args...)
 }
 
 func Error(format string, args ...interface{}) {
-	Default().Error(format, args...)
+	Default().log(LevelError, format, args...)
 }
 
 // Fatal 输出致命错误日志并退出程序
 func Fatal(format string, args ...interface{}) {
-	Default().Fatal(format, args...)
+	Default().log(LevelFatal, format, args...)
+	os.Exit(1)
 }
 
 // Fatalf 输出致命错误日志并退出程序
 func Fatalf(format string, args ...interface{}) {
-	Default().Fatalf(format, args...)
+	Default().log(LevelFatal, format, args...)
+	os.Exit(1)
 }
 
 func Close() error {
@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateWriter 按大小轮转的文件写入器
+// 文件超过 MaxSize 后重命名为 "<文件名>.<时间>" 并重新创建，按 MaxBackups、MaxAge 清理旧文件，可选压缩旧文件
+type RotateWriter struct {
+	mu     sync.Mutex
+	config LogConfig
+	file   *os.File
+	// size 当前文件大小，单位: 字节
+	size int64
+	done chan struct{}
+}
+
+// NewRotateWriter 打开轮转文件写入器
+// 参数:
+//   - cfg: 日志配置，使用其中的 File、MaxSize、MaxBackups、MaxAge、Compress，MaxSize<=0 时不轮转
+//
+// 返回:
+//   - *RotateWriter: 写入器，不再使用时应调用 Close
+//   - error: 创建目录或打开文件失败时返回错误
+func NewRotateWriter(cfg LogConfig) (*RotateWriter, error) {
+	if cfg.File == "" {
+		return nil, fmt.Errorf("日志文件路径不能为空")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+
+	w := &RotateWriter{config: cfg, done: make(chan struct{})}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	go w.cleanupLoop()
+	return w, nil
+}
+
+// open 以追加方式打开日志文件
+func (w *RotateWriter) open() error {
+	f, err := os.OpenFile(w.config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("获取文件信息失败: %w", err)
+	}
+	w.file = f
+	w.size = fi.Size()
+	return nil
+}
+
+// Path 获取当前写入的文件路径
+func (w *RotateWriter) Path() string {
+	return w.config.File
+}
+
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if w.config.MaxSize > 0 && w.size >= int64(w.config.MaxSize)*1024*1024 {
+		w.rotate()
+	}
+	return n, err
+}
+
+// Close 关闭文件并停止清理旧文件，关闭后写入返回 os.ErrClosed
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	close(w.done)
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate 将当前文件重命名为备份文件并重新创建
+// 注意: 调用方需持有 w.mu
+func (w *RotateWriter) rotate() {
+	w.file.Close()
+	w.file = nil
+
+	backupPath := fmt.Sprintf("%s.%s", w.config.File, time.Now().Format("20060102-150405"))
+	os.Rename(w.config.File, backupPath)
+
+	if w.config.Compress {
+		go compressFile(backupPath)
+	}
+
+	if err := w.open(); err != nil {
+		fmt.Fprintf(os.Stderr, "重新打开日志文件失败: %v\n", err)
+		return
+	}
+
+	w.cleanOldBackups()
+}
+
+// cleanOldBackups 按 MaxBackups 和 MaxAge 删除旧文件
+func (w *RotateWriter) cleanOldBackups() {
+	if w.config.MaxBackups <= 0 && w.config.MaxAge <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(w.config.File)
+	base := filepath.Base(w.config.File)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, base+".") && name != base {
+			info, err := entry.Info()
+			if err == nil {
+				backups = append(backups, info)
+			}
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+
+	now := time.Now()
+	for i, info := range backups {
+		shouldDelete := false
+
+		if w.config.MaxBackups > 0 && i >= w.config.MaxBackups {
+			shouldDelete = true
+		}
+
+		if w.config.MaxAge > 0 {
+			if now.Sub(info.ModTime()) > time.Duration(w.config.MaxAge)*24*time.Hour {
+				shouldDelete = true
+			}
+		}
+
+		if shouldDelete {
+			path := filepath.Join(dir, info.Name())
+			os.Remove(path)
+			if strings.HasSuffix(info.Name(), ".gz") {
+				os.Remove(strings.TrimSuffix(path, ".gz"))
+			}
+		}
+	}
+}
+
+func (w *RotateWriter) cleanupLoop() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			w.cleanOldBackups()
+			w.mu.Unlock()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func compressFile(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	defer gz.Close()
+
+	io.Copy(gz, src)
+	os.Remove(path)
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	w, err := NewRotateWriter(LogConfig{File: path, MaxSize: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("NewRotateWriter() error = %v", err)
+	}
+	defer w.Close()
+
+	// 旧的备份文件，轮转后超过 MaxBackups 应被删除
+	stale := path + ".20000101-000000"
+	if err := os.WriteFile(stale, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(stale, old, old)
+
+	line := append(bytes.Repeat([]byte("x"), 1023), '\n')
+	for i := 0; i < 1024; i++ {
+		if _, err := w.Write(line); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取日志文件失败: %v", err)
+	}
+	if string(data) != "after\n" {
+		t.Errorf("轮转后应写入新文件, 文件大小 %d", len(data))
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var backups []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "access.log.") {
+			backups = append(backups, e.Name())
+		}
+	}
+	if len(backups) != 1 || filepath.Join(dir, backups[0]) == stale {
+		t.Errorf("应只保留最新的备份文件, 实际为 %v", backups)
+	}
+
+	w.Close()
+	if _, err := w.Write([]byte("closed\n")); err == nil {
+		t.Error("关闭后写入应返回错误")
+	}
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// lineTimeLayout 日志文件每行开头的时间格式（log.LstdFlags）
+const lineTimeLayout = "2006/01/02 15:04:05"
+
+// linePattern 解析日志行: "<时间> [<文件:行号>: ][<级别>] [[<实例名称>] ]<内容>"
+var linePattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) (?:\S+:\d+: )?\[(DEBUG|INFO|WARN|ERROR|FATAL)\] (?:\[([^\]\s]+)\] )?`)
+
+// SearchQuery 日志文件检索条件
+type SearchQuery struct {
+	// Since 起始时间（含），零值表示不限制
+	Since time.Time
+	// Until 结束时间（不含），零值表示不限制
+	Until time.Time
+	// Level 最低日志级别
+	Level Level
+	// Instance 实例名称，只匹配带 "[实例名称]" 前缀的记录，为空表示不限制
+	Instance string
+	// Pattern 内容匹配的正则表达式，nil 表示不限制，子串匹配可使用 regexp.QuoteMeta
+	Pattern *regexp.Regexp
+	// Offset 跳过的匹配条数
+	Offset int
+	// Limit 最多返回的条数，<=0 表示不限制
+	Limit int
+}
+
+// SearchMatch 日志检索的匹配行
+type SearchMatch struct {
+	// File 所在文件名
+	File string `json:"file"`
+	// Line 在文件中的行号，从1开始
+	Line int `json:"line"`
+	// Time 记录时间
+	Time time.Time `json:"timestamp"`
+	// Level 日志级别，可选值: "debug", "info", "warn", "error", "fatal"
+	Level string `json:"level"`
+	// Instance 实例名称，非实例日志为空
+	Instance string `json:"instance,omitempty"`
+	// Text 原始日志行
+	Text string `json:"text"`
+}
+
+// SearchResult 日志检索结果
+type SearchResult struct {
+	// Matches 当前页的匹配行，按时间顺序排列
+	Matches []SearchMatch `json:"matches"`
+	// Total 匹配的总条数
+	Total int `json:"total"`
+}
+
+// lineRecord 解析后的日志行属性，无法解析的行（如多行日志的后续行）沿用上一行的属性
+type lineRecord struct {
+	time     time.Time
+	level    Level
+	instance string
+}
+
+// Search 按时间顺序检索日志文件，支持文本与JSON格式以及 gzip 压缩的文件
+// 参数:
+//   - files: 日志文件路径，应按时间从早到晚排列，以 ".gz" 结尾的文件按 gzip 解压读取
+//   - q: 检索条件
+//
+// 返回:
+//   - *SearchResult: 检索结果
+//   - error: 读取文件失败时返回错误
+//
+// 注意:
+//   - 最后修改时间早于 Since 的文件直接跳过
+//   - 遇到不早于 Until 的记录后停止检索
+func Search(files []string, q SearchQuery) (*SearchResult, error) {
+	result := &SearchResult{Matches: make([]SearchMatch, 0)}
+	for _, path := range files {
+		if !q.Since.IsZero() {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(q.Since) {
+				continue
+			}
+		}
+		done, err := searchFile(path, &q, result)
+		if err != nil {
+			return nil, fmt.Errorf("检索日志文件 %s 失败: %w", filepath.Base(path), err)
+		}
+		if done {
+			break
+		}
+	}
+	return result, nil
+}
+
+// searchFile 检索单个文件，将匹配行追加到 result
+// 返回:
+//   - bool: 已遇到不早于 Until 的记录，无需继续检索后续文件
+//   - error: 读取失败时返回错误
+func searchFile(path string, q *SearchQuery, result *SearchResult) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	var reader io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return false, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	name := filepath.Base(path)
+	var rec lineRecord
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		text := scanner.Text()
+		if r, ok := parseLine(text); ok {
+			rec = r
+		}
+		if rec.time.IsZero() {
+			continue
+		}
+		if !q.Until.IsZero() && !rec.time.Before(q.Until) {
+			return true, scanner.Err()
+		}
+		if !q.match(&rec, text) {
+			continue
+		}
+
+		result.Total++
+		if result.Total <= q.Offset || (q.Limit > 0 && len(result.Matches) >= q.Limit) {
+			continue
+		}
+		result.Matches = append(result.Matches, SearchMatch{
+			File:     name,
+			Line:     lineNo,
+			Time:     rec.time,
+			Level:    strings.ToLower(rec.level.String()),
+			Instance: rec.instance,
+			Text:     text,
+		})
+	}
+	return false, scanner.Err()
+}
+
+// parseLine 解析文本格式或JSON格式（FormatJSON）的日志行
+// 返回:
+//   - lineRecord: 日志行的时间、级别与实例
+//   - bool: 是否为一条记录的首行
+func parseLine(text string) (lineRecord, bool) {
+	if strings.HasPrefix(text, "{") {
+		var v struct {
+			Time     time.Time `json:"time"`
+			Level    string    `json:"level"`
+			Instance string    `json:"instance"`
+		}
+		if err := json.Unmarshal([]byte(text), &v); err != nil || v.Time.IsZero() {
+			return lineRecord{}, false
+		}
+		return lineRecord{time: v.Time, level: ParseLevel(v.Level), instance: v.Instance}, true
+	}
+	m := linePattern.FindStringSubmatch(text)
+	if m == nil {
+		return lineRecord{}, false
+	}
+	t, err := time.ParseInLocation(lineTimeLayout, m[1], time.Local)
+	if err != nil {
+		return lineRecord{}, false
+	}
+	return lineRecord{time: t, level: ParseLevel(m[2]), instance: m[3]}, true
+}
+
+// match 判断日志行是否满足起始时间、级别、实例与内容条件
+func (q *SearchQuery) match(rec *lineRecord, text string) bool {
+	if !q.Since.IsZero() && rec.time.Before(q.Since) {
+		return false
+	}
+	if rec.level < q.Level {
+		return false
+	}
+	if q.Instance != "" && rec.instance != q.Instance {
+		return false
+	}
+	if q.Pattern != nil && !q.Pattern.MatchString(text) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+
+	// 已轮转并压缩的旧文件
+	archive := filepath.Join(dir, "tlcpchan.log.20240101-100000.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("2024/01/01 09:00:00 main.go:10: [INFO] 服务启动\n" +
+		"2024/01/01 09:30:00 server.go:172: [WARN] [gm-server] 握手失败 10.0.0.8:51234: bad certificate\n" +
+		"2024/01/01 09:40:00 server.go:191: [ERROR] [gm-server] 连接目标服务失败: connection refused\n" +
+		"    后续行\n"))
+	gz.Close()
+	f.Close()
+
+	current := filepath.Join(dir, "tlcpchan.log")
+	os.WriteFile(current, []byte("2024/01/01 10:00:01 client.go:88: [ERROR] [gm-client] 连接目标服务失败: timeout\n"+
+		"2024/01/01 10:30:00 main.go:20: [INFO] 配置已重载\n"), 0644)
+	files := []string{archive, current}
+
+	at := func(s string) time.Time {
+		v, _ := time.ParseInLocation("2006/01/02 15:04:05", s, time.Local)
+		return v
+	}
+	// 以 "<文件>:<行号>:<级别>:<实例>" 描述匹配行，压缩文件记为 gz
+	texts := func(r *SearchResult) string {
+		var s []string
+		for _, m := range r.Matches {
+			file := "log"
+			if strings.HasSuffix(m.File, ".gz") {
+				file = "gz"
+			}
+			s = append(s, fmt.Sprintf("%s:%d:%s:%s", file, m.Line, m.Level, m.Instance))
+		}
+		return strings.Join(s, ",")
+	}
+
+	tests := []struct {
+		name      string
+		q         SearchQuery
+		wantTotal int
+		want      string
+	}{
+		{"全部", SearchQuery{Limit: 2}, 6, "gz:1:info:,gz:2:warn:gm-server"},
+		{"级别与实例", SearchQuery{Level: LevelError, Instance: "gm-server"}, 2, "gz:3:error:gm-server,gz:4:error:gm-server"},
+		{"跨文件子串匹配", SearchQuery{Pattern: regexp.MustCompile(regexp.QuoteMeta("连接目标服务失败"))}, 2, "gz:3:error:gm-server,log:1:error:gm-client"},
+		{"正则与分页", SearchQuery{Pattern: regexp.MustCompile(`refused|timeout`), Offset: 1, Limit: 5}, 2, "log:1:error:gm-client"},
+		{"时间范围", SearchQuery{Since: at("2024/01/01 09:30:00"), Until: at("2024/01/01 10:00:01")}, 3, "gz:2:warn:gm-server,gz:3:error:gm-server,gz:4:error:gm-server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Search(files, tt.q)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if r.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", r.Total, tt.wantTotal)
+			}
+			if got := texts(r); got != tt.want {
+				t.Errorf("Matches =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	r, _ := Search(files, SearchQuery{Instance: "gm-client"})
+	if len(r.Matches) != 1 || r.Matches[0].Line != 1 || !r.Matches[0].Time.Equal(at("2024/01/01 10:00:01")) {
+		t.Errorf("匹配行的行号或时间错误: %+v", r.Matches)
+	}
+}
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 日志输出目标
+const (
+	// OutputStdout 标准输出，容器中运行时可只使用该输出
+	OutputStdout = "stdout"
+	// OutputFile 按大小轮转的日志文件
+	OutputFile = "file"
+	// OutputSyslog RFC 5424 syslog
+	OutputSyslog = "syslog"
+)
+
+// 日志输出格式
+const (
+	// FormatText 文本格式: "<时间> <文件:行号>: [<级别>] [<实例名称>] <内容> <字段>=<值>..."
+	FormatText = "text"
+	// FormatJSON 每行一个JSON对象，字段单独输出
+	FormatJSON = "json"
+)
+
+// Record 一条日志记录
+type Record struct {
+	// Time 记录时间
+	Time time.Time
+	// Level 日志级别
+	Level Level
+	// Message 日志内容，不含结构化字段
+	Message string
+	// Source 调用位置，格式: "文件名:行号"，未知时为空
+	Source string
+	// Instance 实例名称，非实例日志为空
+	Instance string
+	// Attrs 结构化字段，分组已展开为 "组.字段"
+	Attrs []slog.Attr
+}
+
+// text 以 "<内容> <字段>=<值>..." 格式返回内容与结构化字段
+func (r *Record) text() string {
+	if len(r.Attrs) == 0 {
+		return r.Message
+	}
+	var buf strings.Builder
+	buf.WriteString(r.Message)
+	for _, a := range r.Attrs {
+		buf.WriteByte(' ')
+		buf.WriteString(a.Key)
+		buf.WriteByte('=')
+		v := a.Value.Resolve().String()
+		if v == "" || strings.ContainsAny(v, " =\"") || strconv.Quote(v) != `"`+v+`"` {
+			v = strconv.Quote(v)
+		}
+		buf.WriteString(v)
+	}
+	return buf.String()
+}
+
+// Sink 日志输出目标
+type Sink interface {
+	// Write 输出一条记录，实现需并发安全
+	Write(r *Record) error
+	// Close 释放输出目标占用的资源
+	Close() error
+}
+
+// writerSink 以文本或JSON格式写入 io.Writer 的输出目标
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format string
+	buf    bytes.Buffer
+	json   slog.Handler
+}
+
+// NewWriterSink 创建写入 io.Writer 的输出目标
+// 参数:
+//   - w: 写入目标，Close 时不关闭
+//   - format: FormatText 或 FormatJSON，为空时使用 FormatText
+func NewWriterSink(w io.Writer, format string) Sink {
+	s := &writerSink{w: w, format: format}
+	if format == FormatJSON {
+		s.json = slog.NewJSONHandler(&s.buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.LevelKey {
+					return slog.String(slog.LevelKey, strings.ToLower(fromSlogLevel(a.Value.Any().(slog.Level)).String()))
+				}
+				return a
+			},
+		})
+	}
+	return s
+}
+
+func (s *writerSink) Write(r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Reset()
+	if s.json != nil {
+		sr := slog.NewRecord(r.Time, toSlogLevel(r.Level), r.Message, 0)
+		if r.Source != "" {
+			sr.AddAttrs(slog.String("source", r.Source))
+		}
+		if r.Instance != "" {
+			sr.AddAttrs(slog.String("instance", r.Instance))
+		}
+		sr.AddAttrs(r.Attrs...)
+		if err := s.json.Handle(context.Background(), sr); err != nil {
+			return err
+		}
+	} else {
+		s.buf.WriteString(r.Time.Format(lineTimeLayout))
+		s.buf.WriteByte(' ')
+		if r.Source != "" {
+			s.buf.WriteString(r.Source)
+			s.buf.WriteString(": ")
+		}
+		fmt.Fprintf(&s.buf, "[%s] ", r.Level.String())
+		if r.Instance != "" {
+			fmt.Fprintf(&s.buf, "[%s] ", r.Instance)
+		}
+		s.buf.WriteString(r.text())
+		s.buf.WriteByte('\n')
+	}
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
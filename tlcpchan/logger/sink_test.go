@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withSinks 临时替换默认日志记录器的输出目标
+func withSinks(t *testing.T, sinks ...Sink) {
+	t.Helper()
+	old := Default().sinks
+	Default().sinks = sinks
+	t.Cleanup(func() { Default().sinks = old })
+}
+
+func TestWriterSink(t *testing.T) {
+	var text, js bytes.Buffer
+	withSinks(t, NewWriterSink(&text, FormatText), NewWriterSink(&js, FormatJSON))
+
+	Instance("gm-server").Slog().With("remote", "10.0.0.8:51234").Warn("握手失败", "error", errors.New("bad certificate"))
+	defer RemoveInstance("gm-server")
+
+	line := text.String()
+	if !strings.Contains(line, `sink_test.go:`) ||
+		!strings.HasSuffix(line, `[WARN] [gm-server] 握手失败 remote=10.0.0.8:51234 error="bad certificate"`+"\n") {
+		t.Errorf("文本格式错误: %q", line)
+	}
+
+	var v map[string]string
+	if err := json.Unmarshal(js.Bytes(), &v); err != nil {
+		t.Fatalf("JSON格式错误: %v, 内容: %s", err, js.String())
+	}
+	if v["level"] != "warn" || v["msg"] != "握手失败" || v["instance"] != "gm-server" ||
+		v["remote"] != "10.0.0.8:51234" || v["error"] != "bad certificate" {
+		t.Errorf("JSON字段错误: %v", v)
+	}
+
+	// 实例的内存缓冲与检索同样可以解析两种格式
+	if e := InstanceEntries("gm-server", Query{}); len(e) != 1 || !strings.Contains(e[0].Message, "remote=10.0.0.8:51234") {
+		t.Errorf("实例日志应包含字段: %+v", e)
+	}
+	for _, l := range []string{text.String(), js.String()} {
+		if rec, ok := parseLine(strings.TrimSpace(l)); !ok || rec.level != LevelWarn || rec.instance != "gm-server" {
+			t.Errorf("parseLine(%q) = %+v, %v", l, rec, ok)
+		}
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	r := &Record{
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:    LevelError,
+		Message:  "连接目标服务失败",
+		Instance: "gm-server",
+		Attrs:    []slog.Attr{slog.String("error", `dial "x"]`)},
+	}
+	want := `[tlcpchan@32473 instance="gm-server" error="dial \"x\"\]"] ` + "\xEF\xBB\xBF连接目标服务失败"
+
+	t.Run("udp", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pc.Close()
+
+		s, err := NewSyslogSink(SyslogConfig{Address: pc.LocalAddr().String(), Facility: "local3", Tag: "gm"})
+		if err != nil {
+			t.Fatalf("NewSyslogSink() error = %v", err)
+		}
+		defer s.Close()
+		if err := s.Write(r); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		buf := make([]byte, 2048)
+		pc.SetReadDeadline(time.Now().Add(3 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(buf[:n])
+		// local3(19)*8 + error(3) = 155
+		if !strings.HasPrefix(msg, "<155>1 2024-01-02T03:04:05.000000Z ") || !strings.Contains(msg, " gm ") || !strings.HasSuffix(msg, want) {
+			t.Errorf("syslog 消息格式错误: %q", msg)
+		}
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			// RFC 6587 分帧: "<长度> <消息>"
+			reader := bufio.NewReader(conn)
+			prefix, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, err := strconv.Atoi(strings.TrimSpace(prefix))
+			if err != nil {
+				return
+			}
+			msg := make([]byte, size)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}()
+
+		s, err := NewSyslogSink(SyslogConfig{Network: "tcp", Address: ln.Addr().String()})
+		if err != nil {
+			t.Fatalf("NewSyslogSink() error = %v", err)
+		}
+		defer s.Close()
+		if err := s.Write(r); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		select {
+		case msg := <-received:
+			if !strings.HasPrefix(msg, "<131>1 ") || !strings.HasSuffix(msg, want) {
+				t.Errorf("syslog 消息格式错误: %q", msg)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("未收到 syslog 消息")
+		}
+	})
+
+	t.Run("连接断开后退避重连", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		var accepted atomic.Int32
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				accepted.Add(1)
+				// 立即断开，模拟 syslog 服务重启
+				conn.Close()
+			}
+		}()
+
+		s, err := NewSyslogSink(SyslogConfig{Network: "tcp", Address: ln.Addr().String()})
+		if err != nil {
+			t.Fatalf("NewSyslogSink() error = %v", err)
+		}
+		start := time.Now()
+		for i := 0; i < 2*syslogQueueSize; i++ {
+			if err := s.Write(r); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if i%100 == 0 {
+				time.Sleep(20 * time.Millisecond)
+			}
+		}
+		if elapsed := time.Since(start); elapsed >= syslogRetryMin {
+			t.Errorf("写入耗时 %v，不应等待网络发送", elapsed)
+		}
+		// 退避期间不重新连接
+		if n := accepted.Load(); n != 1 {
+			t.Errorf("连接次数 = %d, 期望 1", n)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if err := s.Write(r); err == nil {
+			t.Error("关闭后写入应返回错误")
+		}
+	})
+
+	if _, err := NewSyslogSink(SyslogConfig{Address: "127.0.0.1:514", Facility: "local9"}); err == nil {
+		t.Error("无效的设施应返回错误")
+	}
+}
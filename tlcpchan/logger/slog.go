@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// toSlogLevel 转换为 slog 级别，Fatal 对应 slog.LevelError+4
+func toSlogLevel(l Level) slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	case LevelFatal:
+		return slog.LevelError + 4
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// fromSlogLevel 转换 slog 级别，介于两个级别之间时取较低的级别
+func fromSlogLevel(l slog.Level) Level {
+	switch {
+	case l < slog.LevelInfo:
+		return LevelDebug
+	case l < slog.LevelWarn:
+		return LevelInfo
+	case l < slog.LevelError:
+		return LevelWarn
+	case l < slog.LevelError+4:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+// handler 将 slog 记录转发到 Logger 的 slog.Handler
+type handler struct {
+	l     *Logger
+	attrs []slog.Attr
+	// group 当前分组前缀，如 "tls."
+	group string
+}
+
+// Handler 获取转发到该日志记录器的 slog.Handler
+// 返回:
+//   - slog.Handler: 记录的级别、启用状态与输出目标以该日志记录器为准，实例日志记录器同样写入实例的内存缓冲
+//
+// 注意: 分组以 "组.字段" 形式展开为普通字段
+func (l *Logger) Handler() slog.Handler {
+	return &handler{l: l}
+}
+
+// Slog 获取转发到该日志记录器的 *slog.Logger，用于以字段形式记录远端地址等信息
+//
+// 示例:
+//
+//	logger.Instance("gm-server").Slog().Warn("握手失败", "remote", addr, "error", err)
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(l.Handler())
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.target().shouldLog(fromSlogLevel(level))
+}
+
+func (h *handler) Handle(_ context.Context, sr slog.Record) error {
+	r := &Record{
+		Time:    sr.Time,
+		Level:   fromSlogLevel(sr.Level),
+		Message: sr.Message,
+		Attrs:   make([]slog.Attr, 0, len(h.attrs)+sr.NumAttrs()),
+	}
+	if sr.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{sr.PC}).Next()
+		if frame.File != "" {
+			r.Source = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+	}
+	r.Attrs = append(r.Attrs, h.attrs...)
+	sr.Attrs(func(a slog.Attr) bool {
+		r.Attrs = appendAttr(r.Attrs, h.group, a)
+		return true
+	})
+	h.l.emit(r)
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	n := &handler{l: h.l, group: h.group, attrs: make([]slog.Attr, 0, len(h.attrs)+len(attrs))}
+	n.attrs = append(n.attrs, h.attrs...)
+	for _, a := range attrs {
+		n.attrs = appendAttr(n.attrs, h.group, a)
+	}
+	return n
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{l: h.l, attrs: h.attrs, group: h.group + name + "."}
+}
+
+// appendAttr 追加字段，展开分组并忽略空字段
+func appendAttr(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+	a.Key = prefix + a.Key
+	return append(attrs, a)
+}
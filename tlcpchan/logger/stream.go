@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"sync"
+)
+
+// subscribers 日志订阅方，每条通过级别过滤的日志记录发送给所有订阅方
+var subscribers = struct {
+	sync.RWMutex
+	chans map[chan Entry]struct{}
+}{chans: make(map[chan Entry]struct{})}
+
+// Subscribe 订阅之后写入的日志记录，包括默认日志记录器与所有实例日志记录器的输出
+// 参数:
+//   - buffer: 通道缓冲的记录条数
+//
+// 返回:
+//   - <-chan Entry: 日志记录通道，实例日志的 Instance 字段为实例名称
+//   - func(): 取消订阅，取消后通道关闭，可重复调用
+//
+// 注意:
+//   - 只推送通过全局级别过滤的记录
+//   - 订阅方处理过慢导致缓冲已满时丢弃记录，不阻塞日志输出
+func Subscribe(buffer int) (<-chan Entry, func()) {
+	ch := make(chan Entry, buffer)
+	subscribers.Lock()
+	subscribers.chans[ch] = struct{}{}
+	subscribers.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			subscribers.Lock()
+			delete(subscribers.chans, ch)
+			subscribers.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish 将记录发送给所有订阅方，缓冲已满的订阅方丢弃该记录
+func publish(e Entry) {
+	subscribers.RLock()
+	defer subscribers.RUnlock()
+	for ch := range subscribers.chans {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
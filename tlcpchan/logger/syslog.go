@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SyslogConfig syslog 输出配置
+type SyslogConfig struct {
+	// Network 传输方式，可选值: "udp"（默认）, "tcp", "unix"
+	Network string
+	// Address syslog 服务地址，udp/tcp 为 "host:port"，unix 为套接字路径如 "/dev/log"
+	Address string
+	// Facility 设施，可选值: "kern", "user", "daemon", "auth", "local0"~"local7" 等，默认 "local0"
+	Facility string
+	// Tag 应用名称（APP-NAME），默认 "tlcpchan"
+	Tag string
+}
+
+// syslogFacilities RFC 5424 设施编号
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSDID 结构化数据元素标识，使用 RFC 5612 中用于文档示例的企业编号
+const syslogSDID = "tlcpchan@32473"
+
+// syslogWriteTimeout 单条消息的写入超时，避免 syslog 服务无响应时长时间阻塞发送
+const syslogWriteTimeout = time.Second
+
+const (
+	// syslogQueueSize 待发送消息队列长度，队列已满时丢弃新消息
+	syslogQueueSize = 1024
+	// syslogRetryMin 连接失败后首次重连的等待时间，之后每次失败翻倍
+	syslogRetryMin = time.Second
+	// syslogRetryMax 重连等待时间上限
+	syslogRetryMax = 30 * time.Second
+)
+
+// syslogSink 按 RFC 5424 格式发送到 syslog 服务的输出目标
+// tcp 与 unix 流式套接字按 RFC 6587 以 "<长度> <消息>" 分帧
+// 消息由后台协程异步发送，Write 不进行网络读写；连接断开后按退避间隔重连，等待期间的消息被丢弃
+type syslogSink struct {
+	// mu 保护 closed 与 queue 的关闭
+	mu       sync.RWMutex
+	cfg      SyslogConfig
+	facility int
+	hostname string
+	closed   bool
+	queue    chan []byte
+	// done 后台协程退出时关闭
+	done chan struct{}
+	// dropped 队列已满或等待重连期间丢弃的消息数
+	dropped atomic.Int64
+
+	// 以下字段仅由后台协程访问
+	conn net.Conn
+	// stream 连接是否为流式连接，需要分帧
+	stream  bool
+	retry   time.Duration
+	retryAt time.Time
+}
+
+// NewSyslogSink 创建 syslog 输出目标并连接服务
+// 参数:
+//   - cfg: syslog 配置
+//
+// 返回:
+//   - Sink: 输出目标
+//   - error: 配置无效或连接失败时返回错误
+func NewSyslogSink(cfg SyslogConfig) (Sink, error) {
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	if cfg.Facility == "" {
+		cfg.Facility = "local0"
+	}
+	if cfg.Tag == "" {
+		cfg.Tag = "tlcpchan"
+	}
+	facility, ok := syslogFacilities[cfg.Facility]
+	if !ok {
+		return nil, fmt.Errorf("无效的 syslog 设施: %s", cfg.Facility)
+	}
+	switch cfg.Network {
+	case "udp", "tcp", "unix":
+	default:
+		return nil, fmt.Errorf("无效的 syslog 传输方式: %s", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog 地址不能为空")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	s := &syslogSink{
+		cfg:      cfg,
+		facility: facility,
+		hostname: hostname,
+		queue:    make(chan []byte, syslogQueueSize),
+		done:     make(chan struct{}),
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+// connect 连接 syslog 服务，unix 优先使用数据报套接字
+// 注意: 仅在创建时或由后台协程调用
+func (s *syslogSink) connect() error {
+	var err error
+	switch s.cfg.Network {
+	case "unix":
+		if s.conn, err = net.DialTimeout("unixgram", s.cfg.Address, syslogWriteTimeout); err == nil {
+			s.stream = false
+			return nil
+		}
+		s.conn, err = net.DialTimeout("unix", s.cfg.Address, syslogWriteTimeout)
+		s.stream = true
+	default:
+		s.conn, err = net.DialTimeout(s.cfg.Network, s.cfg.Address, syslogWriteTimeout)
+		s.stream = s.cfg.Network == "tcp"
+	}
+	if err != nil {
+		return fmt.Errorf("连接 syslog 服务失败: %w", err)
+	}
+	return nil
+}
+
+// syslogSeverity RFC 5424 严重性
+func syslogSeverity(l Level) int {
+	switch l {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// escapeSDParam 转义结构化数据参数值中的 '"'、'\' 与 ']'
+func escapeSDParam(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+// format 按 RFC 5424 格式化记录:
+// "<PRI>1 <时间> <主机名> <应用名> <进程号> - [tlcpchan@32473 instance=".." 字段=".."] <BOM><内容>"
+func (s *syslogSink) format(r *Record) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %d - ",
+		s.facility*8+syslogSeverity(r.Level),
+		r.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, s.cfg.Tag, os.Getpid())
+
+	if r.Instance == "" && r.Source == "" && len(r.Attrs) == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteString("[" + syslogSDID)
+		param := func(key, value string) {
+			// PARAM-NAME 不能包含空格、'='、']'、'"'
+			key = strings.Map(func(c rune) rune {
+				if c <= ' ' || c == '=' || c == ']' || c == '"' || c > '~' {
+					return '_'
+				}
+				return c
+			}, key)
+			fmt.Fprintf(&buf, ` %s="%s"`, key, escapeSDParam(value))
+		}
+		if r.Source != "" {
+			param("source", r.Source)
+		}
+		if r.Instance != "" {
+			param("instance", r.Instance)
+		}
+		for _, a := range r.Attrs {
+			param(a.Key, a.Value.Resolve().String())
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteString(" \xEF\xBB\xBF")
+	buf.WriteString(r.Message)
+	return buf.Bytes()
+}
+
+// Write 将记录加入发送队列，队列已满时丢弃该记录
+func (s *syslogSink) Write(r *Record) error {
+	msg := s.format(r)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+	select {
+	case s.queue <- msg:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Close 停止接收记录，等待队列中的消息发送完成后关闭连接
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	return nil
+}
+
+// run 后台协程，依次发送队列中的消息
+func (s *syslogSink) run() {
+	defer close(s.done)
+	for msg := range s.queue {
+		if err := s.send(msg); err != nil {
+			fmt.Fprintf(os.Stderr, "写入日志失败: %v\n", err)
+		}
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// send 发送一条消息，连接断开时按退避间隔重连，等待重连期间丢弃消息
+func (s *syslogSink) send(msg []byte) error {
+	if s.conn == nil {
+		if time.Now().Before(s.retryAt) {
+			s.dropped.Add(1)
+			return nil
+		}
+		if err := s.connect(); err != nil {
+			s.backoff()
+			s.dropped.Add(1)
+			return err
+		}
+		s.retry = 0
+	}
+	if n := s.dropped.Swap(0); n > 0 {
+		fmt.Fprintf(os.Stderr, "syslog 输出丢弃 %d 条日志\n", n)
+	}
+
+	if s.stream {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := s.conn.Write(msg); err != nil {
+		// 连接已断开时丢弃该条消息，退避后重新连接
+		s.conn.Close()
+		s.conn = nil
+		s.backoff()
+		return fmt.Errorf("发送 syslog 消息失败: %w", err)
+	}
+	return nil
+}
+
+// backoff 计算下一次重连时间
+func (s *syslogSink) backoff() {
+	s.retry = min(max(s.retry*2, syslogRetryMin), syslogRetryMax)
+	s.retryAt = time.Now().Add(s.retry)
+}
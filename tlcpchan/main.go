@@ -109,6 +109,16 @@ func main() {
 			MaxAge:     cfg.Server.Log.MaxAge,
 			Compress:   cfg.Server.Log.Compress,
 			Enabled:    cfg.Server.Log.Enabled,
+			Format:     cfg.Server.Log.Format,
+			Outputs:    cfg.Server.Log.Outputs,
+		}
+		if sc := cfg.Server.Log.Syslog; sc != nil {
+			logCfg.Syslog = &logger.SyslogConfig{
+				Network:  sc.Network,
+				Address:  sc.Address,
+				Facility: sc.Facility,
+				Tag:      sc.Tag,
+			}
 		}
 		if err := logger.InitDefault(logCfg); err != nil {
 			logger.Warn("初始化日志失败: %v", err)
@@ -121,9 +131,11 @@ func main() {
 	ksEntries := make([]keystore.ConfigEntry, 0, len(cfg.KeyStores))
 	for _, ksCfg := range cfg.KeyStores {
 		ksEntries = append(ksEntries, keystore.ConfigEntry{
-			Name:   ksCfg.Name,
-			Type:   ksCfg.Type,
-			Params: ksCfg.Params,
+			Name:    ksCfg.Name,
+			Type:    ksCfg.Type,
+			Params:  ksCfg.Params,
+			Issuer:  ksCfg.Issuer,
+			Renewal: ksCfg.Renewal,
 		})
 	}
 	if err := keyStoreMgr.LoadFromConfigs(ksEntries); err != nil {
@@ -136,8 +148,37 @@ func main() {
 	if err := rootCertMgr.Initialize(); err != nil {
 		logger.Warn("初始化根证书管理器失败: %v", err)
 	}
+	rootCertMgr.StartCRLReload(security.DefaultCRLReloadInterval)
+	defer rootCertMgr.Close()
 
 	instMgr := instance.NewManager(logger.Default(), keyStoreMgr, rootCertMgr)
+	// keystore 的证书文件变化或通过API更新后，引用它的实例自动重新加载证书
+	keyStoreMgr.Subscribe(func(name string) {
+		instMgr.ReloadKeyStore(name)
+	})
+	keyStoreMgr.StartWatch(security.DefaultKeyStoreWatchInterval)
+	defer keyStoreMgr.Close()
+	// 添加、删除或重新加载根证书后，引用根证书的实例自动替换证书池
+	rootCertMgr.Subscribe(func() []string {
+		return instMgr.ReloadRootCerts()
+	})
+
+	// 由内置根 CA 签发且设置了续期策略的 keystore 在过期前自动续期，续期后引用它的实例自动重新加载证书
+	renewer := security.NewRenewer(keyStoreMgr, security.RenewalOptions{})
+	renewer.Start()
+	defer renewer.Close()
+
+	expiryOpts := security.ExpiryOptions{}
+	if cfg.CertExpiry != nil {
+		expiryOpts = security.ExpiryOptions{
+			Interval:     cfg.CertExpiry.Interval,
+			WarningDays:  cfg.CertExpiry.WarningDays,
+			CriticalDays: cfg.CertExpiry.CriticalDays,
+		}
+	}
+	expiryMonitor := security.NewExpiryMonitor(keyStoreMgr, rootCertMgr, expiryOpts)
+	expiryMonitor.Start()
+	defer expiryMonitor.Close()
 
 	for i := range cfg.Instances {
 		inst := &cfg.Instances[i]
@@ -157,6 +198,7 @@ func main() {
 		KeyStoreManager: keyStoreMgr,
 		RootCertManager: rootCertMgr,
 		InstanceManager: instMgr,
+		ExpiryMonitor:   expiryMonitor,
 		StaticDir:       filepath.Join(wd, "ui"),
 	}
 	apiServer := controller.NewServer(opts)
@@ -174,7 +216,12 @@ func main() {
 	sig := <-quit
 	logger.Info("收到信号 %v，开始关闭...", sig)
 
-	instMgr.StopAll()
+	// SIGTERM 通常来自滚动部署，排空活跃连接后再退出；SIGINT 立即停止
+	if sig == syscall.SIGTERM {
+		instMgr.DrainAll()
+	} else {
+		instMgr.StopAll()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -0,0 +1,290 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+)
+
+// 连接关闭原因
+const (
+	// CloseNormal 任一方正常关闭连接
+	CloseNormal = "normal"
+	// CloseError 数据传输出错
+	CloseError = "error"
+	// CloseIdleTimeout 连接空闲超过读取超时
+	CloseIdleTimeout = "idle_timeout"
+	// CloseMaxSession 连接存活超过最长会话时长
+	CloseMaxSession = "max_session"
+	// CloseKilled 通过管理接口强制关闭
+	CloseKilled = "killed"
+	// CloseDrained 排空宽限期结束后被强制关闭
+	CloseDrained = "drained"
+	// CloseProxyHeaderFailed PROXY协议头无效
+	CloseProxyHeaderFailed = "proxy_header_failed"
+	// CloseHandshakeFailed TLCP/TLS握手失败
+	CloseHandshakeFailed = "handshake_failed"
+	// CloseDialFailed 连接目标服务失败
+	CloseDialFailed = "dial_failed"
+)
+
+// AccessRecord 访问日志记录，对应一个已结束的客户端连接
+type AccessRecord struct {
+	// Time 连接结束时间
+	Time time.Time `json:"time"`
+	// Instance 实例名称
+	Instance string `json:"instance"`
+	// ConnID 连接编号，与活跃连接列表中的编号一致
+	ConnID string `json:"connId"`
+	// Client 客户端地址，启用 PROXY 协议接收时为上游传递的真实地址
+	Client string `json:"client,omitempty"`
+	// Target 转发的目标地址，HTTP代理实例的目标由连接池管理，不记录
+	Target string `json:"target,omitempty"`
+	// Protocol 协议，"tlcp" 或 "tls"
+	Protocol string `json:"protocol,omitempty"`
+	// Version 协议版本，如 "TLCPv1.1"、"TLSv1.3"
+	Version string `json:"version,omitempty"`
+	// CipherSuite 密码套件名称
+	CipherSuite string `json:"cipherSuite,omitempty"`
+	// ServerName SNI名称
+	ServerName string `json:"serverName,omitempty"`
+	// PeerSubject 对端证书主题，TLCP为签名证书，服务端实例为客户端证书，客户端实例为目标服务证书
+	PeerSubject string `json:"peerSubject,omitempty"`
+	// PeerSerial 对端证书序列号（16进制）
+	PeerSerial string `json:"peerSerial,omitempty"`
+	// BytesIn 从客户端读取的字节数
+	BytesIn int64 `json:"bytesIn"`
+	// BytesOut 写回客户端的字节数
+	BytesOut int64 `json:"bytesOut"`
+	// DurationMs 连接持续时间（毫秒）
+	DurationMs int64 `json:"durationMs"`
+	// CloseReason 关闭原因，见 Close* 常量
+	CloseReason string `json:"closeReason"`
+	// HandshakeError 握手失败的错误信息
+	HandshakeError string `json:"handshakeError,omitempty"`
+	// Error 其他导致连接关闭的错误信息
+	Error string `json:"error,omitempty"`
+	// AlertCode 握手或传输中收到或发送的告警码
+	AlertCode int `json:"alertCode,omitempty"`
+	// AlertSource 告警来源，"local" 表示本端发送，"remote" 表示对端发送
+	AlertSource string `json:"alertSource,omitempty"`
+}
+
+// setError 根据关闭原因记录错误信息和告警码
+func (r *AccessRecord) setError(err error) {
+	if err == nil {
+		return
+	}
+	if r.CloseReason == CloseHandshakeFailed {
+		r.HandshakeError = err.Error()
+	} else {
+		r.Error = err.Error()
+	}
+	r.AlertCode, r.AlertSource = alertFromError(err)
+}
+
+// pipeCloseReason 根据数据管道结束时的状态判断连接关闭原因
+// 参数:
+//   - ctx: 数据管道使用的会话上下文
+//   - err: Pipe 返回的错误
+func pipeCloseReason(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return CloseMaxSession
+	case isTimeout(err):
+		return CloseIdleTimeout
+	case err != nil:
+		return CloseError
+	default:
+		return CloseNormal
+	}
+}
+
+// alertFromError 从握手或读写错误中提取 TLCP/TLS 告警
+// 返回:
+//   - int: 告警码，错误不是告警时返回 0
+//   - string: "local" 或 "remote"，错误不是告警时为空
+//
+// 注意: TLCP与TLS的告警均以 Op 为 "local error"/"remote error" 的 *net.OpError 返回，
+// 告警类型未导出，按其底层类型 uint8 读取告警码
+func alertFromError(err error) (int, string) {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || opErr.Err == nil {
+		return 0, ""
+	}
+	var source string
+	switch opErr.Op {
+	case "local error":
+		source = "local"
+	case "remote error":
+		source = "remote"
+	default:
+		return 0, ""
+	}
+	v := reflect.ValueOf(opErr.Err)
+	if v.Kind() != reflect.Uint8 {
+		return 0, ""
+	}
+	return int(v.Uint()), source
+}
+
+// marshal 按格式序列化记录，以换行结尾
+func (r *AccessRecord) marshal(format string) []byte {
+	if format == config.AccessLogLogfmt {
+		return r.logfmt()
+	}
+	data, _ := json.Marshal(r)
+	return append(data, '\n')
+}
+
+// logfmt 以 key=value 格式序列化记录，键名与JSON一致，省略空值
+func (r *AccessRecord) logfmt() []byte {
+	var buf bytes.Buffer
+	field := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		// 含空格、等号、引号或不可打印字符时加引号
+		if quoted := strconv.Quote(value); strings.ContainsAny(value, " =") || quoted != `"`+value+`"` {
+			buf.WriteString(quoted)
+		} else {
+			buf.WriteString(value)
+		}
+	}
+	field("time", r.Time.Format(time.RFC3339Nano))
+	field("instance", r.Instance)
+	field("connId", r.ConnID)
+	field("client", r.Client)
+	field("target", r.Target)
+	field("protocol", r.Protocol)
+	field("version", r.Version)
+	field("cipherSuite", r.CipherSuite)
+	field("serverName", r.ServerName)
+	field("peerSubject", r.PeerSubject)
+	field("peerSerial", r.PeerSerial)
+	field("bytesIn", strconv.FormatInt(r.BytesIn, 10))
+	field("bytesOut", strconv.FormatInt(r.BytesOut, 10))
+	field("durationMs", strconv.FormatInt(r.DurationMs, 10))
+	field("closeReason", r.CloseReason)
+	field("handshakeError", r.HandshakeError)
+	field("error", r.Error)
+	if r.AlertSource != "" {
+		field("alertCode", strconv.Itoa(r.AlertCode))
+		field("alertSource", r.AlertSource)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// AccessLogger 实例的连接访问日志，每个连接结束时写入一条记录
+// 未启用时 Log 不做任何操作，nil 值同样可用
+type AccessLogger struct {
+	mu       sync.Mutex
+	instance string
+	cfg      config.AccessLogConfig
+	writer   *logger.RotateWriter
+}
+
+// NewAccessLogger 创建未启用的访问日志，调用 Configure 后生效
+// 参数:
+//   - instance: 实例名称，写入每条记录
+func NewAccessLogger(instance string) *AccessLogger {
+	return &AccessLogger{instance: instance}
+}
+
+// Configure 按实例配置打开、重新打开或关闭访问日志文件
+// 参数:
+//   - cfg: 实例配置，未配置 access-log 或未启用时关闭访问日志
+//
+// 返回:
+//   - error: 打开文件失败时返回错误，此时保持原有配置
+//
+// 注意: 配置未变化时不重新打开文件
+func (l *AccessLogger) Configure(cfg *config.InstanceConfig) error {
+	var next config.AccessLogConfig
+	if cfg.AccessLog != nil && cfg.AccessLog.Enabled {
+		next = *cfg.AccessLog
+		next.File = cfg.AccessLogFile()
+		if next.Format == "" {
+			next.Format = config.AccessLogJSON
+		}
+		if next.MaxSize == 0 {
+			next.MaxSize = config.DefaultAccessLogMaxSize
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if next == l.cfg {
+		return nil
+	}
+
+	var writer *logger.RotateWriter
+	if next.Enabled {
+		w, err := logger.NewRotateWriter(logger.LogConfig{
+			File:       next.File,
+			MaxSize:    next.MaxSize,
+			MaxBackups: next.MaxBackups,
+			MaxAge:     next.MaxAge,
+			Compress:   next.Compress,
+		})
+		if err != nil {
+			return fmt.Errorf("打开访问日志失败: %w", err)
+		}
+		writer = w
+	}
+	if l.writer != nil {
+		l.writer.Close()
+	}
+	l.cfg = next
+	l.writer = writer
+	return nil
+}
+
+// Log 写入一条访问记录，未启用时忽略
+func (l *AccessLogger) Log(r *AccessRecord) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.writer == nil {
+		return
+	}
+	r.Instance = l.instance
+	if _, err := l.writer.Write(r.marshal(l.cfg.Format)); err != nil {
+		logger.Default().Error("写入访问日志失败 %s: %v", l.writer.Path(), err)
+	}
+}
+
+// Close 关闭访问日志文件，实例删除后调用
+func (l *AccessLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = config.AccessLogConfig{}
+	if l.writer == nil {
+		return nil
+	}
+	err := l.writer.Close()
+	l.writer = nil
+	return err
+}
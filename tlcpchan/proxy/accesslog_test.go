@@ -0,0 +1,140 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+)
+
+// readAccessLog 读取访问日志文件中的所有JSON记录
+func readAccessLog(t *testing.T, path string) []AccessRecord {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("打开访问日志失败: %v", err)
+	}
+	defer f.Close()
+
+	var records []AccessRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r AccessRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("解析访问日志记录失败: %v, 内容: %s", err, scanner.Text())
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestAccessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	access := NewAccessLogger("audit")
+	defer access.Close()
+	err := access.Configure(&config.InstanceConfig{
+		Name:      "audit",
+		AccessLog: &config.AccessLogConfig{Enabled: true, File: path},
+	})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	tracker := newConnTracker()
+	tracker.access.Store(access)
+
+	t.Run("握手失败", func(t *testing.T) {
+		srv := httptest.NewUnstartedServer(nil)
+		srv.StartTLS()
+		defer srv.Close()
+
+		// 客户端不信任自签名证书，发送 bad_certificate 告警
+		userConn, rawConn := tcpPair(t)
+		clientConn := tls.Server(rawConn, &tls.Config{Certificates: srv.TLS.Certificates})
+		go tls.Client(userConn, &tls.Config{ServerName: "example.com"}).Handshake()
+
+		tc, _ := tracker.track(clientConn, nil)
+		_, err := handshake(clientConn, 3*time.Second)
+		if err == nil {
+			t.Fatal("握手应失败")
+		}
+		tc.setRemote(clientConn.RemoteAddr())
+		tc.setProtocol(connProtocol(clientConn))
+		tc.finish(CloseHandshakeFailed, err)
+		tc.release()
+	})
+
+	t.Run("强制关闭", func(t *testing.T) {
+		done := trackPipe(t, tracker)
+		conns := tracker.list()
+		if len(conns) != 1 || !tracker.kill(conns[0].ID) {
+			t.Fatalf("关闭连接失败: %+v", conns)
+		}
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatal("关闭后数据管道应结束")
+		}
+	})
+
+	records := readAccessLog(t, path)
+	if len(records) != 2 {
+		t.Fatalf("访问日志记录数应为 2, 实际为 %d", len(records))
+	}
+
+	r := records[0]
+	if r.Instance != "audit" || r.Client == "" || r.Protocol != "tls" {
+		t.Errorf("握手失败记录的连接信息错误: %+v", r)
+	}
+	if r.CloseReason != CloseHandshakeFailed || r.HandshakeError == "" {
+		t.Errorf("握手失败记录的关闭原因错误: %+v", r)
+	}
+	// 42: bad_certificate
+	if r.AlertCode != 42 || r.AlertSource != "remote" {
+		t.Errorf("告警码 = %d(%s), want 42(remote)", r.AlertCode, r.AlertSource)
+	}
+
+	if r := records[1]; r.CloseReason != CloseKilled || r.HandshakeError != "" {
+		t.Errorf("强制关闭记录的关闭原因错误: %+v", r)
+	}
+
+	// 关闭访问日志后不再写入
+	access.Configure(&config.InstanceConfig{Name: "audit"})
+	done := trackPipe(t, tracker)
+	tracker.closeAll()
+	<-done
+	if n := len(readAccessLog(t, path)); n != 2 {
+		t.Errorf("关闭访问日志后不应写入记录, 记录数为 %d", n)
+	}
+}
+
+func TestAccessRecordLogfmt(t *testing.T) {
+	r := &AccessRecord{
+		Time:        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Instance:    "gm-server",
+		ConnID:      "7",
+		Client:      "10.0.0.1:5000",
+		Protocol:    "tlcp",
+		PeerSubject: "CN=张三,O=Example",
+		BytesIn:     10,
+		DurationMs:  1500,
+		CloseReason: CloseIdleTimeout,
+		Error:       `read tcp: i/o timeout`,
+	}
+	want := `time=2024-01-02T03:04:05Z instance=gm-server connId=7 client=10.0.0.1:5000 protocol=tlcp ` +
+		`peerSubject="CN=张三,O=Example" bytesIn=10 bytesOut=0 durationMs=1500 closeReason=idle_timeout ` +
+		`error="read tcp: i/o timeout"` + "\n"
+	if got := string(r.marshal(config.AccessLogLogfmt)); got != want {
+		t.Errorf("logfmt =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := string(r.marshal(config.AccessLogJSON)); !strings.HasPrefix(got, `{"time":"2024-01-02T03:04:05Z","instance":"gm-server"`) {
+		t.Errorf("json = %s", got)
+	}
+}
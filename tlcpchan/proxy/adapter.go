@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"sync"
@@ -14,7 +15,9 @@ import (
 	"github.com/Trisia/tlcpchan/logger"
 	"github.com/Trisia/tlcpchan/security"
 	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/Trisia/tlcpchan/security/ocsp"
 	"github.com/Trisia/tlcpchan/stats"
+	"github.com/emmansun/gmsm/smx509"
 )
 
 func detectProtocol(data []byte) ProtocolType {
@@ -89,8 +92,11 @@ type TLCPAdapter struct {
 	tlsKeyStore      security.KeyStore
 	keyStoreManager  *security.KeyStoreManager
 	rootCertManager  *security.RootCertManager
+	ocspCache        *ocsp.Cache
 	stats            *stats.Collector
 	logger           *logger.Logger
+	// reloadMu 串行化配置重载，keystore 变化触发的重载可能与手动重载同时进行
+	reloadMu sync.Mutex
 }
 
 func NewTLCPAdapter(
@@ -100,6 +106,7 @@ func NewTLCPAdapter(
 	return &TLCPAdapter{
 		keyStoreManager: keyStoreMgr,
 		rootCertManager: rootCertMgr,
+		ocspCache:       ocsp.NewCache(),
 		stats:           stats.DefaultCollector(),
 		logger:          logger.Default(),
 	}, nil
@@ -121,6 +128,48 @@ func (a *TLCPAdapter) loadKeyStoreFromConfig(ksConfig *config.KeyStoreConfig, su
 	return a.keyStoreManager.LoadAndRegister(ksConfig.Name, suggestedName, string(ksConfig.Type), ksConfig.Params)
 }
 
+// KeyStoreNames 获取实例配置引用的 keystore 名称
+// 参数:
+//   - cfg: 实例配置
+//
+// 返回:
+//   - []string: tlcp.keystore 与 tls.keystore 在 keystore 管理器中的名称，未命名的 keystore 以 "<实例名称>-tlcp"、"<实例名称>-tls" 注册
+func KeyStoreNames(cfg *config.InstanceConfig) []string {
+	var names []string
+	add := func(ksConfig *config.KeyStoreConfig, suggestedName string) {
+		if ksConfig == nil {
+			return
+		}
+		name := ksConfig.Name
+		if string(ksConfig.Type) == string(keystore.LoaderTypeNamed) {
+			if name == "" {
+				name = ksConfig.Params["name"]
+			}
+		} else if name == "" {
+			name = suggestedName
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	add(cfg.TLCP.Keystore, cfg.Name+"-tlcp")
+	add(cfg.TLS.Keystore, cfg.Name+"-tls")
+	return names
+}
+
+// RootCertNames 获取实例配置引用的根证书文件名
+// 参数:
+//   - cfg: 实例配置
+//
+// 返回:
+//   - []string: 服务端实例为 client-ca，客户端实例为 server-ca
+func RootCertNames(cfg *config.InstanceConfig) []string {
+	if cfg.Type == TypeServer || cfg.Type == TypeHTTPServer {
+		return cfg.ClientCA
+	}
+	return cfg.ServerCA
+}
+
 func (a *TLCPAdapter) TLCPListener(l net.Listener) net.Listener {
 	return tlcp.NewListener(l, a.outerTLCPConfig)
 }
@@ -154,43 +203,113 @@ func (a *TLCPAdapter) getTimeoutConfig(cfg *config.InstanceConfig) *config.Timeo
 	return config.DefaultTimeout()
 }
 
+// DialTiming 建立安全连接各阶段的耗时
+type DialTiming struct {
+	// Dial TCP连接建立耗时
+	Dial time.Duration
+	// Handshake TLCP/TLS握手耗时
+	Handshake time.Duration
+}
+
 func (a *TLCPAdapter) DialTLCP(network, addr string, cfg *config.InstanceConfig) (net.Conn, error) {
-	timeout := a.getTimeoutConfig(cfg).Dial
-	dialer := &net.Dialer{
-		Timeout: timeout,
-	}
-	tlcpConfig := a.atomicTLCPConfig.Load().(*tlcp.Config)
-	return tlcp.DialWithDialer(dialer, network, addr, tlcpConfig)
+	return a.dialTimed(network, addr, ProtocolTLCP, cfg, &DialTiming{})
 }
 
 func (a *TLCPAdapter) DialTLS(network, addr string, cfg *config.InstanceConfig) (net.Conn, error) {
-	timeout := a.getTimeoutConfig(cfg).Dial
-	dialer := &net.Dialer{
-		Timeout: timeout,
-	}
-	tlsConfig := a.atomicTLSConfig.Load().(*tls.Config)
-	return tls.DialWithDialer(dialer, network, addr, tlsConfig)
+	return a.dialTimed(network, addr, ProtocolTLS, cfg, &DialTiming{})
 }
 
 func (a *TLCPAdapter) DialWithProtocol(network, addr string, protocol ProtocolType, cfg *config.InstanceConfig) (net.Conn, error) {
+	conn, _, err := a.DialWithTiming(network, addr, protocol, cfg)
+	return conn, err
+}
+
+// DialWithTiming 使用指定协议连接目标服务，并返回各阶段耗时
+// 参数:
+//   - network: 网络类型
+//   - addr: 目标地址
+//   - protocol: 协议类型，auto 时先尝试TLCP，失败后使用TLS
+//   - cfg: 实例配置
+//
+// 返回:
+//   - net.Conn: 已完成握手的安全连接
+//   - DialTiming: TCP连接建立和握手耗时，auto 协议时为最后一次尝试的耗时
+//   - error: 连接或握手失败时返回错误
+func (a *TLCPAdapter) DialWithTiming(network, addr string, protocol ProtocolType, cfg *config.InstanceConfig) (net.Conn, DialTiming, error) {
+	var timing DialTiming
 	switch protocol {
-	case ProtocolTLCP:
-		return a.DialTLCP(network, addr, cfg)
-	case ProtocolTLS:
-		return a.DialTLS(network, addr, cfg)
-	default:
-		return a.autoDial(network, addr, cfg)
+	case ProtocolTLCP, ProtocolTLS:
+		conn, err := a.dialTimed(network, addr, protocol, cfg, &timing)
+		return conn, timing, err
 	}
-}
 
-func (a *TLCPAdapter) autoDial(network, addr string, cfg *config.InstanceConfig) (net.Conn, error) {
-	conn, err := a.DialTLCP(network, addr, cfg)
+	conn, err := a.dialTimed(network, addr, ProtocolTLCP, cfg, &timing)
 	if err == nil {
-		return conn, nil
+		return conn, timing, nil
 	}
 
 	a.logger.Debug("TLCP连接失败，尝试TLS: %v", err)
-	return a.DialTLS(network, addr, cfg)
+	conn, err = a.dialTimed(network, addr, ProtocolTLS, cfg, &timing)
+	return conn, timing, err
+}
+
+// dialTimed 分别完成TCP连接建立和握手，记录各阶段耗时
+// 注意: TCP连接建立受连接超时限制，握手受握手超时限制；
+// 未配置握手超时时与 tlcp/tls.DialWithDialer 一致，连接超时同时限制TCP连接建立和握手的总耗时
+func (a *TLCPAdapter) dialTimed(network, addr string, protocol ProtocolType,
+	cfg *config.InstanceConfig, timing *DialTiming) (net.Conn, error) {
+	timeouts := a.getTimeoutConfig(cfg)
+	timeout := timeouts.Dial
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+
+	start := time.Now()
+	rawConn, err := dialer.Dial(network, addr)
+	timing.Dial = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	if timeouts.Handshake > 0 {
+		rawConn.SetDeadline(time.Now().Add(timeouts.Handshake))
+	} else if timeout > 0 {
+		rawConn.SetDeadline(start.Add(timeout))
+	}
+
+	serverName := addr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		serverName = host
+	}
+
+	var conn interface {
+		net.Conn
+		Handshake() error
+	}
+	if protocol == ProtocolTLCP {
+		tlcpConfig := a.atomicTLCPConfig.Load().(*tlcp.Config)
+		if tlcpConfig.ServerName == "" {
+			tlcpConfig = tlcpConfig.Clone()
+			tlcpConfig.ServerName = serverName
+		}
+		conn = tlcp.Client(rawConn, tlcpConfig)
+	} else {
+		tlsConfig := a.atomicTLSConfig.Load().(*tls.Config)
+		if tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = serverName
+		}
+		conn = tls.Client(rawConn, tlsConfig)
+	}
+
+	handshakeStart := time.Now()
+	err = conn.Handshake()
+	timing.Handshake = time.Since(handshakeStart)
+	if err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+	rawConn.SetDeadline(time.Time{})
+	return conn, nil
 }
 
 func (a *TLCPAdapter) Protocol() ProtocolType {
@@ -200,6 +319,9 @@ func (a *TLCPAdapter) Protocol() ProtocolType {
 }
 
 func (a *TLCPAdapter) ReloadConfig(cfg *config.InstanceConfig) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
 	a.mu.Lock()
 	a.protocol = ParseProtocolType(cfg.Protocol)
 	a.mu.Unlock()
@@ -210,6 +332,203 @@ func (a *TLCPAdapter) ReloadConfig(cfg *config.InstanceConfig) error {
 	return a.reloadClientConfig(cfg)
 }
 
+// ReloadRootCerts 以根证书管理器中的最新根证书替换实例的 client-ca/server-ca 证书池
+// 参数:
+//   - cfg: 实例配置
+//
+// 返回:
+//   - error: 未配置根证书管理器时返回错误
+//
+// 注意:
+//   - 只替换证书池，复制当前的TLCP/TLS配置后原子替换，新握手使用新证书池，已建立的连接不受影响
+//   - 已从根证书管理器删除的根证书不再信任，证书池中不再有可用的根证书时对端证书验证全部失败
+//   - 配置未引用根证书时不做处理
+func (a *TLCPAdapter) ReloadRootCerts(cfg *config.InstanceConfig) error {
+	filenames := RootCertNames(cfg)
+	if len(filenames) == 0 {
+		return nil
+	}
+	if a.rootCertManager == nil {
+		return fmt.Errorf("未配置根证书管理器")
+	}
+
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	available := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		if err := a.rootCertManager.CheckFiles([]string{filename}); err != nil {
+			a.logger.Warn("实例 %s 引用的根证书 %s 已不存在，不再信任该根证书", cfg.Name, filename)
+			continue
+		}
+		available = append(available, filename)
+	}
+	pool, err := a.rootCertManager.GetPoolFor(available)
+	if err != nil {
+		return err
+	}
+
+	server := cfg.Type == TypeServer || cfg.Type == TypeHTTPServer
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.tlcpConfig != nil {
+		tlcpConfig := a.tlcpConfig.Clone()
+		if server {
+			tlcpConfig.ClientCAs = pool.GetSMCertPool()
+		} else {
+			tlcpConfig.RootCAs = pool.GetSMCertPool()
+		}
+		a.tlcpConfig = tlcpConfig
+		a.atomicTLCPConfig.Store(tlcpConfig)
+	}
+	if a.tlsConfig != nil {
+		tlsConfig := a.tlsConfig.Clone()
+		if server {
+			tlsConfig.ClientCAs = pool.GetCertPool()
+		} else {
+			tlsConfig.RootCAs = pool.GetCertPool()
+		}
+		a.tlsConfig = tlsConfig
+		a.atomicTLSConfig.Store(tlsConfig)
+	}
+	return nil
+}
+
+// instanceRootCertPool 构建只包含实例引用根证书的证书池
+// 参数:
+//   - filenames: 实例配置 client-ca/server-ca 中的根证书文件名
+//
+// 注意: 不同实例的信任锚相互隔离，一个实例引用的CA不会用于验证其他实例的对端证书
+func (a *TLCPAdapter) instanceRootCertPool(filenames []string) (security.RootCertPool, error) {
+	if a.rootCertManager == nil {
+		return nil, fmt.Errorf("未配置根证书管理器")
+	}
+	return a.rootCertManager.GetPoolFor(filenames)
+}
+
+// applyRevocationCheck 按实例配置为TLCP/TLS配置设置对端证书吊销检查
+// 参数:
+//   - cfg: 实例配置，crl-check 为 false 且未启用 ocsp 时不做处理
+//   - tlcpConfig: TLCP配置，可为nil
+//   - tlsConfig: TLS配置，可为nil
+//
+// 注意:
+//   - 吊销检查基于握手验证通过的证书链，未验证对端证书（如跳过验证或不要求客户端证书）时不检查
+//   - 先检查CRL再检查OCSP，OCSP只检查对端证书
+//   - TLCP 使用 VerifyPeerCertificate；TLS 使用 VerifyConnection，会话恢复时同样会检查，并优先使用对端装订的OCSP响应
+func (a *TLCPAdapter) applyRevocationCheck(cfg *config.InstanceConfig, tlcpConfig *tlcp.Config, tlsConfig *tls.Config) error {
+	checkCRL := cfg.CRLCheck
+	checker := a.newOCSPChecker(cfg)
+	if !checkCRL && checker == nil {
+		return nil
+	}
+	if checkCRL && a.rootCertManager == nil {
+		return fmt.Errorf("启用CRL检查失败: 未配置根证书管理器")
+	}
+
+	rootCertMgr := a.rootCertManager
+	if tlcpConfig != nil {
+		tlcpConfig.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*smx509.Certificate) error {
+			if checkCRL {
+				if err := rootCertMgr.CheckRevocation(verifiedChains); err != nil {
+					return err
+				}
+			}
+			if checker != nil {
+				return checker.VerifyChains(verifiedChains, nil)
+			}
+			return nil
+		}
+	}
+	if tlsConfig != nil {
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			if checkCRL {
+				if err := rootCertMgr.CheckRevocationX509(cs.VerifiedChains); err != nil {
+					return err
+				}
+			}
+			if checker != nil {
+				return checker.VerifyChains(smChains(cs.VerifiedChains), cs.OCSPResponse)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// newOCSPChecker 按实例配置创建OCSP检查器，未启用时返回nil
+// 注意: 检查器共享适配器的响应缓存，热重载后缓存仍然有效
+func (a *TLCPAdapter) newOCSPChecker(cfg *config.InstanceConfig) *ocsp.Checker {
+	if cfg.OCSP == nil || !cfg.OCSP.Enabled {
+		return nil
+	}
+	name := cfg.Name
+	return ocsp.NewChecker(ocsp.Options{
+		Responders: cfg.OCSP.Responders,
+		Policy:     ocsp.FailurePolicy(cfg.OCSP.FailurePolicy),
+		Timeout:    cfg.OCSP.Timeout,
+		CacheTTL:   cfg.OCSP.CacheTTL,
+		OnError: func(cert *smx509.Certificate, err error) {
+			a.logger.Warn("实例 %s OCSP检查失败，按 soft-fail 策略放行 %s: %v", name, cert.Subject.String(), err)
+		},
+	}, a.ocspCache)
+}
+
+// newOCSPStapler 为TLS服务端证书创建OCSP装订响应维护器
+// 参数:
+//   - cfg: 实例配置
+//   - cert: TLS服务端证书
+//
+// 返回:
+//   - *ocsp.Stapler: 装订响应维护器
+//   - error: 找不到证书签发者时返回错误
+//
+// 注意: 签发者优先取证书链中的第二张证书，其次在根证书中查找
+func (a *TLCPAdapter) newOCSPStapler(cfg *config.InstanceConfig, cert *tls.Certificate) (*ocsp.Stapler, error) {
+	if len(cert.Certificate) == 0 {
+		return nil, fmt.Errorf("TLS证书为空")
+	}
+	leaf, err := smx509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析TLS证书失败: %w", err)
+	}
+
+	var issuer *smx509.Certificate
+	if len(cert.Certificate) > 1 {
+		if c, err := smx509.ParseCertificate(cert.Certificate[1]); err == nil && leaf.CheckSignatureFrom(c) == nil {
+			issuer = c
+		}
+	}
+	if issuer == nil && a.rootCertManager != nil {
+		issuer, _ = a.rootCertManager.FindIssuer(leaf)
+	}
+	if issuer == nil {
+		return nil, fmt.Errorf("找不到证书 %s 的签发者", leaf.Subject.String())
+	}
+
+	name := cfg.Name
+	checker := ocsp.NewChecker(ocsp.Options{
+		Timeout:  cfg.OCSP.Timeout,
+		CacheTTL: cfg.OCSP.CacheTTL,
+		OnError: func(cert *smx509.Certificate, err error) {
+			a.logger.Warn("实例 %s 刷新OCSP装订响应失败: %v", name, err)
+		},
+	}, a.ocspCache)
+	return ocsp.NewStapler(*cert, issuer, checker)
+}
+
+// smChains 将标准库证书链转换为国密证书链
+func smChains(chains [][]*x509.Certificate) [][]*smx509.Certificate {
+	result := make([][]*smx509.Certificate, len(chains))
+	for i, chain := range chains {
+		result[i] = make([]*smx509.Certificate, len(chain))
+		for j, cert := range chain {
+			result[i][j] = (*smx509.Certificate)(cert)
+		}
+	}
+	return result
+}
+
 func (a *TLCPAdapter) reloadServerConfig(cfg *config.InstanceConfig) error {
 	var tlcpConfig *tlcp.Config
 	var tlsConfig *tls.Config
@@ -218,19 +537,23 @@ func (a *TLCPAdapter) reloadServerConfig(cfg *config.InstanceConfig) error {
 	if newTLCPKS, err := a.loadKeyStoreFromConfig(cfg.TLCP.Keystore, cfg.Name+"-tlcp"); err == nil {
 		a.tlcpKeyStore = newTLCPKS
 	} else {
-		logger.Error("实例 %s 加载 TLCP Keystore错误: %v", cfg.Name, err)
+		a.logger.Error("实例 %s 加载 TLCP Keystore错误: %v", cfg.Name, err)
 		a.tlcpKeyStore = nil
 	}
 
 	if newTLSKS, err := a.loadKeyStoreFromConfig(cfg.TLS.Keystore, cfg.Name+"-tls"); err == nil {
 		a.tlsKeyStore = newTLSKS
 	} else {
-		logger.Error("实例 %s 加载 TLS Keystore错误: %v", cfg.Name, err)
+		a.logger.Error("实例 %s 加载 TLS Keystore错误: %v", cfg.Name, err)
 		a.tlsKeyStore = nil
 	}
 
 	if len(cfg.ClientCA) > 0 {
-		rootCertPool = a.rootCertManager.GetPool()
+		pool, err := a.instanceRootCertPool(cfg.ClientCA)
+		if err != nil {
+			return fmt.Errorf("加载客户端CA失败: %w", err)
+		}
+		rootCertPool = pool
 	}
 
 	if a.tlcpKeyStore != nil {
@@ -279,6 +602,20 @@ func (a *TLCPAdapter) reloadServerConfig(cfg *config.InstanceConfig) error {
 			return err
 		}
 		tlsConfig.Certificates = []tls.Certificate{*cert}
+		if cfg.OCSP != nil && cfg.OCSP.Stapling {
+			if stapler, err := a.newOCSPStapler(cfg, cert); err == nil {
+				// 未设置 Certificates 时 crypto/tls 总是通过 GetCertificate 获取证书
+				tlsConfig.Certificates = nil
+				tlsConfig.GetCertificate = stapler.GetCertificate
+				go func() {
+					if err := stapler.Refresh(); err != nil {
+						a.logger.Warn("实例 %s 获取OCSP装订响应失败: %v", cfg.Name, err)
+					}
+				}()
+			} else {
+				a.logger.Warn("实例 %s 无法启用OCSP装订: %v", cfg.Name, err)
+			}
+		}
 
 		tlsConfig.ClientAuth, _ = config.ParseTLSClientAuth(cfg.TLS.ClientAuthType)
 		if rootCertPool != nil {
@@ -305,6 +642,10 @@ func (a *TLCPAdapter) reloadServerConfig(cfg *config.InstanceConfig) error {
 		}
 	}
 
+	if err := a.applyRevocationCheck(cfg, tlcpConfig, tlsConfig); err != nil {
+		return err
+	}
+
 	a.mu.Lock()
 
 	a.tlcpConfig = tlcpConfig
@@ -362,7 +703,11 @@ func (a *TLCPAdapter) reloadClientConfig(cfg *config.InstanceConfig) error {
 	}
 
 	if len(cfg.ServerCA) > 0 {
-		rootCertPool = a.rootCertManager.GetPool()
+		pool, err := a.instanceRootCertPool(cfg.ServerCA)
+		if err != nil {
+			return fmt.Errorf("加载服务端CA失败: %w", err)
+		}
+		rootCertPool = pool
 	}
 
 	tlcpConfig = &tlcp.Config{
@@ -466,6 +811,10 @@ func (a *TLCPAdapter) reloadClientConfig(cfg *config.InstanceConfig) error {
 		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(100)
 	}
 
+	if err := a.applyRevocationCheck(cfg, tlcpConfig, tlsConfig); err != nil {
+		return err
+	}
+
 	a.mu.Lock()
 
 	a.tlcpConfig = tlcpConfig
@@ -498,28 +847,7 @@ func (a *TLCPAdapter) CheckHealth(protocol ProtocolType, timeout time.Duration,
 		return result
 	}
 
-	dialer := &net.Dialer{
-		Timeout: timeout,
-	}
-
-	var conn net.Conn
-	var err error
-
-	switch protocol {
-	case ProtocolTLCP:
-		conn, err = a.checkTLCPHealth(dialer, targetAddr)
-	case ProtocolTLS:
-		conn, err = a.checkTLSHealth(dialer, targetAddr)
-	case ProtocolAuto:
-		conn, err = a.checkTLCPHealth(dialer, targetAddr)
-		if err != nil {
-			a.logger.Debug("TLCP健康检查失败，尝试TLS: %v", err)
-			conn, err = a.checkTLSHealth(dialer, targetAddr)
-		}
-	default:
-		result.Error = "不支持的协议类型"
-		return result
-	}
+	conn, _, err := a.DialHealth(protocol, timeout, targetAddr)
 
 	latency := time.Since(start).Milliseconds()
 	result.Latency = latency
@@ -537,18 +865,91 @@ func (a *TLCPAdapter) CheckHealth(protocol ProtocolType, timeout time.Duration,
 	return result
 }
 
-func (a *TLCPAdapter) checkTLCPHealth(dialer *net.Dialer, targetAddr string) (net.Conn, error) {
+// DialHealth 以健康检查方式建立 TLCP/TLS 连接，不校验对端证书
+// 参数:
+//   - protocol: 协议类型，auto 时先尝试 TLCP，失败后尝试 TLS
+//   - timeout: 连接及握手超时时间
+//   - targetAddr: 目标地址
+//
+// 返回:
+//   - net.Conn: 已完成握手的连接，由调用方关闭
+//   - ProtocolType: 实际使用的协议
+//   - error: 连接或握手失败时返回错误
+func (a *TLCPAdapter) DialHealth(protocol ProtocolType, timeout time.Duration, targetAddr string) (net.Conn, ProtocolType, error) {
+	return a.DialHealthWith(protocol, timeout, targetAddr, nil)
+}
+
+// DialHealthWith 与 DialHealth 相同，在握手前对底层连接执行 prepare
+// 参数:
+//   - prepare: 握手前执行的操作，如写入PROXY协议头，可为 nil；auto 模式下每次尝试均会执行
+func (a *TLCPAdapter) DialHealthWith(protocol ProtocolType, timeout time.Duration, targetAddr string, prepare func(net.Conn) error) (net.Conn, ProtocolType, error) {
+	dial := func(handshake func(net.Conn) (net.Conn, error)) (net.Conn, error) {
+		deadline := time.Now().Add(timeout)
+		raw, err := net.DialTimeout("tcp", targetAddr, timeout)
+		if err != nil {
+			return nil, err
+		}
+		raw.SetDeadline(deadline)
+		if prepare != nil {
+			if err := prepare(raw); err != nil {
+				raw.Close()
+				return nil, err
+			}
+		}
+		conn, err := handshake(raw)
+		if err != nil {
+			raw.Close()
+			return nil, err
+		}
+		raw.SetDeadline(time.Time{})
+		return conn, nil
+	}
+	serverName := targetAddr
+	if host, _, err := net.SplitHostPort(targetAddr); err == nil {
+		serverName = host
+	}
+
+	switch protocol {
+	case ProtocolTLCP:
+		conn, err := a.checkTLCPHealth(dial, serverName)
+		return conn, ProtocolTLCP, err
+	case ProtocolTLS:
+		conn, err := a.checkTLSHealth(dial, serverName)
+		return conn, ProtocolTLS, err
+	case ProtocolAuto:
+		conn, err := a.checkTLCPHealth(dial, serverName)
+		if err == nil {
+			return conn, ProtocolTLCP, nil
+		}
+		a.logger.Debug("TLCP健康检查失败，尝试TLS: %v", err)
+		conn, err = a.checkTLSHealth(dial, serverName)
+		return conn, ProtocolTLS, err
+	default:
+		return nil, protocol, fmt.Errorf("不支持的协议类型")
+	}
+}
+
+// healthDialFunc 建立底层连接并执行握手
+type healthDialFunc func(handshake func(net.Conn) (net.Conn, error)) (net.Conn, error)
+
+func (a *TLCPAdapter) checkTLCPHealth(dial healthDialFunc, serverName string) (net.Conn, error) {
 	baseConfig := a.atomicTLCPConfig.Load().(*tlcp.Config)
 	if baseConfig == nil {
 		return nil, fmt.Errorf("TLCP配置未初始化")
 	}
 	healthConfig := baseConfig.Clone()
 	healthConfig.InsecureSkipVerify = true
+	if healthConfig.ServerName == "" {
+		healthConfig.ServerName = serverName
+	}
 
-	return tlcp.DialWithDialer(dialer, "tcp", targetAddr, healthConfig)
+	return dial(func(raw net.Conn) (net.Conn, error) {
+		conn := tlcp.Client(raw, healthConfig)
+		return conn, conn.Handshake()
+	})
 }
 
-func (a *TLCPAdapter) checkTLSHealth(dialer *net.Dialer, targetAddr string) (net.Conn, error) {
+func (a *TLCPAdapter) checkTLSHealth(dial healthDialFunc, serverName string) (net.Conn, error) {
 	baseConfig := a.atomicTLSConfig.Load().(*tls.Config)
 	if baseConfig == nil {
 		return nil, fmt.Errorf("TLS配置未初始化")
@@ -556,6 +957,12 @@ func (a *TLCPAdapter) checkTLSHealth(dialer *net.Dialer, targetAddr string) (net
 
 	healthConfig := baseConfig.Clone()
 	healthConfig.InsecureSkipVerify = true
+	if healthConfig.ServerName == "" {
+		healthConfig.ServerName = serverName
+	}
 
-	return tls.DialWithDialer(dialer, "tcp", targetAddr, healthConfig)
+	return dial(func(raw net.Conn) (net.Conn, error) {
+		conn := tls.Client(raw, healthConfig)
+		return conn, conn.Handshake()
+	})
 }
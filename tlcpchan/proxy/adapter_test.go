@@ -126,3 +126,21 @@ func TestReloadServerConfigWithMissingKeystore(t *testing.T) {
 		})
 	}
 }
+
+// TestReloadClientConfigWithMissingServerCA 测试引用不存在的根证书时配置加载失败
+func TestReloadClientConfigWithMissingServerCA(t *testing.T) {
+	adapter, err := NewTLCPAdapter(security.NewKeyStoreManager(), security.NewRootCertManager(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewTLCPAdapter() error = %v", err)
+	}
+
+	err = adapter.ReloadConfig(&config.InstanceConfig{
+		Name:     "test-client",
+		Type:     "client",
+		Protocol: "tlcp",
+		ServerCA: []string{"missing-ca.crt"},
+	})
+	if err == nil {
+		t.Fatal("ReloadConfig() 引用不存在的根证书时应返回错误")
+	}
+}
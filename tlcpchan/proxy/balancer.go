@@ -0,0 +1,273 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+)
+
+const (
+	// defaultMaxFails 默认连续失败摘除阈值
+	defaultMaxFails = 3
+	// defaultFailTimeout 默认摘除时长
+	defaultFailTimeout = 30 * time.Second
+)
+
+// upstream 负载均衡中的单个目标
+type upstream struct {
+	addr   string
+	weight int
+	active atomic.Int64
+
+	// 以下字段由 Balancer.mu 保护
+	currentWeight int
+	fails         int
+	ejectedUntil  time.Time
+}
+
+// formatTargets 格式化实例的目标地址列表，用于日志输出
+func formatTargets(cfg *config.InstanceConfig) string {
+	targets := cfg.TargetList()
+	addrs := make([]string, len(targets))
+	for i, t := range targets {
+		addrs[i] = t.Address
+	}
+	return strings.Join(addrs, ",")
+}
+
+// DialFunc 连接指定目标地址的函数
+type DialFunc func(addr string) (net.Conn, error)
+
+// Balancer 多目标负载均衡器
+// 按策略确定目标的尝试顺序，连接失败时依次尝试下一个目标，连续失败达到阈值的目标被暂时摘除
+type Balancer struct {
+	strategy    string
+	maxFails    int
+	failTimeout time.Duration
+	upstreams   []*upstream
+	logger      *logger.Logger
+
+	mu sync.Mutex
+}
+
+// NewBalancer 按实例配置创建负载均衡器
+// 参数:
+//   - cfg: 实例配置，目标地址取自 TargetList，策略取自 LoadBalance
+//
+// 返回:
+//   - *Balancer: 负载均衡器，只有一个目标时同样适用
+func NewBalancer(cfg *config.InstanceConfig) *Balancer {
+	b := &Balancer{logger: logger.Instance(cfg.Name)}
+	b.Update(cfg)
+	return b
+}
+
+// Update 按新配置更新策略与目标，用于配置热重载
+// 参数:
+//   - cfg: 实例配置
+//
+// 注意: 地址未变化的目标保留活跃连接数与摘除状态，只增删变化的目标；
+// 已删除目标上的连接关闭时仍释放原目标的计数，不影响保留的目标
+func (b *Balancer) Update(cfg *config.InstanceConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.strategy = config.LoadBalanceRoundRobin
+	b.maxFails = defaultMaxFails
+	b.failTimeout = defaultFailTimeout
+	if lb := cfg.LoadBalance; lb != nil {
+		if lb.Strategy != "" {
+			b.strategy = lb.Strategy
+		}
+		if lb.MaxFails > 0 {
+			b.maxFails = lb.MaxFails
+		}
+		if lb.FailTimeout > 0 {
+			b.failTimeout = lb.FailTimeout
+		}
+	}
+
+	// 同一地址可能配置多次，按出现顺序依次复用
+	existing := make(map[string][]*upstream, len(b.upstreams))
+	for _, u := range b.upstreams {
+		existing[u.addr] = append(existing[u.addr], u)
+	}
+	targets := cfg.TargetList()
+	upstreams := make([]*upstream, 0, len(targets))
+	for _, t := range targets {
+		if reused := existing[t.Address]; len(reused) > 0 {
+			u := reused[0]
+			existing[t.Address] = reused[1:]
+			u.weight = t.Weight
+			upstreams = append(upstreams, u)
+			continue
+		}
+		upstreams = append(upstreams, &upstream{addr: t.Address, weight: t.Weight})
+	}
+	b.upstreams = upstreams
+}
+
+// Dial 按负载均衡策略连接目标
+// 参数:
+//   - client: 客户端地址，source-hash 策略使用其IP，可为nil
+//   - dial: 连接单个目标的函数
+//
+// 返回:
+//   - net.Conn: 目标连接，关闭时释放目标的活跃连接计数
+//   - string: 实际连接的目标地址
+//   - error: 所有目标均连接失败时返回最后一个错误
+//
+// 注意: 所有目标都被摘除时仍会按顺序尝试全部目标，避免目标恢复后无法重新接入
+func (b *Balancer) Dial(client net.Addr, dial DialFunc) (net.Conn, string, error) {
+	candidates := b.order(client)
+	if len(candidates) == 0 {
+		return nil, "", errors.New("目标地址未配置")
+	}
+
+	var lastErr error
+	for _, u := range candidates {
+		u.active.Add(1)
+		conn, err := dial(u.addr)
+		if err != nil {
+			u.active.Add(-1)
+			// 请求被取消不是目标的问题，不计入失败也不再尝试其他目标
+			if errors.Is(err, context.Canceled) {
+				return nil, "", err
+			}
+			b.markFailure(u)
+			lastErr = fmt.Errorf("%s: %w", u.addr, err)
+			continue
+		}
+		b.markSuccess(u)
+		return &balancedConn{Conn: conn, upstream: u}, u.addr, nil
+	}
+	return nil, "", lastErr
+}
+
+// order 确定本次连接的目标尝试顺序，未被摘除的目标在前
+func (b *Balancer) order(client net.Addr) []*upstream {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]*upstream, 0, len(b.upstreams))
+	var ejected []*upstream
+	for _, u := range b.upstreams {
+		if now.Before(u.ejectedUntil) {
+			ejected = append(ejected, u)
+		} else {
+			healthy = append(healthy, u)
+		}
+	}
+
+	switch b.strategy {
+	case config.LoadBalanceLeastConn:
+		sortLeastConn(healthy)
+	case config.LoadBalanceSourceHash:
+		sortSourceHash(healthy, client)
+	default:
+		b.sortRoundRobin(healthy)
+	}
+	return append(healthy, ejected...)
+}
+
+// sortRoundRobin 平滑加权轮询选出首个目标，其余目标保持配置顺序作为重试顺序
+// 注意: 调用方需持有 b.mu
+func (b *Balancer) sortRoundRobin(ups []*upstream) {
+	if len(ups) < 2 {
+		return
+	}
+	total := 0
+	best := 0
+	for i, u := range ups {
+		u.currentWeight += u.weight
+		total += u.weight
+		if u.currentWeight > ups[best].currentWeight {
+			best = i
+		}
+	}
+	ups[best].currentWeight -= total
+
+	rotated := append(append([]*upstream{}, ups[best:]...), ups[:best]...)
+	copy(ups, rotated)
+}
+
+// sortLeastConn 按 活跃连接数/权重 从小到大排序，相同时保持配置顺序
+func sortLeastConn(ups []*upstream) {
+	sort.SliceStable(ups, func(i, j int) bool {
+		// 交叉相乘比较 active/weight，避免浮点运算
+		return ups[i].active.Load()*int64(ups[j].weight) < ups[j].active.Load()*int64(ups[i].weight)
+	})
+}
+
+// sortSourceHash 按客户端IP进行加权最高随机权重（rendezvous）哈希排序
+// 同一客户端的目标顺序固定，目标增减或被摘除时只影响原本落在该目标上的客户端
+func sortSourceHash(ups []*upstream, client net.Addr) {
+	var ip string
+	if tcpAddr, ok := client.(*net.TCPAddr); ok {
+		ip = tcpAddr.IP.String()
+	} else if client != nil {
+		ip, _, _ = net.SplitHostPort(client.String())
+	}
+
+	scores := make(map[*upstream]float64, len(ups))
+	for _, u := range ups {
+		h := fnv.New64a()
+		h.Write([]byte(ip))
+		h.Write([]byte{0})
+		h.Write([]byte(u.addr))
+		// 将哈希值映射到 (0,1)，得分为 weight / -ln(x)
+		x := (float64(h.Sum64()>>11) + 0.5) / (1 << 53)
+		scores[u] = float64(u.weight) / -math.Log(x)
+	}
+	sort.SliceStable(ups, func(i, j int) bool {
+		return scores[ups[i]] > scores[ups[j]]
+	})
+}
+
+func (b *Balancer) markFailure(u *upstream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	u.fails++
+	if u.fails >= b.maxFails && !time.Now().Before(u.ejectedUntil) {
+		u.ejectedUntil = time.Now().Add(b.failTimeout)
+		b.logger.Warn("目标 %s 连续 %d 次连接失败，摘除 %v", u.addr, u.fails, b.failTimeout)
+	}
+}
+
+func (b *Balancer) markSuccess(u *upstream) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if u.fails >= b.maxFails {
+		b.logger.Info("目标 %s 已恢复", u.addr)
+	}
+	u.fails = 0
+	u.ejectedUntil = time.Time{}
+}
+
+// balancedConn 负载均衡建立的目标连接，关闭时释放目标的活跃连接计数
+type balancedConn struct {
+	net.Conn
+	upstream *upstream
+	once     sync.Once
+}
+
+func (c *balancedConn) Close() error {
+	c.once.Do(func() {
+		c.upstream.active.Add(-1)
+	})
+	return c.Conn.Close()
+}
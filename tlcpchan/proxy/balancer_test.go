@@ -0,0 +1,199 @@
+package proxy
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+)
+
+// pipeDialer 模拟目标连接，记录每次连接的目标，failing 中的目标连接失败
+type pipeDialer struct {
+	failing map[string]bool
+	dialed  []string
+}
+
+func (d *pipeDialer) dial(addr string) (net.Conn, error) {
+	d.dialed = append(d.dialed, addr)
+	if d.failing[addr] {
+		return nil, errors.New("connection refused")
+	}
+	c1, c2 := net.Pipe()
+	c2.Close()
+	return c1, nil
+}
+
+func newTestBalancer(strategy string, targets ...config.TargetConfig) *Balancer {
+	return NewBalancer(&config.InstanceConfig{
+		Targets:     targets,
+		LoadBalance: &config.LoadBalanceConfig{Strategy: strategy, MaxFails: 2, FailTimeout: 50 * time.Millisecond},
+	})
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	b := newTestBalancer(config.LoadBalanceRoundRobin,
+		config.TargetConfig{Address: "a:1", Weight: 3},
+		config.TargetConfig{Address: "b:1"})
+	d := &pipeDialer{}
+
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		conn, target, err := b.Dial(nil, d.dial)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		conn.Close()
+		counts[target]++
+	}
+	if counts["a:1"] != 6 || counts["b:1"] != 2 {
+		t.Errorf("按 3:1 权重轮询, 实际分布 %v", counts)
+	}
+}
+
+func TestBalancerLeastConn(t *testing.T) {
+	b := newTestBalancer(config.LoadBalanceLeastConn,
+		config.TargetConfig{Address: "a:1"},
+		config.TargetConfig{Address: "b:1"})
+	d := &pipeDialer{}
+
+	first, target1, _ := b.Dial(nil, d.dial)
+	defer first.Close()
+	second, target2, _ := b.Dial(nil, d.dial)
+	if target1 == target2 {
+		t.Errorf("应选择活跃连接较少的目标, 两次均为 %s", target1)
+	}
+
+	// 关闭后活跃连接数归零，再次选择该目标
+	second.Close()
+	third, target3, _ := b.Dial(nil, d.dial)
+	defer third.Close()
+	if target3 != target2 {
+		t.Errorf("连接关闭后应重新选择 %s, 实际为 %s", target2, target3)
+	}
+}
+
+func TestBalancerSourceHash(t *testing.T) {
+	targets := []config.TargetConfig{{Address: "a:1"}, {Address: "b:1"}, {Address: "c:1"}}
+	b := newTestBalancer(config.LoadBalanceSourceHash, targets...)
+	d := &pipeDialer{}
+
+	seen := make(map[string]bool)
+	for i := 1; i <= 32; i++ {
+		client := &net.TCPAddr{IP: net.IPv4(10, 0, 0, byte(i)), Port: 40000 + i}
+		_, first, _ := b.Dial(client, d.dial)
+		client.Port++
+		_, again, _ := b.Dial(client, d.dial)
+		if first != again {
+			t.Errorf("同一客户端IP %s 应固定连接同一目标, 实际为 %s 和 %s", client.IP, first, again)
+		}
+		seen[first] = true
+	}
+	if len(seen) != len(targets) {
+		t.Errorf("客户端应分布到所有目标, 实际只用到 %v", seen)
+	}
+}
+
+func TestBalancerFailover(t *testing.T) {
+	b := newTestBalancer(config.LoadBalanceRoundRobin,
+		config.TargetConfig{Address: "a:1"},
+		config.TargetConfig{Address: "b:1"})
+	d := &pipeDialer{failing: map[string]bool{"a:1": true}}
+
+	for i := 0; i < 4; i++ {
+		conn, target, err := b.Dial(nil, d.dial)
+		if err != nil {
+			t.Fatalf("目标 a:1 失败时应重试 b:1: %v", err)
+		}
+		conn.Close()
+		if target != "b:1" {
+			t.Errorf("实际连接目标 = %s, want b:1", target)
+		}
+	}
+
+	// a:1 连续失败 2 次后被摘除，摘除期间不再尝试
+	d.dialed = nil
+	for i := 0; i < 2; i++ {
+		conn, _, _ := b.Dial(nil, d.dial)
+		conn.Close()
+	}
+	for _, addr := range d.dialed {
+		if addr == "a:1" {
+			t.Fatalf("被摘除的目标不应被尝试: %v", d.dialed)
+		}
+	}
+
+	// 摘除到期后恢复的目标重新参与负载均衡
+	time.Sleep(60 * time.Millisecond)
+	delete(d.failing, "a:1")
+	counts := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		conn, target, _ := b.Dial(nil, d.dial)
+		conn.Close()
+		counts[target]++
+	}
+	if counts["a:1"] == 0 {
+		t.Errorf("摘除到期后目标应恢复, 实际分布 %v", counts)
+	}
+
+	d.failing = map[string]bool{"a:1": true, "b:1": true}
+	if _, _, err := b.Dial(nil, d.dial); err == nil {
+		t.Error("所有目标均失败时应返回错误")
+	}
+}
+
+func TestBalancerUpdate(t *testing.T) {
+	b := newTestBalancer(config.LoadBalanceLeastConn,
+		config.TargetConfig{Address: "a:1"},
+		config.TargetConfig{Address: "b:1"},
+		config.TargetConfig{Address: "old:1"})
+	d := &pipeDialer{failing: map[string]bool{"a:1": true}}
+
+	// a:1 连续失败后被摘除，b:1 保持一个活跃连接
+	var held net.Conn
+	for i := 0; i < 2; i++ {
+		conn, target, err := b.Dial(nil, d.dial)
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		if target == "b:1" && held == nil {
+			held = conn
+			continue
+		}
+		conn.Close()
+	}
+	if held == nil {
+		t.Fatal("应建立到 b:1 的连接")
+	}
+
+	b.Update(&config.InstanceConfig{
+		Targets: []config.TargetConfig{{Address: "a:1"}, {Address: "b:1"}, {Address: "c:1"}},
+		LoadBalance: &config.LoadBalanceConfig{
+			Strategy: config.LoadBalanceLeastConn, MaxFails: 2, FailTimeout: time.Minute,
+		},
+	})
+	if len(b.upstreams) != 3 || b.upstreams[2].addr != "c:1" {
+		t.Fatalf("更新后的目标 = %v", b.upstreams)
+	}
+	if got := b.upstreams[1].active.Load(); got != 1 {
+		t.Errorf("未变化目标的活跃连接数 = %d, want 1", got)
+	}
+
+	// 摘除状态保留，最少连接选择新增的 c:1
+	delete(d.failing, "a:1")
+	d.dialed = nil
+	conn, target, err := b.Dial(nil, d.dial)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	conn.Close()
+	if target != "c:1" || len(d.dialed) != 1 {
+		t.Errorf("实际连接目标 = %s, 尝试顺序 %v, want c:1", target, d.dialed)
+	}
+
+	held.Close()
+	if got := b.upstreams[1].active.Load(); got != 0 {
+		t.Errorf("更新前建立的连接关闭后活跃连接数 = %d, want 0", got)
+	}
+}
@@ -22,12 +22,14 @@ type ClientProxy struct {
 	cfg             *config.InstanceConfig
 	adapter         *TLCPAdapter
 	handler         *ConnHandler
+	balancer        *Balancer
 	listener        net.Listener
 	keyStoreManager *security.KeyStoreManager
 	rootCertManager *security.RootCertManager
 	stats           *stats.Collector
 	logger          *logger.Logger
 	shutdownChan    chan struct{}
+	conns           *connTracker
 	mu              sync.Mutex
 	running         bool
 	stopped         bool
@@ -37,23 +39,37 @@ type ClientProxy struct {
 	cacheTTL      time.Duration
 }
 
+// NewClientProxy 创建客户端代理
+// 参数:
+//   - cfg: 实例配置
+//   - keyStoreMgr: 密钥存储管理器
+//   - rootCertMgr: 根证书管理器
+//   - collector: 实例统计收集器，为nil时创建汇总到默认收集器的独立收集器
 func NewClientProxy(cfg *config.InstanceConfig,
 	keyStoreMgr *security.KeyStoreManager,
-	rootCertMgr *security.RootCertManager) (*ClientProxy, error) {
+	rootCertMgr *security.RootCertManager,
+	collector *stats.Collector) (*ClientProxy, error) {
 	adapter, err := NewTLCPAdapter(keyStoreMgr, rootCertMgr)
 	if err != nil {
 		return nil, fmt.Errorf("创建协议适配器失败: %w", err)
 	}
+	adapter.logger = logger.Instance(cfg.Name)
+
+	if collector == nil {
+		collector = newInstanceCollector()
+	}
 
 	proxy := &ClientProxy{
 		cfg:             cfg,
 		adapter:         adapter,
-		handler:         NewConnHandler(stats.DefaultCollector(), cfg.BufferSize),
+		handler:         NewConnHandler(collector, cfg.BufferSize),
+		balancer:        NewBalancer(cfg),
 		keyStoreManager: keyStoreMgr,
 		rootCertManager: rootCertMgr,
-		stats:           stats.DefaultCollector(),
-		logger:          logger.Default(),
+		stats:           collector,
+		logger:          logger.Instance(cfg.Name),
 		shutdownChan:    make(chan struct{}),
+		conns:           newConnTracker(),
 		protocolCache:   make(map[string]protocolCacheEntry),
 		cacheTTL:        5 * time.Minute,
 	}
@@ -81,12 +97,20 @@ func (p *ClientProxy) Start() error {
 		p.mu.Unlock()
 		return fmt.Errorf("监听失败 %s: %w", p.cfg.Listen, err)
 	}
+	wrapped, err := acceptProxyProtocol(listener, p.cfg)
+	if err != nil {
+		listener.Close()
+		p.mu.Unlock()
+		return err
+	}
+	listener = wrapped
 
 	p.listener = listener
 	p.running = true
+	p.conns.open()
 	p.mu.Unlock()
 
-	p.logger.Info("客户端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, p.cfg.Target, p.cfg.Protocol)
+	p.logger.Info("客户端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, formatTargets(p.cfg), p.cfg.Protocol)
 
 	go p.acceptLoop()
 
@@ -117,54 +141,104 @@ func (p *ClientProxy) acceptLoop() {
 			continue
 		}
 
-		p.stats.IncrementConnections()
-
 		go p.handleConnection(conn)
 	}
 }
 
 func (p *ClientProxy) handleConnection(clientConn net.Conn) {
-	defer p.stats.DecrementConnections()
 	defer clientConn.Close()
 
-	start := time.Now()
+	// 排空超时后通过 cancel 结束数据管道
+	connCtx, cancelConn := context.WithCancel(context.Background())
+	defer cancelConn()
+	tc, ok := p.conns.track(clientConn, cancelConn)
+	if !ok {
+		return
+	}
+	defer tc.release()
 
-	protocol := p.getProtocol()
-	if protocol == ProtocolAuto {
-		protocol = p.detectAndCacheProtocol()
+	// 先解析PROXY协议头，协议头无效时不再连接目标服务
+	if err := readClientProxyHeader(clientConn); err != nil {
+		p.logger.Slog().Warn("解析PROXY协议头失败", "conn", tc.id, "remote", clientConn.RemoteAddr().String(), "error", err)
+		protocolStats(p.stats, "").IncrementErrors()
+		tc.finish(CloseProxyHeaderFailed, err)
+		return
 	}
+	tc.setRemote(clientConn.RemoteAddr())
+	log := p.logger.Slog().With("conn", tc.id, "remote", clientConn.RemoteAddr().String())
 
-	targetConn, err := p.adapter.DialWithProtocol("tcp", p.cfg.Target, protocol, p.cfg)
+	start := time.Now()
+
+	// 协议为 auto 时各目标分别探测协议，统计维度使用实际连接目标的协议
+	var protocol ProtocolType
+	var timing DialTiming
+	targetConn, target, err := p.currentBalancer().Dial(clientConn.RemoteAddr(), func(addr string) (net.Conn, error) {
+		protocol = p.resolveProtocol(addr)
+		conn, t, err := p.adapter.DialWithTiming("tcp", addr, protocol, p.cfg)
+		timing = t
+		return conn, err
+	})
+	cs := protocolStats(p.stats, protocol.String())
+	cs.IncrementConnections()
+	defer cs.DecrementConnections()
+	if protocol != ProtocolAuto {
+		tc.setProtocol(protocol.String())
+	}
 	if err != nil {
-		p.logger.Error("连接目标服务失败 %s: %v", p.cfg.Target, err)
-		p.stats.IncrementErrors()
+		log.Error("连接目标服务失败", "error", err)
+		if isHandshakeError(err) {
+			cs.IncrementHandshakeFailures()
+			tc.finish(CloseHandshakeFailed, err)
+		} else {
+			cs.IncrementErrors()
+			tc.finish(CloseDialFailed, err)
+		}
 		return
 	}
 	defer targetConn.Close()
+	tc.setTarget(target)
+	tc.setSecure(targetConn)
+	cs.RecordDialLatency(timing.Dial)
+	cs.RecordHandshakeLatency(timing.Handshake)
+	cs.RecordLatency(time.Since(start))
 
-	p.logger.Debug("连接建立: %s -> %s (%s)", clientConn.RemoteAddr(), p.cfg.Target, protocol)
+	log.Debug("连接建立", "target", target, "protocol", protocol.String())
 
-	ctx, cancel := context.WithCancel(context.Background())
+	timeout := p.adapter.getTimeoutConfig(p.cfg)
+	ctx, cancel := sessionContext(connCtx, timeout)
 	defer cancel()
 
-	received, sent, err := p.handler.Pipe(ctx, clientConn, targetConn)
+	sessionStart := time.Now()
+	received, sent, err := p.handler.withStats(cs).withTimeouts(timeout).Pipe(ctx, tc.meter(clientConn), targetConn)
 	if err != nil {
-		p.logger.Debug("连接关闭: %v", err)
+		log.Debug("连接关闭", "error", err)
 	}
+	tc.finish(pipeCloseReason(ctx, err), err)
+
+	duration := time.Since(sessionStart)
+	cs.RecordSessionDuration(duration)
 
-	latency := time.Since(start)
-	p.stats.RecordLatency(latency)
+	log.Debug("连接结束", "bytesIn", received, "bytesOut", sent, "durationMs", duration.Milliseconds())
+}
 
-	p.logger.Debug("连接结束: 收发 %d/%d 字节, 耗时 %v", received, sent, latency)
+// resolveProtocol 获取连接目标服务使用的协议，协议为 auto 且无缓存时进行探测
+// 参数:
+//   - target: 目标地址，协议探测结果按目标地址分别缓存
+func (p *ClientProxy) resolveProtocol(target string) ProtocolType {
+	protocol := p.getProtocol(target)
+	if protocol == ProtocolAuto {
+		protocol = p.detectAndCacheProtocol(target)
+	}
+	return protocol
 }
 
-func (p *ClientProxy) getProtocol() ProtocolType {
+func (p *ClientProxy) getProtocol(target string) ProtocolType {
 	if p.adapter.Protocol() != ProtocolAuto {
 		return p.adapter.Protocol()
 	}
 
 	p.cacheMu.RLock()
-	entry, ok := p.protocolCache[p.cfg.Target]
+	entry, ok := p.protocolCache[target]
 	p.cacheMu.RUnlock()
 
 	if ok && time.Since(entry.detected) < p.cacheTTL {
@@ -174,12 +248,12 @@ func (p *ClientProxy) getProtocol() ProtocolType {
 	return ProtocolAuto
 }
 
-func (p *ClientProxy) detectAndCacheProtocol() ProtocolType {
-	conn, err := p.adapter.DialTLCP("tcp", p.cfg.Target, p.cfg)
+func (p *ClientProxy) detectAndCacheProtocol(target string) ProtocolType {
+	conn, err := p.adapter.DialTLCP("tcp", target, p.cfg)
 	if err == nil {
 		conn.Close()
 		p.cacheMu.Lock()
-		p.protocolCache[p.cfg.Target] = protocolCacheEntry{
+		p.protocolCache[target] = protocolCacheEntry{
 			protocol: ProtocolTLCP,
 			detected: time.Now(),
 		}
@@ -190,7 +264,7 @@ func (p *ClientProxy) detectAndCacheProtocol() ProtocolType {
 	p.logger.Debug("TLCP连接检测失败，使用TLS: %v", err)
 
 	p.cacheMu.Lock()
-	p.protocolCache[p.cfg.Target] = protocolCacheEntry{
+	p.protocolCache[target] = protocolCacheEntry{
 		protocol: ProtocolTLS,
 		detected: time.Now(),
 	}
@@ -199,6 +273,13 @@ func (p *ClientProxy) detectAndCacheProtocol() ProtocolType {
 	return ProtocolTLS
 }
 
+// currentBalancer 获取当前配置对应的负载均衡器
+func (p *ClientProxy) currentBalancer() *Balancer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.balancer
+}
+
 func (p *ClientProxy) Stop() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -222,6 +303,56 @@ func (p *ClientProxy) Stop() error {
 	return nil
 }
 
+// Drain 排空代理：停止接收新连接，等待活跃连接在宽限期内结束，超时后强制关闭剩余连接
+// 参数:
+//   - grace: 宽限期，<=0 时立即强制关闭所有连接
+//
+// 返回:
+//   - *DrainResult: 排空结果
+func (p *ClientProxy) Drain(grace time.Duration) *DrainResult {
+	p.logger.Info("排空客户端代理: %s, 宽限期 %v", p.cfg.Name, grace)
+	if err := p.Stop(); err != nil {
+		p.logger.Error("停止客户端代理失败: %v", err)
+	}
+	return p.conns.drain(grace, p.conns.wait)
+}
+
+// ActiveConnections 获取活跃连接数，包括停止监听后仍未结束的连接
+func (p *ClientProxy) ActiveConnections() int {
+	return p.conns.count()
+}
+
+// InheritConnections 接管被替换代理的活跃连接，用于以新配置重启实例
+// 参数:
+//   - old: 被替换的代理，已停止监听
+//
+// 注意: 需在 Start 之前调用；旧代理停止监听后仍在转发的连接可通过新代理查询、强制关闭和排空
+func (p *ClientProxy) InheritConnections(old *ClientProxy) {
+	p.conns = old.conns
+}
+
+// SetAccessLogger 设置连接访问日志
+// 参数:
+//   - l: 访问日志，为nil时不记录
+func (p *ClientProxy) SetAccessLogger(l *AccessLogger) {
+	p.conns.access.Store(l)
+}
+
+// Connections 获取所有活跃连接的信息，按建立时间排序
+func (p *ClientProxy) Connections() []*ConnInfo {
+	return p.conns.list()
+}
+
+// CloseConnection 强制关闭指定的活跃连接
+// 参数:
+//   - id: 连接编号
+//
+// 返回:
+//   - bool: 连接不存在或已结束时返回 false
+func (p *ClientProxy) CloseConnection(id string) bool {
+	return p.conns.kill(id)
+}
+
 func (p *ClientProxy) Restart() error {
 	if err := p.Stop(); err != nil {
 		return err
@@ -260,6 +391,8 @@ func (p *ClientProxy) Reload(cfg *config.InstanceConfig) error {
 		return err
 	}
 
+	p.balancer.Update(cfg)
+
 	p.ClearProtocolCache()
 	p.logger.Info("客户端代理配置热重载成功: %s", p.cfg.Name)
 	return nil
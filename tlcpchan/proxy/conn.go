@@ -6,15 +6,50 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Trisia/tlcpchan/config"
 	"github.com/Trisia/tlcpchan/logger"
 	"github.com/Trisia/tlcpchan/stats"
 )
 
+// defaultMaxSnapshots 实例统计收集器默认保留的快照数量
+const defaultMaxSnapshots = 360
+
+// protocolUnknown 无法确定协商协议时使用的统计维度名称
+const protocolUnknown = "unknown"
+
+// newInstanceCollector 创建实例级统计收集器，数据同时汇总到进程级默认收集器
+func newInstanceCollector() *stats.Collector {
+	return stats.NewChildCollector(stats.DefaultCollector(), defaultMaxSnapshots)
+}
+
+// protocolStats 获取按协商协议细分的统计收集器
+// 参数:
+//   - collector: 实例统计收集器
+//   - protocol: 协商协议，为空时归入 "unknown"
+//
+// 返回:
+//   - *stats.Collector: 协议子收集器，数据同时汇总到实例统计收集器
+func protocolStats(collector *stats.Collector, protocol string) *stats.Collector {
+	if protocol == "" {
+		protocol = protocolUnknown
+	}
+	return collector.Child(protocol)
+}
+
 type ConnHandler struct {
 	stats      *stats.Collector
 	logger     *logger.Logger
 	bufferSize int
+
+	// readTimeout 读取空闲超时，0 表示不限制
+	readTimeout time.Duration
+	// writeTimeout 单次写入超时，0 表示不限制
+	writeTimeout time.Duration
+	// lastActive 管道两个方向最近一次传输数据的时间（UnixNano），由 Pipe 为每个连接创建
+	lastActive *atomic.Int64
 }
 
 func NewConnHandler(stats *stats.Collector, bufferSize int) *ConnHandler {
@@ -29,6 +64,8 @@ func NewConnHandler(stats *stats.Collector, bufferSize int) *ConnHandler {
 }
 
 // copyWithStats 从src复制数据到dst，并在每次写入时更新统计信息
+// 设置了超时时，每次读取前设置读取截止时间，每次写入前设置写入截止时间；
+// 读取超时但管道另一方向在超时时长内仍有数据传输时继续等待，只有两个方向均空闲才返回超时错误
 // 参数:
 //   - dst: 目标写入器
 //   - src: 源读取器
@@ -42,9 +79,19 @@ func (h *ConnHandler) copyWithStats(dst io.Writer, src io.Reader, stats *stats.C
 	buf := make([]byte, h.bufferSize)
 	var written int64
 
+	rd, _ := src.(interface{ SetReadDeadline(time.Time) error })
+	wd, _ := dst.(interface{ SetWriteDeadline(time.Time) error })
+
 	for {
+		if rd != nil && h.readTimeout > 0 {
+			rd.SetReadDeadline(time.Now().Add(h.readTimeout))
+		}
 		nr, er := src.Read(buf)
 		if nr > 0 {
+			h.touch()
+			if wd != nil && h.writeTimeout > 0 {
+				wd.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+			}
 			nw, ew := dst.Write(buf[0:nr])
 			if nw > 0 {
 				written += int64(nw)
@@ -65,6 +112,10 @@ func (h *ConnHandler) copyWithStats(dst io.Writer, src io.Reader, stats *stats.C
 			}
 		}
 		if er != nil {
+			if isTimeout(er) && h.activeWithin(h.readTimeout) {
+				// 另一方向仍在传输数据，连接未空闲
+				continue
+			}
 			if er.Error() == "EOF" {
 				return written, nil
 			}
@@ -76,6 +127,57 @@ func (h *ConnHandler) copyWithStats(dst io.Writer, src io.Reader, stats *stats.C
 	}
 }
 
+// touch 记录管道有数据传输
+func (h *ConnHandler) touch() {
+	if h.lastActive != nil {
+		h.lastActive.Store(time.Now().UnixNano())
+	}
+}
+
+// activeWithin 管道在最近 d 时长内是否有数据传输
+func (h *ConnHandler) activeWithin(d time.Duration) bool {
+	if h.lastActive == nil || d <= 0 {
+		return false
+	}
+	return time.Since(time.Unix(0, h.lastActive.Load())) < d
+}
+
+// isTimeout 判断是否为截止时间到期导致的错误
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// withTimeouts 返回使用指定读写超时的连接处理器副本
+// 参数:
+//   - timeout: 实例超时配置，Read 作为读取空闲超时，Write 作为单次写入超时，为 nil 时不限制
+func (h *ConnHandler) withTimeouts(timeout *config.TimeoutConfig) *ConnHandler {
+	handler := *h
+	if timeout != nil {
+		handler.readTimeout = timeout.Read
+		handler.writeTimeout = timeout.Write
+	}
+	return &handler
+}
+
+// sessionContext 创建单个连接的会话上下文，配置了最长会话时长时到期自动结束
+// 参数:
+//   - parent: 父上下文，排空代理强制关闭连接时取消
+//   - timeout: 实例超时配置，为 nil 时不限制会话时长
+func sessionContext(parent context.Context, timeout *config.TimeoutConfig) (context.Context, context.CancelFunc) {
+	if timeout != nil && timeout.MaxSession > 0 {
+		return context.WithTimeout(parent, timeout.MaxSession)
+	}
+	return context.WithCancel(parent)
+}
+
+// withStats 返回使用指定统计收集器的连接处理器副本
+func (h *ConnHandler) withStats(collector *stats.Collector) *ConnHandler {
+	handler := *h
+	handler.stats = collector
+	return &handler
+}
+
 // Pipe 在clientConn和targetConn之间建立双向数据管道
 // 参数:
 //   - ctx: 上下文，用于取消操作
@@ -87,19 +189,35 @@ func (h *ConnHandler) copyWithStats(dst io.Writer, src io.Reader, stats *stats.C
 //   - int64: 发送的总字节数
 //   - error: 错误信息
 //
-// 注意: 使用自定义复制函数实现统计信息实时更新
+// 注意:
+//   - 使用自定义复制函数实现统计信息实时更新
+//   - 任一方向因空闲或写入超时结束时关闭两端连接；ctx 结束（如达到最长会话时长）时同样关闭两端连接
 func (h *ConnHandler) Pipe(ctx context.Context, clientConn, targetConn net.Conn) (received int64, sent int64, err error) {
 	var wg sync.WaitGroup
 	wg.Add(2)
 
+	// 两个方向共享最近传输时间，用于判断连接是否空闲
+	handler := *h
+	handler.lastActive = &atomic.Int64{}
+	handler.touch()
+	h = &handler
+
 	var clientToTargetErr error
 	var targetToClientErr error
 
+	closeOnTimeout := func(err error) {
+		if isTimeout(err) {
+			clientConn.Close()
+			targetConn.Close()
+		}
+	}
+
 	go func() {
 		defer wg.Done()
 		var n int64
 		n, clientToTargetErr = h.copyWithStats(targetConn, clientConn, h.stats, true)
 		sent = n
+		closeOnTimeout(clientToTargetErr)
 	}()
 
 	go func() {
@@ -107,6 +225,7 @@ func (h *ConnHandler) Pipe(ctx context.Context, clientConn, targetConn net.Conn)
 		var n int64
 		n, targetToClientErr = h.copyWithStats(clientConn, targetConn, h.stats, false)
 		received = n
+		closeOnTimeout(targetToClientErr)
 	}()
 
 	done := make(chan struct{})
@@ -123,6 +242,12 @@ func (h *ConnHandler) Pipe(ctx context.Context, clientConn, targetConn net.Conn)
 	case <-done:
 	}
 
+	// 超时关闭连接后另一方向返回的是连接已关闭错误，优先返回超时错误
+	for _, e := range []error{clientToTargetErr, targetToClientErr} {
+		if isTimeout(e) {
+			return received, sent, e
+		}
+	}
 	if clientToTargetErr != nil && !isNormalError(clientToTargetErr) {
 		return received, sent, clientToTargetErr
 	}
@@ -133,6 +258,250 @@ func (h *ConnHandler) Pipe(ctx context.Context, clientConn, targetConn net.Conn)
 	return received, sent, nil
 }
 
+// countingConn 统计连接数和读写字节数的连接包装
+// 首次读写时完成握手并确定协商协议，连接计入对应协议的统计收集器，关闭时释放活跃连接计数并记录会话时长
+// 从客户端读取的数据计入发送字节数，写回客户端的数据计入接收字节数，与 Pipe 的统计口径一致
+// 握手受握手超时限制，握手完成后连接存活超过最长会话时长时被关闭
+type countingConn struct {
+	net.Conn
+	parent *stats.Collector
+	// protocol 协议判断函数，为 nil 时由调用方通过 bind 确定统计维度
+	protocol func(net.Conn) string
+	timeout  *config.TimeoutConfig
+
+	initOnce    sync.Once
+	initErr     error
+	established time.Time
+	// stats 连接所属协议的统计收集器，确定统计维度前为 nil
+	stats atomic.Pointer[stats.Collector]
+
+	mu           sync.Mutex
+	initialized  bool
+	counted      bool
+	closed       bool
+	sessionTimer *time.Timer
+	// 确定统计维度前收发的字节数，确定后计入统计收集器
+	pendingSent     int64
+	pendingReceived int64
+	// tracked 代理活跃连接中的登记，关闭时注销，可为nil
+	tracked *trackedConn
+
+	// 调用方（如 http.Server）设置的截止时间，握手结束后恢复
+	deadlineMu    sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// newCountingConn 创建统计连接包装
+// 参数:
+//   - conn: 客户端连接
+//   - parent: 实例统计收集器
+//   - protocol: 协议判断函数，在握手完成后调用，返回值作为统计维度；为 nil 时由调用方通过 bind 确定
+//   - timeout: 实例超时配置，为 nil 时不限制握手时间和会话时长
+func newCountingConn(conn net.Conn, parent *stats.Collector, protocol func(net.Conn) string,
+	timeout *config.TimeoutConfig) *countingConn {
+	if timeout == nil {
+		timeout = &config.TimeoutConfig{}
+	}
+	return &countingConn{Conn: conn, parent: parent, protocol: protocol, timeout: timeout}
+}
+
+// init 完成握手并登记连接，握手失败时计入握手失败数
+func (c *countingConn) init() error {
+	c.initOnce.Do(func() {
+		start := time.Now()
+		handshaked, err := handshake(c.Conn, c.timeout.Handshake)
+		if handshaked && c.timeout.Handshake > 0 {
+			c.restoreDeadlines()
+		}
+		c.established = time.Now()
+		c.initErr = err
+
+		c.mu.Lock()
+		c.initialized = true
+		// 握手失败的连接不会再由调用方确定统计维度
+		if c.protocol != nil || err != nil {
+			protocol := protocolUnknown
+			if c.protocol != nil {
+				protocol = c.protocol(c.Conn)
+			}
+			s := c.bindLocked(protocol)
+			if err != nil {
+				s.IncrementHandshakeFailures()
+			} else if handshaked {
+				s.RecordHandshakeLatency(c.established.Sub(start))
+			}
+		}
+		if !c.closed && err == nil && c.timeout.MaxSession > 0 {
+			c.sessionTimer = time.AfterFunc(c.timeout.MaxSession, func() {
+				if c.tracked != nil {
+					c.tracked.finish(CloseMaxSession, nil)
+				}
+				c.Conn.Close()
+			})
+		}
+		c.mu.Unlock()
+
+		if c.tracked != nil {
+			c.tracked.setRemote(c.Conn.RemoteAddr())
+			c.tracked.setProtocol(connProtocol(c.Conn))
+			if err == nil {
+				c.tracked.setSecure(c.Conn)
+			} else {
+				c.tracked.finish(CloseHandshakeFailed, err)
+			}
+		}
+	})
+	return c.initErr
+}
+
+// bind 确定连接的统计维度并登记连接，已确定时忽略
+// 参数:
+//   - protocol: 统计维度，为空时归入 "unknown"
+func (c *countingConn) bind(protocol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bindLocked(protocol)
+}
+
+// bindLocked 同 bind，返回连接所属协议的统计收集器
+// 注意: 调用方需持有 c.mu
+func (c *countingConn) bindLocked(protocol string) *stats.Collector {
+	if s := c.stats.Load(); s != nil {
+		return s
+	}
+	s := protocolStats(c.parent, protocol)
+	s.IncrementConnections()
+	s.AddBytesSent(c.pendingSent)
+	s.AddBytesReceived(c.pendingReceived)
+	c.pendingSent, c.pendingReceived = 0, 0
+	if c.closed {
+		s.DecrementConnections()
+	} else {
+		c.counted = true
+	}
+	c.stats.Store(s)
+	return s
+}
+
+// addBytes 记录收发字节数，确定统计维度前暂存
+func (c *countingConn) addBytes(sent, received int64) {
+	s := c.stats.Load()
+	if s == nil {
+		c.mu.Lock()
+		if s = c.stats.Load(); s == nil {
+			c.pendingSent += sent
+			c.pendingReceived += received
+		}
+		c.mu.Unlock()
+		if s == nil {
+			return
+		}
+	}
+	s.AddBytesSent(sent)
+	s.AddBytesReceived(received)
+}
+
+// restoreDeadlines 握手结束后恢复调用方设置的截止时间
+func (c *countingConn) restoreDeadlines() {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.Conn.SetReadDeadline(c.readDeadline)
+	c.Conn.SetWriteDeadline(c.writeDeadline)
+}
+
+func (c *countingConn) SetDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *countingConn) SetReadDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *countingConn) SetWriteDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.writeDeadline = t
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// collector 获取连接所属协议的统计收集器，尚未确定统计维度时为 nil
+func (c *countingConn) collector() *stats.Collector {
+	c.init()
+	return c.stats.Load()
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.addBytes(int64(n), 0)
+		if c.tracked != nil {
+			c.tracked.sent.Add(int64(n))
+		}
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.addBytes(0, int64(n))
+		if c.tracked != nil {
+			c.tracked.received.Add(int64(n))
+		}
+	}
+	return n, err
+}
+
+func (c *countingConn) Close() error {
+	c.mu.Lock()
+	// 关闭前未确定统计维度的连接归入 "unknown"
+	if c.initialized {
+		c.bindLocked(protocolUnknown)
+	}
+	c.closed = true
+	if c.sessionTimer != nil {
+		c.sessionTimer.Stop()
+	}
+	if c.counted {
+		c.counted = false
+		s := c.stats.Load()
+		s.DecrementConnections()
+		if c.initErr == nil {
+			s.RecordSessionDuration(time.Since(c.established))
+		}
+	}
+	c.mu.Unlock()
+	if c.tracked != nil {
+		c.tracked.release()
+	}
+	return c.Conn.Close()
+}
+
+// connStats 获取客户端连接所属协议的统计收集器
+// 参数:
+//   - conn: 客户端连接，可为 countingConn 或原始连接
+//   - parent: 实例统计收集器
+//   - protocol: 协议判断函数，conn 不是 countingConn 时使用
+func connStats(conn net.Conn, parent *stats.Collector, protocol func(net.Conn) string) *stats.Collector {
+	if cc, ok := conn.(*countingConn); ok {
+		return cc.collector()
+	}
+	return protocolStats(parent, protocol(conn))
+}
+
 // isNormalError 判断是否为正常的连接关闭错误
 // 参数:
 //   - err: 错误信息
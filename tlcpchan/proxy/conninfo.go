@@ -0,0 +1,169 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"gitee.com/Trisia/gotlcp/tlcp"
+	"github.com/Trisia/tlcpchan/config"
+)
+
+// SecurityInfo 连接的安全协商信息（握手完成后有效）
+type SecurityInfo struct {
+	// Protocol 协商的协议，"tlcp" 或 "tls"
+	Protocol string
+	// Version 协议版本号
+	Version uint16
+	// CipherSuite 密码套件名称
+	CipherSuite string
+	// ServerName 客户端请求的SNI名称
+	ServerName string
+	// PeerSubject 对端证书主题，对端未提供证书时为空
+	PeerSubject string
+	// PeerSerial 对端证书序列号（16进制），对端未提供证书时为空
+	PeerSerial string
+	// PeerCommonName 对端证书主题中的通用名称，对端未提供证书时为空
+	PeerCommonName string
+	// PeerVerified 对端证书是否通过验证
+	PeerVerified bool
+}
+
+// protectedConn 自动协议监听器（pa）返回的连接，可获取实际的 TLCP/TLS 连接
+type protectedConn interface {
+	ProtectedConn() net.Conn
+}
+
+// unwrapConn 剥离代理内部的连接包装，返回原始的安全连接
+func unwrapConn(conn net.Conn) net.Conn {
+	for {
+		switch c := conn.(type) {
+		case *countingConn:
+			conn = c.Conn
+		case *balancedConn:
+			conn = c.Conn
+		case protectedConn:
+			inner := c.ProtectedConn()
+			if inner == nil {
+				return conn
+			}
+			conn = inner
+		default:
+			return conn
+		}
+	}
+}
+
+// handshaker 支持显式握手的连接
+type handshaker interface {
+	Handshake() error
+}
+
+// handshake 对支持握手的连接执行握手
+// 参数:
+//   - conn: 客户端连接
+//   - timeout: 握手超时时间，包括 auto 协议的协议识别，0 表示不限制
+//
+// 返回:
+//   - bool: 连接是否支持握手，明文连接返回 false
+//   - error: 握手失败或超时时返回错误
+//
+// 注意: 设置了超时时握手完成后清除连接的截止时间
+func handshake(conn net.Conn, timeout time.Duration) (bool, error) {
+	h, ok := unwrapConn(conn).(handshaker)
+	if !ok {
+		return false, nil
+	}
+	if timeout <= 0 {
+		return true, h.Handshake()
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	err := h.Handshake()
+	conn.SetDeadline(time.Time{})
+	return true, err
+}
+
+// isHandshakeError 判断建立安全连接失败是否发生在握手阶段
+// 注意: TCP 连接建立失败返回 Op 为 "dial" 的 *net.OpError，其余错误视为握手失败
+func isHandshakeError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+	return true
+}
+
+// connProtocol 根据连接类型判断安全协议
+// 返回:
+//   - string: "tlcp"、"tls"，无法判断时（如自动协议连接尚未识别）返回空字符串
+//
+// 注意: 只依据连接类型判断，握手失败的连接同样可以得到协议
+func connProtocol(conn net.Conn) string {
+	switch unwrapConn(conn).(type) {
+	case *tlcp.Conn:
+		return ProtocolTLCP.String()
+	case *tls.Conn:
+		return ProtocolTLS.String()
+	default:
+		return ""
+	}
+}
+
+// GetSecurityInfo 获取连接的安全协商信息
+// 参数:
+//   - conn: 客户端连接，可以是 TLCP/TLS 连接或代理内部包装的连接
+//
+// 返回:
+//   - *SecurityInfo: 安全协商信息，非 TLCP/TLS 连接或握手未完成时返回 nil
+func GetSecurityInfo(conn net.Conn) *SecurityInfo {
+	switch c := unwrapConn(conn).(type) {
+	case *tlcp.Conn:
+		state := c.ConnectionState()
+		if !state.HandshakeComplete {
+			return nil
+		}
+		info := &SecurityInfo{
+			Protocol:    ProtocolTLCP.String(),
+			Version:     state.Version,
+			CipherSuite: tlcpCipherSuiteName(state.CipherSuite),
+			ServerName:  state.ServerName,
+		}
+		if len(state.PeerCertificates) > 0 {
+			info.PeerSubject = state.PeerCertificates[0].Subject.String()
+			info.PeerSerial = state.PeerCertificates[0].SerialNumber.Text(16)
+			info.PeerCommonName = state.PeerCertificates[0].Subject.CommonName
+			info.PeerVerified = len(state.VerifiedChains) > 0
+		}
+		return info
+	case *tls.Conn:
+		state := c.ConnectionState()
+		if !state.HandshakeComplete {
+			return nil
+		}
+		info := &SecurityInfo{
+			Protocol:    ProtocolTLS.String(),
+			Version:     state.Version,
+			CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+			ServerName:  state.ServerName,
+		}
+		if len(state.PeerCertificates) > 0 {
+			info.PeerSubject = state.PeerCertificates[0].Subject.String()
+			info.PeerSerial = state.PeerCertificates[0].SerialNumber.Text(16)
+			info.PeerCommonName = state.PeerCertificates[0].Subject.CommonName
+			info.PeerVerified = len(state.VerifiedChains) > 0
+		}
+		return info
+	default:
+		return nil
+	}
+}
+
+func tlcpCipherSuiteName(id uint16) string {
+	for name, v := range config.TLCPCipherSuiteNames {
+		if v == id {
+			return name
+		}
+	}
+	return ""
+}
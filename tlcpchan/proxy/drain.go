@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"context"
+	"time"
+)
+
+// DrainResult 排空代理的结果
+type DrainResult struct {
+	// Active 开始排空时的活跃连接数
+	Active int `json:"active"`
+	// ForceClosed 宽限期结束后被强制关闭的连接数
+	ForceClosed int `json:"forceClosed"`
+	// DurationMs 排空耗时（毫秒）
+	DurationMs int64 `json:"durationMs"`
+}
+
+// drain 等待活跃连接在宽限期内结束，超时后强制关闭剩余连接
+// 参数:
+//   - grace: 宽限期，<=0 时立即强制关闭
+//   - wait: 等待连接结束的函数，ctx 到期时返回错误
+//
+// 注意: 调用前应已停止接收新连接
+func (t *connTracker) drain(grace time.Duration, wait func(ctx context.Context) error) *DrainResult {
+	start := time.Now()
+	result := &DrainResult{Active: t.count()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	wait(ctx)
+	result.ForceClosed = t.closeAll()
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/stats"
+)
+
+// trackPipe 登记连接并在后台运行 Pipe，模拟代理处理单个连接
+func trackPipe(t *testing.T, tracker *connTracker) <-chan error {
+	t.Helper()
+	userConn, clientConn := tcpPair(t)
+	targetConn, backendConn := tcpPair(t)
+	_, _ = userConn, backendConn
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tc, ok := tracker.track(clientConn, cancel)
+	if !ok {
+		t.Fatal("排空前应允许登记连接")
+	}
+	h := NewConnHandler(stats.NewCollector(10), 4096)
+	done := make(chan error, 1)
+	go func() {
+		defer tc.release()
+		defer cancel()
+		_, _, err := h.Pipe(ctx, tc.meter(clientConn), targetConn)
+		done <- err
+	}()
+	return done
+}
+
+func TestConnTrackerDrain(t *testing.T) {
+	t.Run("宽限期内结束", func(t *testing.T) {
+		tracker := newConnTracker()
+		userConn, clientConn := tcpPair(t)
+		tc, _ := tracker.track(clientConn, nil)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			userConn.Close()
+			tc.release()
+		}()
+
+		result := tracker.drain(5*time.Second, tracker.wait)
+		if result.Active != 1 || result.ForceClosed != 0 {
+			t.Errorf("排空结果错误: %+v", result)
+		}
+		if result.DurationMs >= 5000 {
+			t.Errorf("连接结束后应立即返回, 耗时 %dms", result.DurationMs)
+		}
+	})
+
+	t.Run("超时强制关闭", func(t *testing.T) {
+		tracker := newConnTracker()
+		done := trackPipe(t, tracker)
+
+		result := tracker.drain(100*time.Millisecond, tracker.wait)
+		if result.Active != 1 || result.ForceClosed != 1 {
+			t.Errorf("排空结果错误: %+v", result)
+		}
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatal("强制关闭后数据管道应结束")
+		}
+		if n := tracker.count(); n != 0 {
+			t.Errorf("活跃连接数应为 0, 实际为 %d", n)
+		}
+
+		_, clientConn := tcpPair(t)
+		if _, ok := tracker.track(clientConn, nil); ok {
+			t.Error("排空后不应再登记新连接")
+		}
+		tracker.open()
+		if _, ok := tracker.track(clientConn, nil); !ok {
+			t.Error("重新启动后应允许登记连接")
+		}
+	})
+}
+
+func TestHTTPClientProxyDrain(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		io.WriteString(w, "done")
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	newProxy := func(t *testing.T) *HTTPClientProxy {
+		p, err := NewHTTPClientProxy(&config.InstanceConfig{
+			Name:     "drain",
+			Type:     TypeHTTPClient,
+			Listen:   "127.0.0.1:0",
+			Target:   strings.TrimPrefix(backend.URL, "https://"),
+			Protocol: string(config.ProtocolTLS),
+			TLS:      config.TLSConfig{InsecureSkipVerify: true},
+		}, nil, nil, stats.NewCollector(10))
+		if err != nil {
+			t.Fatalf("NewHTTPClientProxy() error = %v", err)
+		}
+		if err := p.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		return p
+	}
+
+	// 发送请求并等待请求进入处理中
+	request := func(t *testing.T, p *HTTPClientProxy) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			resp, err := http.Get("http://" + p.listener.Addr().String() + "/")
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+			done <- err
+		}()
+		deadline := time.Now().Add(3 * time.Second)
+		for p.ActiveConnections() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if p.ActiveConnections() == 0 {
+			t.Fatal("请求未到达代理")
+		}
+		return done
+	}
+
+	t.Run("等待处理中的请求", func(t *testing.T) {
+		p := newProxy(t)
+		done := request(t, p)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			release <- struct{}{}
+		}()
+
+		result := p.Drain(5 * time.Second)
+		if result.ForceClosed != 0 {
+			t.Errorf("请求在宽限期内完成, 不应强制关闭连接: %+v", result)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("排空期间处理中的请求应正常完成, err = %v", err)
+		}
+		if p.IsRunning() {
+			t.Error("排空后代理应停止")
+		}
+	})
+
+	t.Run("超时强制关闭", func(t *testing.T) {
+		p := newProxy(t)
+		done := request(t, p)
+
+		result := p.Drain(100 * time.Millisecond)
+		if result.Active != 1 || result.ForceClosed != 1 {
+			t.Errorf("排空结果错误: %+v", result)
+		}
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Error("被强制关闭的请求应失败")
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("强制关闭后请求应结束")
+		}
+	})
+}
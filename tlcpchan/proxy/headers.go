@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/Trisia/tlcpchan/config"
+)
+
+const (
+	// HeaderSSLProtocol 客户端与代理协商的安全协议（tlcp/tls）
+	HeaderSSLProtocol = "X-SSL-Protocol"
+	// HeaderSSLCipher 客户端与代理协商的密码套件
+	HeaderSSLCipher = "X-SSL-Cipher"
+	// HeaderSSLClientSubject 客户端证书主题
+	HeaderSSLClientSubject = "X-SSL-Client-Subject"
+	// HeaderSSLClientSerial 客户端证书序列号（16进制）
+	HeaderSSLClientSerial = "X-SSL-Client-Serial"
+)
+
+// sslHeaders 由代理生成的安全信息头，转发前必须清除客户端自行携带的同名头，防止伪造
+var sslHeaders = []string{
+	HeaderSSLProtocol,
+	HeaderSSLCipher,
+	HeaderSSLClientSubject,
+	HeaderSSLClientSerial,
+}
+
+// applyHeaderRules 按配置处理HTTP头
+// 参数:
+//   - h: 待处理的HTTP头
+//   - rules: 头处理规则
+//   - vars: 变量集合，用于替换规则值中的 $remote_addr 等变量
+//
+// 注意: 处理顺序为 Remove -> Set -> Add
+func applyHeaderRules(h http.Header, rules config.HeadersConfig, vars *Variables) {
+	for _, name := range rules.Remove {
+		h.Del(name)
+	}
+	for name, value := range vars.ReplaceMap(rules.Set) {
+		h.Set(name, value)
+	}
+	for name, value := range vars.ReplaceMap(rules.Add) {
+		h.Add(name, value)
+	}
+}
+
+type connContextKey struct{}
+
+// withConn 将客户端连接保存到上下文，供请求处理阶段获取连接信息
+func withConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// connFromContext 从上下文中获取客户端连接
+func connFromContext(ctx context.Context) net.Conn {
+	conn, _ := ctx.Value(connContextKey{}).(net.Conn)
+	return conn
+}
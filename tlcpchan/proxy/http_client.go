@@ -1,20 +1,327 @@
 package proxy
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
 	"github.com/Trisia/tlcpchan/config"
 	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/stats"
 )
 
+// HTTPClientProxy HTTP客户端代理
+// 接收本地应用的明文HTTP请求，通过连接池中的 TLCP/TLS 连接将请求重新发往目标服务
 type HTTPClientProxy struct {
 	*ClientProxy
+	httpServer *http.Server
+	transport  *http.Transport
 }
 
 func NewHTTPClientProxy(cfg *config.InstanceConfig,
 	keyStoreMgr *security.KeyStoreManager,
-	rootCertMgr *security.RootCertManager) (*HTTPClientProxy, error) {
-	cp, err := NewClientProxy(cfg, keyStoreMgr, rootCertMgr)
+	rootCertMgr *security.RootCertManager,
+	collector *stats.Collector) (*HTTPClientProxy, error) {
+	cp, err := NewClientProxy(cfg, keyStoreMgr, rootCertMgr, collector)
 	if err != nil {
 		return nil, err
 	}
-	return &HTTPClientProxy{ClientProxy: cp}, nil
+	p := &HTTPClientProxy{ClientProxy: cp}
+	p.transport = p.newTransport()
+	return p, nil
+}
+
+func (p *HTTPClientProxy) Start() error {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return fmt.Errorf("代理服务已在运行")
+	}
+
+	listener, err := net.Listen("tcp", p.cfg.Listen)
+	if err != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("监听失败 %s: %w", p.cfg.Listen, err)
+	}
+	wrapped, err := acceptProxyProtocol(listener, p.cfg)
+	if err != nil {
+		listener.Close()
+		p.mu.Unlock()
+		return err
+	}
+	listener = wrapped
+
+	p.listener = listener
+	p.httpServer = p.newHTTPServer()
+	p.running = true
+	p.stopped = false
+	p.conns.open()
+	server := p.httpServer
+	// 本地连接为明文连接，统计维度在转发请求时按所用目标连接的协议确定
+	ln := &countingListener{Listener: listener, stats: p.stats,
+		timeout: p.adapter.getTimeoutConfig(p.cfg), conns: p.conns}
+	p.mu.Unlock()
+
+	p.logger.Info("HTTP客户端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, formatTargets(p.cfg), p.cfg.Protocol)
+
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("HTTP客户端代理异常退出: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (p *HTTPClientProxy) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		return nil
+	}
+
+	p.logger.Info("停止HTTP客户端代理: %s", p.cfg.Name)
+
+	p.stopped = true
+	close(p.shutdownChan)
+
+	if p.httpServer != nil {
+		p.httpServer.Close()
+	}
+	p.transport.CloseIdleConnections()
+
+	p.running = false
+	p.shutdownChan = make(chan struct{})
+
+	return nil
+}
+
+// Drain 排空代理：停止接收新连接并关闭空闲连接，等待处理中的请求在宽限期内完成，超时后强制关闭剩余连接
+// 参数:
+//   - grace: 宽限期，<=0 时立即强制关闭所有连接
+//
+// 返回:
+//   - *DrainResult: 排空结果
+func (p *HTTPClientProxy) Drain(grace time.Duration) *DrainResult {
+	p.mu.Lock()
+	server := p.httpServer
+	if p.running {
+		p.logger.Info("排空HTTP客户端代理: %s, 宽限期 %v", p.cfg.Name, grace)
+		p.stopped = true
+		close(p.shutdownChan)
+		p.running = false
+		p.shutdownChan = make(chan struct{})
+	}
+	p.mu.Unlock()
+
+	result := drainHTTPServer(server, p.conns, grace)
+	p.transport.CloseIdleConnections()
+	return result
+}
+
+func (p *HTTPClientProxy) Restart() error {
+	if err := p.Stop(); err != nil {
+		return err
+	}
+	return p.Start()
+}
+
+// Reload 热重载配置，并关闭连接池中使用旧配置建立的空闲连接
+func (p *HTTPClientProxy) Reload(cfg *config.InstanceConfig) error {
+	if err := p.ClientProxy.Reload(cfg); err != nil {
+		return err
+	}
+	p.transport.CloseIdleConnections()
+	return nil
+}
+
+// ReloadCertificates 以当前配置重新加载 keystore 证书与根证书，并关闭连接池中使用旧证书建立的空闲连接
+func (p *HTTPClientProxy) ReloadCertificates() error {
+	if err := p.adapter.ReloadConfig(p.currentConfig()); err != nil {
+		return err
+	}
+	p.transport.CloseIdleConnections()
+	return nil
+}
+
+// ReloadRootCerts 替换 server-ca 证书池，并关闭连接池中按旧证书池验证建立的空闲连接
+func (p *HTTPClientProxy) ReloadRootCerts() error {
+	if err := p.adapter.ReloadRootCerts(p.currentConfig()); err != nil {
+		return err
+	}
+	p.transport.CloseIdleConnections()
+	return nil
+}
+
+// currentConfig 获取当前配置，热重载期间保证读取一致
+func (p *HTTPClientProxy) currentConfig() *config.InstanceConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+func (p *HTTPClientProxy) newHTTPServer() *http.Server {
+	timeout := p.adapter.getTimeoutConfig(p.cfg)
+	return &http.Server{
+		Handler:           p.newHandler(),
+		ReadHeaderTimeout: timeout.Read,
+		IdleTimeout:       timeout.Read,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return withConn(ctx, c)
+		},
+	}
+}
+
+// newTransport 创建到目标服务的连接池
+// 注意:
+//   - 转发请求使用 http 协议，由 DialContext 负责建立 TLCP/TLS 连接，
+//     因此连接池中缓存的是已完成握手的安全连接
+//   - 协议为 auto 时沿用 ClientProxy 的协议探测缓存
+//   - 配置多个目标时按负载均衡策略选择目标，连接池中的连接可能来自不同目标
+//   - 返回的连接记录实际使用的协议，转发请求时作为统计维度
+func (p *HTTPClientProxy) newTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			cfg := p.currentConfig()
+			var clientAddr net.Addr
+			if conn := connFromContext(ctx); conn != nil {
+				clientAddr = conn.RemoteAddr()
+			}
+
+			var protocol ProtocolType
+			var timing DialTiming
+			conn, target, err := p.currentBalancer().Dial(clientAddr, func(addr string) (net.Conn, error) {
+				protocol = p.resolveProtocol(addr)
+				conn, t, err := p.adapter.DialWithTiming(network, addr, protocol, cfg)
+				timing = t
+				if err != nil && isHandshakeError(err) {
+					protocolStats(p.stats, protocol.String()).IncrementHandshakeFailures()
+				}
+				return conn, err
+			})
+			if err != nil {
+				return nil, err
+			}
+			cs := protocolStats(p.stats, protocol.String())
+			cs.RecordDialLatency(timing.Dial)
+			cs.RecordHandshakeLatency(timing.Handshake)
+			p.logger.Debug("建立目标连接: %s (%s)", target, protocol)
+			return &upstreamConn{Conn: conn, protocol: protocol}, nil
+		},
+		MaxIdleConnsPerHost: 32,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// upstreamConn 连接池中到目标服务的连接，记录建立连接时使用的协议
+type upstreamConn struct {
+	net.Conn
+	protocol ProtocolType
+}
+
+// requestState 转发单个请求过程中记录的统计信息
+type requestState struct {
+	// protocol 转发请求所用目标连接的协议，未获取到目标连接时为空
+	protocol string
+	// failed 转发是否失败
+	failed bool
+}
+
+type requestStateKey struct{}
+
+// newHandler 创建请求处理器，在反向代理外层统计请求数和请求耗时
+// 请求按所用目标连接的协议统计，本地连接在首次获取目标连接时确定统计维度
+func (p *HTTPClientProxy) newHandler() http.Handler {
+	rp := &httputil.ReverseProxy{
+		Rewrite:        p.rewriteRequest,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleError,
+		Transport:      p.transport,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		state := &requestState{}
+		local, _ := connFromContext(r.Context()).(*countingConn)
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				uc, ok := info.Conn.(*upstreamConn)
+				if !ok {
+					return
+				}
+				// 重试时可能再次获取连接，请求只计一次
+				if state.protocol == "" {
+					state.protocol = uc.protocol.String()
+					protocolStats(p.stats, state.protocol).IncrementRequests()
+				}
+				if local != nil {
+					local.bind(state.protocol)
+				}
+			},
+		}
+		ctx := context.WithValue(r.Context(), requestStateKey{}, state)
+		rp.ServeHTTP(w, r.WithContext(httptrace.WithClientTrace(ctx, trace)))
+
+		cs := protocolStats(p.stats, state.protocol)
+		if state.protocol == "" {
+			// 未获取到目标连接的请求归入 "unknown"
+			cs.IncrementRequests()
+		}
+		if state.failed {
+			cs.IncrementErrors()
+		}
+		cs.RecordLatency(time.Since(start))
+	})
+}
+
+// rewriteRequest 将本地应用的请求改写为发往目标服务的请求
+// 注意: Host 改写为目标地址，配置了 SNI 时使用 SNI 名称
+func (p *HTTPClientProxy) rewriteRequest(pr *httputil.ProxyRequest) {
+	cfg := p.currentConfig()
+
+	pr.SetURL(&url.URL{Scheme: "http", Host: cfg.PrimaryTarget()})
+	if cfg.SNI != "" {
+		pr.Out.Host = cfg.SNI
+	}
+
+	if cfg.HTTP != nil {
+		applyHeaderRules(pr.Out.Header, cfg.HTTP.RequestHeaders, p.requestVariables(pr.In, cfg))
+	}
+}
+
+// modifyResponse 应用 http.response-headers 配置的头处理规则
+func (p *HTTPClientProxy) modifyResponse(resp *http.Response) error {
+	cfg := p.currentConfig()
+	if cfg.HTTP == nil {
+		return nil
+	}
+	applyHeaderRules(resp.Header, cfg.HTTP.ResponseHeaders, p.requestVariables(resp.Request, cfg))
+	return nil
+}
+
+func (p *HTTPClientProxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	p.logger.Error("转发HTTP请求失败 %s %s: %v", r.Method, r.URL.Path, err)
+	if state, ok := r.Context().Value(requestStateKey{}).(*requestState); ok {
+		state.failed = true
+	}
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// requestVariables 构造请求对应的变量集合，$protocol 为与目标服务使用的协议
+func (p *HTTPClientProxy) requestVariables(r *http.Request, cfg *config.InstanceConfig) *Variables {
+	var serverAddr string
+	if conn := connFromContext(r.Context()); conn != nil {
+		serverAddr = conn.LocalAddr().String()
+	}
+	target := cfg.PrimaryTarget()
+	return ExtractVariables(r.RemoteAddr, serverAddr, target, p.getProtocol(target).String(), cfg.Name)
 }
@@ -0,0 +1,131 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/stats"
+)
+
+// TestHTTPClientProxyForward 测试HTTP客户端代理通过TLS连接池转发请求
+func TestHTTPClientProxyForward(t *testing.T) {
+	var remoteAddrs []string
+	var backendHeader http.Header
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil {
+			t.Errorf("目标服务应收到TLS请求")
+		}
+		remoteAddrs = append(remoteAddrs, r.RemoteAddr)
+		backendHeader = r.Header.Clone()
+		w.Header().Set("X-Powered-By", "backend")
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer backend.Close()
+
+	cfg := &config.InstanceConfig{
+		Name:     "legacy",
+		Type:     TypeHTTPClient,
+		Target:   strings.TrimPrefix(backend.URL, "https://"),
+		Protocol: string(config.ProtocolTLS),
+		TLS:      config.TLSConfig{InsecureSkipVerify: true},
+		HTTP: &config.HTTPConfig{
+			RequestHeaders: config.HeadersConfig{
+				Set: map[string]string{"X-Forwarded-By": "$instance"},
+			},
+			ResponseHeaders: config.HeadersConfig{
+				Remove: []string{"X-Powered-By"},
+				Set:    map[string]string{"X-Upstream-Protocol": "$protocol"},
+			},
+		},
+	}
+	p, err := NewHTTPClientProxy(cfg, nil, nil, stats.NewCollector(10))
+	if err != nil {
+		t.Fatalf("NewHTTPClientProxy() error = %v", err)
+	}
+	defer p.transport.CloseIdleConnections()
+
+	front := httptest.NewServer(p.newHandler())
+	defer front.Close()
+
+	for _, path := range []string{"/a", "/b"} {
+		resp, err := http.Get(front.URL + path)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if string(body) != path {
+			t.Errorf("响应 = %q, want %q", body, path)
+		}
+		if got := resp.Header.Get("X-Powered-By"); got != "" {
+			t.Errorf("响应头 X-Powered-By 应被删除, got %q", got)
+		}
+		if got := resp.Header.Get("X-Upstream-Protocol"); got != "tls" {
+			t.Errorf("响应头 X-Upstream-Protocol = %q, want tls", got)
+		}
+	}
+
+	if got := backendHeader.Get("X-Forwarded-By"); got != "legacy" {
+		t.Errorf("X-Forwarded-By = %q, want legacy", got)
+	}
+	if len(remoteAddrs) != 2 || remoteAddrs[0] != remoteAddrs[1] {
+		t.Errorf("两次请求应复用同一条目标连接, got %v", remoteAddrs)
+	}
+	if got := p.stats.GetSnapshot().Requests; got != 2 {
+		t.Errorf("Requests = %d, want 2", got)
+	}
+}
+
+// TestHTTPClientProxyProtocolStats 测试本地连接与请求按所用目标连接的协议统计
+func TestHTTPClientProxyProtocolStats(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	cfg := &config.InstanceConfig{
+		Name:     "legacy",
+		Type:     TypeHTTPClient,
+		Listen:   "127.0.0.1:0",
+		Target:   strings.TrimPrefix(backend.URL, "https://"),
+		Protocol: string(config.ProtocolTLS),
+		TLS:      config.TLSConfig{InsecureSkipVerify: true},
+	}
+	p, err := NewHTTPClientProxy(cfg, nil, nil, stats.NewCollector(10))
+	if err != nil {
+		t.Fatalf("NewHTTPClientProxy() error = %v", err)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Stop()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	url := "http://" + p.listener.Addr().String() + "/"
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		io.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	client.CloseIdleConnections()
+
+	_, children := p.stats.Children()
+	tlsStats, ok := children["tls"]
+	if !ok {
+		t.Fatalf("应存在 tls 协议统计, got %v", children)
+	}
+	if got := tlsStats.GetSnapshot(); got.Requests != 2 || got.TotalConnections != 1 || got.BytesSent == 0 {
+		t.Errorf("tls 协议统计 = %+v, 期望 2 个请求、1 个连接", got)
+	}
+	if _, ok := children[protocolUnknown]; ok {
+		t.Error("已转发请求的连接不应归入 unknown")
+	}
+}
@@ -1,20 +1,322 @@
 package proxy
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
 	"github.com/Trisia/tlcpchan/config"
 	"github.com/Trisia/tlcpchan/security"
+	"github.com/Trisia/tlcpchan/stats"
 )
 
+// HTTPServerProxy HTTP服务端代理
+// 终结客户端的 TLCP/TLS 连接并解析 HTTP/1.1 请求，按配置处理请求/响应头后转发到明文HTTP目标服务
 type HTTPServerProxy struct {
 	*ServerProxy
+	httpServer *http.Server
 }
 
 func NewHTTPServerProxy(cfg *config.InstanceConfig,
 	keyStoreMgr *security.KeyStoreManager,
-	rootCertMgr *security.RootCertManager) (*HTTPServerProxy, error) {
-	sp, err := NewServerProxy(cfg, keyStoreMgr, rootCertMgr)
+	rootCertMgr *security.RootCertManager,
+	collector *stats.Collector) (*HTTPServerProxy, error) {
+	sp, err := NewServerProxy(cfg, keyStoreMgr, rootCertMgr, collector)
 	if err != nil {
 		return nil, err
 	}
 	return &HTTPServerProxy{ServerProxy: sp}, nil
 }
+
+func (p *HTTPServerProxy) Start() error {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return fmt.Errorf("代理服务已在运行")
+	}
+
+	listener, err := net.Listen("tcp", p.cfg.Listen)
+	if err != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("监听失败 %s: %w", p.cfg.Listen, err)
+	}
+	wrapped, err := acceptProxyProtocol(listener, p.cfg)
+	if err != nil {
+		listener.Close()
+		p.mu.Unlock()
+		return err
+	}
+	listener = wrapped
+
+	p.listener = p.adapter.WrapServerListener(listener)
+	p.httpServer = p.newHTTPServer()
+	p.running = true
+	p.conns.open()
+	server := p.httpServer
+	ln := &countingListener{Listener: p.listener, stats: p.stats, protocol: connProtocol,
+		timeout: p.adapter.getTimeoutConfig(p.cfg), conns: p.conns}
+	p.mu.Unlock()
+
+	p.logger.Info("HTTP服务端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, formatTargets(p.cfg), p.cfg.Protocol)
+
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("HTTP服务端代理异常退出: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (p *HTTPServerProxy) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.running {
+		return nil
+	}
+
+	p.logger.Info("停止HTTP服务端代理: %s", p.cfg.Name)
+
+	close(p.shutdownChan)
+
+	// 关闭HTTP服务会同时关闭监听器和所有活跃连接
+	if p.httpServer != nil {
+		p.httpServer.Close()
+	}
+
+	p.running = false
+	p.shutdownChan = make(chan struct{})
+
+	return nil
+}
+
+// Drain 排空代理：停止接收新连接并关闭空闲连接，等待处理中的请求在宽限期内完成，超时后强制关闭剩余连接
+// 参数:
+//   - grace: 宽限期，<=0 时立即强制关闭所有连接
+//
+// 返回:
+//   - *DrainResult: 排空结果
+func (p *HTTPServerProxy) Drain(grace time.Duration) *DrainResult {
+	p.mu.Lock()
+	server := p.httpServer
+	if p.running {
+		p.logger.Info("排空HTTP服务端代理: %s, 宽限期 %v", p.cfg.Name, grace)
+		close(p.shutdownChan)
+		p.running = false
+		p.shutdownChan = make(chan struct{})
+	}
+	p.mu.Unlock()
+
+	return drainHTTPServer(server, p.conns, grace)
+}
+
+// drainHTTPServer 优雅关闭HTTP服务，宽限期结束后强制关闭剩余连接
+// 参数:
+//   - server: HTTP服务，为 nil 时只等待已跟踪的连接
+//   - conns: 代理的活跃连接
+//   - grace: 宽限期
+func drainHTTPServer(server *http.Server, conns *connTracker, grace time.Duration) *DrainResult {
+	if server == nil {
+		return conns.drain(grace, conns.wait)
+	}
+	result := conns.drain(grace, server.Shutdown)
+	server.Close()
+	return result
+}
+
+func (p *HTTPServerProxy) Restart() error {
+	if err := p.Stop(); err != nil {
+		return err
+	}
+	return p.Start()
+}
+
+// currentConfig 获取当前配置，热重载期间保证读取一致
+func (p *HTTPServerProxy) currentConfig() *config.InstanceConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+func (p *HTTPServerProxy) newHTTPServer() *http.Server {
+	timeout := p.adapter.getTimeoutConfig(p.cfg)
+	return &http.Server{
+		Handler:           p.newHandler(),
+		ReadHeaderTimeout: timeout.Read,
+		IdleTimeout:       timeout.Read,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return withConn(ctx, c)
+		},
+	}
+}
+
+// newHandler 创建请求处理器，在反向代理外层统计请求数和请求耗时
+func (p *HTTPServerProxy) newHandler() http.Handler {
+	rp := p.newReverseProxy()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		cs := p.requestStats(r)
+		cs.IncrementRequests()
+		rp.ServeHTTP(w, r)
+		cs.RecordLatency(time.Since(start))
+	})
+}
+
+// requestStats 获取请求所在连接协商协议对应的统计收集器
+func (p *HTTPServerProxy) requestStats(r *http.Request) *stats.Collector {
+	return connStats(connFromContext(r.Context()), p.stats, connProtocol)
+}
+
+func (p *HTTPServerProxy) newReverseProxy() *httputil.ReverseProxy {
+	timeout := p.adapter.getTimeoutConfig(p.cfg)
+	dialer := &net.Dialer{
+		Timeout: timeout.Dial,
+	}
+	return &httputil.ReverseProxy{
+		Rewrite:        p.rewriteRequest,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleError,
+		Transport: &http.Transport{
+			// 请求URL中的主机固定为主目标，实际连接的目标由负载均衡器选择
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				clientConn := connFromContext(ctx)
+				var clientAddr net.Addr
+				if clientConn != nil {
+					clientAddr = clientConn.RemoteAddr()
+				}
+				var start time.Time
+				conn, _, err := p.currentBalancer().Dial(clientAddr, func(addr string) (net.Conn, error) {
+					start = time.Now()
+					return dialer.DialContext(ctx, network, addr)
+				})
+				if err == nil {
+					connStats(clientConn, p.stats, connProtocol).RecordDialLatency(time.Since(start))
+				}
+				return conn, err
+			},
+			MaxIdleConnsPerHost: 32,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// rewriteRequest 将客户端请求改写为发往目标服务的请求
+// 参数:
+//   - pr: 反向代理请求，In 为客户端请求，Out 为转发请求
+//
+// 注意:
+//   - 保留客户端原始 Host，并设置 X-Forwarded-For/Host/Proto
+//   - 客户端携带的 X-SSL-* 头会被清除，再由代理根据实际握手结果填写
+//   - 最后应用 http.request-headers 配置的头处理规则
+func (p *HTTPServerProxy) rewriteRequest(pr *httputil.ProxyRequest) {
+	cfg := p.currentConfig()
+
+	pr.SetURL(&url.URL{Scheme: "http", Host: cfg.PrimaryTarget()})
+	pr.Out.Host = pr.In.Host
+	pr.SetXForwarded()
+	pr.Out.Header.Set("X-Forwarded-Proto", "https")
+
+	for _, name := range sslHeaders {
+		pr.Out.Header.Del(name)
+	}
+
+	conn := connFromContext(pr.In.Context())
+	info := GetSecurityInfo(conn)
+	if info != nil {
+		pr.Out.Header.Set(HeaderSSLProtocol, info.Protocol)
+		if info.CipherSuite != "" {
+			pr.Out.Header.Set(HeaderSSLCipher, info.CipherSuite)
+		}
+		if info.PeerSubject != "" {
+			pr.Out.Header.Set(HeaderSSLClientSubject, info.PeerSubject)
+			pr.Out.Header.Set(HeaderSSLClientSerial, info.PeerSerial)
+		}
+	}
+
+	if cfg.HTTP != nil {
+		applyHeaderRules(pr.Out.Header, cfg.HTTP.RequestHeaders, p.requestVariables(pr.In, conn, info, cfg))
+	}
+}
+
+// modifyResponse 应用 http.response-headers 配置的头处理规则
+func (p *HTTPServerProxy) modifyResponse(resp *http.Response) error {
+	cfg := p.currentConfig()
+	if cfg.HTTP == nil {
+		return nil
+	}
+
+	conn := connFromContext(resp.Request.Context())
+	vars := p.requestVariables(resp.Request, conn, GetSecurityInfo(conn), cfg)
+	applyHeaderRules(resp.Header, cfg.HTTP.ResponseHeaders, vars)
+	return nil
+}
+
+func (p *HTTPServerProxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	p.logger.Error("转发HTTP请求失败 %s %s: %v", r.Method, r.URL.Path, err)
+	p.requestStats(r).IncrementErrors()
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// requestVariables 构造请求对应的变量集合
+func (p *HTTPServerProxy) requestVariables(r *http.Request, conn net.Conn,
+	info *SecurityInfo, cfg *config.InstanceConfig) *Variables {
+	remoteAddr := r.RemoteAddr
+	var serverAddr string
+	if conn != nil {
+		serverAddr = conn.LocalAddr().String()
+	}
+
+	var protocol string
+	if info != nil {
+		protocol = info.Protocol
+	}
+
+	vars := ExtractVariables(remoteAddr, serverAddr, cfg.PrimaryTarget(), protocol, cfg.Name)
+	if info != nil {
+		vars.ClientSubject = info.PeerSubject
+		vars.ClientSerial = info.PeerSerial
+	}
+	return vars
+}
+
+// countingListener 包装监听器，为每个连接统计连接数和收发字节数
+type countingListener struct {
+	net.Listener
+	stats *stats.Collector
+	// protocol 判断连接统计维度的函数
+	protocol func(net.Conn) string
+	// timeout 实例超时配置，用于握手超时和最长会话时长
+	timeout *config.TimeoutConfig
+	// conns 代理的活跃连接，排空时等待或强制关闭，可为nil
+	conns *connTracker
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		cc := newCountingConn(conn, l.stats, l.protocol, l.timeout)
+		if l.conns == nil {
+			return cc, nil
+		}
+		tc, ok := l.conns.track(cc, nil)
+		if !ok {
+			// 代理已排空，拒绝停止监听前到达的连接
+			cc.Close()
+			continue
+		}
+		cc.tracked = tc
+		return cc, nil
+	}
+}
@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/stats"
+)
+
+func newTestHTTPServerProxy(t *testing.T, cfg *config.InstanceConfig) *HTTPServerProxy {
+	t.Helper()
+	adapter, err := NewTLCPAdapter(nil, nil)
+	if err != nil {
+		t.Fatalf("NewTLCPAdapter() error = %v", err)
+	}
+	return &HTTPServerProxy{
+		ServerProxy: &ServerProxy{
+			cfg:          cfg,
+			adapter:      adapter,
+			balancer:     NewBalancer(cfg),
+			stats:        stats.NewCollector(10),
+			logger:       logger.Default(),
+			shutdownChan: make(chan struct{}),
+		},
+	}
+}
+
+// TestApplyHeaderRules 测试头处理规则的顺序和变量替换
+func TestApplyHeaderRules(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Powered-By", "php")
+	h.Set("X-Frame-Options", "SAMEORIGIN")
+	h.Set("X-Via", "old")
+
+	vars := ExtractVariables("10.0.0.1:5000", "10.0.0.2:443", "127.0.0.1:8080", "tlcp", "web")
+	applyHeaderRules(h, config.HeadersConfig{
+		Remove: []string{"X-Powered-By"},
+		Set:    map[string]string{"X-Frame-Options": "DENY", "X-Real-IP": "$remote_ip"},
+		Add:    map[string]string{"X-Via": "$instance/$protocol"},
+	}, vars)
+
+	if got := h.Get("X-Powered-By"); got != "" {
+		t.Errorf("X-Powered-By 应被删除, got %q", got)
+	}
+	if got := h.Get("X-Frame-Options"); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", got)
+	}
+	if got := h.Get("X-Real-IP"); got != "10.0.0.1" {
+		t.Errorf("X-Real-IP = %q, want 10.0.0.1", got)
+	}
+	if got := h.Values("X-Via"); len(got) != 2 || got[1] != "web/tlcp" {
+		t.Errorf("X-Via = %v, want [old web/tlcp]", got)
+	}
+}
+
+// TestHTTPServerProxyForward 测试HTTP服务端代理转发请求并处理请求/响应头
+func TestHTTPServerProxyForward(t *testing.T) {
+	var backendHeader http.Header
+	var backendHost string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backendHeader = r.Header.Clone()
+		backendHost = r.Host
+		w.Header().Set("Server", "backend")
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	cfg := &config.InstanceConfig{
+		Name:   "web",
+		Type:   TypeHTTPServer,
+		Target: strings.TrimPrefix(backend.URL, "http://"),
+		HTTP: &config.HTTPConfig{
+			RequestHeaders: config.HeadersConfig{
+				Set:    map[string]string{"X-Instance": "$instance", "X-Real-IP": "$remote_ip"},
+				Remove: []string{"X-Secret"},
+			},
+			ResponseHeaders: config.HeadersConfig{
+				Remove: []string{"Server"},
+				Add:    map[string]string{"X-Proxy-Protocol": "$protocol"},
+			},
+		},
+	}
+	p := newTestHTTPServerProxy(t, cfg)
+
+	front := httptest.NewUnstartedServer(p.newHandler())
+	front.Config.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		return withConn(ctx, c)
+	}
+	front.StartTLS()
+	defer front.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL+"/hello", nil)
+	req.Host = "app.example.com"
+	req.Header.Set("X-Secret", "s")
+	req.Header.Set(HeaderSSLClientSubject, "CN=forged")
+	resp, err := front.Client().Do(req)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("响应 = %d %q, want 200 ok", resp.StatusCode, body)
+	}
+	if backendHost != "app.example.com" {
+		t.Errorf("Host = %q, want app.example.com", backendHost)
+	}
+	if got := backendHeader.Get("X-Forwarded-For"); got != "127.0.0.1" {
+		t.Errorf("X-Forwarded-For = %q, want 127.0.0.1", got)
+	}
+	if got := backendHeader.Get("X-Forwarded-Proto"); got != "https" {
+		t.Errorf("X-Forwarded-Proto = %q, want https", got)
+	}
+	if got := backendHeader.Get(HeaderSSLProtocol); got != "tls" {
+		t.Errorf("%s = %q, want tls", HeaderSSLProtocol, got)
+	}
+	if got := backendHeader.Get(HeaderSSLClientSubject); got != "" {
+		t.Errorf("客户端伪造的 %s 应被清除, got %q", HeaderSSLClientSubject, got)
+	}
+	if got := backendHeader.Get("X-Instance"); got != "web" {
+		t.Errorf("X-Instance = %q, want web", got)
+	}
+	if got := backendHeader.Get("X-Real-IP"); got != "127.0.0.1" {
+		t.Errorf("X-Real-IP = %q, want 127.0.0.1", got)
+	}
+	if got := backendHeader.Get("X-Secret"); got != "" {
+		t.Errorf("X-Secret 应被删除, got %q", got)
+	}
+	if got := resp.Header.Get("Server"); got != "" {
+		t.Errorf("响应头 Server 应被删除, got %q", got)
+	}
+	if got := resp.Header.Get("X-Proxy-Protocol"); got != "tls" {
+		t.Errorf("响应头 X-Proxy-Protocol = %q, want tls", got)
+	}
+
+	if got := p.stats.GetSnapshot().Requests; got != 1 {
+		t.Errorf("Requests = %d, want 1", got)
+	}
+}
+
+// TestHTTPServerProxyBadGateway 测试目标服务不可达时返回502并记录错误
+func TestHTTPServerProxyBadGateway(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	target := l.Addr().String()
+	l.Close()
+
+	p := newTestHTTPServerProxy(t, &config.InstanceConfig{Name: "web", Type: TypeHTTPServer, Target: target})
+	front := httptest.NewServer(p.newHandler())
+	defer front.Close()
+
+	resp, err := http.Get(front.URL)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+	if got := p.stats.GetSnapshot().Errors; got != 1 {
+		t.Errorf("Errors = %d, want 1", got)
+	}
+}
@@ -0,0 +1,465 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/logger"
+)
+
+const (
+	// ProxyProtocolV1 PROXY协议 v1 文本格式
+	ProxyProtocolV1 = "v1"
+	// ProxyProtocolV2 PROXY协议 v2 二进制格式
+	ProxyProtocolV2 = "v2"
+)
+
+// proxyV2Signature PROXY协议 v2 头部签名
+var proxyV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+const (
+	proxyV2CmdProxy   = 0x21 // 版本2，PROXY 命令
+	proxyV2FamUnspec  = 0x00
+	proxyV2FamTCP4    = 0x11
+	proxyV2FamTCP6    = 0x21
+	proxyV2MaxPayload = 0xFFFF
+)
+
+// PROXY协议 v2 TLV 类型
+const (
+	// PP2TypeAuthority 客户端请求的主机名（SNI）
+	PP2TypeAuthority = 0x02
+	// PP2TypeSSL 安全连接信息，值内嵌下列 PP2SubtypeSSL* 子TLV
+	PP2TypeSSL = 0x20
+	// PP2SubtypeSSLVersion 协议版本，如 "TLSv1.3"、"TLCPv1.1"
+	PP2SubtypeSSLVersion = 0x21
+	// PP2SubtypeSSLCN 客户端证书主题中的通用名称
+	PP2SubtypeSSLCN = 0x22
+	// PP2SubtypeSSLCipher 密码套件名称
+	PP2SubtypeSSLCipher = 0x23
+
+	// PP2TypeProtocol 协商的安全协议，"tlcp" 或 "tls"（自定义类型）
+	PP2TypeProtocol = 0xE0
+	// PP2TypeClientSubject 客户端签名证书主题（自定义类型）
+	PP2TypeClientSubject = 0xE1
+	// PP2TypeClientSerial 客户端签名证书序列号，16进制（自定义类型）
+	PP2TypeClientSerial = 0xE2
+)
+
+// PP2TypeSSL 中 client 字段的标志位
+const (
+	pp2ClientSSL      = 0x01
+	pp2ClientCertConn = 0x02
+)
+
+// writeProxyHeader 向目标服务写入PROXY协议头
+// 参数:
+//   - w: 目标服务连接
+//   - version: 协议版本，"v1" 或 "v2"
+//   - src: 客户端地址
+//   - dst: 代理接收连接的地址
+//   - info: 客户端连接的安全协商信息，可为nil，仅 v2 使用
+//
+// 返回:
+//   - error: 版本无效或写入失败时返回错误
+func writeProxyHeader(w io.Writer, version string, src, dst net.Addr, info *SecurityInfo) error {
+	var header []byte
+	switch version {
+	case ProxyProtocolV1:
+		header = proxyHeaderV1(src, dst)
+	case ProxyProtocolV2:
+		var err error
+		if header, err = proxyHeaderV2(src, dst, info); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("无效的PROXY协议版本: %s", version)
+	}
+	_, err := w.Write(header)
+	return err
+}
+
+// WriteProxyLocalHeader 写入 v2 LOCAL 命令头，用于本机发起的健康检查连接
+// 注意: 接收方保留连接的原始地址，连接仍需来自可信上游
+func WriteProxyLocalHeader(w io.Writer) error {
+	header := append(append([]byte{}, proxyV2Signature...), proxyV2CmdLocal, proxyV2FamUnspec, 0, 0)
+	_, err := w.Write(header)
+	return err
+}
+
+// tcpAddrs 获取 TCP 地址对，IPv4 地址统一为4字节形式
+// 返回:
+//   - bool: 两个地址均为同一地址族的 TCP 地址时返回 true
+func tcpAddrs(src, dst net.Addr) (*net.TCPAddr, *net.TCPAddr, bool) {
+	s, ok1 := src.(*net.TCPAddr)
+	d, ok2 := dst.(*net.TCPAddr)
+	if !ok1 || !ok2 {
+		return nil, nil, false
+	}
+	s4, d4 := s.IP.To4(), d.IP.To4()
+	if (s4 == nil) != (d4 == nil) {
+		return nil, nil, false
+	}
+	if s4 != nil {
+		return &net.TCPAddr{IP: s4, Port: s.Port}, &net.TCPAddr{IP: d4, Port: d.Port}, true
+	}
+	return s, d, true
+}
+
+// proxyHeaderV1 生成 v1 文本格式头部，无法表示的地址使用 UNKNOWN
+func proxyHeaderV1(src, dst net.Addr) []byte {
+	s, d, ok := tcpAddrs(src, dst)
+	if !ok {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+	family := "TCP4"
+	if len(s.IP) == net.IPv6len {
+		family = "TCP6"
+	}
+	return fmt.Appendf(nil, "PROXY %s %s %s %d %d\r\n", family, s.IP, d.IP, s.Port, d.Port)
+}
+
+// proxyHeaderV2 生成 v2 二进制格式头部
+// 注意: 无法表示的地址使用 UNSPEC 地址族，TLV 仍然携带
+func proxyHeaderV2(src, dst net.Addr, info *SecurityInfo) ([]byte, error) {
+	var payload bytes.Buffer
+	family := byte(proxyV2FamUnspec)
+	if s, d, ok := tcpAddrs(src, dst); ok {
+		family = proxyV2FamTCP4
+		if len(s.IP) == net.IPv6len {
+			family = proxyV2FamTCP6
+		}
+		payload.Write(s.IP)
+		payload.Write(d.IP)
+		binary.Write(&payload, binary.BigEndian, uint16(s.Port))
+		binary.Write(&payload, binary.BigEndian, uint16(d.Port))
+	}
+
+	if info != nil {
+		if info.ServerName != "" {
+			appendTLV(&payload, PP2TypeAuthority, []byte(info.ServerName))
+		}
+		appendTLV(&payload, PP2TypeSSL, sslTLVValue(info))
+		appendTLV(&payload, PP2TypeProtocol, []byte(info.Protocol))
+		if info.PeerSubject != "" {
+			appendTLV(&payload, PP2TypeClientSubject, []byte(info.PeerSubject))
+			appendTLV(&payload, PP2TypeClientSerial, []byte(info.PeerSerial))
+		}
+	}
+
+	if payload.Len() > proxyV2MaxPayload {
+		return nil, fmt.Errorf("PROXY协议头过长: %d 字节", payload.Len())
+	}
+
+	header := make([]byte, 0, 16+payload.Len())
+	header = append(header, proxyV2Signature...)
+	header = append(header, proxyV2CmdProxy, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(payload.Len()))
+	return append(header, payload.Bytes()...), nil
+}
+
+// sslTLVValue 生成 PP2TypeSSL 的值：client 标志、verify 结果及子TLV
+func sslTLVValue(info *SecurityInfo) []byte {
+	var value bytes.Buffer
+	client := byte(pp2ClientSSL)
+	if info.PeerSubject != "" {
+		client |= pp2ClientCertConn
+	}
+	value.WriteByte(client)
+
+	// verify 为0表示客户端提供了证书且验证通过
+	var verify uint32 = 1
+	if info.PeerVerified {
+		verify = 0
+	}
+	binary.Write(&value, binary.BigEndian, verify)
+
+	appendTLV(&value, PP2SubtypeSSLVersion, []byte(protocolVersionName(info.Protocol, info.Version)))
+	if info.PeerCommonName != "" {
+		appendTLV(&value, PP2SubtypeSSLCN, []byte(info.PeerCommonName))
+	}
+	if info.CipherSuite != "" {
+		appendTLV(&value, PP2SubtypeSSLCipher, []byte(info.CipherSuite))
+	}
+	return value.Bytes()
+}
+
+// appendTLV 追加一个TLV，超过长度上限的值被截断
+func appendTLV(buf *bytes.Buffer, typ byte, value []byte) {
+	if len(value) > proxyV2MaxPayload {
+		value = value[:proxyV2MaxPayload]
+	}
+	buf.WriteByte(typ)
+	binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	buf.Write(value)
+}
+
+// protocolVersionName 获取协议版本名称
+func protocolVersionName(protocol string, version uint16) string {
+	if protocol == ProtocolTLCP.String() {
+		return fmt.Sprintf("TLCPv%d.%d", version>>8, version&0xFF)
+	}
+	// 与 OpenSSL 的命名保持一致，如 "TLS 1.3" 转为 "TLSv1.3"
+	return strings.Replace(tls.VersionName(version), "TLS ", "TLSv", 1)
+}
+
+const (
+	proxyV1MaxLength       = 107 // v1 头部最大长度，包含结尾的 CRLF
+	proxyV2CmdLocal        = 0x20
+	defaultProxyHeaderWait = 10 * time.Second
+)
+
+// readProxyHeader 读取并解析PROXY协议头
+// 参数:
+//   - r: 连接的缓冲读取器，解析后剩余数据仍可从中读取
+//
+// 返回:
+//   - net.Addr: 客户端地址，LOCAL 命令或未知地址族时为 nil
+//   - net.Addr: 代理（负载均衡）接收连接的地址，同上
+//   - error: 缺少协议头或格式错误时返回错误
+func readProxyHeader(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	prefix, err := r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取PROXY协议头失败: %w", err)
+	}
+	switch {
+	case bytes.Equal(prefix, proxyV2Signature):
+		return readProxyHeaderV2(r)
+	case bytes.HasPrefix(prefix, []byte("PROXY ")):
+		return readProxyHeaderV1(r)
+	default:
+		return nil, nil, errors.New("缺少PROXY协议头")
+	}
+}
+
+func readProxyHeaderV1(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= proxyV1MaxLength {
+			return nil, nil, errors.New("PROXY协议 v1 头部过长")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取PROXY协议头失败: %w", err)
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("无效的PROXY协议 v1 头部: %q", line)
+	}
+	src, err := parseProxyV1Addr(fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := parseProxyV1Addr(fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, dst, nil
+}
+
+func parseProxyV1Addr(host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	p, err := strconv.ParseUint(port, 10, 16)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("无效的PROXY协议 v1 地址: %s %s", host, port)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+func readProxyHeaderV2(r *bufio.Reader) (net.Addr, net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("读取PROXY协议头失败: %w", err)
+	}
+	if header[12]>>4 != 2 {
+		return nil, nil, fmt.Errorf("不支持的PROXY协议版本: %d", header[12]>>4)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, fmt.Errorf("读取PROXY协议头失败: %w", err)
+	}
+
+	// LOCAL 命令由负载均衡自身发起（如健康检查），保留连接的原始地址
+	if header[12] == proxyV2CmdLocal {
+		return nil, nil, nil
+	}
+	if header[12] != proxyV2CmdProxy {
+		return nil, nil, fmt.Errorf("无效的PROXY协议命令: 0x%02x", header[12])
+	}
+
+	var ipLen int
+	switch header[13] {
+	case proxyV2FamTCP4:
+		ipLen = net.IPv4len
+	case proxyV2FamTCP6:
+		ipLen = net.IPv6len
+	default:
+		return nil, nil, nil
+	}
+	if len(payload) < 2*ipLen+4 {
+		return nil, nil, errors.New("PROXY协议 v2 地址长度不足")
+	}
+	ports := payload[2*ipLen:]
+	src := &net.TCPAddr{IP: net.IP(payload[:ipLen]), Port: int(binary.BigEndian.Uint16(ports[0:2]))}
+	dst := &net.TCPAddr{IP: net.IP(payload[ipLen : 2*ipLen]), Port: int(binary.BigEndian.Uint16(ports[2:4]))}
+	return src, dst, nil
+}
+
+// proxyProtoConn 携带PROXY协议头的连接
+// 首次读取或获取地址时解析协议头，之后 RemoteAddr/LocalAddr 返回协议头中的地址
+type proxyProtoConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	err    error
+	remote net.Addr
+	local  net.Addr
+
+	// readDeadline 调用方设置的读取截止时间，解析协议头后恢复
+	deadlineMu   sync.Mutex
+	readDeadline time.Time
+}
+
+// init 解析PROXY协议头，解析期间设置读取超时，防止上游不发送协议头时长期占用连接
+// 注意: 调用方已设置更早的截止时间（如握手超时）时使用调用方的截止时间，解析完成后恢复
+func (c *proxyProtoConn) init() error {
+	c.once.Do(func() {
+		c.deadlineMu.Lock()
+		deadline := time.Now().Add(c.timeout)
+		if !c.readDeadline.IsZero() && c.readDeadline.Before(deadline) {
+			deadline = c.readDeadline
+		}
+		c.Conn.SetReadDeadline(deadline)
+		c.deadlineMu.Unlock()
+
+		c.remote, c.local, c.err = readProxyHeader(c.reader)
+
+		c.deadlineMu.Lock()
+		c.Conn.SetReadDeadline(c.readDeadline)
+		c.deadlineMu.Unlock()
+	})
+	return c.err
+}
+
+func (c *proxyProtoConn) SetDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.readDeadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *proxyProtoConn) SetReadDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *proxyProtoConn) Read(b []byte) (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+	return c.reader.Read(b)
+}
+
+func (c *proxyProtoConn) RemoteAddr() net.Addr {
+	if c.init() == nil && c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyProtoConn) LocalAddr() net.Addr {
+	if c.init() == nil && c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// proxyProtoListener 接收携带PROXY协议头连接的监听器
+// 注意: Accept 不读取协议头，避免慢连接阻塞其他连接的接收，协议头在连接首次使用时解析
+type proxyProtoListener struct {
+	net.Listener
+	trusted []*net.IPNet
+	timeout time.Duration
+	logger  *logger.Logger
+}
+
+func (l *proxyProtoListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if !l.isTrusted(conn.RemoteAddr()) {
+			l.logger.Slog().Warn("拒绝来自非可信上游的连接: 不允许发送PROXY协议头", "remote", conn.RemoteAddr().String())
+			conn.Close()
+			continue
+		}
+		return &proxyProtoConn{Conn: conn, reader: bufio.NewReader(conn), timeout: l.timeout}, nil
+	}
+}
+
+// isTrusted 判断连接是否来自可信上游，未配置可信上游时拒绝所有连接
+func (l *proxyProtoListener) isTrusted(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range l.trusted {
+		if n.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptProxyProtocol 按实例配置包装监听器，启用 proxy-protocol.accept 时解析连接上的PROXY协议头
+// 参数:
+//   - l: 原始TCP监听器，须在 TLCP/TLS 监听器包装之前调用
+//   - cfg: 实例配置
+//
+// 返回:
+//   - net.Listener: 未启用时返回原监听器
+//   - error: 可信上游地址配置无效时返回错误
+func acceptProxyProtocol(l net.Listener, cfg *config.InstanceConfig) (net.Listener, error) {
+	if cfg.ProxyProtocol == nil || !cfg.ProxyProtocol.Accept {
+		return l, nil
+	}
+	trusted, err := config.ParseTrustedCIDRs(cfg.ProxyProtocol.Trusted)
+	if err != nil {
+		return nil, fmt.Errorf("PROXY协议可信上游配置错误: %w", err)
+	}
+	timeout := defaultProxyHeaderWait
+	if cfg.Timeout != nil && cfg.Timeout.Handshake > 0 {
+		timeout = cfg.Timeout.Handshake
+	}
+	return &proxyProtoListener{Listener: l, trusted: trusted, timeout: timeout, logger: logger.Instance(cfg.Name)}, nil
+}
+
+// readClientProxyHeader 解析客户端连接上的PROXY协议头，未启用时直接返回
+func readClientProxyHeader(conn net.Conn) error {
+	if c, ok := conn.(*proxyProtoConn); ok {
+		return c.init()
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+)
+
+// parseTLVs 解析 v2 头部中的 TLV，返回类型到值的映射
+func parseTLVs(t *testing.T, data []byte) map[byte][]byte {
+	t.Helper()
+	tlvs := make(map[byte][]byte)
+	for len(data) > 0 {
+		if len(data) < 3 {
+			t.Fatalf("TLV 长度不足: %x", data)
+		}
+		n := int(binary.BigEndian.Uint16(data[1:3]))
+		if len(data) < 3+n {
+			t.Fatalf("TLV 值长度不足: %x", data)
+		}
+		tlvs[data[0]] = data[3 : 3+n]
+		data = data[3+n:]
+	}
+	return tlvs
+}
+
+func TestProxyHeaderV1(t *testing.T) {
+	tests := []struct {
+		name string
+		src  net.Addr
+		dst  net.Addr
+		want string
+	}{
+		{
+			"IPv4",
+			&net.TCPAddr{IP: net.ParseIP("192.168.1.10"), Port: 50000},
+			&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443},
+			"PROXY TCP4 192.168.1.10 10.0.0.1 50000 443\r\n",
+		},
+		{
+			"IPv6",
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 50000},
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+			"PROXY TCP6 2001:db8::1 2001:db8::2 50000 443\r\n",
+		},
+		{
+			"地址族不一致",
+			&net.TCPAddr{IP: net.ParseIP("192.168.1.10"), Port: 50000},
+			&net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443},
+			"PROXY UNKNOWN\r\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeProxyHeader(&buf, ProxyProtocolV1, tt.src, tt.dst, nil); err != nil {
+				t.Fatalf("writeProxyHeader() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeProxyHeader() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyHeaderV2(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("192.168.1.10"), Port: 50000}
+	dst := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443}
+	info := &SecurityInfo{
+		Protocol:       ProtocolTLCP.String(),
+		Version:        0x0101,
+		CipherSuite:    "ECC_SM4_GCM_SM3",
+		ServerName:     "gm.example.com",
+		PeerSubject:    "CN=client,O=test",
+		PeerSerial:     "1a2b",
+		PeerCommonName: "client",
+		PeerVerified:   true,
+	}
+
+	var buf bytes.Buffer
+	if err := writeProxyHeader(&buf, ProxyProtocolV2, src, dst, info); err != nil {
+		t.Fatalf("writeProxyHeader() error = %v", err)
+	}
+	header := buf.Bytes()
+
+	if !bytes.Equal(header[:12], proxyV2Signature) {
+		t.Fatalf("头部签名错误: %x", header[:12])
+	}
+	if header[12] != proxyV2CmdProxy || header[13] != proxyV2FamTCP4 {
+		t.Errorf("命令/地址族错误: %x %x", header[12], header[13])
+	}
+	length := int(binary.BigEndian.Uint16(header[14:16]))
+	if length != len(header)-16 {
+		t.Fatalf("长度字段 %d 与实际负载 %d 不一致", length, len(header)-16)
+	}
+
+	addrs := header[16:28]
+	if !net.IP(addrs[0:4]).Equal(src.IP) || !net.IP(addrs[4:8]).Equal(dst.IP) {
+		t.Errorf("地址错误: %x", addrs)
+	}
+	if binary.BigEndian.Uint16(addrs[8:10]) != 50000 || binary.BigEndian.Uint16(addrs[10:12]) != 443 {
+		t.Errorf("端口错误: %x", addrs[8:12])
+	}
+
+	tlvs := parseTLVs(t, header[28:])
+	wants := map[byte]string{
+		PP2TypeAuthority:     "gm.example.com",
+		PP2TypeProtocol:      "tlcp",
+		PP2TypeClientSubject: "CN=client,O=test",
+		PP2TypeClientSerial:  "1a2b",
+	}
+	for typ, want := range wants {
+		if got := string(tlvs[typ]); got != want {
+			t.Errorf("TLV 0x%02x = %q, want %q", typ, got, want)
+		}
+	}
+
+	ssl, ok := tlvs[PP2TypeSSL]
+	if !ok || len(ssl) < 5 {
+		t.Fatalf("缺少 SSL TLV: %x", ssl)
+	}
+	if ssl[0] != pp2ClientSSL|pp2ClientCertConn {
+		t.Errorf("SSL client 标志 = %x", ssl[0])
+	}
+	if binary.BigEndian.Uint32(ssl[1:5]) != 0 {
+		t.Errorf("客户端证书已验证时 verify 应为0")
+	}
+	sub := parseTLVs(t, ssl[5:])
+	subWants := map[byte]string{
+		PP2SubtypeSSLVersion: "TLCPv1.1",
+		PP2SubtypeSSLCN:      "client",
+		PP2SubtypeSSLCipher:  "ECC_SM4_GCM_SM3",
+	}
+	for typ, want := range subWants {
+		if got := string(sub[typ]); got != want {
+			t.Errorf("SSL 子TLV 0x%02x = %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestProxyHeaderV2WithoutClientCert(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 50000}
+	dst := &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 443}
+	info := &SecurityInfo{Protocol: ProtocolTLS.String(), Version: 0x0304}
+
+	header, err := proxyHeaderV2(src, dst, info)
+	if err != nil {
+		t.Fatalf("proxyHeaderV2() error = %v", err)
+	}
+	if header[13] != proxyV2FamTCP6 {
+		t.Errorf("地址族应为 TCP6, 实际为 %x", header[13])
+	}
+
+	tlvs := parseTLVs(t, header[16+36:])
+	if _, ok := tlvs[PP2TypeClientSubject]; ok {
+		t.Error("未提供客户端证书时不应携带证书主题")
+	}
+	ssl := tlvs[PP2TypeSSL]
+	if ssl[0] != pp2ClientSSL {
+		t.Errorf("SSL client 标志 = %x", ssl[0])
+	}
+	if got := string(parseTLVs(t, ssl[5:])[PP2SubtypeSSLVersion]); got != "TLSv1.3" {
+		t.Errorf("协议版本 = %q, want TLSv1.3", got)
+	}
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	src := &net.TCPAddr{IP: net.ParseIP("192.168.1.10").To4(), Port: 50000}
+	dst := &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 443}
+	info := &SecurityInfo{Protocol: ProtocolTLCP.String(), Version: 0x0101, PeerSubject: "CN=client"}
+
+	for _, version := range []string{ProxyProtocolV1, ProxyProtocolV2} {
+		t.Run(version, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeProxyHeader(&buf, version, src, dst, info); err != nil {
+				t.Fatalf("writeProxyHeader() error = %v", err)
+			}
+			buf.WriteString("payload")
+
+			r := bufio.NewReader(&buf)
+			gotSrc, gotDst, err := readProxyHeader(r)
+			if err != nil {
+				t.Fatalf("readProxyHeader() error = %v", err)
+			}
+			if gotSrc.String() != src.String() || gotDst.String() != dst.String() {
+				t.Errorf("地址 = %v -> %v, want %v -> %v", gotSrc, gotDst, src, dst)
+			}
+			if rest, _ := io.ReadAll(r); string(rest) != "payload" {
+				t.Errorf("协议头之后的数据 = %q, want payload", rest)
+			}
+		})
+	}
+
+	t.Run("LOCAL命令", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteProxyLocalHeader(&buf); err != nil {
+			t.Fatalf("写入 LOCAL 命令头失败: %v", err)
+		}
+		gotSrc, gotDst, err := readProxyHeader(bufio.NewReader(&buf))
+		if err != nil || gotSrc != nil || gotDst != nil {
+			t.Errorf("LOCAL 命令应保留原始地址, got %v %v %v", gotSrc, gotDst, err)
+		}
+	})
+
+	t.Run("缺少协议头", func(t *testing.T) {
+		data := []byte{0x16, 0x01, 0x01, 0x00, 0x20, 0x01, 0x00, 0x00, 0x1c, 0x01, 0x01, 0x00}
+		if _, _, err := readProxyHeader(bufio.NewReader(bytes.NewReader(data))); err == nil {
+			t.Error("缺少PROXY协议头时应返回错误")
+		}
+	})
+}
+
+// acceptOne 在后台接收一个连接
+func acceptOne(l net.Listener) <-chan net.Conn {
+	ch := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			ch <- conn
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func TestProxyProtoListener(t *testing.T) {
+	newListener := func(t *testing.T, trusted ...string) net.Listener {
+		raw, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("监听失败: %v", err)
+		}
+		l, err := acceptProxyProtocol(raw, &config.InstanceConfig{
+			ProxyProtocol: &config.ProxyProtocolConfig{Accept: true, Trusted: trusted},
+		})
+		if err != nil {
+			t.Fatalf("acceptProxyProtocol() error = %v", err)
+		}
+		t.Cleanup(func() { l.Close() })
+		return l
+	}
+
+	t.Run("可信上游", func(t *testing.T) {
+		l := newListener(t, "127.0.0.0/8")
+		accepted := acceptOne(l)
+
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer client.Close()
+		realClient := &net.TCPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 40000}
+		if err := writeProxyHeader(client, ProxyProtocolV2, realClient, l.Addr(), nil); err != nil {
+			t.Fatalf("发送PROXY协议头失败: %v", err)
+		}
+		client.Write([]byte("hello"))
+
+		conn := <-accepted
+		if conn == nil {
+			t.Fatal("未接收到连接")
+		}
+		defer conn.Close()
+		if conn.RemoteAddr().String() != realClient.String() {
+			t.Errorf("RemoteAddr() = %v, want %v", conn.RemoteAddr(), realClient)
+		}
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "hello" {
+			t.Errorf("读取数据 = %q, %v, want hello", buf, err)
+		}
+	})
+
+	t.Run("未配置可信上游", func(t *testing.T) {
+		l := newListener(t)
+		acceptOne(l)
+
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer client.Close()
+		realClient := &net.TCPAddr{IP: net.ParseIP("203.0.113.7").To4(), Port: 40000}
+		writeProxyHeader(client, ProxyProtocolV2, realClient, l.Addr(), nil)
+		client.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("未配置可信上游时连接应被关闭, err = %v", err)
+		}
+	})
+
+	t.Run("非可信上游", func(t *testing.T) {
+		l := newListener(t, "10.0.0.0/8")
+		acceptOne(l)
+
+		client, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("连接失败: %v", err)
+		}
+		defer client.Close()
+		client.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err := client.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("非可信上游的连接应被关闭, err = %v", err)
+		}
+	})
+}
@@ -17,23 +17,37 @@ type ServerProxy struct {
 	cfg             *config.InstanceConfig
 	adapter         *TLCPAdapter
 	handler         *ConnHandler
+	balancer        *Balancer
 	listener        net.Listener
 	keyStoreManager *security.KeyStoreManager
 	rootCertManager *security.RootCertManager
 	stats           *stats.Collector
 	logger          *logger.Logger
 	shutdownChan    chan struct{}
+	conns           *connTracker
 	mu              sync.Mutex
 	running         bool
 }
 
+// NewServerProxy 创建服务端代理
+// 参数:
+//   - cfg: 实例配置
+//   - keyStoreMgr: 密钥存储管理器
+//   - rootCertMgr: 根证书管理器
+//   - collector: 实例统计收集器，为nil时创建汇总到默认收集器的独立收集器
 func NewServerProxy(cfg *config.InstanceConfig,
 	keyStoreMgr *security.KeyStoreManager,
-	rootCertMgr *security.RootCertManager) (*ServerProxy, error) {
+	rootCertMgr *security.RootCertManager,
+	collector *stats.Collector) (*ServerProxy, error) {
 	adapter, err := NewTLCPAdapter(keyStoreMgr, rootCertMgr)
 	if err != nil {
 		return nil, fmt.Errorf("创建协议适配器失败: %w", err)
 	}
+	adapter.logger = logger.Instance(cfg.Name)
+
+	if collector == nil {
+		collector = newInstanceCollector()
+	}
 
 	bufferSize := cfg.BufferSize
 	if bufferSize <= 0 {
@@ -43,12 +57,14 @@ func NewServerProxy(cfg *config.InstanceConfig,
 	proxy := &ServerProxy{
 		cfg:             cfg,
 		adapter:         adapter,
-		handler:         NewConnHandler(stats.DefaultCollector(), bufferSize),
+		handler:         NewConnHandler(collector, bufferSize),
+		balancer:        NewBalancer(cfg),
 		keyStoreManager: keyStoreMgr,
 		rootCertManager: rootCertMgr,
-		stats:           stats.DefaultCollector(),
-		logger:          logger.Default(),
+		stats:           collector,
+		logger:          logger.Instance(cfg.Name),
 		shutdownChan:    make(chan struct{}),
+		conns:           newConnTracker(),
 	}
 
 	if err := adapter.ReloadConfig(cfg); err != nil {
@@ -74,12 +90,20 @@ func (p *ServerProxy) Start() error {
 		p.mu.Unlock()
 		return fmt.Errorf("监听失败 %s: %w", p.cfg.Listen, err)
 	}
+	wrapped, err := acceptProxyProtocol(listener, p.cfg)
+	if err != nil {
+		listener.Close()
+		p.mu.Unlock()
+		return err
+	}
+	listener = wrapped
 
 	p.listener = p.adapter.WrapServerListener(listener)
 	p.running = true
+	p.conns.open()
 	p.mu.Unlock()
 
-	p.logger.Info("服务端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, p.cfg.Target, p.cfg.Protocol)
+	p.logger.Info("服务端代理启动: %s -> %s, 协议: %s", p.cfg.Listen, formatTargets(p.cfg), p.cfg.Protocol)
 
 	go p.acceptLoop()
 
@@ -87,9 +111,13 @@ func (p *ServerProxy) Start() error {
 }
 
 func (p *ServerProxy) acceptLoop() {
+	p.mu.Lock()
+	shutdown := p.shutdownChan
+	p.mu.Unlock()
+
 	for {
 		select {
-		case <-p.shutdownChan:
+		case <-shutdown:
 			return
 		default:
 		}
@@ -97,7 +125,7 @@ func (p *ServerProxy) acceptLoop() {
 		conn, err := p.listener.Accept()
 		if err != nil {
 			select {
-			case <-p.shutdownChan:
+			case <-shutdown:
 				return
 			default:
 				p.logger.Error("接受连接失败: %v", err)
@@ -105,53 +133,109 @@ func (p *ServerProxy) acceptLoop() {
 			}
 		}
 
-		p.stats.IncrementConnections()
-
 		go p.handleConnection(conn)
 	}
 }
 
 func (p *ServerProxy) handleConnection(clientConn net.Conn) {
-	defer p.stats.DecrementConnections()
 	defer clientConn.Close()
+
+	// 排空超时后通过 cancel 结束数据管道
+	connCtx, cancelConn := context.WithCancel(context.Background())
+	defer cancelConn()
+	tc, ok := p.conns.track(clientConn, cancelConn)
+	if !ok {
+		return
+	}
+	defer tc.release()
+
+	// 协商协议在握手后才能确定，握手完成前的异常计入 unknown
+	var cs *stats.Collector
 	defer func() {
 		if r := recover(); r != nil {
 			p.logger.Error("连接处理panic: %v", r)
-			p.stats.IncrementErrors()
+			if cs == nil {
+				cs = protocolStats(p.stats, "")
+			}
+			cs.IncrementErrors()
 		}
 	}()
 
 	start := time.Now()
-
-	timeout := 10 * time.Second
-	if p.cfg.Timeout != nil {
-		timeout = p.cfg.Timeout.Dial
+	timeout := p.adapter.getTimeoutConfig(p.cfg)
+
+	// 显式完成握手以确定协商协议，握手失败或超时时不再连接目标服务
+	handshaked, err := handshake(clientConn, timeout.Handshake)
+	handshakeLatency := time.Since(start)
+	cs = protocolStats(p.stats, connProtocol(clientConn))
+	cs.IncrementConnections()
+	defer cs.DecrementConnections()
+	tc.setRemote(clientConn.RemoteAddr())
+	tc.setProtocol(connProtocol(clientConn))
+	log := p.logger.Slog().With("conn", tc.id, "remote", clientConn.RemoteAddr().String())
+	if err != nil {
+		log.Warn("握手失败", "error", err)
+		cs.IncrementHandshakeFailures()
+		tc.finish(CloseHandshakeFailed, err)
+		return
+	}
+	if handshaked {
+		cs.RecordHandshakeLatency(handshakeLatency)
 	}
+	tc.setSecure(clientConn)
+
 	dialer := &net.Dialer{
-		Timeout: timeout,
+		Timeout: timeout.Dial,
 	}
-	targetConn, err := dialer.Dial("tcp", p.cfg.Target)
+	var dialStart time.Time
+	targetConn, target, err := p.currentBalancer().Dial(clientConn.RemoteAddr(), func(addr string) (net.Conn, error) {
+		dialStart = time.Now()
+		return dialer.Dial("tcp", addr)
+	})
 	if err != nil {
-		p.logger.Error("连接目标服务失败 %s: %v", p.cfg.Target, err)
-		p.stats.IncrementErrors()
+		log.Error("连接目标服务失败", "error", err)
+		cs.IncrementErrors()
+		tc.finish(CloseDialFailed, err)
 		return
 	}
 	defer targetConn.Close()
+	tc.setTarget(target)
+	cs.RecordDialLatency(time.Since(dialStart))
+	cs.RecordLatency(time.Since(start))
+
+	if pp := p.cfg.ProxyProtocol; pp != nil && pp.Send != "" {
+		err := writeProxyHeader(targetConn, pp.Send, clientConn.RemoteAddr(), clientConn.LocalAddr(), GetSecurityInfo(clientConn))
+		if err != nil {
+			log.Error("发送PROXY协议头失败", "target", target, "error", err)
+			cs.IncrementErrors()
+			tc.finish(CloseError, err)
+			return
+		}
+	}
 
-	p.logger.Debug("连接建立: %s <-> %s", clientConn.RemoteAddr(), p.cfg.Target)
+	log.Debug("连接建立", "target", target)
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := sessionContext(connCtx, timeout)
 	defer cancel()
 
-	received, sent, err := p.handler.Pipe(ctx, clientConn, targetConn)
+	sessionStart := time.Now()
+	received, sent, err := p.handler.withStats(cs).withTimeouts(timeout).Pipe(ctx, tc.meter(clientConn), targetConn)
 	if err != nil {
-		p.logger.Debug("连接关闭: %v", err)
+		log.Debug("连接关闭", "error", err)
 	}
+	tc.finish(pipeCloseReason(ctx, err), err)
 
-	latency := time.Since(start)
-	p.stats.RecordLatency(latency)
+	duration := time.Since(sessionStart)
+	cs.RecordSessionDuration(duration)
 
-	p.logger.Debug("连接结束: 收发 %d/%d 字节, 耗时 %v", received, sent, latency)
+	log.Debug("连接结束", "bytesIn", received, "bytesOut", sent, "durationMs", duration.Milliseconds())
+}
+
+// currentBalancer 获取当前配置对应的负载均衡器
+func (p *ServerProxy) currentBalancer() *Balancer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.balancer
 }
 
 func (p *ServerProxy) Stop() error {
@@ -176,6 +260,56 @@ func (p *ServerProxy) Stop() error {
 	return nil
 }
 
+// Drain 排空代理：停止接收新连接，等待活跃连接在宽限期内结束，超时后强制关闭剩余连接
+// 参数:
+//   - grace: 宽限期，<=0 时立即强制关闭所有连接
+//
+// 返回:
+//   - *DrainResult: 排空结果
+func (p *ServerProxy) Drain(grace time.Duration) *DrainResult {
+	p.logger.Info("排空服务端代理: %s, 宽限期 %v", p.cfg.Name, grace)
+	if err := p.Stop(); err != nil {
+		p.logger.Error("停止服务端代理失败: %v", err)
+	}
+	return p.conns.drain(grace, p.conns.wait)
+}
+
+// ActiveConnections 获取活跃连接数，包括停止监听后仍未结束的连接
+func (p *ServerProxy) ActiveConnections() int {
+	return p.conns.count()
+}
+
+// InheritConnections 接管被替换代理的活跃连接，用于以新配置重启实例
+// 参数:
+//   - old: 被替换的代理，已停止监听
+//
+// 注意: 需在 Start 之前调用；旧代理停止监听后仍在转发的连接可通过新代理查询、强制关闭和排空
+func (p *ServerProxy) InheritConnections(old *ServerProxy) {
+	p.conns = old.conns
+}
+
+// SetAccessLogger 设置连接访问日志
+// 参数:
+//   - l: 访问日志，为nil时不记录
+func (p *ServerProxy) SetAccessLogger(l *AccessLogger) {
+	p.conns.access.Store(l)
+}
+
+// Connections 获取所有活跃连接的信息，按建立时间排序
+func (p *ServerProxy) Connections() []*ConnInfo {
+	return p.conns.list()
+}
+
+// CloseConnection 强制关闭指定的活跃连接
+// 参数:
+//   - id: 连接编号
+//
+// 返回:
+//   - bool: 连接不存在或已结束时返回 false
+func (p *ServerProxy) CloseConnection(id string) bool {
+	return p.conns.kill(id)
+}
+
 func (p *ServerProxy) Restart() error {
 	if err := p.Stop(); err != nil {
 		return err
@@ -214,6 +348,8 @@ func (p *ServerProxy) Reload(cfg *config.InstanceConfig) error {
 		return err
 	}
 
+	p.balancer.Update(cfg)
+
 	p.logger.Info("服务端代理配置热重载成功: %s", p.cfg.Name)
 	return nil
 }
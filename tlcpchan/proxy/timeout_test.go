@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/config"
+	"github.com/Trisia/tlcpchan/stats"
+)
+
+// tcpPair 创建一对相互连接的TCP连接
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	defer l.Close()
+
+	accepted := acceptOne(l)
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	server := <-accepted
+	if server == nil {
+		t.Fatal("未接收到连接")
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client, server
+}
+
+// runPipe 在后台运行 Pipe 并返回结束信号
+func runPipe(h *ConnHandler, timeout *config.TimeoutConfig, clientConn, targetConn net.Conn) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := sessionContext(context.Background(), timeout)
+		defer cancel()
+		_, _, err := h.withTimeouts(timeout).Pipe(ctx, clientConn, targetConn)
+		done <- err
+	}()
+	return done
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	_, server := tcpPair(t)
+
+	// 客户端不发送 ClientHello，握手应在超时后失败
+	conn := tls.Server(server, &tls.Config{})
+	start := time.Now()
+	handshaked, err := handshake(conn, 100*time.Millisecond)
+	if !handshaked || err == nil {
+		t.Fatalf("握手应超时失败, handshaked = %v, err = %v", handshaked, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("握手超时未生效, 耗时 %v", elapsed)
+	}
+}
+
+func TestPipeIdleTimeout(t *testing.T) {
+	h := NewConnHandler(stats.NewCollector(10), 4096)
+	timeout := &config.TimeoutConfig{Read: 100 * time.Millisecond, Write: time.Second}
+
+	t.Run("两个方向均空闲", func(t *testing.T) {
+		userConn, clientConn := tcpPair(t)
+		targetConn, backendConn := tcpPair(t)
+		_, _ = userConn, backendConn
+
+		select {
+		case err := <-runPipe(h, timeout, clientConn, targetConn):
+			if !isTimeout(err) {
+				t.Errorf("空闲超时应返回超时错误, err = %v", err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("连接空闲超过读取超时后应关闭")
+		}
+	})
+
+	t.Run("单向传输", func(t *testing.T) {
+		userConn, clientConn := tcpPair(t)
+		targetConn, backendConn := tcpPair(t)
+		done := runPipe(h, timeout, clientConn, targetConn)
+
+		// 客户端不发送数据，目标持续发送超过读取超时的时长，连接不应被关闭
+		go io.Copy(io.Discard, userConn)
+		for i := 0; i < 10; i++ {
+			if _, err := backendConn.Write([]byte("data")); err != nil {
+				t.Fatalf("写入失败: %v", err)
+			}
+			time.Sleep(30 * time.Millisecond)
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("仍有数据传输时连接不应关闭, err = %v", err)
+		default:
+		}
+		backendConn.Close()
+		userConn.Close()
+		<-done
+	})
+}
+
+func TestPipeMaxSession(t *testing.T) {
+	h := NewConnHandler(stats.NewCollector(10), 4096)
+	timeout := &config.TimeoutConfig{MaxSession: 100 * time.Millisecond}
+
+	userConn, clientConn := tcpPair(t)
+	targetConn, backendConn := tcpPair(t)
+	_, _ = userConn, backendConn
+
+	select {
+	case <-runPipe(h, timeout, clientConn, targetConn):
+	case <-time.After(3 * time.Second):
+		t.Fatal("超过最长会话时长后连接应关闭")
+	}
+}
+
+func TestProxyProtoConnKeepsDeadline(t *testing.T) {
+	_, server := tcpPair(t)
+	conn := &proxyProtoConn{Conn: server, reader: bufio.NewReader(server), timeout: 10 * time.Second}
+
+	// 调用方设置的截止时间早于协议头等待时间，应以调用方为准
+	conn.SetDeadline(time.Now().Add(100 * time.Millisecond))
+	start := time.Now()
+	if _, err := conn.Read(make([]byte, 1)); !isTimeout(err) {
+		t.Errorf("应返回超时错误, err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("调用方设置的截止时间未生效, 耗时 %v", elapsed)
+	}
+}
@@ -0,0 +1,340 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnInfo 活跃连接信息
+type ConnInfo struct {
+	// ID 连接编号，实例内唯一
+	ID string `json:"id"`
+	// RemoteAddr 客户端地址，启用 PROXY 协议接收时为上游传递的真实地址，读取PROXY协议头或握手完成前为空
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	// Target 转发的目标地址，连接目标前为空
+	Target string `json:"target,omitempty"`
+	// Protocol 协商的协议，"tlcp" 或 "tls"，握手完成前为空
+	Protocol string `json:"protocol,omitempty"`
+	// Version 协议版本，如 "TLCPv1.1"、"TLSv1.3"
+	Version string `json:"version,omitempty"`
+	// CipherSuite 密码套件名称
+	CipherSuite string `json:"cipherSuite,omitempty"`
+	// ServerName SNI名称
+	ServerName string `json:"serverName,omitempty"`
+	// PeerSubject 对端证书主题，服务端实例为客户端证书，客户端实例为目标服务证书
+	PeerSubject string `json:"peerSubject,omitempty"`
+	// StartTime 连接建立时间
+	StartTime time.Time `json:"startTime"`
+	// BytesReceived 写回客户端的字节数
+	BytesReceived int64 `json:"bytesReceived"`
+	// BytesSent 从客户端读取并转发的字节数
+	BytesSent int64 `json:"bytesSent"`
+}
+
+// trackedConn 被跟踪的活跃连接
+type trackedConn struct {
+	seq     uint64
+	id      string
+	conn    net.Conn
+	cancel  context.CancelFunc
+	start   time.Time
+	tracker *connTracker
+
+	received atomic.Int64
+	sent     atomic.Int64
+
+	// 以下字段由 connTracker.mu 保护
+	remote string
+	target string
+	// secure 用于获取安全协商信息的连接，服务端实例为客户端连接，客户端实例为目标连接
+	secure net.Conn
+	// protocol 握手前根据连接类型判断的协议，握手失败时用于访问日志
+	protocol string
+	// reason 关闭原因，首次记录后不再改变
+	reason string
+	err    error
+}
+
+// release 连接结束时从跟踪器中注销并写入访问日志，可重复调用
+func (tc *trackedConn) release() {
+	if tc.tracker.remove(tc) {
+		tc.tracker.logAccess(tc)
+	}
+}
+
+// finish 记录连接的关闭原因，只保留首次记录的原因
+// 参数:
+//   - reason: 关闭原因，见 Close* 常量
+//   - err: 导致关闭的错误，可为nil
+func (tc *trackedConn) finish(reason string, err error) {
+	tc.tracker.mu.Lock()
+	defer tc.tracker.mu.Unlock()
+	if tc.reason == "" {
+		tc.reason = reason
+		tc.err = err
+	}
+}
+
+// close 结束连接的数据管道并关闭客户端连接
+// 参数:
+//   - reason: 关闭原因，见 Close* 常量
+func (tc *trackedConn) close(reason string) {
+	tc.finish(reason, nil)
+	if tc.cancel != nil {
+		tc.cancel()
+	}
+	tc.conn.Close()
+}
+
+// setRemote 记录客户端地址
+// 注意: 启用PROXY协议接收时，连接的 RemoteAddr 会阻塞到协议头读取完成，应在读取协议头或握手完成后调用
+func (tc *trackedConn) setRemote(addr net.Addr) {
+	tc.tracker.mu.Lock()
+	defer tc.tracker.mu.Unlock()
+	tc.remote = addr.String()
+}
+
+// setTarget 记录连接转发的目标地址
+func (tc *trackedConn) setTarget(target string) {
+	tc.tracker.mu.Lock()
+	defer tc.tracker.mu.Unlock()
+	tc.target = target
+}
+
+// setProtocol 记录根据连接类型判断的协议
+func (tc *trackedConn) setProtocol(protocol string) {
+	tc.tracker.mu.Lock()
+	defer tc.tracker.mu.Unlock()
+	tc.protocol = protocol
+}
+
+// setSecure 记录用于获取安全协商信息的连接
+// 注意: 握手未完成时获取连接状态会阻塞到握手结束，应在握手完成后调用
+func (tc *trackedConn) setSecure(conn net.Conn) {
+	tc.tracker.mu.Lock()
+	defer tc.tracker.mu.Unlock()
+	tc.secure = conn
+}
+
+// meter 包装客户端连接，读写字节数计入连接的实时计数
+func (tc *trackedConn) meter(conn net.Conn) net.Conn {
+	return &meteredConn{Conn: conn, tc: tc}
+}
+
+// meteredConn 统计单个连接读写字节数的连接包装
+type meteredConn struct {
+	net.Conn
+	tc *trackedConn
+}
+
+func (c *meteredConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.tc.sent.Add(int64(n))
+	return n, err
+}
+
+func (c *meteredConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.tc.received.Add(int64(n))
+	return n, err
+}
+
+// connTracker 跟踪代理的活跃连接，用于查询、关闭单个连接、排空以及写入访问日志
+type connTracker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	conns   map[string]*trackedConn
+	closed  bool
+	changed chan struct{}
+	// access 访问日志，为nil时不记录
+	access atomic.Pointer[AccessLogger]
+}
+
+func newConnTracker() *connTracker {
+	return &connTracker{
+		conns:   make(map[string]*trackedConn),
+		changed: make(chan struct{}),
+	}
+}
+
+// track 登记活跃连接
+// 参数:
+//   - conn: 客户端连接
+//   - cancel: 强制关闭时调用，用于结束连接的数据管道，可为nil
+//
+// 返回:
+//   - *trackedConn: 连接结束时调用其 release 注销
+//   - bool: 代理已排空时返回 false，调用方应直接关闭连接
+func (t *connTracker) track(conn net.Conn, cancel context.CancelFunc) (*trackedConn, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, false
+	}
+	t.nextID++
+	tc := &trackedConn{
+		seq:     t.nextID,
+		id:      strconv.FormatUint(t.nextID, 10),
+		conn:    conn,
+		cancel:  cancel,
+		start:   time.Now(),
+		tracker: t,
+	}
+	t.conns[tc.id] = tc
+	return tc, true
+}
+
+// remove 注销活跃连接
+// 返回:
+//   - bool: 连接已注销时返回 false
+func (t *connTracker) remove(tc *trackedConn) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conns[tc.id] != tc {
+		return false
+	}
+	delete(t.conns, tc.id)
+	close(t.changed)
+	t.changed = make(chan struct{})
+	return true
+}
+
+// logAccess 为已结束的连接写入访问日志
+func (t *connTracker) logAccess(tc *trackedConn) {
+	access := t.access.Load()
+	if access == nil {
+		return
+	}
+
+	t.mu.Lock()
+	r := &AccessRecord{
+		ConnID:      tc.id,
+		Client:      tc.remote,
+		Target:      tc.target,
+		Protocol:    tc.protocol,
+		CloseReason: tc.reason,
+	}
+	secure, err := tc.secure, tc.err
+	t.mu.Unlock()
+
+	r.Time = time.Now()
+	r.DurationMs = r.Time.Sub(tc.start).Milliseconds()
+	r.BytesIn = tc.sent.Load()
+	r.BytesOut = tc.received.Load()
+	if r.CloseReason == "" {
+		r.CloseReason = CloseNormal
+	}
+	if sec := GetSecurityInfo(secure); sec != nil {
+		r.Protocol = sec.Protocol
+		r.Version = protocolVersionName(sec.Protocol, sec.Version)
+		r.CipherSuite = sec.CipherSuite
+		r.ServerName = sec.ServerName
+		r.PeerSubject = sec.PeerSubject
+		r.PeerSerial = sec.PeerSerial
+	}
+	r.setError(err)
+	access.Log(r)
+}
+
+// count 获取活跃连接数
+func (t *connTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.conns)
+}
+
+// open 代理重新启动后允许登记新连接
+func (t *connTracker) open() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = false
+}
+
+// list 获取所有活跃连接的信息，按建立时间排序
+func (t *connTracker) list() []*ConnInfo {
+	t.mu.Lock()
+	conns := make([]*trackedConn, 0, len(t.conns))
+	for _, tc := range t.conns {
+		conns = append(conns, tc)
+	}
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].seq < conns[j].seq
+	})
+	infos := make([]*ConnInfo, len(conns))
+	secures := make([]net.Conn, len(conns))
+	for i, tc := range conns {
+		infos[i] = &ConnInfo{ID: tc.id, RemoteAddr: tc.remote, Target: tc.target, StartTime: tc.start}
+		secures[i] = tc.secure
+	}
+	t.mu.Unlock()
+
+	for i, tc := range conns {
+		info := infos[i]
+		info.BytesReceived = tc.received.Load()
+		info.BytesSent = tc.sent.Load()
+		if sec := GetSecurityInfo(secures[i]); sec != nil {
+			info.Protocol = sec.Protocol
+			info.Version = protocolVersionName(sec.Protocol, sec.Version)
+			info.CipherSuite = sec.CipherSuite
+			info.ServerName = sec.ServerName
+			info.PeerSubject = sec.PeerSubject
+		}
+	}
+	return infos
+}
+
+// kill 强制关闭指定连接
+// 返回:
+//   - bool: 连接不存在时返回 false
+func (t *connTracker) kill(id string) bool {
+	t.mu.Lock()
+	tc, ok := t.conns[id]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+	tc.close(CloseKilled)
+	return true
+}
+
+// wait 等待所有活跃连接结束
+// 返回:
+//   - error: ctx 结束前仍有活跃连接时返回 ctx 的错误
+func (t *connTracker) wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		n, changed := len(t.conns), t.changed
+		t.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// closeAll 强制关闭所有活跃连接，之后不再登记新连接，直到调用 open
+// 返回:
+//   - int: 被关闭的连接数
+func (t *connTracker) closeAll() int {
+	t.mu.Lock()
+	t.closed = true
+	conns := make([]*trackedConn, 0, len(t.conns))
+	for _, tc := range t.conns {
+		conns = append(conns, tc)
+	}
+	t.mu.Unlock()
+
+	for _, tc := range conns {
+		tc.close(CloseDrained)
+	}
+	return len(conns)
+}
@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConnTrackerList(t *testing.T) {
+	// 借用 httptest 的自签名证书完成一次 TLS 握手
+	srv := httptest.NewUnstartedServer(nil)
+	srv.StartTLS()
+	defer srv.Close()
+
+	userConn, rawConn := tcpPair(t)
+	clientConn := tls.Server(rawConn, &tls.Config{Certificates: srv.TLS.Certificates})
+	user := tls.Client(userConn, &tls.Config{InsecureSkipVerify: true, ServerName: "gm.example.com"})
+	errCh := make(chan error, 1)
+	go func() { errCh <- user.Handshake() }()
+	if err := clientConn.Handshake(); err != nil {
+		t.Fatalf("握手失败: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("客户端握手失败: %v", err)
+	}
+
+	tracker := newConnTracker()
+	tc, _ := tracker.track(clientConn, nil)
+	defer tc.release()
+	tc.setRemote(clientConn.RemoteAddr())
+	tc.setTarget("127.0.0.1:8080")
+	tc.setSecure(clientConn)
+
+	// 模拟 Pipe 读写客户端连接
+	metered := tc.meter(clientConn)
+	go user.Write([]byte("hello"))
+	if _, err := io.ReadFull(metered, make([]byte, 5)); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	go io.ReadFull(user, make([]byte, 3))
+	if _, err := metered.Write([]byte("abc")); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	conns := tracker.list()
+	if len(conns) != 1 {
+		t.Fatalf("活跃连接数应为 1, 实际为 %d", len(conns))
+	}
+	c := conns[0]
+	if c.ID != tc.id || c.RemoteAddr != clientConn.RemoteAddr().String() || c.Target != "127.0.0.1:8080" {
+		t.Errorf("连接地址信息错误: %+v", c)
+	}
+	if c.Protocol != "tls" || c.Version != "TLSv1.3" || c.CipherSuite == "" || c.ServerName != "gm.example.com" {
+		t.Errorf("安全协商信息错误: %+v", c)
+	}
+	if c.BytesSent != 5 || c.BytesReceived != 3 {
+		t.Errorf("收发字节数 = %d/%d, want 3/5", c.BytesReceived, c.BytesSent)
+	}
+	if time.Since(c.StartTime) > time.Minute {
+		t.Errorf("建立时间错误: %v", c.StartTime)
+	}
+}
+
+func TestConnTrackerKill(t *testing.T) {
+	tracker := newConnTracker()
+	first := trackPipe(t, tracker)
+	second := trackPipe(t, tracker)
+
+	conns := tracker.list()
+	if len(conns) != 2 {
+		t.Fatalf("活跃连接数应为 2, 实际为 %d", len(conns))
+	}
+	if tracker.kill("not-exist") {
+		t.Error("关闭不存在的连接应返回 false")
+	}
+	if !tracker.kill(conns[0].ID) {
+		t.Fatal("关闭连接失败")
+	}
+
+	select {
+	case <-first:
+	case <-time.After(3 * time.Second):
+		t.Fatal("关闭后数据管道应结束")
+	}
+	select {
+	case err := <-second:
+		t.Fatalf("未关闭的连接不应结束, err = %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if n := tracker.count(); n != 1 {
+		t.Errorf("活跃连接数应为 1, 实际为 %d", n)
+	}
+}
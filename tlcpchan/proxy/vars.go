@@ -30,6 +30,10 @@ type Variables struct {
 	Protocol string
 	// InstanceName 实例名称
 	InstanceName string
+	// ClientSubject 客户端证书主题，未提供客户端证书时为空
+	ClientSubject string
+	// ClientSerial 客户端证书序列号（16进制），未提供客户端证书时为空
+	ClientSerial string
 }
 
 func ExtractVariables(remoteAddr, serverAddr, targetAddr, protocol, instanceName string) *Variables {
@@ -89,6 +93,8 @@ func (v *Variables) Replace(s string) string {
 	s = strings.ReplaceAll(s, "$target_port", v.TargetPort)
 	s = strings.ReplaceAll(s, "$protocol", v.Protocol)
 	s = strings.ReplaceAll(s, "$instance", v.InstanceName)
+	s = strings.ReplaceAll(s, "$client_subject", v.ClientSubject)
+	s = strings.ReplaceAll(s, "$client_serial", v.ClientSerial)
 	return s
 }
 
@@ -0,0 +1,477 @@
+package ca
+
+import (
+	"archive/zip"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// DefaultDays 签发证书的默认有效期（天）
+const DefaultDays = 365
+
+// RecordFile 签发记录文件名
+const RecordFile = "issued.json"
+
+// 签发方式
+const (
+	// KindCSR 签发上传的证书请求
+	KindCSR = "csr"
+	// KindClient 生成客户端证书及密钥
+	KindClient = "client"
+)
+
+// 证书用途
+const (
+	// UsageSign TLCP 签名证书
+	UsageSign = "sign"
+	// UsageEnc TLCP 加密证书
+	UsageEnc = "enc"
+	// UsageTLS TLS 证书
+	UsageTLS = "tls"
+)
+
+// Record 签发记录，不包含私钥
+type Record struct {
+	// SerialNumber 证书序列号（十六进制）
+	SerialNumber string `json:"serialNumber"`
+	// CA 签发 CA 的 keystore 名称
+	CA string `json:"ca"`
+	// Kind 签发方式，可选值: "csr", "client"
+	Kind string `json:"kind"`
+	// Usage 证书用途，可选值: "sign", "enc", "tls"
+	Usage string `json:"usage"`
+	// Subject 证书主题
+	Subject string `json:"subject"`
+	// DNSNames DNS 备用名称
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// IPAddresses IP 备用名称
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+	// NotBefore 证书生效时间
+	NotBefore time.Time `json:"notBefore"`
+	// NotAfter 证书过期时间
+	NotAfter time.Time `json:"notAfter"`
+	// IssuedAt 签发时间
+	IssuedAt time.Time `json:"issuedAt"`
+}
+
+// SignRequest 证书请求签发参数
+type SignRequest struct {
+	// Days 有效期（天），默认 DefaultDays，不超过 CA 证书的剩余有效期
+	Days int
+	// Usage 证书用途，可选值: "sign", "enc", "tls"，为空时 SM2 证书请求为 "sign"，其他为 "tls"
+	Usage string
+}
+
+// ClientRequest 客户端证书签发参数
+type ClientRequest struct {
+	CommonName      string
+	Country         string
+	StateOrProvince string
+	Locality        string
+	Org             string
+	OrgUnit         string
+	// Days 有效期（天），默认 DefaultDays，不超过 CA 证书的剩余有效期
+	Days        int
+	DNSNames    []string
+	IPAddresses []string
+	// KeyAlgorithm 密钥算法，仅 RSA/ECDSA CA 有效，可选值: "rsa", "ecdsa"，默认 "ecdsa"
+	KeyAlgorithm certgen.KeyAlgorithm
+	// KeyBits RSA 密钥长度，默认 2048
+	KeyBits int
+}
+
+// Issued 签发的证书
+type Issued struct {
+	Record
+	// CertPEM 证书 PEM
+	CertPEM []byte
+}
+
+// File 客户端证书包中的文件
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Bundle 客户端证书包，SM2 CA 签发签名与加密双证书，RSA/ECDSA CA 签发 TLS 证书
+type Bundle struct {
+	// Records 签发记录，TLCP 依次为签名证书与加密证书
+	Records []Record
+	// Files 证书包文件，包含证书、私钥与 CA 证书
+	Files []File
+}
+
+// WriteZip 将证书包写入 zip
+func (b *Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	for _, f := range b.Files {
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Authority 内置 CA，使用文件类型的 CA keystore 签发证书请求和客户端证书，
+// 签发记录保存在 RecordFile 中，序列号不可重复
+type Authority struct {
+	keyStoreMgr *keystore.Manager
+	dir         string
+
+	mu      sync.Mutex
+	loaded  bool
+	records []Record
+	serials map[string]struct{}
+}
+
+// NewAuthority 创建内置 CA
+// 参数:
+//   - keyStoreMgr: keystore 管理器
+//   - dir: 签发记录保存目录
+func NewAuthority(keyStoreMgr *keystore.Manager, dir string) *Authority {
+	return &Authority{keyStoreMgr: keyStoreMgr, dir: dir}
+}
+
+// issuer 签发 CA
+type issuer struct {
+	name string
+	cert *x509.Certificate
+	key  crypto.PrivateKey
+	sm2  bool
+}
+
+// SignCSR 使用 CA 签发证书请求
+// 参数:
+//   - caName: CA keystore 名称
+//   - csrData: 证书请求，支持 PEM、DER、HEX、Base64 格式
+//   - req: 签发参数
+//
+// 返回:
+//   - *Issued: 签发的证书
+//   - error: CA 不可用、证书请求无效或保存签发记录失败时返回错误
+func (a *Authority) SignCSR(caName string, csrData []byte, req SignRequest) (*Issued, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ca, err := a.loadCA(caName)
+	if err != nil {
+		return nil, err
+	}
+	days, err := ca.days(req.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := certgen.CertGenConfig{Days: days}
+	switch req.Usage {
+	case "":
+	case UsageSign:
+		cfg.Type = certgen.CertTypeTLCPSign
+	case UsageEnc:
+		cfg.Type = certgen.CertTypeTLCPEnc
+	case UsageTLS:
+		cfg.Type = certgen.CertTypeTLS
+	default:
+		return nil, fmt.Errorf("不支持的证书用途: %s", req.Usage)
+	}
+
+	generated, err := certgen.SignCSR(ca.cert, ca.key, csrData, cfg)
+	if err != nil {
+		return nil, err
+	}
+	usage := req.Usage
+	if usage == "" {
+		cert, err := parseCert(generated.CertPEM)
+		if err != nil {
+			return nil, err
+		}
+		usage = UsageTLS
+		if pub, ok := cert.PublicKey.(*ecdsa.PublicKey); ok && pub.Curve == sm2.P256() {
+			usage = UsageSign
+		}
+	}
+
+	records, err := a.record(ca.name, KindCSR, []string{usage}, []*certgen.GeneratedCert{generated})
+	if err != nil {
+		return nil, err
+	}
+	return &Issued{Record: records[0], CertPEM: generated.CertPEM}, nil
+}
+
+// IssueClient 使用 CA 生成客户端证书及密钥
+// 参数:
+//   - caName: CA keystore 名称
+//   - req: 签发参数，CommonName 不能为空
+//
+// 返回:
+//   - *Bundle: 客户端证书包，SM2 CA 签发签名与加密双证书，RSA/ECDSA CA 签发 TLS 证书
+//   - error: CA 不可用、生成证书或保存签发记录失败时返回错误
+//
+// 注意: 私钥仅包含在返回的证书包中，不会保存
+func (a *Authority) IssueClient(caName string, req ClientRequest) (*Bundle, error) {
+	if req.CommonName == "" {
+		return nil, fmt.Errorf("通用名称不能为空")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ca, err := a.loadCA(caName)
+	if err != nil {
+		return nil, err
+	}
+	days, err := ca.days(req.Days)
+	if err != nil {
+		return nil, err
+	}
+	cfg := certgen.CertGenConfig{
+		CommonName:      req.CommonName,
+		Country:         req.Country,
+		StateOrProvince: req.StateOrProvince,
+		Locality:        req.Locality,
+		Org:             req.Org,
+		OrgUnit:         req.OrgUnit,
+		Days:            days,
+		DNSNames:        req.DNSNames,
+		IPAddresses:     req.IPAddresses,
+		KeyAlgorithm:    req.KeyAlgorithm,
+		KeyBits:         req.KeyBits,
+	}
+
+	var usages []string
+	var generated []*certgen.GeneratedCert
+	if ca.sm2 {
+		signCert, encCert, err := certgen.GenerateTLCPPair(ca.cert, ca.key, cfg, cfg)
+		if err != nil {
+			return nil, err
+		}
+		usages = []string{UsageSign, UsageEnc}
+		generated = []*certgen.GeneratedCert{signCert, encCert}
+	} else {
+		switch req.KeyAlgorithm {
+		case "", certgen.KeyAlgorithmRSA, certgen.KeyAlgorithmECDSA:
+		default:
+			return nil, fmt.Errorf("RSA/ECDSA CA 不支持的密钥算法: %s", req.KeyAlgorithm)
+		}
+		cert, err := certgen.GenerateTLSCert(ca.cert, ca.key, cfg)
+		if err != nil {
+			return nil, err
+		}
+		usages = []string{UsageTLS}
+		generated = []*certgen.GeneratedCert{cert}
+	}
+
+	records, err := a.record(ca.name, KindClient, usages, generated)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{Records: records}
+	for i, g := range generated {
+		prefix := "client"
+		if ca.sm2 {
+			prefix = usages[i]
+		}
+		bundle.Files = append(bundle.Files,
+			File{Name: prefix + ".crt", Data: g.CertPEM},
+			File{Name: prefix + ".key", Data: g.KeyPEM})
+	}
+	bundle.Files = append(bundle.Files, File{
+		Name: "ca.crt",
+		Data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}),
+	})
+	return bundle, nil
+}
+
+// List 列出签发记录，按签发时间从新到旧排列
+// 参数:
+//   - caName: CA keystore 名称，为空时列出所有 CA 的签发记录
+func (a *Authority) List(caName string) ([]Record, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(a.records))
+	for _, r := range a.records {
+		if caName == "" || r.CA == caName {
+			records = append(records, r)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].IssuedAt.After(records[j].IssuedAt)
+	})
+	return records, nil
+}
+
+// loadCA 加载文件类型 keystore 中的 CA 证书及私钥，证书为 SM2 时按 TLCP CA 加载
+func (a *Authority) loadCA(name string) (*issuer, error) {
+	info, err := a.keyStoreMgr.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.LoaderType != keystore.LoaderTypeFile {
+		return nil, fmt.Errorf("CA %s 不是文件类型的 keystore", name)
+	}
+
+	certPath, keyPath := info.Params["sign-cert"], info.Params["sign-key"]
+	cert, key, err := certgen.LoadTLCPCertFromFile(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载 CA %s 失败: %w", name, err)
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	isSM2 := ok && pub.Curve == sm2.P256()
+	if !isSM2 {
+		if cert, key, err = certgen.LoadTLSCertFromFile(certPath, keyPath); err != nil {
+			return nil, fmt.Errorf("加载 CA %s 失败: %w", name, err)
+		}
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("keystore %s 的证书不是 CA 证书", name)
+	}
+	if !time.Now().Before(cert.NotAfter) {
+		return nil, fmt.Errorf("CA %s 的证书已过期", name)
+	}
+	return &issuer{name: name, cert: cert, key: key, sm2: isSM2}, nil
+}
+
+// days 计算签发证书的有效期，不超过 CA 证书的剩余有效期
+func (ca *issuer) days(days int) (int, error) {
+	if days < 0 {
+		return 0, fmt.Errorf("有效期不能为负数")
+	}
+	if days == 0 {
+		days = DefaultDays
+	}
+	left := int(math.Floor(time.Until(ca.cert.NotAfter).Hours() / 24))
+	if left < 1 {
+		return 0, fmt.Errorf("CA %s 的证书剩余有效期不足1天", ca.name)
+	}
+	if days > left {
+		days = left
+	}
+	return days, nil
+}
+
+// record 保存签发记录，序列号重复时不保存并返回错误
+func (a *Authority) record(caName, kind string, usages []string, generated []*certgen.GeneratedCert) ([]Record, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	records := make([]Record, 0, len(generated))
+	for i, g := range generated {
+		cert, err := parseCert(g.CertPEM)
+		if err != nil {
+			return nil, err
+		}
+		r := Record{
+			SerialNumber: fmt.Sprintf("%X", cert.SerialNumber),
+			CA:           caName,
+			Kind:         kind,
+			Usage:        usages[i],
+			Subject:      cert.Subject.String(),
+			DNSNames:     cert.DNSNames,
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			IssuedAt:     now,
+		}
+		for _, ip := range cert.IPAddresses {
+			r.IPAddresses = append(r.IPAddresses, ip.String())
+		}
+		if _, ok := a.serials[r.SerialNumber]; ok {
+			return nil, fmt.Errorf("证书序列号 %s 重复", r.SerialNumber)
+		}
+		records = append(records, r)
+	}
+
+	all := append(append([]Record{}, a.records...), records...)
+	if err := a.save(all); err != nil {
+		return nil, err
+	}
+	a.records = all
+	for _, r := range records {
+		a.serials[r.SerialNumber] = struct{}{}
+	}
+	return records, nil
+}
+
+// load 首次使用时读取签发记录
+func (a *Authority) load() error {
+	if a.loaded {
+		return nil
+	}
+	var records []Record
+	data, err := os.ReadFile(filepath.Join(a.dir, RecordFile))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取签发记录失败: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return fmt.Errorf("解析签发记录失败: %w", err)
+		}
+	}
+	a.records = records
+	a.serials = make(map[string]struct{}, len(records))
+	for _, r := range records {
+		a.serials[r.SerialNumber] = struct{}{}
+	}
+	a.loaded = true
+	return nil
+}
+
+// save 写入临时文件后替换签发记录文件
+func (a *Authority) save(records []Record) error {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return fmt.Errorf("创建签发记录目录失败: %w", err)
+	}
+	path := filepath.Join(a.dir, RecordFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存签发记录失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存签发记录失败: %w", err)
+	}
+	return nil
+}
+
+// parseCert 解析签发的证书 PEM
+func parseCert(certPEM []byte) (*smx509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("无法解析证书PEM")
+	}
+	cert, err := smx509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析证书失败: %w", err)
+	}
+	return cert, nil
+}
@@ -0,0 +1,233 @@
+package ca
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// newCAManager 生成 TLCP 与 TLS 根 CA，并创建 "sm2-ca" 与 "tls-ca" 两个 keystore
+func newCAManager(t *testing.T) (*keystore.Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	mgr := keystore.NewManager()
+	for name, gen := range map[string]func(certgen.CertGenConfig) (*certgen.GeneratedCert, error){
+		"sm2-ca": certgen.GenerateTLCPRootCA,
+		"tls-ca": certgen.GenerateTLSRootCA,
+	} {
+		ca, err := gen(certgen.CertGenConfig{CommonName: name})
+		if err != nil {
+			t.Fatalf("生成根证书失败: %v", err)
+		}
+		params := map[string]string{"sign-cert": filepath.Join(dir, name+".crt"), "sign-key": filepath.Join(dir, name+".key")}
+		if err := certgen.SaveCertToFile(ca.CertPEM, ca.KeyPEM, params["sign-cert"], params["sign-key"]); err != nil {
+			t.Fatalf("保存根证书失败: %v", err)
+		}
+		if _, err := mgr.Create(name, keystore.LoaderTypeFile, params, false); err != nil {
+			t.Fatalf("创建 keystore 失败: %v", err)
+		}
+	}
+	return mgr, filepath.Join(dir, "ca")
+}
+
+func parseIssued(t *testing.T, certPEM []byte) *smx509.Certificate {
+	t.Helper()
+	cert, err := parseCert(certPEM)
+	if err != nil {
+		t.Fatalf("解析签发的证书失败: %v", err)
+	}
+	return cert
+}
+
+func TestAuthority_SignCSR(t *testing.T) {
+	mgr, dir := newCAManager(t)
+	a := NewAuthority(mgr, dir)
+
+	// SM2 证书请求
+	sm2Key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成 SM2 密钥失败: %v", err)
+	}
+	csrDER, err := smx509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "gm-client", Organization: []string{"ops"}},
+		DNSNames: []string{"client.example.com"},
+	}, sm2Key)
+	if err != nil {
+		t.Fatalf("创建证书请求失败: %v", err)
+	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+
+	issued, err := a.SignCSR("sm2-ca", csrPEM, SignRequest{Days: 30})
+	if err != nil {
+		t.Fatalf("签发证书请求失败: %v", err)
+	}
+	if issued.Usage != UsageSign || issued.Kind != KindCSR || issued.CA != "sm2-ca" {
+		t.Errorf("签发记录 = %+v", issued.Record)
+	}
+	cert := parseIssued(t, issued.CertPEM)
+	if cert.Subject.CommonName != "gm-client" || len(cert.DNSNames) != 1 || cert.DNSNames[0] != "client.example.com" {
+		t.Errorf("证书主题或备用名称与证书请求不一致: %s %v", cert.Subject, cert.DNSNames)
+	}
+	if cert.KeyUsage != smx509.KeyUsageDigitalSignature {
+		t.Errorf("签名证书密钥用途 = %v", cert.KeyUsage)
+	}
+	if days := cert.NotAfter.Sub(cert.NotBefore).Hours() / 24; days != 30 {
+		t.Errorf("证书有效期 = %.0f 天, 期望 30 天", days)
+	}
+
+	// 加密证书，CSR 为 Base64 格式
+	issued, err = a.SignCSR("sm2-ca", []byte(pemBase64(csrPEM)), SignRequest{Usage: UsageEnc})
+	if err != nil {
+		t.Fatalf("签发加密证书失败: %v", err)
+	}
+	if cert := parseIssued(t, issued.CertPEM); cert.KeyUsage != smx509.KeyUsageKeyEncipherment|smx509.KeyUsageDataEncipherment {
+		t.Errorf("加密证书密钥用途 = %v", cert.KeyUsage)
+	}
+
+	// ECDSA 证书请求
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成 ECDSA 密钥失败: %v", err)
+	}
+	csrDER, err = x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "web-client"}}, ecKey)
+	if err != nil {
+		t.Fatalf("创建证书请求失败: %v", err)
+	}
+	issued, err = a.SignCSR("tls-ca", csrDER, SignRequest{})
+	if err != nil {
+		t.Fatalf("签发证书请求失败: %v", err)
+	}
+	if issued.Usage != UsageTLS {
+		t.Errorf("ECDSA 证书请求的默认用途 = %s, 期望 tls", issued.Usage)
+	}
+	caCert, _, err := certgen.LoadTLSCertFromFile(filepath.Join(filepath.Dir(dir), "tls-ca.crt"), filepath.Join(filepath.Dir(dir), "tls-ca.key"))
+	if err != nil {
+		t.Fatalf("加载根证书失败: %v", err)
+	}
+	block, _ := pem.Decode(issued.CertPEM)
+	stdCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("解析签发的证书失败: %v", err)
+	}
+	if err := stdCert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("证书应由 CA 签发: %v", err)
+	}
+
+	// 篡改签名的证书请求
+	tampered := append([]byte{}, csrDER...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := a.SignCSR("tls-ca", tampered, SignRequest{}); err == nil {
+		t.Error("证书请求签名无效时签发应失败")
+	}
+	if _, err := a.SignCSR("tls-ca", csrDER, SignRequest{Usage: "ca"}); err == nil {
+		t.Error("不支持的证书用途签发应失败")
+	}
+	if _, err := a.SignCSR("missing", csrDER, SignRequest{}); err == nil {
+		t.Error("CA 不存在时签发应失败")
+	}
+
+	records, err := a.List("")
+	if err != nil {
+		t.Fatalf("列出签发记录失败: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("签发记录条数 = %d, 期望 3", len(records))
+	}
+}
+
+func TestAuthority_IssueClient(t *testing.T) {
+	mgr, dir := newCAManager(t)
+	a := NewAuthority(mgr, dir)
+
+	if _, err := a.IssueClient("sm2-ca", ClientRequest{}); err == nil {
+		t.Error("通用名称为空时签发应失败")
+	}
+
+	// SM2 CA 签发签名与加密双证书
+	bundle, err := a.IssueClient("sm2-ca", ClientRequest{CommonName: "gm-client", OrgUnit: "ops", Days: 90})
+	if err != nil {
+		t.Fatalf("签发客户端证书失败: %v", err)
+	}
+	if len(bundle.Records) != 2 || bundle.Records[0].Usage != UsageSign || bundle.Records[1].Usage != UsageEnc {
+		t.Fatalf("签发记录 = %+v", bundle.Records)
+	}
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		t.Fatalf("写入 zip 失败: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("读取 zip 失败: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("打开 %s 失败: %v", f.Name, err)
+		}
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	for _, name := range []string{"sign.crt", "sign.key", "enc.crt", "enc.key", "ca.crt"} {
+		if len(files[name]) == 0 {
+			t.Errorf("证书包缺少 %s", name)
+		}
+	}
+	signCert := parseIssued(t, files["sign.crt"])
+	if signCert.Subject.CommonName != "gm-client" || signCert.IsCA {
+		t.Errorf("签名证书主题 = %s", signCert.Subject)
+	}
+	caCert := parseIssued(t, files["ca.crt"])
+	if err := signCert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("签名证书应由 CA 签发: %v", err)
+	}
+
+	// RSA/ECDSA CA 签发 TLS 证书，有效期不超过 CA 证书
+	bundle, err = a.IssueClient("tls-ca", ClientRequest{CommonName: "web-client", Days: 100000, KeyAlgorithm: certgen.KeyAlgorithmRSA})
+	if err != nil {
+		t.Fatalf("签发客户端证书失败: %v", err)
+	}
+	if len(bundle.Records) != 1 || bundle.Records[0].Usage != UsageTLS || len(bundle.Files) != 3 {
+		t.Fatalf("证书包 = %+v", bundle.Records)
+	}
+	tlsCA := parseIssued(t, bundle.Files[2].Data)
+	if bundle.Records[0].NotAfter.After(tlsCA.NotAfter) {
+		t.Errorf("证书过期时间 %s 晚于 CA 证书 %s", bundle.Records[0].NotAfter, tlsCA.NotAfter)
+	}
+	if _, err := a.IssueClient("tls-ca", ClientRequest{CommonName: "web-client", KeyAlgorithm: certgen.KeyAlgorithmSM2}); err == nil {
+		t.Error("RSA/ECDSA CA 签发 SM2 密钥的证书应失败")
+	}
+
+	// 签发记录持久化
+	reloaded, err := NewAuthority(mgr, dir).List("sm2-ca")
+	if err != nil {
+		t.Fatalf("列出签发记录失败: %v", err)
+	}
+	if len(reloaded) != 2 || reloaded[0].Kind != KindClient || reloaded[0].SerialNumber != fmt.Sprintf("%X", signCert.SerialNumber) {
+		t.Errorf("重新加载的签发记录 = %+v", reloaded)
+	}
+	all, _ := NewAuthority(mgr, dir).List("")
+	if len(all) != 3 {
+		t.Errorf("签发记录条数 = %d, 期望 3", len(all))
+	}
+}
+
+// pemBase64 提取 PEM 中的 Base64 内容
+func pemBase64(data []byte) string {
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	return string(bytes.Join(lines[1:len(lines)-1], nil))
+}
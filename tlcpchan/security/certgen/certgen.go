@@ -16,6 +16,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/Trisia/tlcpchan/security/der"
 	"github.com/emmansun/gmsm/sm2"
 	"github.com/emmansun/gmsm/smx509"
 )
@@ -76,6 +77,9 @@ type CertGenConfig struct {
 	DNSNames []string
 	// IPAddresses IP 主题备用名称
 	IPAddresses []string
+	// Key 证书私钥，为 nil 时生成新密钥，续期证书时可沿用原有密钥
+	// TLCP 证书须为 *sm2.PrivateKey，TLS 证书须为 *rsa.PrivateKey 或 *ecdsa.PrivateKey
+	Key crypto.PrivateKey
 }
 
 // GeneratedCert 生成的证书结果
@@ -376,8 +380,14 @@ func GenerateTLCPPair(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 //   - 使用 SM2 算法生成密钥对
 //   - IsCA 设置为 false，表示这不是一个 CA 证书
 func generateTLCPCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey, cfg CertGenConfig, keyUsage smx509.KeyUsage) (*GeneratedCert, error) {
-	priv, err := sm2.GenerateKey(rand.Reader)
-	if err != nil {
+	var priv *sm2.PrivateKey
+	var err error
+	if cfg.Key != nil {
+		var ok bool
+		if priv, ok = cfg.Key.(*sm2.PrivateKey); !ok {
+			return nil, fmt.Errorf("TLCP 证书私钥必须为 SM2 密钥，实际为 %T", cfg.Key)
+		}
+	} else if priv, err = sm2.GenerateKey(rand.Reader); err != nil {
 		return nil, fmt.Errorf("生成SM2密钥失败: %w", err)
 	}
 
@@ -415,8 +425,13 @@ func generateTLCPCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 		subject.OrganizationalUnit = []string{cfg.OrgUnit}
 	}
 
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
 	template := &smx509.Certificate{
-		SerialNumber:          big.NewInt(time.Now().Unix()),
+		SerialNumber:          serialNumber,
 		Subject:               subject,
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
@@ -491,8 +506,10 @@ func GenerateTLSCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 	var priv crypto.PrivateKey
 	var err error
 
-	switch cfg.KeyAlgorithm {
-	case KeyAlgorithmRSA:
+	switch {
+	case cfg.Key != nil:
+		priv = cfg.Key
+	case cfg.KeyAlgorithm == KeyAlgorithmRSA:
 		keyBits := cfg.KeyBits
 		if keyBits <= 0 {
 			keyBits = 2048
@@ -501,7 +518,7 @@ func GenerateTLSCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 		if err != nil {
 			return nil, fmt.Errorf("生成RSA密钥失败: %w", err)
 		}
-	case KeyAlgorithmECDSA:
+	case cfg.KeyAlgorithm == KeyAlgorithmECDSA:
 		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 		if err != nil {
 			return nil, fmt.Errorf("生成ECDSA密钥失败: %w", err)
@@ -540,8 +557,13 @@ func GenerateTLSCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 		subject.OrganizationalUnit = []string{cfg.OrgUnit}
 	}
 
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
 	template := &x509.Certificate{
-		SerialNumber:          big.NewInt(time.Now().Unix()),
+		SerialNumber:          serialNumber,
 		Subject:               subject,
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
@@ -588,6 +610,114 @@ func GenerateTLSCert(signerCert *x509.Certificate, signerKey crypto.PrivateKey,
 	}, nil
 }
 
+// SignCSR 使用 CA 签发证书请求(CSR)
+//
+// 功能：
+//
+//	校验 CSR 的签名，并使用 CA 证书和私钥签发证书，支持 SM2 与 RSA/ECDSA 的 CSR
+//
+// 参数：
+//
+//	signerCert - 签发者（CA）证书
+//	signerKey - 签发者（CA）私钥，SM2 私钥使用 SM2-SM3 签名
+//	csrData - CSR 数据，支持 PEM、DER、HEX、Base64 格式
+//	cfg - 证书配置
+//	    - Type 为 CertTypeTLCPSign、CertTypeTLCPEnc、CertTypeTLS 之一，决定证书的密钥用途；
+//	      为空时 SM2 CSR 按签名证书、其他 CSR 按 TLS 证书签发
+//	    - 如果 Years <= 0 且 Days <= 0，默认使用 5 年
+//	    - 主题和备用名称取自 CSR，cfg 中的主题字段不生效
+//
+// 返回值：
+//
+//	*GeneratedCert - 签发的证书，KeyPEM 为空
+//	error - 错误信息，包括 CSR 解析失败、CSR 签名无效、证书创建失败等
+//
+// 注意事项：
+//   - TLS 证书具有 ExtKeyUsageServerAuth 和 ExtKeyUsageClientAuth 扩展密钥用途
+//   - IsCA 设置为 false，不会签发 CA 证书
+func SignCSR(signerCert *x509.Certificate, signerKey crypto.PrivateKey, csrData []byte, cfg CertGenConfig) (*GeneratedCert, error) {
+	csrDER, err := der.Any2DER(csrData)
+	if err != nil {
+		return nil, fmt.Errorf("解析CSR失败: %w", err)
+	}
+	csr, err := smx509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		return nil, fmt.Errorf("解析CSR失败: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("CSR签名无效: %w", err)
+	}
+
+	isSM2 := false
+	if pub, ok := csr.PublicKey.(*ecdsa.PublicKey); ok && pub.Curve == sm2.P256() {
+		isSM2 = true
+	}
+	if cfg.Type == "" {
+		cfg.Type = CertTypeTLS
+		if isSM2 {
+			cfg.Type = CertTypeTLCPSign
+		}
+	}
+
+	var keyUsage smx509.KeyUsage
+	var extKeyUsage []smx509.ExtKeyUsage
+	switch cfg.Type {
+	case CertTypeTLCPSign:
+		keyUsage = smx509.KeyUsageDigitalSignature
+	case CertTypeTLCPEnc:
+		keyUsage = smx509.KeyUsageKeyEncipherment | smx509.KeyUsageDataEncipherment
+	case CertTypeTLS:
+		keyUsage = smx509.KeyUsageDigitalSignature | smx509.KeyUsageKeyEncipherment
+		extKeyUsage = []smx509.ExtKeyUsage{smx509.ExtKeyUsageServerAuth, smx509.ExtKeyUsageClientAuth}
+	default:
+		return nil, fmt.Errorf("不支持的证书类型: %s", cfg.Type)
+	}
+
+	smSignerCert, err := smx509.ParseCertificate(signerCert.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("解析签发者证书失败: %w", err)
+	}
+
+	notBefore := time.Now()
+	var notAfter time.Time
+	if cfg.Days > 0 {
+		notAfter = notBefore.AddDate(0, 0, cfg.Days)
+	} else if cfg.Years > 0 {
+		notAfter = notBefore.AddDate(cfg.Years, 0, 0)
+	} else {
+		notAfter = notBefore.AddDate(5, 0, 0)
+	}
+
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	template := &smx509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               csr.Subject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		DNSNames:              csr.DNSNames,
+		IPAddresses:           csr.IPAddresses,
+		EmailAddresses:        csr.EmailAddresses,
+		URIs:                  csr.URIs,
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+	}
+
+	certBytes, err := smx509.CreateCertificate(rand.Reader, template, smSignerCert, csr.PublicKey, signerKey)
+	if err != nil {
+		return nil, fmt.Errorf("创建证书失败: %w", err)
+	}
+
+	return &GeneratedCert{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}),
+	}, nil
+}
+
 // SaveCertToFile 保存证书和密钥到文件
 //
 // 功能：
@@ -755,6 +885,16 @@ func LoadTLSCertFromFile(certPath, keyPath string) (*x509.Certificate, crypto.Pr
 	return cert, priv, nil
 }
 
+// randomSerialNumber 生成128位随机证书序列号
+// 注意: 同一CA签发的证书序列号必须唯一，否则无法通过CRL单独吊销
+func randomSerialNumber() (*big.Int, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("生成证书序列号失败: %w", err)
+	}
+	return serialNumber, nil
+}
+
 // parseIPAddresses 解析字符串列表为 net.IP 列表
 func parseIPAddresses(ipStrs []string) []net.IP {
 	var ips []net.IP
@@ -0,0 +1,361 @@
+package expiry
+
+import (
+	"crypto/tls"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/Trisia/tlcpchan/security/rootcert"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+const (
+	// DefaultInterval 默认检查间隔
+	DefaultInterval = time.Hour
+	// DefaultWarningDays 默认告警阈值（天）
+	DefaultWarningDays = 30
+	// DefaultCriticalDays 默认严重告警阈值（天）
+	DefaultCriticalDays = 7
+)
+
+// Status 证书有效期状态
+type Status string
+
+const (
+	// StatusOK 剩余有效期不少于告警阈值
+	StatusOK Status = "ok"
+	// StatusWarning 剩余有效期少于告警阈值
+	StatusWarning Status = "warning"
+	// StatusCritical 剩余有效期少于严重告警阈值
+	StatusCritical Status = "critical"
+	// StatusExpired 已过期
+	StatusExpired Status = "expired"
+	// StatusError keystore 加载失败，无法读取证书
+	StatusError Status = "error"
+)
+
+// 证书来源
+const (
+	SourceKeyStore = "keystore"
+	SourceRootCert = "rootcert"
+)
+
+// Options 证书有效期检查选项
+type Options struct {
+	// Interval 检查间隔，默认 DefaultInterval
+	Interval time.Duration
+	// WarningDays 剩余有效期少于该天数时为 warning，默认 DefaultWarningDays
+	WarningDays int
+	// CriticalDays 剩余有效期少于该天数时为 critical，默认 DefaultCriticalDays
+	CriticalDays int
+}
+
+// Item 单个证书的有效期检查结果
+type Item struct {
+	// Source 证书来源，可选值: "keystore", "rootcert"
+	Source string `json:"source"`
+	// Name keystore 名称或根证书文件名
+	Name string `json:"name"`
+	// Usage keystore 证书用途，可选值: "sign", "enc"，TLS keystore 的证书为 "sign"，根证书为空
+	Usage string `json:"usage,omitempty"`
+	// Subject 证书主题
+	Subject string `json:"subject,omitempty"`
+	// SerialNumber 证书序列号（十六进制）
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// NotBefore 证书生效时间
+	NotBefore time.Time `json:"notBefore,omitempty"`
+	// NotAfter 证书过期时间
+	NotAfter time.Time `json:"notAfter,omitempty"`
+	// DaysLeft 剩余有效天数，向下取整，已过期时为负数
+	DaysLeft int `json:"daysLeft"`
+	// Status 有效期状态
+	Status Status `json:"status"`
+	// Error keystore 加载失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// key 证书在检查结果中的唯一标识
+func (it *Item) key() string {
+	return it.Source + "/" + it.Name + "/" + it.Usage
+}
+
+// Report 一次检查的结果
+type Report struct {
+	// CheckedAt 检查时间
+	CheckedAt time.Time `json:"checkedAt"`
+	// WarningDays 告警阈值（天）
+	WarningDays int `json:"warningDays"`
+	// CriticalDays 严重告警阈值（天）
+	CriticalDays int `json:"criticalDays"`
+	// Items 证书检查结果，按剩余有效期从短到长排列，加载失败的 keystore 排在最前
+	Items []Item `json:"items"`
+	// Summary 各状态的证书数量
+	Summary map[Status]int `json:"summary"`
+}
+
+// Monitor 证书有效期监控，定期检查所有 keystore 证书（签名与加密）和根证书的剩余有效期
+type Monitor struct {
+	keyStoreMgr *keystore.Manager
+	rootCertMgr *rootcert.Manager
+	opts        Options
+
+	// checkMu 串行化检查，保证保存的结果为最后一次检查的结果
+	checkMu sync.Mutex
+	mu      sync.RWMutex
+	report  *Report
+	stop    chan struct{}
+	// unsubscribe 取消订阅 keystore 与根证书变化
+	unsubscribe []func()
+}
+
+// NewMonitor 创建证书有效期监控
+// 参数:
+//   - keyStoreMgr: keystore 管理器，可为 nil
+//   - rootCertMgr: 根证书管理器，可为 nil
+//   - opts: 检查选项，未设置的字段使用默认值
+func NewMonitor(keyStoreMgr *keystore.Manager, rootCertMgr *rootcert.Manager, opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.WarningDays <= 0 {
+		opts.WarningDays = DefaultWarningDays
+	}
+	if opts.CriticalDays <= 0 {
+		opts.CriticalDays = DefaultCriticalDays
+	}
+	return &Monitor{keyStoreMgr: keyStoreMgr, rootCertMgr: rootCertMgr, opts: opts}
+}
+
+// Start 立即检查一次并启动定期检查，keystore 或根证书变化后同样重新检查
+// 注意: 重复调用会先停止之前的检查任务，调用 Close 停止
+func (m *Monitor) Start() {
+	m.Close()
+	m.Check()
+
+	stop := make(chan struct{})
+	var unsubscribe []func()
+	if m.keyStoreMgr != nil {
+		unsubscribe = append(unsubscribe, m.keyStoreMgr.Subscribe(func(string) { m.Check() }))
+	}
+	if m.rootCertMgr != nil {
+		unsubscribe = append(unsubscribe, m.rootCertMgr.Subscribe(func() []string {
+			m.Check()
+			return nil
+		}))
+	}
+	m.mu.Lock()
+	m.stop = stop
+	m.unsubscribe = unsubscribe
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(m.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.Check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止定期检查
+func (m *Monitor) Close() {
+	m.mu.Lock()
+	stop, unsubscribe := m.stop, m.unsubscribe
+	m.stop, m.unsubscribe = nil, nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+	}
+	for _, fn := range unsubscribe {
+		fn()
+	}
+}
+
+// Report 获取最近一次检查的结果，尚未检查时立即检查
+func (m *Monitor) Report() *Report {
+	m.mu.RLock()
+	report := m.report
+	m.mu.RUnlock()
+	if report != nil {
+		return report
+	}
+	return m.Check()
+}
+
+// Check 立即检查所有证书，并对状态变为 warning、critical、expired、error 的证书记录日志
+// 返回:
+//   - *Report: 检查结果
+func (m *Monitor) Check() *Report {
+	m.checkMu.Lock()
+	defer m.checkMu.Unlock()
+
+	now := time.Now()
+	report := &Report{
+		CheckedAt:    now,
+		WarningDays:  m.opts.WarningDays,
+		CriticalDays: m.opts.CriticalDays,
+		Items:        make([]Item, 0),
+		Summary:      make(map[Status]int),
+	}
+
+	if m.keyStoreMgr != nil {
+		for _, info := range m.keyStoreMgr.List() {
+			// named 加载器引用其他 keystore，证书已在被引用的 keystore 中检查
+			if info.LoaderType == keystore.LoaderTypeNamed {
+				continue
+			}
+			report.Items = append(report.Items, m.keyStoreItems(info, now)...)
+		}
+	}
+	if m.rootCertMgr != nil {
+		for _, rc := range m.rootCertMgr.List() {
+			item := Item{
+				Source:       SourceRootCert,
+				Name:         rc.Filename,
+				Subject:      rc.Subject,
+				SerialNumber: rc.SerialNumber,
+				NotBefore:    rc.NotBefore,
+				NotAfter:     rc.NotAfter,
+			}
+			m.evaluate(&item, now)
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := &report.Items[i], &report.Items[j]
+		if (a.Status == StatusError) != (b.Status == StatusError) {
+			return a.Status == StatusError
+		}
+		if !a.NotAfter.Equal(b.NotAfter) {
+			return a.NotAfter.Before(b.NotAfter)
+		}
+		return a.key() < b.key()
+	})
+	for _, item := range report.Items {
+		report.Summary[item.Status]++
+	}
+
+	m.mu.Lock()
+	previous := m.report
+	m.report = report
+	m.mu.Unlock()
+
+	m.logChanges(previous, report)
+	return report
+}
+
+// keyStoreItems 读取 keystore 的证书，TLCP keystore 依次为签名证书与加密证书
+func (m *Monitor) keyStoreItems(info *keystore.KeyStoreInfo, now time.Time) []Item {
+	failed := func(err error) []Item {
+		return []Item{{Source: SourceKeyStore, Name: info.Name, Status: StatusError, Error: err.Error()}}
+	}
+
+	ks, err := m.keyStoreMgr.GetKeyStore(info.Name)
+	if err != nil {
+		return failed(err)
+	}
+
+	var chains [][][]byte
+	if ks.Type() == keystore.KeyStoreTypeTLCP {
+		certs, err := ks.TLCPCertificate()
+		if err != nil {
+			return failed(err)
+		}
+		for _, cert := range certs {
+			chains = append(chains, cert.Certificate)
+		}
+	} else {
+		var cert *tls.Certificate
+		if cert, err = ks.TLSCertificate(); err != nil {
+			return failed(err)
+		}
+		chains = append(chains, cert.Certificate)
+	}
+
+	usages := []keystore.KeyType{keystore.KeyTypeSign, keystore.KeyTypeEnc}
+	items := make([]Item, 0, len(chains))
+	for i, chain := range chains {
+		item := Item{Source: SourceKeyStore, Name: info.Name}
+		if i < len(usages) {
+			item.Usage = string(usages[i])
+		}
+		if len(chain) == 0 {
+			item.Status = StatusError
+			item.Error = "证书为空"
+			items = append(items, item)
+			continue
+		}
+		cert, err := smx509.ParseCertificate(chain[0])
+		if err != nil {
+			item.Status = StatusError
+			item.Error = fmt.Sprintf("解析证书失败: %v", err)
+			items = append(items, item)
+			continue
+		}
+		item.Subject = cert.Subject.String()
+		item.SerialNumber = fmt.Sprintf("%X", cert.SerialNumber)
+		item.NotBefore = cert.NotBefore
+		item.NotAfter = cert.NotAfter
+		m.evaluate(&item, now)
+		items = append(items, item)
+	}
+	return items
+}
+
+// evaluate 按阈值计算剩余天数与状态
+func (m *Monitor) evaluate(item *Item, now time.Time) {
+	left := item.NotAfter.Sub(now)
+	item.DaysLeft = int(math.Floor(left.Hours() / 24))
+	switch {
+	case left <= 0:
+		item.Status = StatusExpired
+	case left < time.Duration(m.opts.CriticalDays)*24*time.Hour:
+		item.Status = StatusCritical
+	case left < time.Duration(m.opts.WarningDays)*24*time.Hour:
+		item.Status = StatusWarning
+	default:
+		item.Status = StatusOK
+	}
+}
+
+// logChanges 记录状态发生变化的证书，首次检查时记录所有状态异常的证书
+func (m *Monitor) logChanges(previous, current *Report) {
+	before := make(map[string]Status)
+	if previous != nil {
+		for _, item := range previous.Items {
+			before[item.key()] = item.Status
+		}
+	}
+
+	for _, item := range current.Items {
+		if item.Status == StatusOK || before[item.key()] == item.Status {
+			continue
+		}
+		name := item.Source + " " + item.Name
+		if item.Usage != "" {
+			name += " (" + item.Usage + ")"
+		}
+		switch item.Status {
+		case StatusWarning:
+			logger.Warn("证书即将过期: %s, 剩余 %d 天, 过期时间 %s", name, item.DaysLeft, item.NotAfter.Format(time.RFC3339))
+		case StatusCritical:
+			logger.Error("证书即将过期: %s, 剩余 %d 天, 过期时间 %s", name, item.DaysLeft, item.NotAfter.Format(time.RFC3339))
+		case StatusExpired:
+			logger.Error("证书已过期: %s, 过期时间 %s", name, item.NotAfter.Format(time.RFC3339))
+		case StatusError:
+			logger.Warn("无法检查证书有效期: %s: %s", name, item.Error)
+		}
+	}
+}
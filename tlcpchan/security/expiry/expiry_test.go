@@ -0,0 +1,179 @@
+package expiry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/Trisia/tlcpchan/security/rootcert"
+)
+
+func TestMonitor_Check(t *testing.T) {
+	dir := t.TempDir()
+	caCertPath := filepath.Join(dir, "ca.crt")
+	caKeyPath := filepath.Join(dir, "ca.key")
+	ca, err := certgen.GenerateTLCPRootCA(certgen.CertGenConfig{CommonName: "expiry-ca"})
+	if err != nil {
+		t.Fatalf("生成根证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(ca.CertPEM, ca.KeyPEM, caCertPath, caKeyPath); err != nil {
+		t.Fatalf("保存根证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(ca.CertPEM, ca.KeyPEM, filepath.Join(dir, "rootcerts", "ca.crt"), filepath.Join(dir, "ca-copy.key")); err != nil {
+		t.Fatalf("保存根证书失败: %v", err)
+	}
+	caCert, caKey, err := certgen.LoadTLCPCertFromFile(caCertPath, caKeyPath)
+	if err != nil {
+		t.Fatalf("加载根证书失败: %v", err)
+	}
+
+	// 签名证书剩余20天（warning），加密证书剩余3天（critical）
+	signCert, encCert, err := certgen.GenerateTLCPPair(caCert, caKey,
+		certgen.CertGenConfig{CommonName: "gm-server", Days: 20},
+		certgen.CertGenConfig{CommonName: "gm-server", Days: 3})
+	if err != nil {
+		t.Fatalf("签发证书失败: %v", err)
+	}
+	params := map[string]string{
+		"sign-cert": filepath.Join(dir, "sign.crt"),
+		"sign-key":  filepath.Join(dir, "sign.key"),
+		"enc-cert":  filepath.Join(dir, "enc.crt"),
+		"enc-key":   filepath.Join(dir, "enc.key"),
+	}
+	if err := certgen.SaveCertToFile(signCert.CertPEM, signCert.KeyPEM, params["sign-cert"], params["sign-key"]); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(encCert.CertPEM, encCert.KeyPEM, params["enc-cert"], params["enc-key"]); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+
+	ksMgr := keystore.NewManager()
+	if _, err := ksMgr.Create("gm-server", keystore.LoaderTypeFile, params, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	// named 加载器引用的证书不重复检查
+	if _, err := ksMgr.Create("gm-alias", keystore.LoaderTypeNamed, map[string]string{"name": "gm-server"}, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+	rcMgr := rootcert.NewManager(filepath.Join(dir, "rootcerts"))
+	if err := rcMgr.Initialize(); err != nil {
+		t.Fatalf("初始化根证书管理器失败: %v", err)
+	}
+
+	m := NewMonitor(ksMgr, rcMgr, Options{})
+	report := m.Check()
+	if report.WarningDays != DefaultWarningDays || report.CriticalDays != DefaultCriticalDays {
+		t.Errorf("阈值 = %d/%d, 期望默认值 %d/%d", report.WarningDays, report.CriticalDays, DefaultWarningDays, DefaultCriticalDays)
+	}
+	if len(report.Items) != 3 {
+		t.Fatalf("检查结果条数 = %d, 期望 3: %+v", len(report.Items), report.Items)
+	}
+
+	want := []struct {
+		source, name, usage string
+		status              Status
+		daysLeft            int
+	}{
+		{SourceKeyStore, "gm-server", "enc", StatusCritical, 2},
+		{SourceKeyStore, "gm-server", "sign", StatusWarning, 19},
+		{SourceRootCert, "ca.crt", "", StatusOK, 0},
+	}
+	for i, w := range want {
+		got := report.Items[i]
+		if got.Source != w.source || got.Name != w.name || got.Usage != w.usage || got.Status != w.status {
+			t.Errorf("第 %d 条 = %s/%s/%s %s, 期望 %s/%s/%s %s", i, got.Source, got.Name, got.Usage, got.Status,
+				w.source, w.name, w.usage, w.status)
+		}
+		if w.daysLeft > 0 && got.DaysLeft != w.daysLeft {
+			t.Errorf("第 %d 条剩余天数 = %d, 期望 %d", i, got.DaysLeft, w.daysLeft)
+		}
+	}
+	if report.Summary[StatusOK] != 1 || report.Summary[StatusWarning] != 1 || report.Summary[StatusCritical] != 1 {
+		t.Errorf("Summary = %v", report.Summary)
+	}
+	if m.Report() != report {
+		t.Error("Report() 应返回最近一次检查的结果")
+	}
+
+	// 调整阈值后加密证书为 warning，签名证书为 ok
+	m = NewMonitor(ksMgr, nil, Options{WarningDays: 10, CriticalDays: 1})
+	report = m.Check()
+	if report.Summary[StatusWarning] != 1 || report.Summary[StatusOK] != 1 {
+		t.Errorf("调整阈值后 Summary = %v", report.Summary)
+	}
+}
+
+func TestMonitor_Evaluate(t *testing.T) {
+	m := NewMonitor(nil, nil, Options{WarningDays: 30, CriticalDays: 7})
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		status   Status
+		daysLeft int
+	}{
+		{"已过期", now.Add(-36 * time.Hour), StatusExpired, -2},
+		{"刚好过期", now, StatusExpired, 0},
+		{"剩余不足严重告警阈值", now.Add(6*24*time.Hour + time.Hour), StatusCritical, 6},
+		{"剩余等于严重告警阈值", now.Add(7 * 24 * time.Hour), StatusWarning, 7},
+		{"剩余不足告警阈值", now.Add(29 * 24 * time.Hour), StatusWarning, 29},
+		{"剩余充足", now.Add(365 * 24 * time.Hour), StatusOK, 365},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := Item{NotAfter: tt.notAfter}
+			m.evaluate(&item, now)
+			if item.Status != tt.status || item.DaysLeft != tt.daysLeft {
+				t.Errorf("evaluate() = %s/%d, 期望 %s/%d", item.Status, item.DaysLeft, tt.status, tt.daysLeft)
+			}
+		})
+	}
+}
+
+func TestMonitor_Start(t *testing.T) {
+	dir := t.TempDir()
+	params := map[string]string{"sign-cert": filepath.Join(dir, "web.crt"), "sign-key": filepath.Join(dir, "web.key")}
+	writeCert := func(days int) {
+		cert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "web", Days: days})
+		if err != nil {
+			t.Fatalf("生成证书失败: %v", err)
+		}
+		if err := certgen.SaveCertToFile(cert.CertPEM, cert.KeyPEM, params["sign-cert"], params["sign-key"]); err != nil {
+			t.Fatalf("保存证书失败: %v", err)
+		}
+	}
+	writeCert(3)
+
+	ksMgr := keystore.NewManager()
+	if _, err := ksMgr.Create("web", keystore.LoaderTypeFile, params, false); err != nil {
+		t.Fatalf("创建 keystore 失败: %v", err)
+	}
+
+	m := NewMonitor(ksMgr, nil, Options{Interval: time.Hour})
+	m.Start()
+	defer m.Close()
+	if items := m.Report().Items; len(items) != 1 || items[0].Status != StatusCritical {
+		t.Fatalf("启动后检查结果 = %+v, 期望 1 条 critical", items)
+	}
+
+	// 证书续期后 keystore 重新加载，无需等待下次定期检查
+	writeCert(365)
+	if _, err := ksMgr.Reload("web", nil); err != nil {
+		t.Fatalf("重新加载 keystore 失败: %v", err)
+	}
+	if items := m.Report().Items; len(items) != 1 || items[0].Status != StatusOK {
+		t.Errorf("续期后检查结果 = %+v, 期望 1 条 ok", items)
+	}
+
+	m.Close()
+	writeCert(3)
+	if _, err := ksMgr.Reload("web", nil); err != nil {
+		t.Fatalf("重新加载 keystore 失败: %v", err)
+	}
+	if items := m.Report().Items; items[0].Status != StatusOK {
+		t.Errorf("停止后不应再检查, 检查结果 = %+v", items)
+	}
+}
@@ -35,6 +35,40 @@ func (f *FileKeyStore) Type() KeyStoreType {
 	return f.keyStoreType
 }
 
+// verifyPairs 验证证书与密钥文件是否匹配，用于重新加载前确认文件有效
+// 注意：签名证书可能为 SM2 证书，也可能为 RSA/ECDSA 证书
+func (f *FileKeyStore) verifyPairs() error {
+	pairs := [][2]string{{f.signCertPath, f.signKeyPath}}
+	if f.encCertPath != "" && f.encKeyPath != "" {
+		pairs = append(pairs, [2]string{f.encCertPath, f.encKeyPath})
+	}
+	for _, pair := range pairs {
+		certData, err := os.ReadFile(pair[0])
+		if err != nil {
+			return fmt.Errorf("读取证书文件失败: %w", err)
+		}
+		keyData, err := os.ReadFile(pair[1])
+		if err != nil {
+			return fmt.Errorf("读取私钥文件失败: %w", err)
+		}
+		if err := VerifyCertificateKeyPair(certData, keyData, false); err != nil {
+			if VerifyCertificateKeyPair(certData, keyData, true) != nil {
+				return fmt.Errorf("证书 %s 与密钥不匹配: %w", filepath.Base(pair[0]), err)
+			}
+		}
+	}
+	return nil
+}
+
+// files 返回证书和密钥文件路径，用于文件变化检测
+func (f *FileKeyStore) files() []string {
+	paths := []string{f.signCertPath, f.signKeyPath}
+	if f.encCertPath != "" && f.encKeyPath != "" {
+		paths = append(paths, f.encCertPath, f.encKeyPath)
+	}
+	return paths
+}
+
 func (f *FileKeyStore) TLSCertificate() (*tls.Certificate, error) {
 	f.mu.RLock()
 	if f.tlsCert != nil {
@@ -271,20 +305,29 @@ func (nl *NamedLoader) Load(loaderType LoaderType, params map[string]string) (Ke
 }
 
 // NamedKeyStore 命名加载器的keystore包装器
+// 每次获取证书时从管理器取被引用的 keystore，被引用的 keystore 重新加载后立即生效
 type NamedKeyStore struct {
 	name     string
 	manager  *Manager
 	delegate KeyStore
 }
 
+// current 获取被引用的 keystore，已从管理器删除时使用加载时的 keystore
+func (n *NamedKeyStore) current() KeyStore {
+	if ks, err := n.manager.GetKeyStore(n.name); err == nil {
+		return ks
+	}
+	return n.delegate
+}
+
 func (n *NamedKeyStore) Type() KeyStoreType {
-	return n.delegate.Type()
+	return n.current().Type()
 }
 
 func (n *NamedKeyStore) TLCPCertificate() ([]*tlcp.Certificate, error) {
-	return n.delegate.TLCPCertificate()
+	return n.current().TLCPCertificate()
 }
 
 func (n *NamedKeyStore) TLSCertificate() (*tls.Certificate, error) {
-	return n.delegate.TLSCertificate()
+	return n.current().TLSCertificate()
 }
@@ -2,6 +2,7 @@ package keystore
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 )
@@ -10,10 +11,14 @@ import (
 // 负责管理 keystore 的创建、加载、获取和管理
 // 注意：该模块不负责持久化，持久化由控制器层通过 config.Config.KeyStores 负责
 type Manager struct {
-	keyStores    map[string]KeyStore      // 已加载的 keystore 实例
-	keyStoreInfo map[string]*KeyStoreInfo // keystore 元信息
-	loaders      map[LoaderType]Loader    // 加载器映射
-	mu           sync.RWMutex             // 读写锁，保证并发安全
+	keyStores    map[string]KeyStore       // 已加载的 keystore 实例
+	keyStoreInfo map[string]*KeyStoreInfo  // keystore 元信息
+	loaders      map[LoaderType]Loader     // 加载器映射
+	fileStates   map[string]*fileState     // 文件 keystore 已加载的文件状态，用于变化检测
+	listeners    map[int]func(name string) // keystore 变化订阅方
+	nextListener int                       // 下一个订阅方编号
+	stopWatch    chan struct{}             // 停止文件变化检测
+	mu           sync.RWMutex              // 读写锁，保证并发安全
 }
 
 // NewManager 创建 keystore 管理器
@@ -24,6 +29,8 @@ func NewManager() *Manager {
 		keyStores:    make(map[string]KeyStore),
 		keyStoreInfo: make(map[string]*KeyStoreInfo),
 		loaders:      make(map[LoaderType]Loader),
+		fileStates:   make(map[string]*fileState),
+		listeners:    make(map[int]func(name string)),
 	}
 
 	m.loaders[LoaderTypeFile] = NewFileLoader("")
@@ -35,9 +42,11 @@ func NewManager() *Manager {
 // ConfigEntry 用于 LoadFromConfigs 的配置条目
 // 用于从外部配置加载 keystores 时使用的简单结构
 type ConfigEntry struct {
-	Name   string            // keystore 名称
-	Type   LoaderType        // 加载器类型
-	Params map[string]string // 加载器参数
+	Name    string            // keystore 名称
+	Type    LoaderType        // 加载器类型
+	Params  map[string]string // 加载器参数
+	Issuer  string            // 签发证书的 CA keystore 名称
+	Renewal *RenewalPolicy    // 证书自动续期策略
 }
 
 // LoadFromConfigs 从配置列表批量加载 keystores
@@ -49,15 +58,15 @@ type ConfigEntry struct {
 //
 // 注意：该方法用于服务启动时从配置文件初始化 keystores
 func (m *Manager) LoadFromConfigs(configs []ConfigEntry) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	for _, cfg := range configs {
+		m.mu.RLock()
 		loader, ok := m.loaders[cfg.Type]
+		m.mu.RUnlock()
 		if !ok {
 			return fmt.Errorf("不支持的加载器类型: %s", cfg.Type)
 		}
 
+		// named 加载器需要获取其他 keystore，加载时不能持有锁
 		ks, err := loader.Load(cfg.Type, cfg.Params)
 		if err != nil {
 			return fmt.Errorf("加载keystore %s 失败: %w", cfg.Name, err)
@@ -70,12 +79,16 @@ func (m *Manager) LoadFromConfigs(configs []ConfigEntry) error {
 			LoaderType: cfg.Type,
 			Params:     cfg.Params,
 			Protected:  false,
+			Issuer:     cfg.Issuer,
+			Renewal:    cfg.Renewal,
 			CreatedAt:  now,
 			UpdatedAt:  now,
 		}
 
+		m.mu.Lock()
 		m.keyStoreInfo[cfg.Name] = info
 		m.keyStores[cfg.Name] = ks
+		m.mu.Unlock()
 	}
 
 	return nil
@@ -104,27 +117,37 @@ func (m *Manager) RegisterLoader(loaderType LoaderType, loader Loader) {
 //
 // 注意：该方法只创建内存中的 keystore，持久化由控制器层负责
 func (m *Manager) Create(name string, loaderType LoaderType, params map[string]string, protected bool) (*KeyStoreInfo, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.mu.RLock()
+	_, exists := m.keyStoreInfo[name]
+	loader, ok := m.loaders[loaderType]
+	m.mu.RUnlock()
 
-	if _, exists := m.keyStoreInfo[name]; exists {
+	if exists {
 		return nil, fmt.Errorf("keystore %s 已存在", name)
 	}
-
-	loader, ok := m.loaders[loaderType]
 	if !ok {
 		return nil, fmt.Errorf("不支持的加载器类型: %s", loaderType)
 	}
 
+	// named 加载器需要获取其他 keystore，加载时不能持有锁
 	ks, err := loader.Load(loaderType, params)
 	if err != nil {
 		return nil, fmt.Errorf("加载keystore失败: %w", err)
 	}
 
+	ksType := ks.Type()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.keyStoreInfo[name]; exists {
+		return nil, fmt.Errorf("keystore %s 已存在", name)
+	}
+
 	now := time.Now()
 	info := &KeyStoreInfo{
 		Name:       name,
-		Type:       ks.Type(),
+		Type:       ksType,
 		LoaderType: loaderType,
 		Params:     params,
 		Protected:  protected,
@@ -138,6 +161,29 @@ func (m *Manager) Create(name string, loaderType LoaderType, params map[string]s
 	return info, nil
 }
 
+// SetIssuer 记录签发 keystore 证书的 CA 及自动续期策略
+// 参数：
+//   - name: keystore 名称
+//   - issuer: 签发证书的 CA keystore 名称
+//   - renewal: 自动续期策略，nil 表示不自动续期
+//
+// 返回：
+//   - error: keystore 不存在时返回错误
+//
+// 注意：该方法只更新内存中的元信息，持久化由控制器层负责
+func (m *Manager) SetIssuer(name, issuer string, renewal *RenewalPolicy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, exists := m.keyStoreInfo[name]
+	if !exists {
+		return fmt.Errorf("keystore %s 不存在", name)
+	}
+	info.Issuer = issuer
+	info.Renewal = renewal
+	return nil
+}
+
 // Delete 删除 keystore
 // 参数：
 //   - name: keystore 名称
@@ -161,10 +207,118 @@ func (m *Manager) Delete(name string) error {
 
 	delete(m.keyStoreInfo, name)
 	delete(m.keyStores, name)
+	delete(m.fileStates, name)
 
 	return nil
 }
 
+// Reload 重新加载 keystore 并通知订阅方
+// 参数：
+//   - name: keystore 名称
+//   - params: 新的加载器参数，为 nil 时使用原有参数
+//
+// 返回：
+//   - *KeyStoreInfo: 更新后的 keystore 信息
+//   - error: keystore 不存在或证书、密钥加载失败时返回错误，此时保留原有的 keystore
+//
+// 注意：
+//   - 新的 keystore 会立即加载证书和密钥，替换掉已缓存的证书
+//   - 通过 named 加载器引用该 keystore 的其他 keystore 同样通知订阅方
+func (m *Manager) Reload(name string, params map[string]string) (*KeyStoreInfo, error) {
+	m.mu.RLock()
+	info, exists := m.keyStoreInfo[name]
+	var loaderType LoaderType
+	var loader Loader
+	if exists {
+		loaderType = info.LoaderType
+		loader = m.loaders[loaderType]
+		if params == nil {
+			params = info.Params
+		}
+	}
+	m.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("keystore %s 不存在", name)
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("不支持的加载器类型: %s", loaderType)
+	}
+
+	ks, err := loader.Load(loaderType, params)
+	if err != nil {
+		return nil, fmt.Errorf("加载keystore失败: %w", err)
+	}
+	// 先记录文件状态再加载证书，加载期间文件再次变化时由变化检测重新加载
+	state := newFileState(ks)
+	if err := preload(ks); err != nil {
+		return nil, fmt.Errorf("加载keystore %s 的证书失败: %w", name, err)
+	}
+
+	ksType := ks.Type()
+
+	m.mu.Lock()
+	m.keyStores[name] = ks
+	info.Params = params
+	info.Type = ksType
+	info.UpdatedAt = time.Now()
+	if state != nil {
+		m.fileStates[name] = state
+	} else {
+		delete(m.fileStates, name)
+	}
+	m.mu.Unlock()
+
+	m.notify(name)
+	return info, nil
+}
+
+// Subscribe 订阅 keystore 变化
+// 参数：
+//   - fn: 通过 Reload 或文件变化检测重新加载 keystore 后以 keystore 名称调用
+//
+// 返回：
+//   - func(): 取消订阅
+//
+// 注意：fn 在触发重新加载的协程中同步调用，耗时的处理应自行启动协程
+func (m *Manager) Subscribe(fn func(name string)) func() {
+	m.mu.Lock()
+	id := m.nextListener
+	m.nextListener++
+	m.listeners[id] = fn
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		delete(m.listeners, id)
+		m.mu.Unlock()
+	}
+}
+
+// notify 通知订阅方 keystore 及通过 named 加载器引用它的 keystore 已变化
+func (m *Manager) notify(name string) {
+	m.mu.RLock()
+	names := []string{name}
+	for i := 0; i < len(names); i++ {
+		for n, info := range m.keyStoreInfo {
+			if info.LoaderType == LoaderTypeNamed && info.Params["name"] == names[i] && !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+	}
+	listeners := make([]func(string), 0, len(m.listeners))
+	for _, fn := range m.listeners {
+		listeners = append(listeners, fn)
+	}
+	m.mu.RUnlock()
+
+	for _, n := range names {
+		for _, fn := range listeners {
+			fn(n)
+		}
+	}
+}
+
 // Set 替换已有的 keystore 实例
 //
 // 功能：
@@ -185,6 +339,8 @@ func (m *Manager) Delete(name string) error {
 //   - 该方法用于 UpdateCertificates 接口更新证书文件后重新加载 keystore
 //   - 会更新 KeyStoreInfo 中的 Params、Type 和 UpdatedAt 字段
 func (m *Manager) Set(name string, ks KeyStore, params map[string]string) error {
+	ksType := ks.Type()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -196,7 +352,7 @@ func (m *Manager) Set(name string, ks KeyStore, params map[string]string) error
 	m.keyStores[name] = ks
 	info.Params = params
 	info.UpdatedAt = time.Now()
-	info.Type = ks.Type()
+	info.Type = ksType
 
 	return nil
 }
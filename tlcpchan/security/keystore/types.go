@@ -40,6 +40,17 @@ const (
 	LoaderTypeSDF   LoaderType = "sdf"
 )
 
+// RenewalPolicy 证书自动续期策略，仅对记录了签发 CA 的文件 keystore 有效
+type RenewalPolicy struct {
+	// BeforeDays 在证书过期前多少天续期，默认 DefaultRenewBeforeDays
+	BeforeDays int `json:"beforeDays,omitempty" yaml:"before-days,omitempty"`
+	// RotateKey 续期时是否生成新密钥，默认沿用原有密钥
+	RotateKey bool `json:"rotateKey,omitempty" yaml:"rotate-key,omitempty"`
+}
+
+// DefaultRenewBeforeDays 默认在证书过期前30天续期
+const DefaultRenewBeforeDays = 30
+
 // KeyStoreInfo keystore 信息
 type KeyStoreInfo struct {
 	Name       string            `json:"name" yaml:"name"`
@@ -47,6 +58,10 @@ type KeyStoreInfo struct {
 	LoaderType LoaderType        `json:"loaderType" yaml:"loaderType"`
 	Params     map[string]string `json:"params" yaml:"params"`
 	Protected  bool              `json:"protected" yaml:"protected"`
-	CreatedAt  time.Time         `json:"createdAt" yaml:"createdAt"`
-	UpdatedAt  time.Time         `json:"updatedAt" yaml:"updatedAt"`
+	// Issuer 签发该 keystore 证书的 CA keystore 名称，为空表示未知
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Renewal 证书自动续期策略，nil 表示不自动续期
+	Renewal   *RenewalPolicy `json:"renewal,omitempty" yaml:"renewal,omitempty"`
+	CreatedAt time.Time      `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" yaml:"updatedAt"`
 }
@@ -0,0 +1,209 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Trisia/tlcpchan/logger"
+)
+
+// DefaultWatchInterval 默认的文件变化检测间隔
+const DefaultWatchInterval = 30 * time.Second
+
+// fileSource 基于文件的 keystore，返回证书和密钥文件路径
+type fileSource interface {
+	files() []string
+}
+
+// fileState keystore 文件状态
+type fileState struct {
+	// stamp 各文件的修改时间与大小，未变化时不再计算摘要
+	stamp string
+	// sum 各文件内容的 SHA-256 摘要，只修改了时间的文件不会触发重新加载
+	sum [sha256.Size]byte
+	// rejected 加载失败的文件内容摘要，内容不变时不再重试
+	rejected [sha256.Size]byte
+}
+
+// newFileState 读取 keystore 当前的文件状态，非文件 keystore 或读取失败时返回 nil
+func newFileState(ks KeyStore) *fileState {
+	src, ok := ks.(fileSource)
+	if !ok {
+		return nil
+	}
+	stamp, err := fileStamp(src.files())
+	if err != nil {
+		return nil
+	}
+	sum, err := fileSum(src.files())
+	if err != nil {
+		return nil
+	}
+	return &fileState{stamp: stamp, sum: sum}
+}
+
+// fileStamp 以 "<路径>:<修改时间>:<大小>" 记录各文件的状态
+func fileStamp(paths []string) (string, error) {
+	var b strings.Builder
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String(), nil
+}
+
+// fileSum 计算各文件内容的 SHA-256 摘要
+func fileSum(paths []string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	h := sha256.New()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return sum, err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return sum, err
+		}
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// preload 加载 keystore 的证书和密钥，用于在替换前确认文件有效
+// 注意：只有签名证书的 keystore 既可能用于 TLS 也可能用于 TLCP，任一方式加载成功即可
+func preload(ks KeyStore) error {
+	if f, ok := ks.(*FileKeyStore); ok {
+		if err := f.verifyPairs(); err != nil {
+			return err
+		}
+	}
+	if ks.Type() == KeyStoreTypeTLCP {
+		_, err := ks.TLCPCertificate()
+		return err
+	}
+	if _, err := ks.TLSCertificate(); err != nil {
+		if _, tlcpErr := ks.TLCPCertificate(); tlcpErr != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartWatch 启动文件 keystore 的变化检测
+// 参数:
+//   - interval: 检测间隔，小于等于0时使用 DefaultWatchInterval
+//
+// 注意:
+//   - 文件的修改时间或大小变化且内容摘要变化时调用 Reload，证书续期后无需手动重载实例
+//   - 加载失败（如只写入了证书还未写入密钥）时保留原有的 keystore，文件内容再次变化后重试
+//   - 重复调用会先停止之前的检测任务，调用 Close 停止
+func (m *Manager) StartWatch(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	m.mu.Lock()
+	if m.stopWatch != nil {
+		close(m.stopWatch)
+	}
+	stop := make(chan struct{})
+	m.stopWatch = stop
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.DetectChanges()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止文件变化检测
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopWatch != nil {
+		close(m.stopWatch)
+		m.stopWatch = nil
+	}
+}
+
+// DetectChanges 检查所有文件 keystore 的文件是否变化，变化时重新加载
+// 返回:
+//   - []string: 重新加载成功的 keystore 名称
+//
+// 注意: 首次检查只记录文件状态
+func (m *Manager) DetectChanges() []string {
+	m.mu.RLock()
+	sources := make(map[string]fileSource)
+	for name, ks := range m.keyStores {
+		if src, ok := ks.(fileSource); ok {
+			sources[name] = src
+		}
+	}
+	m.mu.RUnlock()
+
+	var reloaded []string
+	for name, src := range sources {
+		// 替换文件的过程中可能暂时不存在，下次检查时重试
+		stamp, err := fileStamp(src.files())
+		if err != nil {
+			continue
+		}
+
+		m.mu.Lock()
+		state := m.fileStates[name]
+		if state == nil {
+			if state = newFileState(src.(KeyStore)); state != nil {
+				m.fileStates[name] = state
+			}
+			m.mu.Unlock()
+			continue
+		}
+		changed := state.stamp != stamp
+		m.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		sum, err := fileSum(src.files())
+		if err != nil {
+			continue
+		}
+		m.mu.Lock()
+		if sum == state.sum || sum == state.rejected {
+			state.stamp = stamp
+			m.mu.Unlock()
+			continue
+		}
+		m.mu.Unlock()
+
+		if _, err := m.Reload(name, nil); err != nil {
+			m.mu.Lock()
+			state.stamp = stamp
+			state.rejected = sum
+			m.mu.Unlock()
+			logger.Warn("keystore %s 的文件已变化，但重新加载失败: %v", name, err)
+			continue
+		}
+		logger.Info("keystore %s 的文件已变化，已重新加载", name)
+		reloaded = append(reloaded, name)
+	}
+	return reloaded
+}
@@ -0,0 +1,160 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+)
+
+// writeTLSKeyStore 生成自签名证书写入 keystore 文件，并将修改时间设置为 mtime
+func writeTLSKeyStore(t *testing.T, certPath, keyPath, cn string, mtime time.Time) []byte {
+	t.Helper()
+	cert, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: cn, Days: 1})
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(cert.CertPEM, cert.KeyPEM, certPath, keyPath); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	block, _ := pem.Decode(cert.CertPEM)
+	return block.Bytes
+}
+
+// leaf 获取 keystore 当前的TLS证书
+func leaf(t *testing.T, m *Manager, name string) []byte {
+	t.Helper()
+	ks, err := m.GetKeyStore(name)
+	if err != nil {
+		t.Fatalf("GetKeyStore(%s) error = %v", name, err)
+	}
+	cert, err := ks.TLSCertificate()
+	if err != nil {
+		t.Fatalf("TLSCertificate() error = %v", err)
+	}
+	return cert.Certificate[0]
+}
+
+func TestManager_DetectChanges(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "web.crt")
+	keyPath := filepath.Join(dir, "web.key")
+	base := time.Now().Add(-time.Hour)
+	v1 := writeTLSKeyStore(t, certPath, keyPath, "v1", base)
+
+	m := NewManager()
+	if _, err := m.Create("web", LoaderTypeFile, map[string]string{"sign-cert": certPath, "sign-key": keyPath}, false); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := m.Create("alias", LoaderTypeNamed, map[string]string{"name": "web"}, false); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	var notified []string
+	m.Subscribe(func(name string) { notified = append(notified, name) })
+
+	if !bytes.Equal(leaf(t, m, "web"), v1) {
+		t.Fatal("初始证书错误")
+	}
+	// 首次检查只记录文件状态
+	if got := m.DetectChanges(); len(got) != 0 {
+		t.Errorf("首次检查不应重新加载: %v", got)
+	}
+
+	// 只修改了时间，内容不变
+	for _, path := range []string{certPath, keyPath} {
+		os.Chtimes(path, base.Add(time.Minute), base.Add(time.Minute))
+	}
+	if got := m.DetectChanges(); len(got) != 0 {
+		t.Errorf("内容未变化时不应重新加载: %v", got)
+	}
+
+	// 证书续期
+	v2 := writeTLSKeyStore(t, certPath, keyPath, "v2", base.Add(2*time.Minute))
+	if got := m.DetectChanges(); !slices.Equal(got, []string{"web"}) {
+		t.Fatalf("DetectChanges() = %v, 期望 [web]", got)
+	}
+	if !bytes.Equal(leaf(t, m, "web"), v2) {
+		t.Error("重新加载后应使用新证书")
+	}
+	if !bytes.Equal(leaf(t, m, "alias"), v2) {
+		t.Error("通过 named 加载器引用的 keystore 应使用新证书")
+	}
+	if !slices.Equal(notified, []string{"web", "alias"}) {
+		t.Errorf("通知 = %v, 期望 [web alias]", notified)
+	}
+
+	// 写入了无效的证书，保留原有的 keystore 且不重复尝试
+	notified = nil
+	os.WriteFile(certPath, []byte("invalid"), 0644)
+	os.Chtimes(certPath, base.Add(3*time.Minute), base.Add(3*time.Minute))
+	for i := 0; i < 2; i++ {
+		if got := m.DetectChanges(); len(got) != 0 {
+			t.Errorf("加载失败时不应返回: %v", got)
+		}
+	}
+	if !bytes.Equal(leaf(t, m, "web"), v2) || len(notified) != 0 {
+		t.Error("加载失败时应保留原有的 keystore")
+	}
+}
+
+func TestManager_Reload(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	writeTLSKeyStore(t, filepath.Join(dir, "a.crt"), filepath.Join(dir, "a.key"), "a", base)
+	b := writeTLSKeyStore(t, filepath.Join(dir, "b.crt"), filepath.Join(dir, "b.key"), "b", base)
+
+	m := NewManager()
+	if _, err := m.Create("web", LoaderTypeFile, map[string]string{
+		"sign-cert": filepath.Join(dir, "a.crt"), "sign-key": filepath.Join(dir, "a.key"),
+	}, false); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	leaf(t, m, "web")
+
+	var notified []string
+	cancel := m.Subscribe(func(name string) { notified = append(notified, name) })
+
+	// 证书与密钥不匹配时保留原有的 keystore
+	if _, err := m.Reload("web", map[string]string{
+		"sign-cert": filepath.Join(dir, "b.crt"), "sign-key": filepath.Join(dir, "a.key"),
+	}); err == nil {
+		t.Error("证书与密钥不匹配时应返回错误")
+	}
+
+	info, err := m.Reload("web", map[string]string{
+		"sign-cert": filepath.Join(dir, "b.crt"), "sign-key": filepath.Join(dir, "b.key"),
+	})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if info.Params["sign-cert"] != filepath.Join(dir, "b.crt") {
+		t.Errorf("参数未更新: %v", info.Params)
+	}
+	if !bytes.Equal(leaf(t, m, "web"), b) {
+		t.Error("重新加载后应使用新证书")
+	}
+	if !slices.Equal(notified, []string{"web"}) {
+		t.Errorf("通知 = %v, 期望 [web]", notified)
+	}
+
+	cancel()
+	if _, err := m.Reload("web", nil); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(notified) != 1 {
+		t.Error("取消订阅后不应再收到通知")
+	}
+	if _, err := m.Reload("missing", nil); err == nil {
+		t.Error("keystore 不存在时应返回错误")
+	}
+}
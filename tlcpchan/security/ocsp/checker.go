@@ -0,0 +1,230 @@
+package ocsp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// FailurePolicy 无法确定证书状态时的处理策略
+type FailurePolicy string
+
+const (
+	// SoftFail 放行，仅在证书明确被吊销时拒绝
+	SoftFail FailurePolicy = "soft-fail"
+	// HardFail 拒绝，只有确认证书有效时才放行
+	HardFail FailurePolicy = "hard-fail"
+)
+
+const (
+	// DefaultTimeout 默认查询超时
+	DefaultTimeout = 5 * time.Second
+	// DefaultCacheTTL 默认响应缓存最长时间
+	DefaultCacheTTL = time.Hour
+)
+
+// Options 证书状态检查选项
+type Options struct {
+	// Responders 响应服务地址列表，为空时使用证书 AIA 扩展中的地址
+	Responders []string
+	// Policy 无法确定证书状态时的处理策略，默认 SoftFail
+	Policy FailurePolicy
+	// Timeout 单次查询超时，默认 DefaultTimeout
+	Timeout time.Duration
+	// CacheTTL 响应缓存最长时间，默认 DefaultCacheTTL，响应的 NextUpdate 更早时以 NextUpdate 为准
+	CacheTTL time.Duration
+	// OnError 软失败放行或装订响应刷新失败时的回调，可为nil
+	OnError func(cert *smx509.Certificate, err error)
+}
+
+// Cache OCSP响应缓存，并发安全，可在多个 Checker 之间共享
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	resp    *Response
+	expires time.Time
+}
+
+// NewCache 创建响应缓存
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *Cache) get(key string) *Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry.resp
+}
+
+func (c *Cache) put(key string, resp *Response, ttl time.Duration) {
+	if !resp.NextUpdate.IsZero() {
+		if untilNext := time.Until(resp.NextUpdate); untilNext < ttl {
+			ttl = untilNext
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{resp: resp, expires: time.Now().Add(ttl)}
+}
+
+// cacheKey 以签发者公钥和证书序列号标识证书
+func cacheKey(cert, issuer *smx509.Certificate) string {
+	sum := sha256.Sum256(issuer.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:]) + ":" + cert.SerialNumber.Text(16)
+}
+
+// Checker 基于 OCSP 的证书状态检查器
+type Checker struct {
+	opts   Options
+	cache  *Cache
+	client *http.Client
+}
+
+// NewChecker 创建证书状态检查器
+// 参数:
+//   - opts: 检查选项，未设置的字段使用默认值
+//   - cache: 响应缓存，为nil时创建独立缓存
+//
+// 返回:
+//   - *Checker: 检查器实例
+func NewChecker(opts Options, cache *Cache) *Checker {
+	if opts.Policy == "" {
+		opts.Policy = SoftFail
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if cache == nil {
+		cache = NewCache()
+	}
+	return &Checker{
+		opts:   opts,
+		cache:  cache,
+		client: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// Check 检查证书状态
+// 参数:
+//   - cert: 待检查的证书
+//   - issuer: 证书的签发者
+//
+// 返回:
+//   - error: 证书已吊销，或硬失败策略下无法确认证书有效时返回错误
+func (c *Checker) Check(cert, issuer *smx509.Certificate) error {
+	resp, err := c.status(cert, issuer, true)
+	if err != nil {
+		return c.fail(cert, err)
+	}
+	return c.evaluate(cert, resp)
+}
+
+// VerifyChains 检查已验证证书链中对端证书的状态，可作为握手的证书验证回调使用
+// 参数:
+//   - verifiedChains: 握手过程中验证通过的证书链
+//   - staple: 对端装订的OCSP响应，可为nil，有效时优先使用，无需再查询响应服务
+//
+// 返回:
+//   - error: 对端证书已吊销，或硬失败策略下无法确认证书有效时返回错误
+//
+// 注意: 只检查对端证书，没有已验证证书链（如未验证对端证书）时不检查
+func (c *Checker) VerifyChains(verifiedChains [][]*smx509.Certificate, staple []byte) error {
+	if len(verifiedChains) == 0 || len(verifiedChains[0]) < 2 {
+		return nil
+	}
+	cert, issuer := verifiedChains[0][0], verifiedChains[0][1]
+
+	if len(staple) > 0 {
+		if resp, err := ParseResponse(staple, cert, issuer); err == nil {
+			c.cache.put(cacheKey(cert, issuer), resp, c.opts.CacheTTL)
+			return c.evaluate(cert, resp)
+		}
+	}
+	return c.Check(cert, issuer)
+}
+
+// Fetch 查询证书状态，不使用缓存
+func (c *Checker) Fetch(cert, issuer *smx509.Certificate) (*Response, error) {
+	return c.status(cert, issuer, false)
+}
+
+// status 获取证书状态，优先使用缓存，依次尝试各个响应服务
+func (c *Checker) status(cert, issuer *smx509.Certificate, useCache bool) (*Response, error) {
+	key := cacheKey(cert, issuer)
+	if useCache {
+		if resp := c.cache.get(key); resp != nil {
+			return resp, nil
+		}
+	}
+
+	urls := c.opts.Responders
+	if len(urls) == 0 {
+		urls = cert.OCSPServer
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("未配置OCSP响应服务地址，证书也未包含AIA扩展")
+	}
+
+	var lastErr error
+	for _, url := range urls {
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+		resp, err := Query(ctx, c.client, url, cert, issuer)
+		cancel()
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", url, err)
+			continue
+		}
+		c.cache.put(key, resp, c.opts.CacheTTL)
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+func (c *Checker) evaluate(cert *smx509.Certificate, resp *Response) error {
+	switch resp.Status {
+	case Good:
+		return nil
+	case Revoked:
+		return fmt.Errorf("证书 %s（序列号 %s）已于 %s 被吊销",
+			cert.Subject.String(), cert.SerialNumber.Text(16), resp.RevokedAt.Format(time.RFC3339))
+	default:
+		return c.fail(cert, fmt.Errorf("OCSP响应服务无法确认证书 %s 的状态", cert.Subject.String()))
+	}
+}
+
+// fail 按处理策略处理无法确定证书状态的情况
+func (c *Checker) fail(cert *smx509.Certificate, err error) error {
+	if c.opts.Policy == HardFail {
+		return fmt.Errorf("OCSP检查失败: %w", err)
+	}
+	if c.opts.OnError != nil {
+		c.opts.OnError(cert, err)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package ocsp 实现 OCSP(RFC 6960) 证书状态查询
+// 同时支持 SM2 与 RSA/ECDSA 签名的 OCSP 响应，用于 TLCP 与 TLS 的对端证书状态检查和 TLS OCSP 装订
+package ocsp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/emmansun/gmsm/sm3"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// Status 证书状态
+type Status int
+
+const (
+	// Good 证书有效
+	Good Status = iota
+	// Revoked 证书已吊销
+	Revoked
+	// Unknown 响应服务不认识该证书
+	Unknown
+)
+
+func (s Status) String() string {
+	switch s {
+	case Good:
+		return "good"
+	case Revoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// maxClockSkew 校验响应有效期时允许的时钟偏差
+const maxClockSkew = 5 * time.Minute
+
+// maxResponseSize OCSP响应最大长度
+const maxResponseSize = 1 << 20
+
+var (
+	oidOCSPBasic = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSM3    = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 401}
+)
+
+// signatureAlgorithms 支持的响应签名算法
+var signatureAlgorithms = []struct {
+	oid  asn1.ObjectIdentifier
+	algo smx509.SignatureAlgorithm
+}{
+	{asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}, smx509.SM2WithSM3},
+	{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 5}, smx509.SHA1WithRSA},
+	{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}, smx509.SHA256WithRSA},
+	{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}, smx509.SHA384WithRSA},
+	{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}, smx509.SHA512WithRSA},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 1}, smx509.ECDSAWithSHA1},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}, smx509.ECDSAWithSHA256},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}, smx509.ECDSAWithSHA384},
+	{asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}, smx509.ECDSAWithSHA512},
+	{asn1.ObjectIdentifier{1, 3, 101, 112}, smx509.PureEd25519},
+}
+
+type certID struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	NameHash      []byte
+	IssuerKeyHash []byte
+	SerialNumber  *big.Int
+}
+
+type ocspRequest struct {
+	TBSRequest tbsRequest
+}
+
+type tbsRequest struct {
+	Version     int `asn1:"explicit,tag:0,default:0,optional"`
+	RequestList []request
+}
+
+type request struct {
+	Cert certID
+}
+
+type responseASN1 struct {
+	Status   asn1.Enumerated
+	Response responseBytes `asn1:"explicit,tag:0,optional"`
+}
+
+type responseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type basicResponse struct {
+	TBSResponseData    responseData
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+	Certificates       []asn1.RawValue `asn1:"explicit,tag:0,optional"`
+}
+
+type responseData struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,default:0,explicit,tag:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          []singleResponse
+	ResponseExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type singleResponse struct {
+	CertID           certID
+	Good             asn1.Flag        `asn1:"tag:0,optional"`
+	Revoked          revokedInfo      `asn1:"tag:1,optional"`
+	Unknown          asn1.Flag        `asn1:"tag:2,optional"`
+	ThisUpdate       time.Time        `asn1:"generalized"`
+	NextUpdate       time.Time        `asn1:"generalized,explicit,tag:0,optional"`
+	SingleExtensions []pkix.Extension `asn1:"explicit,tag:1,optional"`
+}
+
+type revokedInfo struct {
+	RevocationTime time.Time       `asn1:"generalized"`
+	Reason         asn1.Enumerated `asn1:"explicit,tag:0,optional"`
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// Response 已验证的 OCSP 响应
+type Response struct {
+	// Status 证书状态
+	Status Status
+	// SerialNumber 证书序列号
+	SerialNumber *big.Int
+	// ProducedAt 响应生成时间
+	ProducedAt time.Time
+	// ThisUpdate 状态信息生成时间
+	ThisUpdate time.Time
+	// NextUpdate 下次更新时间，零值表示未指定
+	NextUpdate time.Time
+	// RevokedAt 吊销时间，仅 Status 为 Revoked 时有效
+	RevokedAt time.Time
+	// Raw 原始 DER 编码响应，可用于 TLS OCSP 装订
+	Raw []byte
+}
+
+// CreateRequest 创建 OCSP 请求
+// 参数:
+//   - cert: 待查询的证书
+//   - issuer: 证书的签发者
+//
+// 返回:
+//   - []byte: DER 编码的 OCSP 请求
+//   - error: 解析签发者公钥失败时返回错误
+//
+// 注意: CertID 使用 SHA-1 摘要，这是 RFC 5019 要求响应服务必须支持的算法
+func CreateRequest(cert, issuer *smx509.Certificate) ([]byte, error) {
+	id, err := newCertID(oidSHA1, cert.SerialNumber, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ocspRequest{tbsRequest{RequestList: []request{{Cert: *id}}}})
+}
+
+func newCertID(hashOID asn1.ObjectIdentifier, serial *big.Int, issuer *smx509.Certificate) (*certID, error) {
+	nameHash, keyHash, err := issuerHashes(hashOID, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return &certID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: hashOID, Parameters: asn1.NullRawValue},
+		NameHash:      nameHash,
+		IssuerKeyHash: keyHash,
+		SerialNumber:  serial,
+	}, nil
+}
+
+// issuerHashes 计算签发者名称和公钥的摘要
+func issuerHashes(hashOID asn1.ObjectIdentifier, issuer *smx509.Certificate) (nameHash, keyHash []byte, err error) {
+	var h hash.Hash
+	switch {
+	case hashOID.Equal(oidSHA1):
+		h = sha1.New()
+	case hashOID.Equal(oidSHA256):
+		h = sha256.New()
+	case hashOID.Equal(oidSM3):
+		h = sm3.New()
+	default:
+		return nil, nil, fmt.Errorf("不支持的CertID摘要算法: %s", hashOID)
+	}
+
+	var spki subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, nil, fmt.Errorf("解析签发者公钥失败: %w", err)
+	}
+
+	h.Write(issuer.RawSubject)
+	nameHash = h.Sum(nil)
+	h.Reset()
+	h.Write(spki.PublicKey.RightAlign())
+	keyHash = h.Sum(nil)
+	return nameHash, keyHash, nil
+}
+
+// ParseResponse 解析并验证 OCSP 响应
+// 参数:
+//   - der: DER 编码的 OCSP 响应
+//   - cert: 待查询的证书
+//   - issuer: 证书的签发者
+//
+// 返回:
+//   - *Response: 证书对应的状态信息
+//   - error: 响应格式错误、签名无效、不包含该证书或已过期时返回错误
+//
+// 注意: 响应可由签发者直接签名，也可由签发者授权的 OCSP 签名证书（扩展密钥用途含 OCSPSigning）签名
+func ParseResponse(der []byte, cert, issuer *smx509.Certificate) (*Response, error) {
+	var resp responseASN1
+	rest, err := asn1.Unmarshal(der, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("解析OCSP响应失败: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("OCSP响应包含多余数据")
+	}
+	if resp.Status != 0 {
+		return nil, fmt.Errorf("OCSP响应状态异常: %d", resp.Status)
+	}
+	if !resp.Response.ResponseType.Equal(oidOCSPBasic) {
+		return nil, errors.New("不支持的OCSP响应类型")
+	}
+
+	var basic basicResponse
+	if _, err := asn1.Unmarshal(resp.Response.Response, &basic); err != nil {
+		return nil, fmt.Errorf("解析OCSP响应失败: %w", err)
+	}
+
+	if err := verifySignature(&basic, issuer); err != nil {
+		return nil, err
+	}
+
+	single, err := findSingleResponse(basic.TBSResponseData.Responses, cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if single.ThisUpdate.After(now.Add(maxClockSkew)) {
+		return nil, errors.New("OCSP响应尚未生效")
+	}
+	if !single.NextUpdate.IsZero() && single.NextUpdate.Before(now.Add(-maxClockSkew)) {
+		return nil, errors.New("OCSP响应已过期")
+	}
+
+	r := &Response{
+		Status:       Unknown,
+		SerialNumber: single.CertID.SerialNumber,
+		ProducedAt:   basic.TBSResponseData.ProducedAt,
+		ThisUpdate:   single.ThisUpdate,
+		NextUpdate:   single.NextUpdate,
+		Raw:          der,
+	}
+	switch {
+	case bool(single.Good):
+		r.Status = Good
+	case !single.Revoked.RevocationTime.IsZero():
+		r.Status = Revoked
+		r.RevokedAt = single.Revoked.RevocationTime
+	}
+	return r, nil
+}
+
+// verifySignature 验证响应签名
+func verifySignature(basic *basicResponse, issuer *smx509.Certificate) error {
+	var algo smx509.SignatureAlgorithm
+	found := false
+	for _, a := range signatureAlgorithms {
+		if basic.SignatureAlgorithm.Algorithm.Equal(a.oid) {
+			algo, found = a.algo, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("不支持的OCSP响应签名算法: %s", basic.SignatureAlgorithm.Algorithm)
+	}
+
+	signer := issuer
+	if len(basic.Certificates) > 0 {
+		responder, err := smx509.ParseCertificate(basic.Certificates[0].FullBytes)
+		if err != nil {
+			return fmt.Errorf("解析OCSP签名证书失败: %w", err)
+		}
+		if !bytes.Equal(responder.Raw, issuer.Raw) {
+			if err := responder.CheckSignatureFrom(issuer); err != nil {
+				return fmt.Errorf("OCSP签名证书不是由证书签发者签发: %w", err)
+			}
+			if !hasOCSPSigning(responder) {
+				return errors.New("OCSP签名证书缺少 OCSPSigning 扩展密钥用途")
+			}
+			signer = responder
+		}
+	}
+
+	if err := signer.CheckSignature(algo, basic.TBSResponseData.Raw, basic.Signature.RightAlign()); err != nil {
+		return fmt.Errorf("OCSP响应签名无效: %w", err)
+	}
+	return nil
+}
+
+func hasOCSPSigning(cert *smx509.Certificate) bool {
+	for _, usage := range cert.ExtKeyUsage {
+		if usage == smx509.ExtKeyUsageOCSPSigning {
+			return true
+		}
+	}
+	return false
+}
+
+// findSingleResponse 查找与证书及其签发者匹配的单个响应
+func findSingleResponse(responses []singleResponse, cert, issuer *smx509.Certificate) (*singleResponse, error) {
+	for i := range responses {
+		single := &responses[i]
+		if single.CertID.SerialNumber == nil || single.CertID.SerialNumber.Cmp(cert.SerialNumber) != 0 {
+			continue
+		}
+		nameHash, keyHash, err := issuerHashes(single.CertID.HashAlgorithm.Algorithm, issuer)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(nameHash, single.CertID.NameHash) && bytes.Equal(keyHash, single.CertID.IssuerKeyHash) {
+			return single, nil
+		}
+	}
+	return nil, errors.New("OCSP响应中不包含该证书的状态")
+}
+
+// Query 向 OCSP 响应服务查询证书状态
+// 参数:
+//   - ctx: 上下文，用于控制超时
+//   - client: HTTP客户端
+//   - url: 响应服务地址
+//   - cert: 待查询的证书
+//   - issuer: 证书的签发者
+//
+// 返回:
+//   - *Response: 已验证的响应
+//   - error: 请求失败或响应无效时返回错误
+func Query(ctx context.Context, client *http.Client, url string, cert, issuer *smx509.Certificate) (*Response, error) {
+	reqDER, err := CreateRequest(cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqDER))
+	if err != nil {
+		return nil, fmt.Errorf("创建OCSP请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求OCSP响应服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP响应服务返回状态码 %d", resp.StatusCode)
+	}
+	der, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取OCSP响应失败: %w", err)
+	}
+	return ParseResponse(der, cert, issuer)
+}
@@ -0,0 +1,354 @@
+package ocsp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/emmansun/gmsm/sm2"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+var (
+	oidSignatureSM2WithSM3      = asn1.ObjectIdentifier{1, 2, 156, 10197, 1, 501}
+	oidSignatureECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+)
+
+// testPKI 测试用CA、OCSP签名证书及其签发的证书
+type testPKI struct {
+	sm2       bool
+	ca        *smx509.Certificate
+	caKey     crypto.Signer
+	responder *smx509.Certificate
+	respKey   crypto.Signer
+}
+
+func generateKey(t *testing.T, useSM2 bool) crypto.Signer {
+	t.Helper()
+	var key crypto.Signer
+	var err error
+	if useSM2 {
+		key, err = sm2.GenerateKey(rand.Reader)
+	} else {
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	return key
+}
+
+func newTestPKI(t *testing.T, useSM2 bool) *testPKI {
+	t.Helper()
+	p := &testPKI{sm2: useSM2}
+	p.caKey = generateKey(t, useSM2)
+	p.ca = p.createCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ocsp-test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, p.caKey.Public())
+	p.respKey = generateKey(t, useSM2)
+	p.responder = p.createCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "ocsp-test-responder"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+	}, p.respKey.Public())
+	return p
+}
+
+var serialCounter atomic.Int64
+
+// createCert 由CA签发证书，CA尚未创建时生成自签名证书
+func (p *testPKI) createCert(t *testing.T, template *x509.Certificate, pub crypto.PublicKey) *smx509.Certificate {
+	t.Helper()
+	template.SerialNumber = big.NewInt(serialCounter.Add(1))
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(24 * time.Hour)
+
+	parent := (*smx509.Certificate)(template)
+	if p.ca != nil {
+		parent = p.ca
+	}
+	der, err := smx509.CreateCertificate(rand.Reader, (*smx509.Certificate)(template), parent, pub, p.caKey)
+	if err != nil {
+		t.Fatalf("签发证书失败: %v", err)
+	}
+	cert, err := smx509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("解析证书失败: %v", err)
+	}
+	return cert
+}
+
+func (p *testPKI) leaf(t *testing.T, cn string, ocspServer ...string) *smx509.Certificate {
+	t.Helper()
+	return p.createCert(t, &x509.Certificate{
+		Subject:    pkix.Name{CommonName: cn},
+		KeyUsage:   x509.KeyUsageDigitalSignature,
+		OCSPServer: ocspServer,
+	}, generateKey(t, p.sm2).Public())
+}
+
+// sign 使用签名密钥签名待签数据
+func (p *testPKI) sign(t *testing.T, key crypto.Signer, tbs []byte) ([]byte, asn1.ObjectIdentifier) {
+	t.Helper()
+	if p.sm2 {
+		sig, err := key.Sign(rand.Reader, tbs, sm2.DefaultSM2SignerOpts)
+		if err != nil {
+			t.Fatalf("签名失败: %v", err)
+		}
+		return sig, oidSignatureSM2WithSM3
+	}
+	digest := sha256.Sum256(tbs)
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	return sig, oidSignatureECDSAWithSHA256
+}
+
+// response 构造 OCSP 响应
+// 参数 delegated 为 true 时由OCSP签名证书签名并在响应中携带该证书，否则由CA直接签名
+func (p *testPKI) response(t *testing.T, serial *big.Int, status Status, delegated bool) []byte {
+	t.Helper()
+	signer, key := p.ca, p.caKey
+	if delegated {
+		signer, key = p.responder, p.respKey
+	}
+
+	id, err := newCertID(oidSHA1, serial, p.ca)
+	if err != nil {
+		t.Fatalf("构造CertID失败: %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	single := singleResponse{
+		CertID:     *id,
+		ThisUpdate: now.Add(-time.Minute),
+		NextUpdate: now.Add(time.Hour),
+	}
+	switch status {
+	case Good:
+		single.Good = true
+	case Revoked:
+		single.Revoked = revokedInfo{RevocationTime: now.Add(-time.Minute)}
+	default:
+		single.Unknown = true
+	}
+
+	tbs := responseData{
+		RawResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: signer.RawSubject},
+		ProducedAt:     now,
+		Responses:      []singleResponse{single},
+	}
+	tbsDER, err := asn1.Marshal(tbs)
+	if err != nil {
+		t.Fatalf("编码响应数据失败: %v", err)
+	}
+	tbs.Raw = tbsDER
+
+	sig, oid := p.sign(t, key, tbsDER)
+	basic := basicResponse{
+		TBSResponseData:    tbs,
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oid},
+		Signature:          asn1.BitString{Bytes: sig, BitLength: 8 * len(sig)},
+	}
+	if delegated {
+		basic.Certificates = []asn1.RawValue{{FullBytes: p.responder.Raw}}
+	}
+	basicDER, err := asn1.Marshal(basic)
+	if err != nil {
+		t.Fatalf("编码响应失败: %v", err)
+	}
+	der, err := asn1.Marshal(responseASN1{Response: responseBytes{ResponseType: oidOCSPBasic, Response: basicDER}})
+	if err != nil {
+		t.Fatalf("编码响应失败: %v", err)
+	}
+	return der
+}
+
+// responderStub 本地 OCSP 响应服务桩
+type responderStub struct {
+	*httptest.Server
+	pki      *testPKI
+	hits     atomic.Int64
+	mu       sync.Mutex
+	statuses map[string]Status
+}
+
+func newResponderStub(t *testing.T, pki *testPKI) *responderStub {
+	t.Helper()
+	s := &responderStub{pki: pki, statuses: make(map[string]Status)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		var req ocspRequest
+		if _, err := asn1.Unmarshal(body, &req); err != nil || len(req.TBSRequest.RequestList) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		serial := req.TBSRequest.RequestList[0].Cert.SerialNumber
+
+		s.mu.Lock()
+		status, ok := s.statuses[serial.String()]
+		s.mu.Unlock()
+		if !ok {
+			status = Unknown
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(pki.response(t, serial, status, true))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *responderStub) set(cert *smx509.Certificate, status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[cert.SerialNumber.String()] = status
+}
+
+func TestCheckerCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		sm2  bool
+	}{
+		{"SM2签名响应", true},
+		{"ECDSA签名响应", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pki := newTestPKI(t, tc.sm2)
+			stub := newResponderStub(t, pki)
+			good := pki.leaf(t, "good", stub.URL)
+			revoked := pki.leaf(t, "revoked", stub.URL)
+			stub.set(good, Good)
+			stub.set(revoked, Revoked)
+
+			checker := NewChecker(Options{Policy: HardFail}, nil)
+			if err := checker.Check(good, pki.ca); err != nil {
+				t.Fatalf("有效证书不应被拒绝: %v", err)
+			}
+			if err := checker.Check(revoked, pki.ca); err == nil {
+				t.Error("已吊销证书应被拒绝")
+			}
+
+			hits := stub.hits.Load()
+			if err := checker.Check(good, pki.ca); err != nil {
+				t.Fatalf("有效证书不应被拒绝: %v", err)
+			}
+			if stub.hits.Load() != hits {
+				t.Error("缓存有效期内不应重复查询响应服务")
+			}
+		})
+	}
+}
+
+func TestCheckerFailurePolicy(t *testing.T) {
+	pki := newTestPKI(t, true)
+	stub := newResponderStub(t, pki)
+	unknown := pki.leaf(t, "unknown", stub.URL)
+	noAIA := pki.leaf(t, "no-aia")
+
+	var softFailures atomic.Int64
+	soft := NewChecker(Options{OnError: func(*smx509.Certificate, error) { softFailures.Add(1) }}, nil)
+	hard := NewChecker(Options{Policy: HardFail}, nil)
+
+	if err := soft.Check(unknown, pki.ca); err != nil {
+		t.Errorf("soft-fail 策略下状态未知的证书应放行: %v", err)
+	}
+	if err := hard.Check(unknown, pki.ca); err == nil {
+		t.Error("hard-fail 策略下状态未知的证书应被拒绝")
+	}
+	if err := soft.Check(noAIA, pki.ca); err != nil {
+		t.Errorf("soft-fail 策略下无法查询的证书应放行: %v", err)
+	}
+	if err := hard.Check(noAIA, pki.ca); err == nil {
+		t.Error("hard-fail 策略下无法查询的证书应被拒绝")
+	}
+	if softFailures.Load() != 2 {
+		t.Errorf("soft-fail 放行时应调用 OnError 2 次, 实际为 %d", softFailures.Load())
+	}
+
+	stub.set(noAIA, Good)
+	configured := NewChecker(Options{Policy: HardFail, Responders: []string{stub.URL}}, nil)
+	if err := configured.Check(noAIA, pki.ca); err != nil {
+		t.Errorf("应使用配置的响应服务地址查询: %v", err)
+	}
+}
+
+func TestVerifyChainsUsesStaple(t *testing.T) {
+	pki := newTestPKI(t, true)
+	stub := newResponderStub(t, pki)
+	leaf := pki.leaf(t, "stapled", stub.URL)
+	chains := [][]*smx509.Certificate{{leaf, pki.ca}}
+
+	checker := NewChecker(Options{Policy: HardFail}, nil)
+	if err := checker.VerifyChains(chains, pki.response(t, leaf.SerialNumber, Revoked, false)); err == nil {
+		t.Error("装订响应表明证书已吊销时应拒绝")
+	}
+	if stub.hits.Load() != 0 {
+		t.Error("装订响应有效时不应查询响应服务")
+	}
+	if err := checker.VerifyChains(nil, nil); err != nil {
+		t.Errorf("没有已验证证书链时不应检查: %v", err)
+	}
+}
+
+func TestParseResponseRejectsForgedSignature(t *testing.T) {
+	pki := newTestPKI(t, true)
+	forger := newTestPKI(t, true)
+	leaf := pki.leaf(t, "leaf")
+
+	if _, err := ParseResponse(pki.response(t, leaf.SerialNumber, Good, false), leaf, pki.ca); err != nil {
+		t.Fatalf("CA签名的响应应验证通过: %v", err)
+	}
+	if _, err := ParseResponse(pki.response(t, leaf.SerialNumber, Good, true), leaf, pki.ca); err != nil {
+		t.Fatalf("授权OCSP签名证书签名的响应应验证通过: %v", err)
+	}
+	if _, err := ParseResponse(forger.response(t, leaf.SerialNumber, Good, false), leaf, pki.ca); err == nil {
+		t.Error("其他CA签名的响应应被拒绝")
+	}
+	if _, err := ParseResponse(forger.response(t, leaf.SerialNumber, Good, true), leaf, pki.ca); err == nil {
+		t.Error("未经CA授权的OCSP签名证书签名的响应应被拒绝")
+	}
+}
+
+func TestStapler(t *testing.T) {
+	pki := newTestPKI(t, false)
+	stub := newResponderStub(t, pki)
+	leaf := pki.leaf(t, "server", stub.URL)
+	stub.set(leaf, Good)
+
+	stapler, err := NewStapler(tls.Certificate{Certificate: [][]byte{leaf.Raw}}, pki.ca, NewChecker(Options{}, nil))
+	if err != nil {
+		t.Fatalf("NewStapler() error = %v", err)
+	}
+	if err := stapler.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	cert, err := stapler.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() error = %v", err)
+	}
+	resp, err := ParseResponse(cert.OCSPStaple, leaf, pki.ca)
+	if err != nil {
+		t.Fatalf("装订响应应可验证: %v", err)
+	}
+	if resp.Status != Good {
+		t.Errorf("装订响应状态应为 good, 实际为 %s", resp.Status)
+	}
+}
@@ -0,0 +1,106 @@
+package ocsp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// stapleRetryInterval 装订响应刷新失败后的重试间隔
+const stapleRetryInterval = time.Minute
+
+// Stapler 为 TLS 服务端证书维护 OCSP 装订响应
+// 握手时通过 GetCertificate 返回携带最新有效响应的证书，响应过半有效期后在后台刷新
+type Stapler struct {
+	cert    tls.Certificate
+	leaf    *smx509.Certificate
+	issuer  *smx509.Certificate
+	checker *Checker
+
+	mu          sync.Mutex
+	resp        *Response
+	refreshAt   time.Time
+	refreshing  bool
+	nextAttempt time.Time
+}
+
+// NewStapler 创建装订响应维护器
+// 参数:
+//   - cert: TLS服务端证书
+//   - issuer: 证书的签发者
+//   - checker: 用于查询响应服务的检查器，使用证书 AIA 扩展中的地址时 Responders 应为空
+//
+// 返回:
+//   - *Stapler: 装订响应维护器
+//   - error: 证书为空或解析失败时返回错误
+func NewStapler(cert tls.Certificate, issuer *smx509.Certificate, checker *Checker) (*Stapler, error) {
+	if len(cert.Certificate) == 0 {
+		return nil, errors.New("TLS证书为空")
+	}
+	leaf, err := smx509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析TLS证书失败: %w", err)
+	}
+	return &Stapler{cert: cert, leaf: leaf, issuer: issuer, checker: checker}, nil
+}
+
+// Refresh 立即查询并更新装订响应
+// 返回:
+//   - error: 查询失败，或证书状态不是有效时返回错误
+func (s *Stapler) Refresh() error {
+	s.mu.Lock()
+	s.refreshing = true
+	s.mu.Unlock()
+
+	resp, err := s.checker.Fetch(s.leaf, s.issuer)
+	if err == nil && resp.Status != Good {
+		err = fmt.Errorf("证书状态为 %s，不装订OCSP响应", resp.Status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	if err != nil {
+		s.nextAttempt = time.Now().Add(stapleRetryInterval)
+		return err
+	}
+	s.resp = resp
+	s.refreshAt = refreshTime(resp, s.checker.opts.CacheTTL)
+	return nil
+}
+
+// refreshTime 计算响应的刷新时间：有效期过半，未指定下次更新时间时按缓存时间刷新
+func refreshTime(resp *Response, ttl time.Duration) time.Time {
+	if resp.NextUpdate.IsZero() {
+		return time.Now().Add(ttl)
+	}
+	return resp.ThisUpdate.Add(resp.NextUpdate.Sub(resp.ThisUpdate) / 2)
+}
+
+// GetCertificate 返回携带装订响应的证书，可直接用作 tls.Config.GetCertificate
+// 注意: 不会阻塞握手，需要刷新时在后台查询，没有有效响应时返回不带装订响应的证书
+func (s *Stapler) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	resp := s.resp
+	if !s.refreshing && now.After(s.nextAttempt) && (resp == nil || now.After(s.refreshAt)) {
+		s.refreshing = true
+		go func() {
+			if err := s.Refresh(); err != nil && s.checker.opts.OnError != nil {
+				s.checker.opts.OnError(s.leaf, err)
+			}
+		}()
+	}
+	s.mu.Unlock()
+
+	cert := s.cert
+	if resp != nil && (resp.NextUpdate.IsZero() || now.Before(resp.NextUpdate)) {
+		cert.OCSPStaple = resp.Raw
+	}
+	return &cert, nil
+}
@@ -0,0 +1,376 @@
+package renewal
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Trisia/tlcpchan/logger"
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/keystore"
+)
+
+// DefaultInterval 默认检查间隔
+const DefaultInterval = time.Hour
+
+// Options 证书自动续期选项
+type Options struct {
+	// Interval 检查间隔，默认 DefaultInterval
+	Interval time.Duration
+}
+
+// Result 单个 keystore 的续期结果
+type Result struct {
+	// Name keystore 名称
+	Name string `json:"name"`
+	// Issuer 签发证书的 CA keystore 名称
+	Issuer string `json:"issuer"`
+	// NotAfter 续期后证书的过期时间，TLCP keystore 为签名证书与加密证书中较早的过期时间
+	NotAfter time.Time `json:"notAfter"`
+	// RotatedKey 是否生成了新密钥
+	RotatedKey bool `json:"rotatedKey"`
+}
+
+// Renewer 证书自动续期，定期检查设置了续期策略的 keystore，
+// 证书剩余有效期少于策略的提前天数时由签发 CA 重新签发，并重新加载 keystore
+type Renewer struct {
+	keyStoreMgr *keystore.Manager
+	opts        Options
+
+	// renewMu 串行化续期，避免同一 keystore 被并发续期
+	renewMu sync.Mutex
+	mu      sync.Mutex
+	stop    chan struct{}
+}
+
+// NewRenewer 创建证书自动续期
+// 参数:
+//   - keyStoreMgr: keystore 管理器
+//   - opts: 续期选项，未设置的字段使用默认值
+func NewRenewer(keyStoreMgr *keystore.Manager, opts Options) *Renewer {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &Renewer{keyStoreMgr: keyStoreMgr, opts: opts}
+}
+
+// Start 立即检查一次并启动定期检查
+// 注意: 重复调用会先停止之前的检查任务，调用 Close 停止
+func (r *Renewer) Start() {
+	r.Close()
+	r.Check()
+
+	stop := make(chan struct{})
+	r.mu.Lock()
+	r.stop = stop
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(r.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.Check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止定期检查
+func (r *Renewer) Close() {
+	r.mu.Lock()
+	stop := r.stop
+	r.stop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+	}
+}
+
+// Check 续期所有即将过期的 keystore 证书
+// 返回:
+//   - []*Result: 本次续期成功的 keystore
+//
+// 注意: 续期失败只记录日志，下次检查时重试
+func (r *Renewer) Check() []*Result {
+	results := make([]*Result, 0)
+	for _, info := range r.keyStoreMgr.List() {
+		if info.Renewal == nil || info.Issuer == "" {
+			continue
+		}
+		result, err := r.renew(info.Name, false)
+		if err != nil {
+			logger.Error("自动续期 keystore %s 的证书失败: %v", info.Name, err)
+			continue
+		}
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Renew 立即续期 keystore 的证书，不论剩余有效期
+// 参数:
+//   - name: keystore 名称，必须记录了签发 CA
+//
+// 返回:
+//   - *Result: 续期结果
+//   - error: 续期失败时返回错误，此时保留原有的证书
+func (r *Renewer) Renew(name string) (*Result, error) {
+	return r.renew(name, true)
+}
+
+// issued 文件 keystore 中的一张证书及其私钥
+type issued struct {
+	cert     *x509.Certificate
+	key      crypto.PrivateKey
+	certPath string
+	keyPath  string
+}
+
+// renew 续期 keystore 的证书，force 为 false 且证书未到续期时间时返回 nil
+func (r *Renewer) renew(name string, force bool) (*Result, error) {
+	r.renewMu.Lock()
+	defer r.renewMu.Unlock()
+
+	info, err := r.keyStoreMgr.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.Issuer == "" {
+		return nil, fmt.Errorf("keystore %s 未记录签发 CA", name)
+	}
+	if info.LoaderType != keystore.LoaderTypeFile {
+		return nil, fmt.Errorf("只有文件类型的 keystore 支持续期")
+	}
+	policy := keystore.RenewalPolicy{}
+	if info.Renewal != nil {
+		policy = *info.Renewal
+	}
+	if policy.BeforeDays <= 0 {
+		policy.BeforeDays = keystore.DefaultRenewBeforeDays
+	}
+
+	tlcp := info.Type == keystore.KeyStoreTypeTLCP
+	current, err := loadIssued(info.Params, tlcp)
+	if err != nil {
+		return nil, err
+	}
+	if !force && time.Until(earliest(current)) >= time.Duration(policy.BeforeDays)*24*time.Hour {
+		return nil, nil
+	}
+
+	caCert, caKey, err := r.loadIssuer(info.Issuer, tlcp)
+	if err != nil {
+		return nil, err
+	}
+	// 新证书的有效期与原证书相同，但不超过签发 CA 的有效期
+	caDays := int(math.Floor(time.Until(caCert.NotAfter).Hours() / 24))
+	if caDays < 1 {
+		return nil, fmt.Errorf("签发 CA %s 的证书已过期或剩余不足1天", info.Issuer)
+	}
+
+	cfgs := make([]certgen.CertGenConfig, len(current))
+	for i, c := range current {
+		cfgs[i] = certConfig(c.cert, caDays)
+		if !policy.RotateKey {
+			cfgs[i].Key = c.key
+		}
+		// 新证书签发后仍在续期时间内，定期检查时会反复续期
+		if !force && cfgs[i].Days <= policy.BeforeDays {
+			return nil, fmt.Errorf("新证书的有效期(%d天)不大于续期提前天数(%d天)", cfgs[i].Days, policy.BeforeDays)
+		}
+	}
+
+	var generated []*certgen.GeneratedCert
+	if tlcp {
+		cfgs[0].Type, cfgs[1].Type = certgen.CertTypeTLCPSign, certgen.CertTypeTLCPEnc
+		signCert, encCert, err := certgen.GenerateTLCPPair(caCert, caKey, cfgs[0], cfgs[1])
+		if err != nil {
+			return nil, err
+		}
+		generated = []*certgen.GeneratedCert{signCert, encCert}
+	} else {
+		cfgs[0].Type = certgen.CertTypeTLS
+		switch k := current[0].key.(type) {
+		case *rsa.PrivateKey:
+			cfgs[0].KeyAlgorithm, cfgs[0].KeyBits = certgen.KeyAlgorithmRSA, k.N.BitLen()
+		case *ecdsa.PrivateKey:
+			cfgs[0].KeyAlgorithm = certgen.KeyAlgorithmECDSA
+		}
+		tlsCert, err := certgen.GenerateTLSCert(caCert, caKey, cfgs[0])
+		if err != nil {
+			return nil, err
+		}
+		generated = []*certgen.GeneratedCert{tlsCert}
+	}
+
+	if err := r.replace(name, current, generated, policy.RotateKey); err != nil {
+		return nil, err
+	}
+
+	renewed, err := loadIssued(info.Params, tlcp)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{
+		Name:       name,
+		Issuer:     info.Issuer,
+		NotAfter:   earliest(renewed),
+		RotatedKey: policy.RotateKey,
+	}
+	logger.Info("已续期 keystore %s 的证书, 签发 CA %s, 新证书过期时间 %s",
+		name, info.Issuer, result.NotAfter.Format(time.RFC3339))
+	return result, nil
+}
+
+// replace 写入新证书（及新密钥）并重新加载 keystore，失败时恢复原有的文件
+func (r *Renewer) replace(name string, current []issued, generated []*certgen.GeneratedCert, rotateKey bool) error {
+	files := make(map[string][]byte)
+	backup := make(map[string][]byte)
+	for i, c := range current {
+		files[c.certPath] = generated[i].CertPEM
+		// 沿用原有密钥时保留原密钥文件的格式
+		if rotateKey {
+			files[c.keyPath] = generated[i].KeyPEM
+		}
+	}
+	for path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", path, err)
+		}
+		backup[path] = data
+	}
+
+	restore := func() {
+		for path, data := range backup {
+			if err := writeFile(path, data); err != nil {
+				logger.Error("恢复 %s 失败: %v", path, err)
+			}
+		}
+	}
+	for path, data := range files {
+		if err := writeFile(path, data); err != nil {
+			restore()
+			return fmt.Errorf("写入 %s 失败: %w", path, err)
+		}
+	}
+	// 重新加载后引用该 keystore 的实例自动使用新证书
+	if _, err := r.keyStoreMgr.Reload(name, nil); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// writeFile 写入文件，保留原有的文件权限
+func writeFile(path string, data []byte) error {
+	perm := os.FileMode(0600)
+	if st, err := os.Stat(path); err == nil {
+		perm = st.Mode().Perm()
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// loadIssued 读取文件 keystore 的证书与私钥，TLCP keystore 依次为签名证书与加密证书
+func loadIssued(params map[string]string, tlcp bool) ([]issued, error) {
+	pairs := [][2]string{{params["sign-cert"], params["sign-key"]}}
+	if tlcp {
+		pairs = append(pairs, [2]string{params["enc-cert"], params["enc-key"]})
+	}
+	result := make([]issued, 0, len(pairs))
+	for _, pair := range pairs {
+		var cert *x509.Certificate
+		var key crypto.PrivateKey
+		var err error
+		if tlcp {
+			cert, key, err = certgen.LoadTLCPCertFromFile(pair[0], pair[1])
+		} else {
+			cert, key, err = certgen.LoadTLSCertFromFile(pair[0], pair[1])
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, issued{cert: cert, key: key, certPath: pair[0], keyPath: pair[1]})
+	}
+	return result, nil
+}
+
+// earliest 返回证书中最早的过期时间
+func earliest(items []issued) time.Time {
+	notAfter := items[0].cert.NotAfter
+	for _, c := range items[1:] {
+		if c.cert.NotAfter.Before(notAfter) {
+			notAfter = c.cert.NotAfter
+		}
+	}
+	return notAfter
+}
+
+// loadIssuer 读取签发 CA 的证书与私钥
+func (r *Renewer) loadIssuer(name string, tlcp bool) (*x509.Certificate, crypto.PrivateKey, error) {
+	info, err := r.keyStoreMgr.Get(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("签发 CA: %w", err)
+	}
+	if info.LoaderType != keystore.LoaderTypeFile {
+		return nil, nil, fmt.Errorf("签发 CA %s 不是文件类型的 keystore", name)
+	}
+	var cert *x509.Certificate
+	var key crypto.PrivateKey
+	if tlcp {
+		cert, key, err = certgen.LoadTLCPCertFromFile(info.Params["sign-cert"], info.Params["sign-key"])
+	} else {
+		cert, key, err = certgen.LoadTLSCertFromFile(info.Params["sign-cert"], info.Params["sign-key"])
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("加载签发 CA %s 失败: %w", name, err)
+	}
+	if !cert.IsCA {
+		return nil, nil, fmt.Errorf("keystore %s 的证书不是 CA 证书", name)
+	}
+	return cert, key, nil
+}
+
+// certConfig 按原证书的主题、备用名称与有效期生成签发配置，有效期不超过 maxDays
+func certConfig(cert *x509.Certificate, maxDays int) certgen.CertGenConfig {
+	days := int(math.Round(cert.NotAfter.Sub(cert.NotBefore).Hours() / 24))
+	if days < 1 {
+		days = 1
+	}
+	if days > maxDays {
+		days = maxDays
+	}
+	first := func(v []string) string {
+		if len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	cfg := certgen.CertGenConfig{
+		CommonName:      cert.Subject.CommonName,
+		Country:         first(cert.Subject.Country),
+		StateOrProvince: first(cert.Subject.Province),
+		Locality:        first(cert.Subject.Locality),
+		Org:             first(cert.Subject.Organization),
+		OrgUnit:         first(cert.Subject.OrganizationalUnit),
+		Days:            days,
+		DNSNames:        cert.DNSNames,
+	}
+	for _, ip := range cert.IPAddresses {
+		cfg.IPAddresses = append(cfg.IPAddresses, ip.String())
+	}
+	return cfg
+}
@@ -0,0 +1,210 @@
+package renewal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/Trisia/tlcpchan/security/keystore"
+)
+
+// newTLCPKeyStore 生成 TLCP 根 CA 及其签发的证书对，并创建 "ca" 与 "gm-server" 两个 keystore
+func newTLCPKeyStore(t *testing.T, days int, policy *keystore.RenewalPolicy) (*keystore.Manager, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	caParams := map[string]string{"sign-cert": filepath.Join(dir, "ca.crt"), "sign-key": filepath.Join(dir, "ca.key")}
+	ca, err := certgen.GenerateTLCPRootCA(certgen.CertGenConfig{CommonName: "renewal-ca"})
+	if err != nil {
+		t.Fatalf("生成根证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(ca.CertPEM, ca.KeyPEM, caParams["sign-cert"], caParams["sign-key"]); err != nil {
+		t.Fatalf("保存根证书失败: %v", err)
+	}
+	caCert, caKey, err := certgen.LoadTLCPCertFromFile(caParams["sign-cert"], caParams["sign-key"])
+	if err != nil {
+		t.Fatalf("加载根证书失败: %v", err)
+	}
+
+	signCert, encCert, err := certgen.GenerateTLCPPair(caCert, caKey,
+		certgen.CertGenConfig{CommonName: "gm-server-sign", OrgUnit: "gw", Days: days, DNSNames: []string{"gm.example.com"}},
+		certgen.CertGenConfig{CommonName: "gm-server-enc", OrgUnit: "gw", Days: days, DNSNames: []string{"gm.example.com"}})
+	if err != nil {
+		t.Fatalf("签发证书失败: %v", err)
+	}
+	params := map[string]string{
+		"sign-cert": filepath.Join(dir, "sign.crt"),
+		"sign-key":  filepath.Join(dir, "sign.key"),
+		"enc-cert":  filepath.Join(dir, "enc.crt"),
+		"enc-key":   filepath.Join(dir, "enc.key"),
+	}
+	if err := certgen.SaveCertToFile(signCert.CertPEM, signCert.KeyPEM, params["sign-cert"], params["sign-key"]); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(encCert.CertPEM, encCert.KeyPEM, params["enc-cert"], params["enc-key"]); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+
+	mgr := keystore.NewManager()
+	if err := mgr.LoadFromConfigs([]keystore.ConfigEntry{
+		{Name: "ca", Type: keystore.LoaderTypeFile, Params: caParams},
+		{Name: "gm-server", Type: keystore.LoaderTypeFile, Params: params, Issuer: "ca", Renewal: policy},
+	}); err != nil {
+		t.Fatalf("加载 keystore 失败: %v", err)
+	}
+	return mgr, params
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取 %s 失败: %v", path, err)
+	}
+	return data
+}
+
+func TestRenewer_Renew(t *testing.T) {
+	mgr, params := newTLCPKeyStore(t, 20, &keystore.RenewalPolicy{})
+	oldSign, _, _ := certgen.LoadTLCPCertFromFile(params["sign-cert"], params["sign-key"])
+	oldSignKey := readFile(t, params["sign-key"])
+
+	var notified []string
+	mgr.Subscribe(func(name string) { notified = append(notified, name) })
+
+	r := NewRenewer(mgr, Options{})
+	result, err := r.Renew("gm-server")
+	if err != nil {
+		t.Fatalf("续期失败: %v", err)
+	}
+	if result.Issuer != "ca" || result.RotatedKey {
+		t.Errorf("续期结果 = %+v", result)
+	}
+	if len(notified) != 1 || notified[0] != "gm-server" {
+		t.Errorf("续期后应重新加载 keystore 并通知订阅方, 实际通知 %v", notified)
+	}
+
+	for _, usage := range []string{"sign", "enc"} {
+		cert, _, err := certgen.LoadTLCPCertFromFile(params[usage+"-cert"], params[usage+"-key"])
+		if err != nil {
+			t.Fatalf("加载续期后的 %s 证书失败: %v", usage, err)
+		}
+		if cert.Subject.CommonName != "gm-server-"+usage || len(cert.Subject.OrganizationalUnit) != 1 ||
+			len(cert.DNSNames) != 1 || cert.DNSNames[0] != "gm.example.com" {
+			t.Errorf("续期后的 %s 证书主题或备用名称不一致: %s %v", usage, cert.Subject, cert.DNSNames)
+		}
+		if days := cert.NotAfter.Sub(cert.NotBefore).Hours() / 24; days != 20 {
+			t.Errorf("续期后的 %s 证书有效期 = %.0f 天, 期望与原证书相同的 20 天", usage, days)
+		}
+	}
+	newSign, _, _ := certgen.LoadTLCPCertFromFile(params["sign-cert"], params["sign-key"])
+	if newSign.SerialNumber.Cmp(oldSign.SerialNumber) == 0 {
+		t.Error("续期后证书序列号应变化")
+	}
+	if !bytes.Equal(readFile(t, params["sign-key"]), oldSignKey) {
+		t.Error("未设置 rotate-key 时应沿用原有密钥")
+	}
+	if !result.NotAfter.Equal(newSign.NotAfter) {
+		t.Errorf("续期结果的过期时间 = %s, 期望 %s", result.NotAfter, newSign.NotAfter)
+	}
+
+	// 生成新密钥
+	if err := mgr.SetIssuer("gm-server", "ca", &keystore.RenewalPolicy{RotateKey: true}); err != nil {
+		t.Fatalf("设置续期策略失败: %v", err)
+	}
+	if _, err := r.Renew("gm-server"); err != nil {
+		t.Fatalf("续期失败: %v", err)
+	}
+	if bytes.Equal(readFile(t, params["sign-key"]), oldSignKey) {
+		t.Error("设置 rotate-key 时应生成新密钥")
+	}
+	ks, err := mgr.GetKeyStore("gm-server")
+	if err != nil {
+		t.Fatalf("获取 keystore 失败: %v", err)
+	}
+	if _, err := ks.TLCPCertificate(); err != nil {
+		t.Errorf("续期后加载证书失败: %v", err)
+	}
+
+	if _, err := r.Renew("ca"); err == nil {
+		t.Error("未记录签发 CA 的 keystore 续期应失败")
+	}
+}
+
+func TestRenewer_Check(t *testing.T) {
+	// 剩余有效期充足，无需续期
+	mgr, params := newTLCPKeyStore(t, 365, &keystore.RenewalPolicy{BeforeDays: 30})
+	before := readFile(t, params["sign-cert"])
+	r := NewRenewer(mgr, Options{})
+	if results := r.Check(); len(results) != 0 {
+		t.Errorf("未到续期时间不应续期: %+v", results)
+	}
+
+	// 新证书签发后仍在续期时间内，拒绝续期
+	if err := mgr.SetIssuer("gm-server", "ca", &keystore.RenewalPolicy{BeforeDays: 400}); err != nil {
+		t.Fatalf("设置续期策略失败: %v", err)
+	}
+	if results := r.Check(); len(results) != 0 {
+		t.Errorf("新证书有效期不大于续期提前天数时不应续期: %+v", results)
+	}
+	if !bytes.Equal(readFile(t, params["sign-cert"]), before) {
+		t.Error("续期失败时不应修改证书文件")
+	}
+
+	// 未设置续期策略的 keystore 不检查
+	if err := mgr.SetIssuer("gm-server", "ca", nil); err != nil {
+		t.Fatalf("设置续期策略失败: %v", err)
+	}
+	if results := r.Check(); len(results) != 0 {
+		t.Errorf("未设置续期策略时不应续期: %+v", results)
+	}
+}
+
+func TestRenewer_RenewTLS(t *testing.T) {
+	dir := t.TempDir()
+	caParams := map[string]string{"sign-cert": filepath.Join(dir, "ca.crt"), "sign-key": filepath.Join(dir, "ca.key")}
+	ca, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "renewal-tls-ca"})
+	if err != nil {
+		t.Fatalf("生成根证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(ca.CertPEM, ca.KeyPEM, caParams["sign-cert"], caParams["sign-key"]); err != nil {
+		t.Fatalf("保存根证书失败: %v", err)
+	}
+	caCert, caKey, err := certgen.LoadTLSCertFromFile(caParams["sign-cert"], caParams["sign-key"])
+	if err != nil {
+		t.Fatalf("加载根证书失败: %v", err)
+	}
+	leaf, err := certgen.GenerateTLSCert(caCert, caKey, certgen.CertGenConfig{
+		CommonName: "web", Days: 10, KeyAlgorithm: certgen.KeyAlgorithmECDSA, IPAddresses: []string{"127.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("签发证书失败: %v", err)
+	}
+	params := map[string]string{"sign-cert": filepath.Join(dir, "web.crt"), "sign-key": filepath.Join(dir, "web.key")}
+	if err := certgen.SaveCertToFile(leaf.CertPEM, leaf.KeyPEM, params["sign-cert"], params["sign-key"]); err != nil {
+		t.Fatalf("保存证书失败: %v", err)
+	}
+
+	mgr := keystore.NewManager()
+	if err := mgr.LoadFromConfigs([]keystore.ConfigEntry{
+		{Name: "tls-ca", Type: keystore.LoaderTypeFile, Params: caParams},
+		{Name: "web", Type: keystore.LoaderTypeFile, Params: params, Issuer: "tls-ca", Renewal: &keystore.RenewalPolicy{RotateKey: true}},
+	}); err != nil {
+		t.Fatalf("加载 keystore 失败: %v", err)
+	}
+
+	if _, err := NewRenewer(mgr, Options{}).Renew("web"); err != nil {
+		t.Fatalf("续期失败: %v", err)
+	}
+	cert, _, err := certgen.LoadTLSCertFromFile(params["sign-cert"], params["sign-key"])
+	if err != nil {
+		t.Fatalf("加载续期后的证书失败: %v", err)
+	}
+	if len(cert.IPAddresses) != 1 || !cert.IPAddresses[0].Equal([]byte{127, 0, 0, 1}) {
+		t.Errorf("续期后的证书 IP 备用名称 = %v", cert.IPAddresses)
+	}
+	if err := cert.CheckSignatureFrom(caCert); err != nil {
+		t.Errorf("续期后的证书应由签发 CA 签发: %v", err)
+	}
+}
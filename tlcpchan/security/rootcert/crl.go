@@ -0,0 +1,295 @@
+package rootcert
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// crlExtension 证书吊销列表文件扩展名
+const crlExtension = ".crl"
+
+// DefaultCRLReloadInterval 默认CRL定期重载间隔
+const DefaultCRLReloadInterval = 10 * time.Minute
+
+// CRL 证书吊销列表
+type CRL struct {
+	Filename     string    `json:"filename" yaml:"filename"`         // CRL文件名
+	Issuer       string    `json:"issuer" yaml:"issuer"`             // CRL签发者
+	ThisUpdate   time.Time `json:"thisUpdate" yaml:"thisUpdate"`     // 本次更新时间
+	NextUpdate   time.Time `json:"nextUpdate" yaml:"nextUpdate"`     // 下次更新时间，零值表示未指定
+	Number       string    `json:"number" yaml:"number"`             // CRL序号（十六进制）
+	RevokedCount int       `json:"revokedCount" yaml:"revokedCount"` // 吊销证书数量
+
+	list      *smx509.RevocationList
+	rawIssuer string
+	revoked   map[string]time.Time // 证书序列号（十六进制） -> 吊销时间
+
+	mu       sync.Mutex
+	verified map[string]error // 签发者证书DER -> 签名验证结果
+}
+
+// IsRevoked 判断证书序列号是否在吊销列表中
+// 参数:
+//   - serial: 证书序列号（十六进制）
+//
+// 返回:
+//   - time.Time: 吊销时间
+//   - bool: 是否已吊销
+func (c *CRL) IsRevoked(serial string) (time.Time, bool) {
+	at, ok := c.revoked[serial]
+	return at, ok
+}
+
+// checkSignatureFrom 验证CRL由指定证书签发，结果按签发者缓存
+func (c *CRL) checkSignatureFrom(issuer *smx509.Certificate) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := string(issuer.Raw)
+	if err, ok := c.verified[key]; ok {
+		return err
+	}
+	err := c.list.CheckSignatureFrom(issuer)
+	if c.verified == nil {
+		c.verified = make(map[string]error)
+	}
+	c.verified[key] = err
+	return err
+}
+
+// parseCRL 解析CRL，支持 PEM("X509 CRL") 与 DER 编码，签名算法支持 SM2 与 RSA/ECDSA
+func parseCRL(data []byte, filename string) (*CRL, error) {
+	der := data
+	if block, _ := pem.Decode(data); block != nil {
+		der = block.Bytes
+	}
+
+	list, err := smx509.ParseRevocationList(der)
+	if err != nil {
+		return nil, fmt.Errorf("解析CRL失败: %w", err)
+	}
+
+	crl := &CRL{
+		Filename:     filename,
+		Issuer:       list.Issuer.String(),
+		ThisUpdate:   list.ThisUpdate,
+		NextUpdate:   list.NextUpdate,
+		RevokedCount: len(list.RevokedCertificateEntries),
+		list:         list,
+		rawIssuer:    string(list.RawIssuer),
+		revoked:      make(map[string]time.Time, len(list.RevokedCertificateEntries)),
+	}
+	if list.Number != nil {
+		crl.Number = hex.EncodeToString(list.Number.Bytes())
+	}
+	for _, entry := range list.RevokedCertificateEntries {
+		if entry.SerialNumber == nil {
+			continue
+		}
+		crl.revoked[hex.EncodeToString(entry.SerialNumber.Bytes())] = entry.RevocationTime
+	}
+	return crl, nil
+}
+
+// AddCRL 添加证书吊销列表（校验格式后保存到根证书目录并重新加载）
+// 参数:
+//   - filename: CRL文件名，扩展名必须为 .crl
+//   - data: CRL内容，PEM 或 DER 编码
+//
+// 返回:
+//   - *CRL: 解析后的CRL信息
+//   - error: 文件名非法、解析失败或写入失败时返回错误
+func (m *Manager) AddCRL(filename string, data []byte) (*CRL, error) {
+	if filename != filepath.Base(filename) || strings.ToLower(filepath.Ext(filename)) != crlExtension {
+		return nil, fmt.Errorf("CRL文件名 %s 无效，扩展名必须为 %s", filename, crlExtension)
+	}
+	if _, err := parseCRL(data, filename); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.baseDir, 0700); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.baseDir, filename), data, 0600); err != nil {
+		return nil, fmt.Errorf("写入CRL失败: %w", err)
+	}
+
+	m.loadCRLs()
+	return m.crls[filename], nil
+}
+
+// DeleteCRL 删除证书吊销列表
+func (m *Manager) DeleteCRL(filename string) error {
+	if strings.ToLower(filepath.Ext(filename)) != crlExtension {
+		return fmt.Errorf("CRL %s 不存在", filename)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.Remove(filepath.Join(m.baseDir, filename)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除CRL失败: %w", err)
+	}
+	m.loadCRLs()
+	return nil
+}
+
+// ListCRLs 列出所有已加载的证书吊销列表
+func (m *Manager) ListCRLs() []*CRL {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*CRL, 0, len(m.crls))
+	for _, crl := range m.crls {
+		result = append(result, crl)
+	}
+	return result
+}
+
+// ReloadCRLs 重新加载根证书目录中的所有CRL
+func (m *Manager) ReloadCRLs() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loadCRLs()
+}
+
+// StartCRLReload 启动CRL定期重载
+// 参数:
+//   - interval: 重载间隔，小于等于0时使用 DefaultCRLReloadInterval
+//
+// 注意: 重复调用会先停止之前的重载任务，调用 Close 停止
+func (m *Manager) StartCRLReload(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCRLReloadInterval
+	}
+
+	m.mu.Lock()
+	if m.stopReload != nil {
+		close(m.stopReload)
+	}
+	stop := make(chan struct{})
+	m.stopReload = stop
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.ReloadCRLs()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止CRL定期重载
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopReload != nil {
+		close(m.stopReload)
+		m.stopReload = nil
+	}
+}
+
+// CheckRevocation 检查已验证证书链中的证书是否被吊销
+// 参数:
+//   - verifiedChains: 握手过程中验证通过的证书链，每条链以对端证书开始、以根证书结束
+//
+// 返回:
+//   - error: 任一证书出现在其签发者的CRL中时返回错误
+//
+// 注意:
+//   - 只使用签名能由链中上级证书验证通过的CRL，签名无效的CRL会被忽略
+//   - 证书的签发者没有对应CRL时视为未吊销
+func (m *Manager) CheckRevocation(verifiedChains [][]*smx509.Certificate) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.crls) == 0 {
+		return nil
+	}
+
+	for _, chain := range verifiedChains {
+		for i := 0; i+1 < len(chain); i++ {
+			cert, issuer := chain[i], chain[i+1]
+			serial := hex.EncodeToString(cert.SerialNumber.Bytes())
+			for _, crl := range m.crls {
+				if crl.rawIssuer != string(cert.RawIssuer) {
+					continue
+				}
+				at, revoked := crl.IsRevoked(serial)
+				if !revoked {
+					continue
+				}
+				if crl.checkSignatureFrom(issuer) != nil {
+					continue
+				}
+				return fmt.Errorf("证书 %s（序列号 %s）已于 %s 被吊销",
+					cert.Subject.String(), serial, at.Format(time.RFC3339))
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRevocationX509 检查标准库证书链中的证书是否被吊销，用于TLS
+func (m *Manager) CheckRevocationX509(verifiedChains [][]*x509.Certificate) error {
+	chains := make([][]*smx509.Certificate, len(verifiedChains))
+	for i, chain := range verifiedChains {
+		chains[i] = make([]*smx509.Certificate, len(chain))
+		for j, cert := range chain {
+			chains[i][j] = (*smx509.Certificate)(cert)
+		}
+	}
+	return m.CheckRevocation(chains)
+}
+
+// loadCRLs 加载根证书目录中的所有CRL，解析失败的文件会被跳过
+// 注意: 调用方需持有写锁
+func (m *Manager) loadCRLs() {
+	m.crls = make(map[string]*CRL)
+
+	if m.baseDir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(m.baseDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.ToLower(filepath.Ext(entry.Name())) != crlExtension {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.baseDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		crl, err := parseCRL(data, entry.Name())
+		if err != nil {
+			continue
+		}
+		m.crls[entry.Name()] = crl
+	}
+}
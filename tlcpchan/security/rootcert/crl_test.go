@@ -0,0 +1,175 @@
+package rootcert
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+	"github.com/emmansun/gmsm/smx509"
+)
+
+// testCA 测试用CA及其签发的两张证书
+type testCA struct {
+	cert    *smx509.Certificate
+	key     crypto.Signer
+	certPEM []byte
+	leaves  []*smx509.Certificate
+}
+
+func newTestCA(t *testing.T, tlcp bool) *testCA {
+	t.Helper()
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "ca.crt")
+	keyPath := filepath.Join(dir, "ca.key")
+
+	gen, load := certgen.GenerateTLSRootCA, certgen.LoadTLSCertFromFile
+	if tlcp {
+		gen, load = certgen.GenerateTLCPRootCA, certgen.LoadTLCPCertFromFile
+	}
+	root, err := gen(certgen.CertGenConfig{CommonName: "crl-test-ca"})
+	if err != nil {
+		t.Fatalf("生成根证书失败: %v", err)
+	}
+	if err := certgen.SaveCertToFile(root.CertPEM, root.KeyPEM, certPath, keyPath); err != nil {
+		t.Fatalf("保存根证书失败: %v", err)
+	}
+	caCert, caKey, err := load(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("加载根证书失败: %v", err)
+	}
+
+	ca := &testCA{cert: (*smx509.Certificate)(caCert), key: caKey.(crypto.Signer), certPEM: root.CertPEM}
+	for _, cn := range []string{"leaf-1", "leaf-2"} {
+		var leaf *certgen.GeneratedCert
+		if tlcp {
+			leaf, _, err = certgen.GenerateTLCPPair(caCert, caKey, certgen.CertGenConfig{CommonName: cn}, certgen.CertGenConfig{})
+		} else {
+			leaf, err = certgen.GenerateTLSCert(caCert, caKey, certgen.CertGenConfig{CommonName: cn})
+		}
+		if err != nil {
+			t.Fatalf("签发证书失败: %v", err)
+		}
+		block, _ := pem.Decode(leaf.CertPEM)
+		cert, err := smx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("解析证书失败: %v", err)
+		}
+		ca.leaves = append(ca.leaves, cert)
+	}
+	return ca
+}
+
+// crlPEM 由CA签发吊销指定证书的CRL
+func (ca *testCA) crlPEM(t *testing.T, revoked ...*smx509.Certificate) []byte {
+	t.Helper()
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(24 * time.Hour),
+	}
+	for _, cert := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+	der, err := smx509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	if err != nil {
+		t.Fatalf("生成CRL失败: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func TestCheckRevocation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tlcp bool
+	}{
+		{"SM2签名CRL", true},
+		{"RSA签名CRL", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ca := newTestCA(t, tc.tlcp)
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "ca.crt"), ca.certPEM, 0600); err != nil {
+				t.Fatalf("写入证书失败: %v", err)
+			}
+			m := NewManager(dir)
+			if err := m.Initialize(); err != nil {
+				t.Fatalf("初始化根证书管理器失败: %v", err)
+			}
+
+			revokedChain := [][]*smx509.Certificate{{ca.leaves[0], ca.cert}}
+			validChain := [][]*smx509.Certificate{{ca.leaves[1], ca.cert}}
+
+			if err := m.CheckRevocation(revokedChain); err != nil {
+				t.Fatalf("未加载CRL时不应拒绝证书: %v", err)
+			}
+
+			crl, err := m.AddCRL("ca.crl", ca.crlPEM(t, ca.leaves[0]))
+			if err != nil {
+				t.Fatalf("AddCRL() error = %v", err)
+			}
+			if crl.RevokedCount != 1 {
+				t.Errorf("吊销证书数量应为1, 实际为 %d", crl.RevokedCount)
+			}
+
+			if err := m.CheckRevocation(revokedChain); err == nil {
+				t.Error("出现在CRL中的证书应被拒绝")
+			}
+			if err := m.CheckRevocation(validChain); err != nil {
+				t.Errorf("未吊销的证书不应被拒绝: %v", err)
+			}
+			x509Chain := [][]*x509.Certificate{{ca.leaves[0].ToX509(), ca.cert.ToX509()}}
+			if err := m.CheckRevocationX509(x509Chain); err == nil {
+				t.Error("TLS证书链中被吊销的证书应被拒绝")
+			}
+
+			if err := m.DeleteCRL("ca.crl"); err != nil {
+				t.Fatalf("DeleteCRL() error = %v", err)
+			}
+			if err := m.CheckRevocation(revokedChain); err != nil {
+				t.Errorf("删除CRL后不应拒绝证书: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckRevocationIgnoresForgedCRL(t *testing.T) {
+	ca := newTestCA(t, true)
+	forger := newTestCA(t, true)
+
+	// 伪造者使用相同的主题名称签发CRL，签名无法由真实CA验证
+	m := NewManager(t.TempDir())
+	if _, err := m.AddCRL("forged.crl", forger.crlPEM(t, ca.leaves[0])); err != nil {
+		t.Fatalf("AddCRL() error = %v", err)
+	}
+	if err := m.CheckRevocation([][]*smx509.Certificate{{ca.leaves[0], ca.cert}}); err != nil {
+		t.Errorf("签名无效的CRL应被忽略: %v", err)
+	}
+}
+
+func TestAddCRLInvalid(t *testing.T) {
+	m := NewManager(t.TempDir())
+	ca := newTestCA(t, false)
+
+	if _, err := m.AddCRL("ca.crt", ca.crlPEM(t)); err == nil {
+		t.Error("扩展名不是 .crl 时应返回错误")
+	}
+	if _, err := m.AddCRL("../ca.crl", ca.crlPEM(t)); err == nil {
+		t.Error("文件名包含路径时应返回错误")
+	}
+	if _, err := m.AddCRL("ca.crl", ca.certPEM); err == nil {
+		t.Error("内容不是CRL时应返回错误")
+	}
+	if len(m.ListCRLs()) != 0 {
+		t.Error("添加失败时不应加载CRL")
+	}
+}
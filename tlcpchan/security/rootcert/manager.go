@@ -1,6 +1,7 @@
 package rootcert
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
@@ -10,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -25,7 +27,12 @@ type Manager struct {
 	certs      map[string]*RootCert
 	certPool   *x509.CertPool
 	smCertPool *smx509.CertPool
-	mu         sync.RWMutex
+	crls       map[string]*CRL
+	stopReload chan struct{}
+	// listeners 根证书变化的订阅方
+	listeners    map[int]func() []string
+	nextListener int
+	mu           sync.RWMutex
 }
 
 // NewManager 创建根证书管理器
@@ -35,10 +42,12 @@ func NewManager(baseDir string) *Manager {
 		certs:      make(map[string]*RootCert),
 		certPool:   x509.NewCertPool(),
 		smCertPool: smx509.NewCertPool(),
+		crls:       make(map[string]*CRL),
+		listeners:  make(map[int]func() []string),
 	}
 }
 
-// Initialize 初始化管理器，加载指定目录中的所有根证书
+// Initialize 初始化管理器，加载指定目录中的所有根证书和CRL
 func (m *Manager) Initialize() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -49,35 +58,44 @@ func (m *Manager) Initialize() error {
 // Add 添加根证书（保存到目录并重新加载）
 func (m *Manager) Add(filename string, certData []byte) (*RootCert, error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	if err := os.MkdirAll(m.baseDir, 0700); err != nil {
+		m.mu.Unlock()
 		return nil, fmt.Errorf("创建目录失败: %w", err)
 	}
 
 	certPath := filepath.Join(m.baseDir, filename)
 	if err := os.WriteFile(certPath, certData, 0600); err != nil {
+		m.mu.Unlock()
 		return nil, fmt.Errorf("写入证书失败: %w", err)
 	}
 
-	if err := m.loadAllCerts(); err != nil {
+	err := m.loadAllCerts()
+	cert := m.certs[filename]
+	m.mu.Unlock()
+
+	m.notify()
+	if err != nil {
 		return nil, err
 	}
-
-	return m.certs[filename], nil
+	return cert, nil
 }
 
 // Delete 删除根证书
 func (m *Manager) Delete(filename string) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	certPath := filepath.Join(m.baseDir, filename)
 	if err := os.Remove(certPath); err != nil && !os.IsNotExist(err) {
+		m.mu.Unlock()
 		return fmt.Errorf("删除证书失败: %w", err)
 	}
 
-	return m.loadAllCerts()
+	err := m.loadAllCerts()
+	m.mu.Unlock()
+
+	m.notify()
+	return err
 }
 
 // Get 获取根证书
@@ -116,12 +134,148 @@ func (m *Manager) GetPool() RootCertPool {
 	}
 }
 
-// Reload 重新加载所有根证书
-func (m *Manager) Reload() error {
+// GetPoolFor 获取只包含指定根证书的证书池
+// 参数:
+//   - filenames: 根证书文件名列表，对应根证书目录中的文件
+//
+// 返回:
+//   - RootCertPool: 仅包含指定根证书的证书池
+//   - error: 任一根证书不存在时返回错误
+//
+// 注意: 每次调用都会创建新的证书池，实例之间互不影响
+func (m *Manager) GetPoolFor(filenames []string) (RootCertPool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pool := &rootCertPool{
+		certPool:   x509.NewCertPool(),
+		smCertPool: smx509.NewCertPool(),
+		certs:      make(map[string]*RootCert, len(filenames)),
+	}
+	for _, filename := range filenames {
+		cert, err := m.lookup(filename)
+		if err != nil {
+			return nil, err
+		}
+		pool.certs[cert.Filename] = cert
+		pool.certPool.AddCert(cert.Cert)
+		if smCert, err := smx509.ParseCertificate(cert.Cert.Raw); err == nil {
+			pool.smCertPool.AddCert(smCert)
+		}
+	}
+	return pool, nil
+}
+
+// CheckFiles 检查指定的根证书是否都已加载
+// 参数:
+//   - filenames: 根证书文件名列表
+//
+// 返回:
+//   - error: 任一根证书不存在时返回错误
+func (m *Manager) CheckFiles(filenames []string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, filename := range filenames {
+		if _, err := m.lookup(filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindIssuer 在已加载的根证书中查找证书的签发者
+// 参数:
+//   - cert: 待查找签发者的证书
+//
+// 返回:
+//   - *smx509.Certificate: 签发者证书
+//   - bool: 是否找到主题匹配且能验证证书签名的根证书
+func (m *Manager) FindIssuer(cert *smx509.Certificate) (*smx509.Certificate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, root := range m.certs {
+		if !bytes.Equal(root.Cert.RawSubject, cert.RawIssuer) {
+			continue
+		}
+		issuer := (*smx509.Certificate)(root.Cert)
+		if cert.CheckSignatureFrom(issuer) == nil {
+			return issuer, true
+		}
+	}
+	return nil, false
+}
+
+// lookup 按文件名查找根证书，兼容带目录前缀的写法
+// 注意: 调用方需持有读锁
+func (m *Manager) lookup(filename string) (*RootCert, error) {
+	if cert, ok := m.certs[filename]; ok {
+		return cert, nil
+	}
+	if cert, ok := m.certs[filepath.Base(filename)]; ok {
+		return cert, nil
+	}
+	return nil, fmt.Errorf("根证书 %s 不存在", filename)
+}
+
+// Reload 重新加载所有根证书和CRL并通知订阅方
+// 返回:
+//   - []string: 各订阅方返回的处理结果（如替换证书池成功的实例名称），去重后按名称排序，无结果时为空切片
+//   - error: 重新加载失败时返回错误，此时仍会通知订阅方，证书池以管理器当前状态为准
+func (m *Manager) Reload() ([]string, error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	err := m.loadAllCerts()
+	m.mu.Unlock()
+	return m.notify(), err
+}
 
-	return m.loadAllCerts()
+// Subscribe 订阅根证书变化
+// 参数:
+//   - fn: Add、Delete、Reload 重建证书池后调用，重新加载失败时同样调用，证书池以管理器当前状态为准；
+//     返回的处理结果由 Reload 汇总后返回给调用方，无结果时返回 nil
+//
+// 返回:
+//   - func(): 取消订阅
+//
+// 注意: fn 在修改根证书的协程中同步调用，返回时订阅方已完成处理；耗时的处理应自行启动协程
+func (m *Manager) Subscribe(fn func() []string) func() {
+	m.mu.Lock()
+	id := m.nextListener
+	m.nextListener++
+	m.listeners[id] = fn
+	m.mu.Unlock()
+
+	return func() {
+		m.mu.Lock()
+		delete(m.listeners, id)
+		m.mu.Unlock()
+	}
+}
+
+// notify 通知订阅方根证书已变化
+// 返回:
+//   - []string: 各订阅方返回的处理结果，去重后按名称排序
+func (m *Manager) notify() []string {
+	m.mu.RLock()
+	listeners := make([]func() []string, 0, len(m.listeners))
+	for _, fn := range m.listeners {
+		listeners = append(listeners, fn)
+	}
+	m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	results := make([]string, 0)
+	for _, fn := range listeners {
+		for _, name := range fn() {
+			if !seen[name] {
+				seen[name] = true
+				results = append(results, name)
+			}
+		}
+	}
+	sort.Strings(results)
+	return results
 }
 
 // ReadFile 读取证书文件内容
@@ -140,6 +294,7 @@ func (m *Manager) loadAllCerts() error {
 	m.certs = make(map[string]*RootCert)
 	m.certPool = x509.NewCertPool()
 	m.smCertPool = smx509.NewCertPool()
+	m.loadCRLs()
 
 	if m.baseDir == "" {
 		return nil
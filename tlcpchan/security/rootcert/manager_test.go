@@ -0,0 +1,115 @@
+package rootcert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Trisia/tlcpchan/security/certgen"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+
+	tlcpCA, err := certgen.GenerateTLCPRootCA(certgen.CertGenConfig{CommonName: "tenant-a-ca"})
+	if err != nil {
+		t.Fatalf("生成TLCP根证书失败: %v", err)
+	}
+	tlsCA, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "tenant-b-ca"})
+	if err != nil {
+		t.Fatalf("生成TLS根证书失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tenant-a.crt"), tlcpCA.CertPEM, 0600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tenant-b.crt"), tlsCA.CertPEM, 0600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+
+	m := NewManager(dir)
+	if err := m.Initialize(); err != nil {
+		t.Fatalf("初始化根证书管理器失败: %v", err)
+	}
+	return m
+}
+
+func TestGetPoolFor(t *testing.T) {
+	m := newTestManager(t)
+
+	pool, err := m.GetPoolFor([]string{"tenant-a.crt"})
+	if err != nil {
+		t.Fatalf("GetPoolFor() error = %v", err)
+	}
+	certs := pool.GetCerts()
+	if len(certs) != 1 || certs[0].Filename != "tenant-a.crt" {
+		t.Fatalf("证书池应只包含 tenant-a.crt, 实际为 %v", certs)
+	}
+	if n := len(pool.GetCertPool().Subjects()); n != 1 {
+		t.Errorf("x509 证书池应只包含1个证书, 实际为 %d", n)
+	}
+	if n := len(pool.GetSMCertPool().Subjects()); n != 1 {
+		t.Errorf("SM 证书池应只包含1个证书, 实际为 %d", n)
+	}
+
+	if _, err := m.GetPoolFor([]string{"rootcerts/tenant-b.crt"}); err != nil {
+		t.Errorf("带目录前缀的文件名应按文件名匹配: %v", err)
+	}
+
+	if _, err := m.GetPoolFor([]string{"tenant-a.crt", "missing.crt"}); err == nil {
+		t.Error("引用不存在的根证书时应返回错误")
+	}
+	if err := m.CheckFiles([]string{"missing.crt"}); err == nil {
+		t.Error("CheckFiles 应报告不存在的根证书")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	m := newTestManager(t)
+
+	calls := 0
+	cancel := m.Subscribe(func() []string {
+		calls++
+		// 订阅方在通知时可以读取最新的证书池
+		m.GetPool()
+		return []string{"b", "a"}
+	})
+	// 多个订阅方的处理结果由 Reload 汇总
+	defer m.Subscribe(func() []string { return []string{"a", "c"} })()
+
+	ca, err := certgen.GenerateTLSRootCA(certgen.CertGenConfig{CommonName: "tenant-c-ca"})
+	if err != nil {
+		t.Fatalf("生成TLS根证书失败: %v", err)
+	}
+	if _, err := m.Add("tenant-c.crt", ca.CertPEM); err != nil {
+		t.Fatalf("添加根证书失败: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Add 后通知次数 = %d, 期望 1", calls)
+	}
+	if err := m.Delete("tenant-c.crt"); err != nil {
+		t.Fatalf("删除根证书失败: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Delete 后通知次数 = %d, 期望 2", calls)
+	}
+	results, err := m.Reload()
+	if err != nil {
+		t.Fatalf("重新加载根证书失败: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Reload 后通知次数 = %d, 期望 3", calls)
+	}
+	if strings.Join(results, ",") != "a,b,c" {
+		t.Errorf("Reload 返回的处理结果 = %v, 期望 [a b c]", results)
+	}
+
+	cancel()
+	if _, err := m.Reload(); err != nil {
+		t.Fatalf("重新加载根证书失败: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("取消订阅后不应再通知, 通知次数 = %d", calls)
+	}
+}
@@ -1,7 +1,10 @@
 package security
 
 import (
+	"github.com/Trisia/tlcpchan/security/ca"
+	"github.com/Trisia/tlcpchan/security/expiry"
 	"github.com/Trisia/tlcpchan/security/keystore"
+	"github.com/Trisia/tlcpchan/security/renewal"
 	"github.com/Trisia/tlcpchan/security/rootcert"
 )
 
@@ -15,6 +18,14 @@ type (
 	RootCert        = rootcert.RootCert
 	RootCertPool    = rootcert.RootCertPool
 	RootCertManager = rootcert.Manager
+	CRL             = rootcert.CRL
+	ExpiryMonitor   = expiry.Monitor
+	ExpiryOptions   = expiry.Options
+	ExpiryReport    = expiry.Report
+	RenewalPolicy   = keystore.RenewalPolicy
+	Renewer         = renewal.Renewer
+	RenewalOptions  = renewal.Options
+	CertAuthority   = ca.Authority
 )
 
 const (
@@ -26,6 +37,9 @@ const (
 	LoaderTypeSDF    = keystore.LoaderTypeSDF
 	KeyTypeSign      = keystore.KeyTypeSign
 	KeyTypeEnc       = keystore.KeyTypeEnc
+
+	DefaultCRLReloadInterval     = rootcert.DefaultCRLReloadInterval
+	DefaultKeyStoreWatchInterval = keystore.DefaultWatchInterval
 )
 
 func NewKeyStoreManager() *KeyStoreManager {
@@ -35,3 +49,15 @@ func NewKeyStoreManager() *KeyStoreManager {
 func NewRootCertManager(baseDir string) *RootCertManager {
 	return rootcert.NewManager(baseDir)
 }
+
+func NewExpiryMonitor(keyStoreMgr *KeyStoreManager, rootCertMgr *RootCertManager, opts ExpiryOptions) *ExpiryMonitor {
+	return expiry.NewMonitor(keyStoreMgr, rootCertMgr, opts)
+}
+
+func NewRenewer(keyStoreMgr *KeyStoreManager, opts RenewalOptions) *Renewer {
+	return renewal.NewRenewer(keyStoreMgr, opts)
+}
+
+func NewCertAuthority(keyStoreMgr *KeyStoreManager, dir string) *CertAuthority {
+	return ca.NewAuthority(keyStoreMgr, dir)
+}
@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,12 +23,20 @@ type Snapshot struct {
 	Requests int64 `json:"requests"`
 	// Errors 累计错误数
 	Errors int64 `json:"errors"`
+	// HandshakeFailures 累计握手失败数
+	HandshakeFailures int64 `json:"handshakeFailures"`
 	// AvgLatency 平均延迟，单位: 纳秒
 	AvgLatency int64 `json:"avgLatencyNs"`
 	// MaxLatency 最大延迟，单位: 纳秒
 	MaxLatency int64 `json:"maxLatencyNs"`
 	// MinLatency 最小延迟，单位: 纳秒
 	MinLatency int64 `json:"minLatencyNs"`
+	// DialLatency 连接目标服务耗时分位数
+	DialLatency Percentiles `json:"dialLatency"`
+	// HandshakeLatency TLCP/TLS握手耗时分位数
+	HandshakeLatency Percentiles `json:"handshakeLatency"`
+	// SessionDuration 会话持续时长分位数
+	SessionDuration Percentiles `json:"sessionDuration"`
 }
 
 // Collector 统计信息收集器
@@ -39,11 +48,17 @@ type Collector struct {
 	bytesSent         atomic.Int64
 	requests          atomic.Int64
 	errors            atomic.Int64
+	handshakeFailures atomic.Int64
 
 	latencySum   atomic.Int64
 	latencyCount atomic.Int64
 	maxLatency   atomic.Int64
 	minLatency   atomic.Int64
+	latencyHist  *Histogram
+
+	dialHist      *Histogram
+	handshakeHist *Histogram
+	sessionHist   *Histogram
 
 	snapshots    []Snapshot
 	snapshotsMu  sync.RWMutex
@@ -52,6 +67,12 @@ type Collector struct {
 	stopChan chan struct{}
 	wg       sync.WaitGroup
 	mu       sync.Mutex
+
+	// parent 父收集器，非nil时统计数据同时汇总到父收集器
+	parent *Collector
+
+	children   map[string]*Collector
+	childrenMu sync.RWMutex
 }
 
 var (
@@ -62,6 +83,8 @@ var (
 // DefaultCollector 获取默认统计收集器单例
 // 返回:
 //   - *Collector: 默认统计收集器实例
+//
+// 注意: 默认收集器同时作为进程级汇总，各实例收集器的数据都会汇总到此处
 func DefaultCollector() *Collector {
 	once.Do(func() {
 		defaultCollector = NewCollector(1000)
@@ -80,12 +103,86 @@ func NewCollector(maxSnapshots int) *Collector {
 		snapshots:    make([]Snapshot, 0, maxSnapshots),
 		maxSnapshots: maxSnapshots,
 		stopChan:     make(chan struct{}),
+		latencyHist:  NewHistogram(nil),
+
+		dialHist:      NewHistogram(nil),
+		handshakeHist: NewHistogram(nil),
+		sessionHist:   NewHistogram(nil),
 	}
+	// 已关闭的 stopChan 表示快照调度器未运行
+	close(c.stopChan)
 	c.enabled.Store(true)
 	c.minLatency.Store(-1)
 	return c
 }
 
+// NewChildCollector 创建汇总到父收集器的统计收集器
+// 参数:
+//   - parent: 父收集器，为nil时等同于 NewCollector
+//   - maxSnapshots: 最大快照保留数量
+//
+// 返回:
+//   - *Collector: 统计收集器实例
+//
+// 注意: 子收集器禁用时，其数据也不会汇总到父收集器
+func NewChildCollector(parent *Collector, maxSnapshots int) *Collector {
+	c := NewCollector(maxSnapshots)
+	c.parent = parent
+	return c
+}
+
+// Parent 获取父收集器
+func (c *Collector) Parent() *Collector {
+	return c.parent
+}
+
+// Child 获取指定名称的子收集器，不存在时创建
+// 参数:
+//   - name: 子收集器名称，如协商协议 "tlcp"/"tls"
+//
+// 返回:
+//   - *Collector: 子收集器，数据同时汇总到当前收集器
+//
+// 注意: 子收集器用于按维度细分统计，不运行快照调度器
+func (c *Collector) Child(name string) *Collector {
+	c.childrenMu.RLock()
+	child, ok := c.children[name]
+	c.childrenMu.RUnlock()
+	if ok {
+		return child
+	}
+
+	c.childrenMu.Lock()
+	defer c.childrenMu.Unlock()
+	if child, ok := c.children[name]; ok {
+		return child
+	}
+	if c.children == nil {
+		c.children = make(map[string]*Collector)
+	}
+	child = NewChildCollector(c, c.maxSnapshots)
+	c.children[name] = child
+	return child
+}
+
+// Children 获取所有子收集器
+// 返回:
+//   - []string: 子收集器名称，按字典序排列
+//   - map[string]*Collector: 名称到子收集器的映射副本
+func (c *Collector) Children() ([]string, map[string]*Collector) {
+	c.childrenMu.RLock()
+	defer c.childrenMu.RUnlock()
+
+	names := make([]string, 0, len(c.children))
+	children := make(map[string]*Collector, len(c.children))
+	for name, child := range c.children {
+		names = append(names, name)
+		children[name] = child
+	}
+	sort.Strings(names)
+	return names, children
+}
+
 func (c *Collector) Enable() {
 	c.enabled.Store(true)
 }
@@ -98,61 +195,102 @@ func (c *Collector) IsEnabled() bool {
 	return c.enabled.Load()
 }
 
+// active 判断是否记录统计数据，任一上级收集器被禁用时同样不记录
+func (c *Collector) active() bool {
+	for x := c; x != nil; x = x.parent {
+		if !x.enabled.Load() {
+			return false
+		}
+	}
+	return true
+}
+
+// IncrementConnections 记录新建连接，禁用时只更新活跃连接数
+// 注意: 活跃连接数不受启用状态影响，避免连接期间切换启用状态导致增减不对称
 func (c *Collector) IncrementConnections() {
-	if !c.enabled.Load() {
-		return
+	counted := c.active()
+	for x := c; x != nil; x = x.parent {
+		if counted {
+			x.totalConnections.Add(1)
+		}
+		x.activeConnections.Add(1)
 	}
-	c.totalConnections.Add(1)
-	c.activeConnections.Add(1)
 }
 
+// DecrementConnections 记录连接关闭，与 IncrementConnections 成对调用
 func (c *Collector) DecrementConnections() {
-	if !c.enabled.Load() {
-		return
+	for x := c; x != nil; x = x.parent {
+		x.activeConnections.Add(-1)
 	}
-	c.activeConnections.Add(-1)
 }
 
 func (c *Collector) AddBytesReceived(n int64) {
-	if !c.enabled.Load() {
+	if !c.active() {
 		return
 	}
 	c.bytesReceived.Add(n)
+	if c.parent != nil {
+		c.parent.AddBytesReceived(n)
+	}
 }
 
 func (c *Collector) AddBytesSent(n int64) {
-	if !c.enabled.Load() {
+	if !c.active() {
 		return
 	}
 	c.bytesSent.Add(n)
+	if c.parent != nil {
+		c.parent.AddBytesSent(n)
+	}
 }
 
 func (c *Collector) IncrementRequests() {
-	if !c.enabled.Load() {
+	if !c.active() {
 		return
 	}
 	c.requests.Add(1)
+	if c.parent != nil {
+		c.parent.IncrementRequests()
+	}
 }
 
 func (c *Collector) IncrementErrors() {
-	if !c.enabled.Load() {
+	if !c.active() {
 		return
 	}
 	c.errors.Add(1)
+	if c.parent != nil {
+		c.parent.IncrementErrors()
+	}
+}
+
+// IncrementHandshakeFailures 增加握手失败计数
+func (c *Collector) IncrementHandshakeFailures() {
+	if !c.active() {
+		return
+	}
+	c.handshakeFailures.Add(1)
+	if c.parent != nil {
+		c.parent.IncrementHandshakeFailures()
+	}
 }
 
 // RecordLatency 记录延迟数据
 // 参数:
-//   - latency: 延迟时间
+//   - latency: 延迟时间，TCP代理为连接建立耗时（握手和连接目标服务），HTTP代理为请求耗时
 //
-// 注意: 会自动更新最大/最小延迟
+// 注意: 会自动更新最大/最小延迟和延迟直方图
 func (c *Collector) RecordLatency(latency time.Duration) {
-	if !c.enabled.Load() {
+	if !c.active() {
 		return
 	}
+	if c.parent != nil {
+		c.parent.RecordLatency(latency)
+	}
 	ns := latency.Nanoseconds()
 	c.latencySum.Add(ns)
 	c.latencyCount.Add(1)
+	c.latencyHist.Observe(latency)
 
 	for {
 		current := c.maxLatency.Load()
@@ -175,6 +313,40 @@ func (c *Collector) RecordLatency(latency time.Duration) {
 	}
 }
 
+// RecordDialLatency 记录连接目标服务的耗时
+// 注意: 对于安全连接只包含TCP连接建立耗时，握手耗时由 RecordHandshakeLatency 记录
+func (c *Collector) RecordDialLatency(d time.Duration) {
+	if !c.active() {
+		return
+	}
+	c.dialHist.Observe(d)
+	if c.parent != nil {
+		c.parent.RecordDialLatency(d)
+	}
+}
+
+// RecordHandshakeLatency 记录TLCP/TLS握手耗时
+func (c *Collector) RecordHandshakeLatency(d time.Duration) {
+	if !c.active() {
+		return
+	}
+	c.handshakeHist.Observe(d)
+	if c.parent != nil {
+		c.parent.RecordHandshakeLatency(d)
+	}
+}
+
+// RecordSessionDuration 记录会话持续时长，即连接建立完成到连接关闭的时长
+func (c *Collector) RecordSessionDuration(d time.Duration) {
+	if !c.active() {
+		return
+	}
+	c.sessionHist.Observe(d)
+	if c.parent != nil {
+		c.parent.RecordSessionDuration(d)
+	}
+}
+
 // GetSnapshot 获取当前统计快照
 // 返回:
 //   - Snapshot: 当前统计信息快照
@@ -198,12 +370,36 @@ func (c *Collector) GetSnapshot() Snapshot {
 		BytesSent:         c.bytesSent.Load(),
 		Requests:          c.requests.Load(),
 		Errors:            c.errors.Load(),
+		HandshakeFailures: c.handshakeFailures.Load(),
 		AvgLatency:        avgLatency,
 		MaxLatency:        c.maxLatency.Load(),
 		MinLatency:        minLat,
+		DialLatency:       c.dialHist.Snapshot().Percentiles(),
+		HandshakeLatency:  c.handshakeHist.Snapshot().Percentiles(),
+		SessionDuration:   c.sessionHist.Snapshot().Percentiles(),
 	}
 }
 
+// LatencyHistogram 获取延迟直方图快照
+func (c *Collector) LatencyHistogram() HistogramSnapshot {
+	return c.latencyHist.Snapshot()
+}
+
+// DialLatencyHistogram 获取连接目标服务耗时直方图快照
+func (c *Collector) DialLatencyHistogram() HistogramSnapshot {
+	return c.dialHist.Snapshot()
+}
+
+// HandshakeLatencyHistogram 获取握手耗时直方图快照
+func (c *Collector) HandshakeLatencyHistogram() HistogramSnapshot {
+	return c.handshakeHist.Snapshot()
+}
+
+// SessionDurationHistogram 获取会话持续时长直方图快照
+func (c *Collector) SessionDurationHistogram() HistogramSnapshot {
+	return c.sessionHist.Snapshot()
+}
+
 // StartSnapshotScheduler 启动快照定时调度器
 // 参数:
 //   - interval: 快照间隔时间
@@ -292,11 +488,21 @@ func (c *Collector) Reset() {
 	c.bytesSent.Store(0)
 	c.requests.Store(0)
 	c.errors.Store(0)
+	c.handshakeFailures.Store(0)
 	c.latencySum.Store(0)
 	c.latencyCount.Store(0)
 	c.maxLatency.Store(0)
 	c.minLatency.Store(-1)
+	c.latencyHist.Reset()
+	c.dialHist.Reset()
+	c.handshakeHist.Reset()
+	c.sessionHist.Reset()
 	c.ClearSnapshots()
+
+	_, children := c.Children()
+	for _, child := range children {
+		child.Reset()
+	}
 }
 
 // Stats 统计信息DTO，用于API返回
@@ -313,12 +519,41 @@ type Stats struct {
 	Requests int64 `json:"requests"`
 	// Errors 累计错误数
 	Errors int64 `json:"errors"`
+	// HandshakeFailures 累计握手失败数
+	HandshakeFailures int64 `json:"handshakeFailures"`
 	// AvgLatencyMs 平均延迟，单位: 毫秒
 	AvgLatencyMs float64 `json:"avgLatencyMs"`
 	// MaxLatencyMs 最大延迟，单位: 毫秒
 	MaxLatencyMs float64 `json:"maxLatencyMs"`
 	// MinLatencyMs 最小延迟，单位: 毫秒
 	MinLatencyMs float64 `json:"minLatencyMs"`
+	// DialLatency 连接目标服务耗时分位数
+	DialLatency PercentilesMs `json:"dialLatency"`
+	// HandshakeLatency TLCP/TLS握手耗时分位数
+	HandshakeLatency PercentilesMs `json:"handshakeLatency"`
+	// SessionDuration 会话持续时长分位数
+	SessionDuration PercentilesMs `json:"sessionDuration"`
+}
+
+// PercentilesMs 分位数统计DTO，用于API返回
+type PercentilesMs struct {
+	// Count 观测总次数
+	Count int64 `json:"count"`
+	// P50Ms 50分位数，单位: 毫秒
+	P50Ms float64 `json:"p50Ms"`
+	// P90Ms 90分位数，单位: 毫秒
+	P90Ms float64 `json:"p90Ms"`
+	// P99Ms 99分位数，单位: 毫秒
+	P99Ms float64 `json:"p99Ms"`
+}
+
+func toPercentilesMs(p Percentiles) PercentilesMs {
+	return PercentilesMs{
+		Count: p.Count,
+		P50Ms: float64(p.P50) / 1e6,
+		P90Ms: float64(p.P90) / 1e6,
+		P99Ms: float64(p.P99) / 1e6,
+	}
 }
 
 func (c *Collector) GetStats() Stats {
@@ -330,9 +565,13 @@ func (c *Collector) GetStats() Stats {
 		BytesSent:         snapshot.BytesSent,
 		Requests:          snapshot.Requests,
 		Errors:            snapshot.Errors,
+		HandshakeFailures: snapshot.HandshakeFailures,
 		AvgLatencyMs:      float64(snapshot.AvgLatency) / 1e6,
 		MaxLatencyMs:      float64(snapshot.MaxLatency) / 1e6,
 		MinLatencyMs:      float64(snapshot.MinLatency) / 1e6,
+		DialLatency:       toPercentilesMs(snapshot.DialLatency),
+		HandshakeLatency:  toPercentilesMs(snapshot.HandshakeLatency),
+		SessionDuration:   toPercentilesMs(snapshot.SessionDuration),
 	}
 }
 
@@ -345,6 +584,7 @@ func AddBytesReceived(n int64)      { DefaultCollector().AddBytesReceived(n) }
 func AddBytesSent(n int64)          { DefaultCollector().AddBytesSent(n) }
 func IncrementRequests()            { DefaultCollector().IncrementRequests() }
 func IncrementErrors()              { DefaultCollector().IncrementErrors() }
+func IncrementHandshakeFailures()   { DefaultCollector().IncrementHandshakeFailures() }
 func RecordLatency(d time.Duration) { DefaultCollector().RecordLatency(d) }
 func GetStats() Stats               { return DefaultCollector().GetStats() }
 func GetSnapshot() Snapshot         { return DefaultCollector().GetSnapshot() }
@@ -460,3 +460,191 @@ func TestSnapshotSchedulerStartStop(t *testing.T) {
 		t.Errorf("快照调度器应生成至少1个快照, 实际为 %d", c.GetSnapshotsCount())
 	}
 }
+
+func TestChildCollectorAggregation(t *testing.T) {
+	parent := NewCollector(10)
+	a := NewChildCollector(parent, 10)
+	b := NewChildCollector(parent, 10)
+
+	a.IncrementConnections()
+	a.AddBytesSent(100)
+	b.IncrementConnections()
+	b.AddBytesReceived(50)
+	b.IncrementErrors()
+	b.DecrementConnections()
+
+	if got := a.GetSnapshot(); got.TotalConnections != 1 || got.Errors != 0 || got.BytesSent != 100 {
+		t.Errorf("子收集器 a 统计错误: %+v", got)
+	}
+	if got := b.GetSnapshot(); got.TotalConnections != 1 || got.ActiveConnections != 0 || got.Errors != 1 {
+		t.Errorf("子收集器 b 统计错误: %+v", got)
+	}
+
+	snapshot := parent.GetSnapshot()
+	if snapshot.TotalConnections != 2 {
+		t.Errorf("父收集器 TotalConnections 应为 2, 实际为 %d", snapshot.TotalConnections)
+	}
+	if snapshot.ActiveConnections != 1 {
+		t.Errorf("父收集器 ActiveConnections 应为 1, 实际为 %d", snapshot.ActiveConnections)
+	}
+	if snapshot.BytesSent != 100 || snapshot.BytesReceived != 50 {
+		t.Errorf("父收集器字节数错误: sent=%d received=%d", snapshot.BytesSent, snapshot.BytesReceived)
+	}
+	if snapshot.Errors != 1 {
+		t.Errorf("父收集器 Errors 应为 1, 实际为 %d", snapshot.Errors)
+	}
+}
+
+func TestDisabledChildCollector(t *testing.T) {
+	parent := NewCollector(10)
+	c := NewChildCollector(parent, 10)
+	c.Disable()
+
+	c.IncrementConnections()
+	c.IncrementRequests()
+
+	if got := parent.GetSnapshot().TotalConnections; got != 0 {
+		t.Errorf("禁用的子收集器不应汇总到父收集器, TotalConnections 实际为 %d", got)
+	}
+}
+
+func TestActiveConnectionsAcrossToggle(t *testing.T) {
+	parent := NewCollector(10)
+	c := NewChildCollector(parent, 10)
+
+	// 启用时建立、禁用后关闭
+	c.IncrementConnections()
+	c.Disable()
+	c.DecrementConnections()
+	// 禁用时建立、启用后关闭
+	c.IncrementConnections()
+	c.Enable()
+	c.DecrementConnections()
+
+	if got := c.GetSnapshot().ActiveConnections; got != 0 {
+		t.Errorf("切换启用状态后 ActiveConnections 应为 0, 实际为 %d", got)
+	}
+	if got := parent.GetSnapshot().ActiveConnections; got != 0 {
+		t.Errorf("父收集器 ActiveConnections 应为 0, 实际为 %d", got)
+	}
+	if got := c.GetSnapshot().TotalConnections; got != 1 {
+		t.Errorf("禁用期间的连接不应计入 TotalConnections, 实际为 %d", got)
+	}
+}
+
+func TestSnapshotSchedulerStartOnNewCollector(t *testing.T) {
+	c := NewCollector(10)
+
+	c.StartSnapshotScheduler(5 * time.Millisecond)
+	time.Sleep(40 * time.Millisecond)
+	c.StopSnapshotScheduler()
+
+	if c.GetSnapshotsCount() < 1 {
+		t.Errorf("新建收集器应可直接启动快照调度器, 快照数实际为 %d", c.GetSnapshotsCount())
+	}
+}
+
+func TestCollectorChild(t *testing.T) {
+	c := NewCollector(10)
+	tlcp := c.Child("tlcp")
+	if c.Child("tlcp") != tlcp {
+		t.Fatal("同名子收集器应复用同一实例")
+	}
+	tlcp.IncrementConnections()
+	tlcp.IncrementHandshakeFailures()
+	c.Child("tls").IncrementConnections()
+
+	names, children := c.Children()
+	if len(names) != 2 || names[0] != "tlcp" || names[1] != "tls" {
+		t.Errorf("子收集器名称应为 [tlcp tls], 实际为 %v", names)
+	}
+	if got := children["tls"].GetSnapshot().TotalConnections; got != 1 {
+		t.Errorf("tls 子收集器 TotalConnections 应为 1, 实际为 %d", got)
+	}
+	snapshot := c.GetSnapshot()
+	if snapshot.TotalConnections != 2 || snapshot.HandshakeFailures != 1 {
+		t.Errorf("父收集器汇总错误: connections=%d handshakeFailures=%d",
+			snapshot.TotalConnections, snapshot.HandshakeFailures)
+	}
+
+	c.Disable()
+	tlcp.IncrementConnections()
+	if got := tlcp.GetSnapshot().TotalConnections; got != 1 {
+		t.Errorf("父收集器禁用时子收集器不应记录, TotalConnections 实际为 %d", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]time.Duration{time.Millisecond, 10 * time.Millisecond})
+	h.Observe(500 * time.Microsecond)
+	h.Observe(time.Millisecond)
+	h.Observe(5 * time.Millisecond)
+	h.Observe(time.Second)
+
+	snap := h.Snapshot()
+	if snap.Count != 4 {
+		t.Errorf("Count 应为 4, 实际为 %d", snap.Count)
+	}
+	if snap.Buckets[0].Count != 2 || snap.Buckets[1].Count != 3 {
+		t.Errorf("桶累计计数错误: %+v", snap.Buckets)
+	}
+	want := (500*time.Microsecond + time.Millisecond + 5*time.Millisecond + time.Second).Nanoseconds()
+	if snap.Sum != want {
+		t.Errorf("Sum 应为 %d, 实际为 %d", want, snap.Sum)
+	}
+}
+
+func TestCollectorTimingPercentiles(t *testing.T) {
+	parent := NewCollector(10)
+	c := NewChildCollector(parent, 10)
+	for i := 1; i <= 100; i++ {
+		c.RecordHandshakeLatency(time.Duration(i) * time.Millisecond)
+	}
+	c.RecordDialLatency(2 * time.Millisecond)
+	c.RecordSessionDuration(3 * time.Second)
+
+	snapshot := c.GetSnapshot()
+	hs := snapshot.HandshakeLatency
+	if hs.Count != 100 {
+		t.Errorf("握手耗时观测次数应为 100, 实际为 %d", hs.Count)
+	}
+	if !(hs.P50 <= hs.P90 && hs.P90 <= hs.P99) {
+		t.Errorf("分位数应单调递增: %+v", hs)
+	}
+	if hs.P50 < int64(25*time.Millisecond) || hs.P50 > int64(100*time.Millisecond) {
+		t.Errorf("握手耗时 p50 应在 (25ms, 100ms] 桶内, 实际为 %v", time.Duration(hs.P50))
+	}
+	if hs.P99 < int64(50*time.Millisecond) || hs.P99 > int64(100*time.Millisecond) {
+		t.Errorf("握手耗时 p99 应在 (50ms, 100ms] 桶内, 实际为 %v", time.Duration(hs.P99))
+	}
+	if snapshot.DialLatency.Count != 1 || snapshot.SessionDuration.Count != 1 {
+		t.Errorf("连接/会话耗时观测次数错误: dial=%d session=%d",
+			snapshot.DialLatency.Count, snapshot.SessionDuration.Count)
+	}
+	if got := parent.GetSnapshot().HandshakeLatency.Count; got != 100 {
+		t.Errorf("父收集器握手耗时观测次数应为 100, 实际为 %d", got)
+	}
+
+	stats := c.GetStats()
+	if stats.SessionDuration.P50Ms <= 2500 || stats.SessionDuration.P50Ms > 5000 {
+		t.Errorf("会话时长 p50 应在 (2.5s, 5s] 桶内, 实际为 %vms", stats.SessionDuration.P50Ms)
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	h := NewHistogram([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond})
+	if got := h.Snapshot().Quantile(0.5); got != 0 {
+		t.Errorf("无观测数据时分位数应为 0, 实际为 %d", got)
+	}
+	for i := 0; i < 4; i++ {
+		h.Observe(15 * time.Millisecond)
+	}
+	// 4个观测值均落在 (10ms, 20ms] 桶内，p50 按线性插值为 15ms
+	if got := h.Snapshot().Quantile(0.5); got != int64(15*time.Millisecond) {
+		t.Errorf("p50 应为 15ms, 实际为 %v", time.Duration(got))
+	}
+	h.Observe(time.Second)
+	if got := h.Snapshot().Quantile(0.99); got != int64(20*time.Millisecond) {
+		t.Errorf("超出最大桶上界时应返回最大桶上界, 实际为 %v", time.Duration(got))
+	}
+}
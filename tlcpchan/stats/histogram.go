@@ -0,0 +1,148 @@
+package stats
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets 默认延迟直方图桶上界
+// 覆盖毫秒级的握手耗时到分钟级的长连接会话
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+}
+
+// Histogram 分桶直方图，并发安全
+type Histogram struct {
+	bounds []time.Duration
+	// counts 各桶的非累计计数，最后一个元素为超出最大桶上界的计数
+	counts []atomic.Int64
+	sum    atomic.Int64
+}
+
+// HistogramBucket 直方图桶
+type HistogramBucket struct {
+	// UpperBound 桶上界，单位: 纳秒
+	UpperBound int64 `json:"upperBoundNs"`
+	// Count 小于等于上界的累计观测次数
+	Count int64 `json:"count"`
+}
+
+// HistogramSnapshot 直方图快照
+type HistogramSnapshot struct {
+	// Buckets 各桶累计计数，按上界升序排列
+	Buckets []HistogramBucket `json:"buckets"`
+	// Count 观测总次数
+	Count int64 `json:"count"`
+	// Sum 观测值总和，单位: 纳秒
+	Sum int64 `json:"sumNs"`
+}
+
+// Percentiles 分位数统计
+type Percentiles struct {
+	// Count 观测总次数
+	Count int64 `json:"count"`
+	// P50 50分位数，单位: 纳秒
+	P50 int64 `json:"p50Ns"`
+	// P90 90分位数，单位: 纳秒
+	P90 int64 `json:"p90Ns"`
+	// P99 99分位数，单位: 纳秒
+	P99 int64 `json:"p99Ns"`
+}
+
+// NewHistogram 创建直方图
+// 参数:
+//   - bounds: 桶上界，必须升序排列，为空时使用 DefaultLatencyBuckets
+//
+// 返回:
+//   - *Histogram: 直方图实例
+func NewHistogram(bounds []time.Duration) *Histogram {
+	if len(bounds) == 0 {
+		bounds = DefaultLatencyBuckets
+	}
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Int64, len(bounds)+1),
+	}
+}
+
+// Observe 记录一次观测值
+func (h *Histogram) Observe(d time.Duration) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	h.counts[i].Add(1)
+	h.sum.Add(d.Nanoseconds())
+}
+
+// Snapshot 获取直方图快照
+// 注意: Count 由各桶计数累加得到，保证不小于最后一个桶的累计计数
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snap := HistogramSnapshot{
+		Buckets: make([]HistogramBucket, len(h.bounds)),
+		Sum:     h.sum.Load(),
+	}
+	var cumulative int64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		snap.Buckets[i] = HistogramBucket{UpperBound: bound.Nanoseconds(), Count: cumulative}
+	}
+	snap.Count = cumulative + h.counts[len(h.bounds)].Load()
+	return snap
+}
+
+// Reset 清空直方图
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.sum.Store(0)
+}
+
+// Quantile 估算分位数
+// 参数:
+//   - q: 分位点，取值范围 [0, 1]
+//
+// 返回:
+//   - int64: 分位数估算值，单位: 纳秒，无观测数据时返回0
+//
+// 注意: 在分位数所在桶内按线性插值估算，落在最大桶上界之外时返回最大桶上界
+func (s HistogramSnapshot) Quantile(q float64) int64 {
+	if s.Count == 0 || len(s.Buckets) == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+
+	var lower, prevCount int64
+	for _, b := range s.Buckets {
+		if float64(b.Count) >= rank && b.Count > prevCount {
+			fraction := (rank - float64(prevCount)) / float64(b.Count-prevCount)
+			return lower + int64(float64(b.UpperBound-lower)*fraction)
+		}
+		lower = b.UpperBound
+		prevCount = b.Count
+	}
+	return s.Buckets[len(s.Buckets)-1].UpperBound
+}
+
+// Percentiles 计算 p50/p90/p99 分位数
+func (s HistogramSnapshot) Percentiles() Percentiles {
+	return Percentiles{
+		Count: s.Count,
+		P50:   s.Quantile(0.50),
+		P90:   s.Quantile(0.90),
+		P99:   s.Quantile(0.99),
+	}
+}